@@ -0,0 +1,154 @@
+package xun
+
+import (
+	"fmt"
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// AccessFunc reports whether the client of the request has the access, e.g. a
+// permission such as "admin:view" of the current user. It is set by WithAccess.
+type AccessFunc func(r *http.Request, access string) bool
+
+// Navigation is an item of the navigation tree of the routes registered with WithNavigation.
+type Navigation struct {
+	Name     string
+	Icon     string
+	Access   string
+	Path     string
+	Children []*Navigation
+}
+
+// HasAccess reports whether the client of the request r has the access by the
+// AccessFunc set by WithAccess. An empty access is always granted, and any
+// other access is denied if no AccessFunc is set, or r is nil, e.g. a template
+// is executed without a request.
+func (app *App) HasAccess(r *http.Request, access string) bool {
+	if access == "" {
+		return true
+	}
+
+	if app.access == nil || r == nil {
+		return false
+	}
+
+	return app.access(r, access)
+}
+
+// Navigation returns the navigation tree of the routes registered with
+// WithNavigation that the client of the request r has access to. Items that
+// can't be accessed are omitted with their children. It is also available to
+// templates as the `nav` function.
+//
+//	{{ range nav }}<a href="{{ .Path }}">{{ .Name }}</a>{{ end }}
+func (app *App) Navigation(r *http.Request) []*Navigation {
+	var items []*Navigation
+
+	app.mu.RLock()
+	for _, it := range app.routes {
+		name := it.Options.GetString(NavigationName)
+		if name == "" {
+			continue
+		}
+
+		_, _, path := splitPattern(it.Pattern)
+		items = append(items, &Navigation{
+			Name:   name,
+			Icon:   it.Options.GetString(NavigationIcon),
+			Access: it.Options.GetString(NavigationAccess),
+			Path:   strings.TrimSuffix("/"+path, "{$}"),
+		})
+	}
+	app.mu.RUnlock()
+
+	return app.filterNavigation(r, navTree(items))
+}
+
+// filterNavigation removes the items that the client of r can't access.
+func (app *App) filterNavigation(r *http.Request, items []*Navigation) []*Navigation {
+	n := 0
+	for _, it := range items {
+		if !app.HasAccess(r, it.Access) {
+			continue
+		}
+
+		it.Children = app.filterNavigation(r, it.Children)
+		items[n] = it
+		n++
+	}
+
+	return items[:n]
+}
+
+// navTree nests the navigation items by their paths, e.g. "/admin/users" in "/admin".
+func navTree(items []*Navigation) []*Navigation {
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Path < items[j].Path
+	})
+
+	var roots []*Navigation
+	for i, it := range items {
+		var parent *Navigation
+		for _, p := range items[:i] {
+			if p.Path == "/" || p.Path == it.Path {
+				continue
+			}
+
+			prefix := strings.TrimSuffix(p.Path, "/") + "/"
+			if strings.HasPrefix(it.Path, prefix) && (parent == nil || len(p.Path) > len(parent.Path)) {
+				parent = p
+			}
+		}
+
+		if parent == nil {
+			roots = append(roots, it)
+		} else {
+			parent.Children = append(parent.Children, it)
+		}
+	}
+
+	return roots
+}
+
+// componentAccess returns the access required by the component name. It is
+// the access set by WithComponentAccess, or the `access` of its front matter.
+func (app *App) componentAccess(t *HtmlTemplate) string {
+	if access, ok := app.componentAccesses[t.name]; ok {
+		return access
+	}
+
+	access, _ := t.meta["access"].(string)
+	return access
+}
+
+// component renders the component name with the data for the request r. It
+// renders nothing if the client of r doesn't have the access of the component.
+func (app *App) component(r *http.Request, name string, data ...any) (template.HTML, error) {
+	app.mu.RLock()
+	t, ok := app.components[name]
+	app.mu.RUnlock()
+
+	if !ok {
+		return "", fmt.Errorf("xun: component %q not found", name)
+	}
+
+	if !app.HasAccess(r, app.componentAccess(t)) {
+		return "", nil
+	}
+
+	var d any
+	if len(data) > 0 {
+		d = data[0]
+	}
+
+	buf := app.bufferPool().Get()
+	defer app.bufferPool().Put(buf)
+
+	if err := t.execute(buf, r, d); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil // nolint: gosec
+}
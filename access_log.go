@@ -0,0 +1,76 @@
+package xun
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// AccessLog returns a middleware that logs the requests by the logger of the
+// App, see WithLogger, with their method, path, status, latency, bytes, host
+// and htmx flags. Server errors are logged at the error level.
+//
+//	app := xun.New(xun.WithLogger(slog.New(slog.NewJSONHandler(os.Stdout, nil))))
+//	app.Use(xun.AccessLog())
+//
+// The request is logged once its response is finalized, with the status that
+// is written to the client, e.g. 400 Bad Request of a *BindError, or the
+// status written by the ErrorHandler. Panics are logged as 500 Internal Server
+// Error, whether they are recovered by Recovery or not.
+func AccessLog() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if c.accessStart.IsZero() {
+				c.accessStart = time.Now()
+			}
+			return next(c)
+		}
+	}
+}
+
+// logAccess logs the request of c with the response recorded by rw, the
+// error returned by the handlers and the value of an unrecovered panic.
+func (app *App) logAccess(c *Context, rw *statsResponseWriter, err error, p any) {
+	status := rw.statusCode
+	switch {
+	case p != nil:
+		// the connection is closed by the http.Server
+		status = http.StatusInternalServerError
+		err = &PanicError{Value: p}
+	case status == 0 && rw.hijacked:
+		status = http.StatusSwitchingProtocols
+	case status == 0:
+		status = http.StatusOK
+	}
+
+	hx := c.Htmx()
+	attrs := []slog.Attr{
+		slog.String("method", c.req.Method),
+		slog.String("path", c.req.URL.Path),
+		slog.Int("status", status),
+		slog.Duration("latency", time.Since(c.accessStart)),
+		slog.Int64("bytes", rw.bytesWritten),
+		slog.String("host", c.req.Host),
+		slog.Bool("htmx", hx.Request),
+		slog.Bool("boosted", hx.Boosted),
+	}
+	if hx.Target != "" {
+		attrs = append(attrs, slog.String("hx_target", hx.Target))
+	}
+
+	level := slog.LevelInfo
+	if status >= http.StatusInternalServerError {
+		level = slog.LevelError
+	}
+	if err != nil {
+		attrs = append(attrs, slog.Any("err", err))
+	}
+
+	app.logger.LogAttrs(c.Context(), level, "xun: access", attrs...)
+}
+
+// Logger returns the logger of the App, see WithLogger, so handlers and
+// middlewares share one structured logger.
+func (c *Context) Logger() *slog.Logger {
+	return c.app.logger
+}
@@ -0,0 +1,176 @@
+package xun
+
+import (
+	"bytes"
+	stdjson "encoding/json"
+	"errors"
+	"io"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+// entries returns the access log entries.
+func (b *syncBuffer) entries(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var items []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(b.buf.String()), "\n") {
+		var it map[string]any
+		require.NoError(t, stdjson.Unmarshal([]byte(line), &it))
+		if it["msg"] == "xun: access" {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+func TestAccessLog(t *testing.T) {
+	out := &syncBuffer{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithLogger(slog.New(slog.NewJSONHandler(out, nil))))
+	app.Use(AccessLog())
+
+	app.Get("/users", func(c *Context) error {
+		return c.View([]string{"xun"})
+	})
+
+	app.Get("/invalid", func(c *Context) error {
+		return &BindError{Message: "invalid query", Source: "query"}
+	})
+
+	app.Get("/boom", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/users", nil)
+	require.NoError(t, err)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Target", "list")
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	for _, path := range []string{"/invalid", "/boom"} {
+		resp, err = client.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	entries := out.entries(t)
+	require.Len(t, entries, 3)
+
+	it := entries[0]
+	require.Equal(t, "INFO", it["level"])
+	require.Equal(t, "GET", it["method"])
+	require.Equal(t, "/users", it["path"])
+	require.Equal(t, float64(http.StatusOK), it["status"])
+	require.Equal(t, float64(len(`["xun"]`)+1), it["bytes"])
+	require.Equal(t, strings.TrimPrefix(srv.URL, "http://"), it["host"])
+	require.Equal(t, true, it["htmx"])
+	require.Equal(t, false, it["boosted"])
+	require.Equal(t, "list", it["hx_target"])
+	require.Contains(t, it, "latency")
+
+	require.Equal(t, "INFO", entries[1]["level"])
+	require.Equal(t, float64(http.StatusBadRequest), entries[1]["status"])
+
+	require.Equal(t, "ERROR", entries[2]["level"])
+	require.Equal(t, float64(http.StatusInternalServerError), entries[2]["status"])
+	require.Equal(t, "boom", entries[2]["err"])
+}
+
+func TestAccessLogFinalized(t *testing.T) {
+	out := &syncBuffer{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithLogger(slog.New(slog.NewJSONHandler(out, nil))),
+		WithErrorHandler(func(c *Context, err error) {
+			c.WriteStatus(http.StatusTeapot)
+		}))
+	app.Use(AccessLog())
+
+	app.Get("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	app.Get("/handled", func(c *Context) error {
+		return errors.New("handled")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	for _, path := range []string{"/panic", "/handled"} {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+
+	entries := out.entries(t)
+	require.Len(t, entries, 2)
+
+	// the status written by the ErrorHandler instead of a guess
+	require.Equal(t, float64(http.StatusTeapot), entries[0]["status"])
+	require.Equal(t, "xun: panic: boom", entries[0]["err"])
+
+	require.Equal(t, float64(http.StatusTeapot), entries[1]["status"])
+	require.Equal(t, "handled", entries[1]["err"])
+
+	t.Run("without_recovery", func(t *testing.T) {
+		out := &syncBuffer{}
+
+		mux := http.NewServeMux()
+		srv := httptest.NewUnstartedServer(mux)
+		srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+		srv.Start()
+		defer srv.Close()
+
+		app := New(WithMux(mux), WithoutRecovery(), WithLogger(slog.New(slog.NewJSONHandler(out, nil))))
+		app.Use(AccessLog())
+
+		app.Get("/panic", func(c *Context) error {
+			panic("boom")
+		})
+
+		app.Start()
+		defer app.Close()
+
+		_, err := client.Get(srv.URL + "/panic")
+		require.Error(t, err)
+
+		entries := out.entries(t)
+		require.Len(t, entries, 1)
+		require.Equal(t, "ERROR", entries[0]["level"])
+		require.Equal(t, float64(http.StatusInternalServerError), entries[0]["status"])
+		require.Equal(t, "xun: panic: boom", entries[0]["err"])
+	})
+}
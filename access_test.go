@@ -0,0 +1,88 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccess(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/admin.html":  {Data: []byte("---\naccess: admin:view\n---\n<b>admin {{ . }}</b>")},
+		"components/public.html": {Data: []byte("<i>public</i>")},
+		"components/audit.html":  {Data: []byte("<u>audit</u>")},
+		"views/home.html": {Data: []byte(`{{ component "components/admin" "menu" }}{{ component "components/public" }}{{ component "components/audit" }}` +
+			`|{{ range nav }}{{ .Name }}[{{ range .Children }}{{ .Name }}{{ end }}]{{ end }}`)},
+		"views/missing.html": {Data: []byte(`{{ component "components/missing" }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys),
+		WithAccess(func(r *http.Request, access string) bool {
+			return strings.Contains(r.Header.Get("X-Roles"), access)
+		}),
+		WithComponentAccess("components/audit", "audit:view"))
+	defer app.Close()
+
+	app.Get("/{$}", func(c *Context) error {
+		return c.View(nil, "views/home")
+	}, WithNavigation("Home", "", ""))
+
+	app.Get("/missing", func(c *Context) error {
+		return c.View(nil, "views/missing")
+	})
+
+	app.Get("/admin", func(c *Context) error {
+		return nil
+	}, WithNavigation("Admin", "", "admin:view"))
+
+	app.Get("/admin/users", func(c *Context) error {
+		return nil
+	}, WithNavigation("Users", "", ""))
+
+	app.Get("/admin/audit", func(c *Context) error {
+		return nil
+	}, WithNavigation("Audit", "", "audit:view"))
+
+	app.Start()
+
+	get := func(path, roles string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("X-Roles", roles)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	_, body := get("/", "")
+	require.Equal(t, "<i>public</i>|Home[]", body)
+
+	_, body = get("/", "admin:view")
+	require.Equal(t, "<b>admin menu</b><i>public</i>|Home[]Admin[Users]", body)
+
+	_, body = get("/", "admin:view,audit:view")
+	require.Equal(t, "<b>admin menu</b><i>public</i><u>audit</u>|Home[]Admin[AuditUsers]", body)
+
+	// children are omitted with their parent
+	_, body = get("/", "audit:view")
+	require.Equal(t, "<i>public</i><u>audit</u>|Home[]", body)
+
+	status, _ := get("/missing", "")
+	require.Equal(t, http.StatusInternalServerError, status)
+
+	require.True(t, app.HasAccess(nil, ""))
+	require.False(t, app.HasAccess(nil, "admin:view"))
+}
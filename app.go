@@ -1,12 +1,18 @@
 package xun
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"html/template"
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/netip"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/yaitoo/xun/fsnotify"
 )
@@ -32,18 +38,85 @@ type Middleware func(next HandleFunc) HandleFunc
 type App struct {
 	mu sync.RWMutex
 
-	mux            *http.ServeMux
-	middlewares    []Middleware
-	viewers        map[string]Viewer
-	routes         map[string]*Routing
-	handlerViewers []Viewer
-	engines        []ViewEngine
-	logger         *slog.Logger
-	fsys           fs.FS
-	watch          bool
-	watcher        *fsnotify.Watcher
-	interceptor    Interceptor
-	compressors    []Compressor
+	mux             *http.ServeMux
+	middlewares     []Middleware
+	viewers         map[string]Viewer
+	routes          map[string]*Routing
+	handlerViewers  []Viewer
+	engines         []ViewEngine
+	logger          *slog.Logger
+	fsys            fs.FS
+	archive         string
+	watch           bool
+	watcher         *fsnotify.Watcher
+	interceptor     Interceptor
+	compressors     []Compressor
+	mimeTypes       map[string]string
+	ignores         []string
+	signKey         []byte
+	assetHost       string
+	fingerprint     bool
+	manifest        map[string]string
+	manifestFile    string
+	funcs           template.FuncMap
+	requestFuncs    requestFuncs
+	trustedProxies  []netip.Prefix
+	coalescer       *coalescer
+	closers         []func()
+	diagnostics     map[string]*TemplateDiagnostic
+	reporter        Reporter
+	console         Middleware
+	loaders         []loaderStats
+	streams         *streamSet
+	drainEvent      string
+	warmups         []func(ctx context.Context) error
+	ready           atomic.Bool
+	readyz          bool
+	mailSender      MailSender
+	pdfRenderer     PDFRenderer
+	layouts         map[string]*HtmlTemplate
+	components      map[string]*HtmlTemplate
+	pages           map[string]*Page
+	layoutRules     []LayoutRule
+	locales         []string
+	clock           Clock
+	defaultLocation *time.Location
+	exporters       map[string]Viewer
+
+	ctx             context.Context
+	cancel          context.CancelFunc
+	baseContext     bool
+	baseContextFunc func(r *http.Request) context.Context
+
+	staticMiddlewares []staticMiddleware
+	buildHooks        []*buildHook
+	access            AccessFunc
+	componentAccesses map[string]string
+	jsonOptions       *JsonOptions
+	validate          ValidateFunc
+	errorHandler      ErrorHandler
+	widgets           []*Widget
+	noRecovery        bool
+	cancelledErrors   bool
+	names             map[string]string // route name => pattern
+	jobWorkers        int
+	preconnects       []preconnect
+	preconnectHeader  string
+	bufPool           *BufferPool
+	requestIDHeader   string
+
+	deferred   sync.Map // map[string]*deferredSection
+	deferOnce  sync.Once
+	deferNonce func(c *Context) string
+
+	inlined sync.Map // map[string]template.HTML
+
+	jobStates       sync.Map // map[string]*jobState
+	jobQueue        chan *jobState
+	jobOnce         sync.Once
+	jobQueueSize    int
+	jobTTL          time.Duration
+	jobPollInterval time.Duration
 }
 
 // New allocates an App instance and loads all view engines.
@@ -54,7 +127,11 @@ type App struct {
 func New(opts ...Option) *App {
 	app := &App{
 		routes:         make(map[string]*Routing),
+		names:          make(map[string]string),
 		viewers:        make(map[string]Viewer),
+		layouts:        make(map[string]*HtmlTemplate),
+		components:     make(map[string]*HtmlTemplate),
+		exporters:      map[string]Viewer{"csv": &CsvViewer{}, "xlsx": &XlsxViewer{}},
 		handlerViewers: []Viewer{&JsonViewer{}},
 	}
 
@@ -69,6 +146,113 @@ func New(opts ...Option) *App {
 		app.mux = http.DefaultServeMux
 	}
 
+	if app.archive != "" {
+		fsys, err := OpenArchive(app.archive)
+		if err != nil {
+			app.logger.Error("xun: open archive", slog.String("name", app.archive), slog.Any("err", err))
+		} else {
+			app.fsys = fsys
+		}
+	}
+
+	if app.ctx == nil {
+		app.ctx = context.Background()
+	}
+	app.ctx, app.cancel = context.WithCancel(app.ctx)
+	app.closers = append(app.closers, app.cancel)
+
+	if app.reporter == nil {
+		app.reporter = nopReporter{}
+	}
+
+	if app.signKey == nil {
+		app.logger.Warn("xun: sign key is not set by WithSignKey, signed URLs are only valid for the current process")
+		app.signKey = newSignKey()
+	}
+
+	app.funcs = template.FuncMap{
+		"asset":   app.AssetURL,
+		"pages":   app.Collection,
+		"lazy":    lazy,
+		"url":     app.URL,
+		"urlArgs": app.URLArgs,
+
+		"inlineCSS":  app.InlineCSS,
+		"preconnect": app.Preconnect,
+	}
+
+	app.coalescer = newCoalescer()
+	app.streams = newStreamSet()
+
+	if app.requestIDHeader == "" {
+		app.requestIDHeader = DefaultRequestIDHeader
+	}
+
+	if app.drainEvent == "" {
+		app.drainEvent = DefaultDrainEvent
+	}
+
+	app.requestFuncs = requestFuncs{
+		"urlAbs": func(r *http.Request) any {
+			return func(path string) string {
+				return app.absoluteURL(r, path)
+			}
+		},
+		"now": func(r *http.Request) any {
+			return func() time.Time {
+				return app.Now().In(app.location(r))
+			}
+		},
+		"localTime": func(r *http.Request) any {
+			return func(t time.Time) time.Time {
+				return t.In(app.location(r))
+			}
+		},
+		"formatTime": func(r *http.Request) any {
+			return func(layout string, t time.Time) string {
+				return t.In(app.location(r)).Format(layout)
+			}
+		},
+		"page": func(r *http.Request) any {
+			return func() *Page {
+				return app.pageOf(r)
+			}
+		},
+		"paginate": func(r *http.Request) any {
+			return func(pages []*Page, size int) PageList {
+				return paginate(r, pages, size)
+			}
+		},
+		"nav": func(r *http.Request) any {
+			return func() []*Navigation {
+				return app.Navigation(r)
+			}
+		},
+		"component": func(r *http.Request) any {
+			return func(name string, data ...any) (template.HTML, error) {
+				return app.component(r, name, data...)
+			}
+		},
+		"deferred": func(r *http.Request) any {
+			return func(name string) template.HTML {
+				return deferred(r, name)
+			}
+		},
+		"widgets": func(r *http.Request) any {
+			return func(names ...string) template.HTML {
+				return app.Widgets(r, names...)
+			}
+		},
+	}
+
+	if app.console != nil {
+		app.handleConsole()
+	}
+
+	if app.readyz {
+		app.handleReadyz()
+	}
+
 	if app.engines == nil {
 		app.engines = []ViewEngine{
 			&StaticViewEngine{},
@@ -78,6 +262,10 @@ func New(opts ...Option) *App {
 	}
 
 	if app.fsys != nil {
+		if app.watch {
+			app.startBuildHooks()
+		}
+
 		for _, ve := range app.engines {
 			err := ve.Load(app.fsys, app)
 			if err != nil {
@@ -85,6 +273,10 @@ func New(opts ...Option) *App {
 			}
 		}
 
+		for _, d := range app.TemplateDiagnostics() {
+			app.logger.Error("xun: load template", slog.String("file", d.File), slog.Int("line", d.Line), slog.Any("err", d.Err))
+		}
+
 		if app.watch {
 			app.watcher = fsnotify.NewWatcher(app.fsys)
 			if err := app.watcher.Add("."); err != nil {
@@ -111,10 +303,14 @@ func (app *App) Group(prefix string) Router {
 // Start initializes and starts the application by locking the mutex,
 // iterating through the routes, and logging the pattern and viewers
 // for each route. It ensures thread safety by using a mutex lock.
-func (app *App) Start() {
+//
+// It returns a *TemplateError if any templates failed to load, so build
+// pipelines can fail fast. See TemplateDiagnostics. It then runs the tasks
+// registered by Warmup, and returns their errors. Once they are completed, the
+// app is ready, and the readiness is sent to systemd if the service is started
+// with Type=notify.
+func (app *App) Start() error {
 	app.mu.Lock()
-	defer app.mu.Unlock()
-
 	for _, r := range app.routes {
 		keys := make([]string, 0, len(r.Viewers))
 		for _, v := range r.Viewers {
@@ -123,16 +319,55 @@ func (app *App) Start() {
 
 		app.logger.Info(r.Pattern, slog.String("viewer", strings.Join(keys, ",")))
 	}
+	app.mu.Unlock()
+
+	if items := app.TemplateDiagnostics(); len(items) > 0 {
+		return &TemplateError{Diagnostics: items}
+	}
+
+	if app.manifestFile != "" {
+		if err := app.writeManifestFile(); err != nil {
+			return err
+		}
+	}
+
+	if err := app.warmup(); err != nil {
+		return err
+	}
+
+	app.ready.Store(true)
+
+	if err := SdNotify(SdNotifyReady); err != nil {
+		app.logger.Error("xun: sd_notify", slog.Any("err", err))
+	}
 
+	return nil
 }
 
 // Close safely locks the App instance, ensuring that no other
 // goroutines can access it until the lock is released. This method
 // should be called when the App instance is no longer needed to
 // prevent any further operations on it.
+//
+// The functions registered by OnClose are called in reverse order.
 func (app *App) Close() {
+	app.mu.Lock()
+	closers := app.closers
+	app.closers = nil
+	app.mu.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i]()
+	}
+}
+
+// OnClose registers a function that is called when the App is closed,
+// e.g. to release resources held by extensions.
+func (app *App) OnClose(fn func()) {
 	app.mu.Lock()
 	defer app.mu.Unlock()
+
+	app.closers = append(app.closers, fn)
 }
 
 // Use registers one or more Middleware functions to be executed
@@ -181,6 +416,10 @@ func (app *App) Next(hf HandleFunc) HandleFunc {
 	return next
 }
 
+func (app *App) middlewareList() []Middleware {
+	return app.middlewares
+}
+
 // HandleFile registers a route handler for serving a file.
 //
 // This function associates a FileViewer with a given file name
@@ -190,7 +429,10 @@ func (app *App) Next(hf HandleFunc) HandleFunc {
 func (app *App) HandleFile(name string, v *FileViewer) {
 	ro := &RoutingOptions{}
 
-	_, _, pat := splitFile(name)
+	_, path, pat := splitFile(name)
+	if path == "" {
+		path = "/"
+	}
 
 	r, ok := app.routes[pat]
 
@@ -202,6 +444,7 @@ func (app *App) HandleFile(name string, v *FileViewer) {
 	app.viewers[name] = v
 
 	hf := func(c *Context) error {
+		c.viewer = v
 		return v.Render(c.rw, c.req, nil)
 	}
 
@@ -209,35 +452,14 @@ func (app *App) HandleFile(name string, v *FileViewer) {
 		Options: ro,
 		Pattern: pat,
 		Handle:  hf,
-		chain:   app,
+		chain:   &staticChain{app: app, path: path},
 	}
 
 	app.routes[pat] = r
 
 	r.Viewers = append(r.Viewers, v)
 
-	app.mux.HandleFunc(pat, func(w http.ResponseWriter, req *http.Request) {
-		rw := app.createWriter(req, w)
-		defer rw.Close()
-
-		ctx := &Context{
-			req:     req,
-			rw:      rw,
-			Routing: *r,
-			app:     app,
-		}
-
-		err := r.Next(ctx)
-
-		if err == nil || errors.Is(err, ErrCancelled) {
-			return
-		}
-
-		logID := nextLogID()
-		ctx.WriteHeader("X-Log-Id", logID)
-		ctx.WriteStatus(http.StatusInternalServerError)
-		app.logger.Error("xun: file", slog.Any("err", err), slog.String("logid", logID))
-	})
+	app.mux.HandleFunc(pat, app.serve(r, "xun: file"))
 }
 
 // HandlePage registers a route handler for a page view.
@@ -260,6 +482,7 @@ func (app *App) HandlePage(pattern string, viewName string, v Viewer) {
 	app.viewers[viewName] = v
 
 	hf := func(c *Context) error {
+		c.viewer = v
 		return v.Render(c.rw, c.req, nil)
 	}
 
@@ -274,29 +497,7 @@ func (app *App) HandlePage(pattern string, viewName string, v Viewer) {
 
 	app.routes[pattern] = r
 
-	app.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
-		rw := app.createWriter(req, w)
-		defer rw.Close()
-
-		ctx := &Context{
-			req:     req,
-			rw:      rw,
-			Routing: *r,
-			app:     app,
-		}
-
-		err := r.Next(ctx)
-
-		if err == nil || errors.Is(err, ErrCancelled) {
-			return
-		}
-
-		logID := nextLogID()
-		ctx.WriteHeader("X-Log-Id", logID)
-		ctx.WriteStatus(http.StatusInternalServerError)
-		app.logger.Error("xun: view", slog.Any("err", err), slog.String("logid", logID))
-
-	})
+	app.mux.HandleFunc(pattern, app.serve(r, "xun: view"))
 
 }
 
@@ -314,16 +515,19 @@ func (app *App) HandleFunc(pattern string, hf HandleFunc, opts ...RoutingOption)
 }
 
 // createWriter creates a ResponseWriter that supports compression based on the
-// "Accept-Encoding" header in the HTTP request. If the header contains a
-// supported encoding or a wildcard "*", it returns a compressed ResponseWriter.
-// Otherwise, it returns a standard ResponseWriter.
+// "Accept-Encoding" header in the HTTP request. It returns a compressed
+// ResponseWriter of the first compressor whose encoding is accepted by the
+// header, or by its wildcard "*". Otherwise, it returns a standard ResponseWriter.
 func (app *App) createWriter(req *http.Request, w http.ResponseWriter) ResponseWriter {
-	acceptEncoding := req.Header.Get("Accept-Encoding")
+	if len(app.compressors) == 0 {
+		return &stdResponseWriter{ResponseWriter: w}
+	}
 
-	stars := strings.ContainsAny(acceptEncoding, "*")
+	w.Header().Add("Vary", "Accept-Encoding")
+	acceptEncoding := req.Header.Get("Accept-Encoding")
 
 	for _, compressor := range app.compressors {
-		if stars || strings.Contains(acceptEncoding, compressor.AcceptEncoding()) {
+		if acceptsEncoding(acceptEncoding, compressor.AcceptEncoding()) {
 			return compressor.New(w)
 		}
 	}
@@ -341,6 +545,16 @@ func (app *App) createHandler(pattern string, hf HandleFunc, opts []RoutingOptio
 		o(ro)
 	}
 
+	if ro.name != "" {
+		app.mu.Lock()
+		if p, ok := app.names[ro.name]; ok && p != pattern {
+			app.mu.Unlock()
+			panic(fmt.Sprintf("xun: route name %q of %q is registered by %q", ro.name, pattern, p))
+		}
+		app.names[ro.name] = pattern
+		app.mu.Unlock()
+	}
+
 	r, ok := app.routes[pattern]
 
 	if ok {
@@ -354,6 +568,7 @@ func (app *App) createHandler(pattern string, hf HandleFunc, opts []RoutingOptio
 			r.Viewers = append(r.Viewers, ro.viewers...)
 		}
 
+		app.createExports(pattern, hf, opts, ro.exports, c)
 		return
 
 	}
@@ -372,29 +587,200 @@ func (app *App) createHandler(pattern string, hf HandleFunc, opts []RoutingOptio
 
 	app.routes[pattern] = r
 
-	app.mux.HandleFunc(pattern, func(w http.ResponseWriter, req *http.Request) {
-		rw := app.createWriter(req, w)
-		defer rw.Close()
+	app.mux.HandleFunc(pattern, app.serve(r, "xun: handle"))
+
+	app.createExports(pattern, hf, opts, ro.exports, c)
+}
+
+// createExports registers the sibling export routes of the route pattern by WithExport.
+func (app *App) createExports(pattern string, hf HandleFunc, opts []RoutingOption, exts []string, c chain) {
+	for _, ext := range exts {
+		v, ok := app.exporters[ext]
+		if !ok {
+			app.logger.Warn("xun: exporter not found", slog.String("route", pattern), slog.String("ext", ext))
+			continue
+		}
+
+		ep, ok := exportPattern(pattern, ext)
+		if !ok {
+			app.logger.Warn("xun: export of a {name...} wildcard route is not supported", slog.String("route", pattern), slog.String("ext", ext))
+			continue
+		}
+
+		app.createHandler(ep, hf, append(opts[:len(opts):len(opts)], func(ro *RoutingOptions) {
+			ro.viewers = []Viewer{v}
+			ro.exports = nil
+			ro.export = v
+			ro.name = ""
+		}), c)
+	}
+}
+
+// exportPattern returns the pattern of the export route with the ext suffix.
+// The ext is put in its own "index" segment after a wildcard, e.g.
+// "/users/{id}/index.csv", because a wildcard must be a full segment. It
+// returns false for a {name...} wildcard, which must be the last segment.
+func exportPattern(pattern, ext string) (string, bool) {
+	p := strings.TrimSuffix(pattern, "{$}")
+
+	if strings.HasSuffix(p, "/") {
+		if strings.Index(p, "/") == len(p)-1 {
+			return p + "index." + ext, true
+		}
+		p = p[:len(p)-1]
+	}
 
-		ctx := &Context{
-			req:     req,
-			rw:      rw,
-			Routing: *r,
-			app:     app,
+	last := p[strings.LastIndex(p, "/")+1:]
+	if strings.HasSuffix(last, "...}") {
+		return "", false
+	}
+
+	if strings.HasPrefix(last, "{") {
+		return p + "/index." + ext, true
+	}
+
+	return p + "." + ext, true
+}
+
+// serve returns the http.HandlerFunc of the route r. It runs the handler with
+// its middlewares, and writes 500 with a X-Log-Id header for unhandled errors,
+// unless they are handled by the ErrorHandler of its group.
+func (app *App) serve(r *Routing, msg string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if app.baseContext {
+			var done context.CancelFunc
+			req, done = app.withBaseContext(req)
+			defer done()
+		}
+
+		if r.Options != nil && r.Options.deadline > 0 {
+			dc, cancel := context.WithTimeout(req.Context(), r.Options.deadline)
+			defer cancel()
+			req = req.WithContext(dc)
+		}
+
+		if bo := app.routeBindOptions(r); bo != nil {
+			req = req.WithContext(context.WithValue(req.Context(), bindOptionsKey{}, bo))
 		}
 
-		err := r.Next(ctx)
+		if app.bufPool != nil {
+			req = req.WithContext(context.WithValue(req.Context(), bufPoolKey{}, app.bufPool))
+		}
+
+		app.writePreconnect(w, req)
 
-		if err == nil || errors.Is(err, ErrCancelled) {
+		rw := &statsResponseWriter{ResponseWriter: app.createWriter(req, w), streams: app.streams}
+		defer rw.Close()
+		defer rw.endStream()
+
+		if r.Options != nil && r.Options.coalesce > 0 && (req.Method == http.MethodGet || req.Method == http.MethodHead) {
+			app.coalesce(r, rw, req, msg)
 			return
 		}
 
-		logID := nextLogID()
-		ctx.WriteHeader("X-Log-Id", logID)
-		ctx.WriteStatus(http.StatusInternalServerError)
-		app.logger.Error("xun: handle", slog.Any("err", err), slog.String("logid", logID))
-	})
+		app.handle(r, rw, req, msg)
+	}
+}
+
+// handle runs the handler of the route r with its middlewares.
+func (app *App) handle(r *Routing, rw *statsResponseWriter, req *http.Request, msg string) {
+	ctx := &Context{
+		req:     req,
+		rw:      rw,
+		stats:   rw,
+		Routing: *r,
+		app:     app,
+	}
+
+	var err error
+	defer func() {
+		p := recover()
+		if !ctx.accessStart.IsZero() {
+			// the response is finalized, e.g. by the ErrorHandler and the buffered writer
+			app.logAccess(ctx, rw, err, p)
+		}
 
+		if p != nil {
+			if p != http.ErrAbortHandler { // nolint: errorlint
+				app.report(ctx, nil, p, requestID(ctx.req))
+			}
+			// let the http.Server log the panic and close the connection as before
+			panic(p)
+		}
+	}()
+
+	next := r.Next
+	if !app.noRecovery {
+		next = Recovery()(next)
+	}
+
+	err = next(ctx)
+
+	if ctx.buffered != nil {
+		defer ctx.buffered.finish()
+	}
+
+	if err == nil {
+		return
+	}
+
+	if errors.Is(err, ErrCancelled) {
+		// the status is usually written before, e.g. by a middleware that rejects
+		// the request, and nothing can be written to hijacked connections
+		if app.cancelledErrors && app.errorHandler != nil && !rw.hijacked && rw.statusCode == 0 && rw.bytesWritten == 0 {
+			app.errorHandler(ctx, err)
+		}
+		return
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) && errors.Is(ctx.req.Context().Err(), context.DeadlineExceeded) {
+		ctx.WriteStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	// a BindError or a 4xx HttpError is a client error, so it isn't logged or reported
+	var be *BindError
+	if errors.As(err, &be) {
+		if !app.handleError(r, ctx, err) {
+			ctx.writeBindError(be)
+		}
+		return
+	}
+
+	status := errorStatus(err)
+	if status < http.StatusInternalServerError {
+		if !app.handleError(r, ctx, err) {
+			ctx.WriteStatus(status)
+		}
+		return
+	}
+
+	logID := requestID(ctx.req)
+	ctx.WriteHeader("X-Log-Id", logID)
+	if !app.handleError(r, ctx, err) {
+		ctx.WriteStatus(status)
+	}
+	attrs := []any{slog.Any("err", err), slog.String("logid", logID)}
+	if stack := panicStack(err); stack != nil {
+		attrs = append(attrs, slog.String("stack", string(stack)))
+	}
+	app.logger.Error(msg, attrs...)
+	app.report(ctx, err, nil, logID)
+}
+
+// handleError passes the error to the ErrorHandler of the group of the route,
+// or the ErrorHandler of the app. It reports whether the error is handled.
+func (app *App) handleError(r *Routing, c *Context, err error) bool {
+	if eh, ok := r.chain.(errorHandler); ok && eh.handleError(c, err) {
+		return true
+	}
+
+	if app.errorHandler == nil {
+		return false
+	}
+
+	app.errorHandler(c, err)
+	return true
 }
 
 func (app *App) enableHotReload() {
@@ -408,6 +794,8 @@ func (app *App) enableHotReload() {
 				return
 			}
 
+			app.triggerBuildHooks(event)
+
 			var err error
 			for _, ve := range app.engines {
 				err = ve.FileChanged(app.fsys, app, event)
@@ -425,3 +813,12 @@ func (app *App) enableHotReload() {
 	}
 
 }
+
+// bufferPool returns the BufferPool set by WithBufferPool, or BufPool.
+func (app *App) bufferPool() *BufferPool {
+	if app.bufPool != nil {
+		return app.bufPool
+	}
+
+	return BufPool
+}
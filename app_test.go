@@ -235,6 +235,27 @@ func TestStatus(t *testing.T) {
 	require.Equal(t, "http://127.0.0.1/redirect", resp.Header.Get("Location"))
 	resp.Body.Close()
 
+	req, err = http.NewRequest("GET", srv.URL+"/301", nil)
+	require.NoError(t, err)
+	req.Header.Set("HX-Request", "true")
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "http://127.0.0.1/redirect", resp.Header.Get("HX-Redirect"))
+	require.Empty(t, resp.Header.Get("Location"))
+	resp.Body.Close()
+
+	req, err = http.NewRequest("GET", srv.URL+"/302", nil)
+	require.NoError(t, err)
+	req.Header.Set("HX-Request", "true")
+	req.Header.Set("HX-Boosted", "true")
+	resp, err = c.Do(req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "http://127.0.0.1/redirect", resp.Header.Get("HX-Location"))
+	require.Empty(t, resp.Header.Get("HX-Redirect"))
+	resp.Body.Close()
+
 }
 
 func TestStaticViewEngine(t *testing.T) {
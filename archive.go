@@ -0,0 +1,88 @@
+package xun
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// OpenArchive loads a zip or tar.gz archive into memory and returns it as a fs.FS.
+//
+// The archive is read only once, so it can be replaced on disk by a new
+// deployment without affecting the running App. A tar.gz archive is
+// converted into an in-memory zip to keep a random access index of its files.
+func OpenArchive(name string) (fs.FS, error) {
+	buf, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	lower := strings.ToLower(name)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		buf, err = tarToZip(bytes.NewReader(buf))
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrUnsupportedArchive
+	}
+
+	return zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+}
+
+// tarToZip converts a tar.gz stream into a zip archive.
+func tarToZip(r io.Reader) ([]byte, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		fh, err := zip.FileInfoHeader(hdr.FileInfo())
+		if err != nil {
+			return nil, err
+		}
+		fh.Name = strings.TrimPrefix(hdr.Name, "./")
+		fh.Method = zip.Store
+
+		w, err := zw.CreateHeader(fh)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := io.Copy(w, tr); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
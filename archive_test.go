@@ -0,0 +1,109 @@
+package xun
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var archiveFiles = map[string]string{
+	"public/app.js":    `console.log("xun")`,
+	"pages/index.html": `<h1>{{ asset "app.js" }}</h1>`,
+}
+
+func writeZip(t *testing.T, name string) {
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	for n, data := range archiveFiles {
+		w, err := zw.Create(n)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+}
+
+func writeTarGz(t *testing.T, name string) {
+	f, err := os.Create(name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for n, data := range archiveFiles {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: "./" + n, Mode: 0600, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err = tw.Write([]byte(data))
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gw.Close())
+}
+
+func TestWithFsysArchive(t *testing.T) {
+	dir := t.TempDir()
+
+	tests := []struct {
+		name  string
+		write func(t *testing.T, name string)
+	}{
+		{name: "app.zip", write: writeZip},
+		{name: "app.tar.gz", write: writeTarGz},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			name := filepath.Join(dir, test.name)
+			test.write(t, name)
+
+			mux := http.NewServeMux()
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			app := New(WithMux(mux), WithFsysArchive(name))
+			app.Start()
+			defer app.Close()
+
+			resp, err := client.Get(srv.URL + "/app.js")
+			require.NoError(t, err)
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, `console.log("xun")`, string(buf))
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept", "text/html")
+
+			resp, err = client.Do(req)
+			require.NoError(t, err)
+			buf, err = io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, `<h1>/app.js</h1>`, string(buf))
+		})
+	}
+}
+
+func TestOpenArchive(t *testing.T) {
+	_, err := OpenArchive(filepath.Join(t.TempDir(), "missing.zip"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	name := filepath.Join(t.TempDir(), "app.rar")
+	require.NoError(t, os.WriteFile(name, []byte("rar"), 0600))
+
+	_, err = OpenArchive(name)
+	require.ErrorIs(t, err, ErrUnsupportedArchive)
+}
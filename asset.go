@@ -0,0 +1,28 @@
+package xun
+
+import "strings"
+
+// AssetURL returns the URL of a static asset in the public directory, e.g.
+// "/app.js" or "https://cdn.example.com/app.js" if WithAssetHost is set.
+// If WithFingerprint is enabled, the fingerprinted path is used, e.g. "/app.3f2a1b9c.js".
+//
+// It is available as the `asset` function in templates:
+//
+//	<script src="{{ asset "/app.js" }}"></script>
+func (app *App) AssetURL(name string) string {
+	name = "/" + strings.TrimPrefix(name, "/")
+
+	if app.fingerprint {
+		app.mu.RLock()
+		if fp, ok := app.manifest[strings.ToLower(name)]; ok {
+			name = fp
+		}
+		app.mu.RUnlock()
+	}
+
+	if app.assetHost == "" || app.watch {
+		return name
+	}
+
+	return app.assetHost + name
+}
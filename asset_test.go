@@ -0,0 +1,75 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAssetURL(t *testing.T) {
+	t.Run("local", func(t *testing.T) {
+		app := New(WithMux(http.NewServeMux()))
+		require.Equal(t, "/app.js", app.AssetURL("app.js"))
+		require.Equal(t, "/css/skin.css", app.AssetURL("/css/skin.css"))
+	})
+
+	t.Run("asset_host", func(t *testing.T) {
+		app := New(WithMux(http.NewServeMux()), WithAssetHost("https://cdn.example.com/"))
+		require.Equal(t, "https://cdn.example.com/app.js", app.AssetURL("app.js"))
+		require.Equal(t, "https://cdn.example.com/css/skin.css", app.AssetURL("/css/skin.css"))
+	})
+
+	t.Run("asset_host_in_dev", func(t *testing.T) {
+		app := New(WithMux(http.NewServeMux()), WithAssetHost("https://cdn.example.com"), WithWatch())
+		require.Equal(t, "/app.js", app.AssetURL("app.js"))
+	})
+}
+
+func TestAssetFunc(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js":    {Data: []byte(`console.log("xun")`)},
+		"pages/index.html": {Data: []byte(`<script src="{{ asset "app.js" }}"></script>`)},
+		"text/assets.txt":  {Data: []byte(`{{ asset "/app.js" }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithAssetHost("https://cdn.example.com"))
+
+	app.Get("/assets.txt", func(c *Context) error {
+		return c.View(nil, "text/assets.txt")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `<script src="https://cdn.example.com/app.js"></script>`, string(buf))
+
+	req, err = http.NewRequest(http.MethodGet, srv.URL+"/assets.txt", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `https://cdn.example.com/app.js`, string(buf))
+}
@@ -0,0 +1,51 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+)
+
+// baseContext is a context.Context that carries the values and cancellation
+// of the request context, and falls back to the values of the base context.
+type baseContext struct {
+	context.Context
+	base context.Context
+}
+
+// Value returns the value of key in the request context, or in the base context.
+func (c *baseContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+	return c.base.Value(key)
+}
+
+// Context returns the app-level context. It is derived from WithBaseContext,
+// and it is cancelled when the App is closed.
+func (app *App) Context() context.Context {
+	return app.ctx
+}
+
+// withBaseContext returns the request derived from the base context, and a
+// function that must be called once the request is handled.
+func (app *App) withBaseContext(req *http.Request) (*http.Request, context.CancelFunc) {
+	base := app.ctx
+	if app.baseContextFunc != nil {
+		base = app.baseContextFunc(req)
+	}
+
+	ctx, cancel := context.WithCancelCause(&baseContext{Context: req.Context(), base: base})
+
+	stopBase := context.AfterFunc(base, func() {
+		cancel(context.Cause(base))
+	})
+	stopApp := context.AfterFunc(app.ctx, func() {
+		cancel(context.Cause(app.ctx))
+	})
+
+	return req.WithContext(ctx), func() {
+		stopApp()
+		stopBase()
+		cancel(context.Canceled)
+	}
+}
@@ -0,0 +1,102 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithBaseContext(t *testing.T) {
+	type loggerKey struct{}
+
+	base, stop := context.WithCancel(context.WithValue(context.Background(), loggerKey{}, "app-logger"))
+	defer stop()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithBaseContext(base))
+
+	app.Get("/value", func(c *Context) error {
+		return c.View(map[string]any{"logger": c.Context().Value(loggerKey{})})
+	})
+
+	started := make(chan struct{})
+	app.Get("/wait", func(c *Context) error {
+		close(started)
+		select {
+		case <-c.Context().Done():
+			return c.View(map[string]any{"shutdown": true})
+		case <-time.After(5 * time.Second):
+			return c.View(map[string]any{"shutdown": false})
+		}
+	})
+
+	app.Start()
+	defer app.Close()
+
+	require.Equal(t, "app-logger", app.Context().Value(loggerKey{}))
+
+	resp, err := client.Get(srv.URL + "/value")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.JSONEq(t, `{"logger":"app-logger"}`, string(buf))
+
+	go func() {
+		<-started
+		stop()
+	}()
+
+	resp, err = client.Get(srv.URL + "/wait")
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.JSONEq(t, `{"shutdown":true}`, string(buf))
+	require.Error(t, app.Context().Err())
+}
+
+func TestWithBaseContextFunc(t *testing.T) {
+	type tenantKey struct{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithBaseContextFunc(func(r *http.Request) context.Context {
+		return context.WithValue(context.Background(), tenantKey{}, r.Host)
+	}))
+
+	started := make(chan struct{})
+	app.Get("/wait", func(c *Context) error {
+		close(started)
+		<-c.Context().Done()
+		return c.View(map[string]any{"tenant": c.Context().Value(tenantKey{})})
+	})
+
+	app.Start()
+
+	go func() {
+		<-started
+		app.Close()
+	}()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/wait", nil)
+	require.NoError(t, err)
+	req.Host = "acme.example.com"
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.JSONEq(t, `{"tenant":"acme.example.com"}`, string(buf))
+}
@@ -0,0 +1,157 @@
+package xun
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/form/v4"
+	"github.com/go-playground/validator/v10"
+)
+
+// BindErrorView is the view that renders a BindError for htmx requests and
+// clients preferring text/html, e.g. a fragment of form errors. The BindError
+// is written as JSON if the view doesn't exist.
+const BindErrorView = "views/errors/400"
+
+// The codes of FieldError.
+const (
+	FieldUnknown      = "unknown_field"
+	FieldTypeMismatch = "type_mismatch"
+	FieldRequired     = "required"
+	FieldInvalid      = "invalid"
+)
+
+// FieldError is the error of a field in a BindError.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BindError is the error returned by BindQuery, BindForm, BindJson and
+// TEntity.Err when the request can't be bound to a struct. It carries the
+// field-level details, and is written as 400 Bad Request with a JSON body,
+// or the BindErrorView, if it is returned by a handler.
+//
+//	{"error":"invalid json","source":"json","fields":[{"field":"age","code":"type_mismatch","message":"..."}]}
+type BindError struct {
+	Message string       `json:"error"`
+	Source  string       `json:"source"`
+	Fields  []FieldError `json:"fields,omitempty"`
+
+	// Status is the status code of the error, 400 Bad Request if it is 0.
+	Status int   `json:"-"`
+	Err    error `json:"-"`
+}
+
+// Error returns the message of the error.
+func (e *BindError) Error() string {
+	if e.Err != nil {
+		return "xun: " + e.Message + ": " + e.Err.Error()
+	}
+	return "xun: " + e.Message
+}
+
+// Unwrap returns the underlying error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// FieldErrors returns the messages of the fields by their names, e.g. for
+// the form error view.
+func (e *BindError) FieldErrors() map[string]string {
+	items := make(map[string]string, len(e.Fields))
+	for _, f := range e.Fields {
+		items[f.Field] = f.Message
+	}
+	return items
+}
+
+// newBindError returns a BindError of the source with the fields found in err.
+func newBindError(source string, err error) *BindError {
+	be := &BindError{Message: "invalid " + source, Source: source, Err: err}
+
+	var decodeErrs form.DecodeErrors
+	var typeErr *stdjson.UnmarshalTypeError
+	var sizeErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &decodeErrs):
+		for field, it := range decodeErrs {
+			be.Fields = append(be.Fields, FieldError{Field: field, Code: FieldTypeMismatch, Message: it.Error()})
+		}
+		// decode errors are a map, so they are sorted to be stable
+		sort.Slice(be.Fields, func(i, j int) bool { return be.Fields[i].Field < be.Fields[j].Field })
+	case errors.As(err, &typeErr):
+		be.Fields = append(be.Fields, FieldError{
+			Field:   typeErr.Field,
+			Code:    FieldTypeMismatch,
+			Message: "cannot use " + typeErr.Value + " as " + typeErr.Type.String(),
+		})
+	case errors.Is(err, io.EOF):
+		be.Message = "empty " + source
+	case errors.As(err, &sizeErr):
+		be.Message = source + " too large"
+		be.Status = http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrJsonTooDeep):
+		be.Message = source + " too deep"
+	default:
+		// e.g. `json: unknown field "name"` of strict decoding
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			field = strings.Trim(field, `"`)
+			be.Fields = append(be.Fields, FieldError{Field: field, Code: FieldUnknown, Message: "unknown field " + field})
+		}
+	}
+
+	return be
+}
+
+// fieldCode returns the code of a validation error, e.g. "required" or "email".
+func fieldCode(err validator.FieldError) string {
+	if err.Tag() == "required" {
+		return FieldRequired
+	}
+	return err.Tag()
+}
+
+// writeBindError writes the BindError as 400 Bad Request, or its Status. It
+// is rendered by the BindErrorView for htmx requests and clients preferring
+// text/html, and JSON otherwise.
+func (c *Context) writeBindError(be *BindError) {
+	buf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(buf)
+
+	status := http.StatusBadRequest
+	if be.Status != 0 {
+		status = be.Status
+	}
+
+	contentType := "application/json"
+	if v := c.bindErrorViewer(); v != nil && v.template.execute(buf, c.req, be) == nil {
+		contentType = "text/html; charset=utf-8"
+	} else {
+		buf.Reset()
+		if err := json.NewEncoder(buf).Encode(be); err != nil {
+			c.WriteStatus(status)
+			return
+		}
+	}
+
+	c.rw.Header().Set("Content-Type", contentType)
+	c.WriteStatus(status)
+	buf.WriteTo(c.rw) // nolint: errcheck
+}
+
+// bindErrorViewer returns the BindErrorView for htmx requests and clients preferring text/html.
+func (c *Context) bindErrorViewer() *HtmlViewer {
+	if !prefersHtml(c.req) {
+		return nil
+	}
+
+	v, _ := c.app.viewers[BindErrorView].(*HtmlViewer)
+	return v
+}
@@ -1,10 +1,15 @@
 package xun
 
 import (
+	stdjson "encoding/json"
+	"io"
+	"mime"
 	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
 
 	"github.com/go-playground/form/v4"
-	"github.com/go-playground/validator/v10"
 	jsoniter "github.com/json-iterator/go"
 )
 
@@ -12,15 +17,42 @@ var (
 	json = jsoniter.Config{UseNumber: false}.Froze()
 
 	// use a single instance of Decoder, it caches struct info
-	formDecoder = form.NewDecoder()
+	formDecoder   = form.NewDecoder()
+	pathDecoder   = newTagDecoder("path")
+	headerDecoder = newTagDecoder("header")
 )
 
+// newTagDecoder returns a form.Decoder of the fields tagged by name, e.g. `path:"id"`.
+func newTagDecoder(name string) *form.Decoder {
+	d := form.NewDecoder()
+	d.SetTagName(name)
+	return d
+}
+
 // BindQuery binds the query string to the given struct.
+//
+// If the decoding or the ValidateFunc of WithValidator fails, it returns a *BindError.
 func BindQuery[T any](req *http.Request) (*TEntity[T], error) {
 
 	data := new(T)
 
 	err := formDecoder.Decode(data, req.URL.Query())
+	if err != nil {
+		return nil, newBindError("query", err)
+	}
+
+	return bound(req, data, "query")
+}
+
+// BindPath binds the path values of the request to the fields of the given
+// struct tagged by `path`, e.g. `path:"id"` for `GET /users/{id}`, with the
+// same conversion rules as BindQuery.
+//
+// If the decoding fails, it returns a *BindError.
+func BindPath[T any](req *http.Request) (*TEntity[T], error) {
+	data := new(T)
+
+	err := decodePath(req, data)
 	if err != nil {
 		return nil, err
 	}
@@ -28,33 +60,228 @@ func BindQuery[T any](req *http.Request) (*TEntity[T], error) {
 	return &TEntity[T]{
 		Data:   *data,
 		Errors: make(map[string]string),
+		source: "path",
 	}, nil
 }
 
+// BindHeader binds the headers of the request to the fields of the given
+// struct tagged by `header`, e.g. `header:"X-Tenant-Id"`, with the same
+// conversion rules as BindQuery. Header names are case-insensitive.
+//
+// If the decoding fails, it returns a *BindError.
+func BindHeader[T any](req *http.Request) (*TEntity[T], error) {
+	data := new(T)
+
+	err := decodeHeader(req, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TEntity[T]{
+		Data:   *data,
+		Errors: make(map[string]string),
+		source: "header",
+	}, nil
+}
+
+// Bind binds the whole request to the given struct, so handlers don't need
+// to call the Bind functions one by one. The body is bound by its
+// Content-Type, as BindJson for JSON, or BindForm for forms, and the query
+// string is bound to the fields tagged by `form` for requests without a body.
+// Then the path values and headers are bound to the fields tagged by `path`
+// and `header`, which are never bound from the body or query string, so
+// clients can't forge them by the body.
+//
+//	type UpdateUser struct {
+//		ID     int    `path:"id"`
+//		Tenant string `header:"X-Tenant-Id"`
+//		Name   string `json:"name" form:"name" validate:"required"`
+//	}
+//
+//	it, err := xun.Bind[UpdateUser](c)
+//
+// If the decoding or the ValidateFunc of WithValidator fails, it returns a
+// *BindError, or a *BindError with 415 Unsupported Media Type if the
+// Content-Type of the body isn't supported.
+func Bind[T any](c *Context) (*TEntity[T], error) {
+	data := new(T)
+	req := c.req
+
+	source := "query"
+	if req.ContentLength != 0 { // -1 if the length of the body is unknown
+		ct, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		mt := NewMimeType(ct)
+		switch {
+		case mt.Type == "application" && (mt.SubType == "json" || strings.HasSuffix(mt.SubType, "+json")):
+			source = "json"
+			if err := decodeJson(req.Body, bindOptionsOf(req).json, data); err != nil {
+				return nil, newBindError(source, err)
+			}
+		case mt.Type == "application" && mt.SubType == "x-www-form-urlencoded",
+			mt.Type == "multipart" && mt.SubType == "form-data":
+			source = "form"
+			if err := parseForm(req, mt); err != nil {
+				return nil, newBindError(source, err)
+			}
+			if err := formDecoder.Decode(data, req.PostForm); err != nil {
+				return nil, newBindError(source, err)
+			}
+		default:
+			return nil, &BindError{Message: "unsupported media type", Source: "body", Status: http.StatusUnsupportedMediaType}
+		}
+	} else if err := formDecoder.Decode(data, req.URL.Query()); err != nil {
+		return nil, newBindError(source, err)
+	}
+
+	// the fields of the path values and headers are never bound from the
+	// body or query, e.g. a forged user id of a trusted header
+	zeroTagged(reflect.ValueOf(data), "path", "header")
+
+	if err := decodePath(req, data); err != nil {
+		return nil, err
+	}
+
+	if err := decodeHeader(req, data); err != nil {
+		return nil, err
+	}
+
+	return bound(req, data, source)
+}
+
+// parseForm parses the form body of the request by its MIME type.
+func parseForm(req *http.Request, mt MimeType) error {
+	if mt.Type == "multipart" {
+		return req.ParseMultipartForm(32 << 20)
+	}
+
+	return req.ParseForm()
+}
+
+// decodePath decodes the path values of the request to the fields of data tagged by `path`.
+func decodePath(req *http.Request, data any) error {
+	names := tagNames(reflect.TypeOf(data), "path")
+	if len(names) == 0 {
+		return nil
+	}
+
+	values := make(url.Values, len(names))
+	for _, name := range names {
+		if v := req.PathValue(name); v != "" {
+			values.Set(name, v)
+		}
+	}
+
+	if err := pathDecoder.Decode(data, values); err != nil {
+		return newBindError("path", err)
+	}
+
+	return nil
+}
+
+// decodeHeader decodes the headers of the request to the fields of data tagged by `header`.
+func decodeHeader(req *http.Request, data any) error {
+	names := tagNames(reflect.TypeOf(data), "header")
+	if len(names) == 0 {
+		return nil
+	}
+
+	values := make(url.Values, len(names))
+	for _, name := range names {
+		if v := req.Header.Values(name); len(v) > 0 {
+			values[name] = v
+		}
+	}
+
+	if err := headerDecoder.Decode(data, values); err != nil {
+		return newBindError("header", err)
+	}
+
+	return nil
+}
+
+// zeroTagged zeroes the fields of the struct v tagged by any of the tags,
+// including the fields of embedded structs.
+func zeroTagged(v reflect.Value, tags ...string) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		if f.Anonymous {
+			zeroTagged(v.Field(i), tags...)
+			continue
+		}
+
+		for _, tag := range tags {
+			if name, _, _ := strings.Cut(f.Tag.Get(tag), ","); name != "" && name != "-" {
+				v.Field(i).SetZero()
+				break
+			}
+		}
+	}
+}
+
+// tagNames returns the names of the fields of the struct t tagged by tag,
+// including the fields of embedded structs.
+func tagNames(t reflect.Type, tag string) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			names = append(names, tagNames(f.Type, tag)...)
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
 // BindForm binds the request body to the given struct.
 //
 // It supports application/x-www-form-urlencoded, multipart/form-data.
 //
-// If the request body is empty or the decoding fails, it returns an error.
+// If the request body is empty, or the decoding or the ValidateFunc of
+// WithValidator fails, it returns a *BindError.
 func BindForm[T any](req *http.Request) (*TEntity[T], error) {
 
 	data := new(T)
 
 	err := req.ParseForm()
 	if err != nil {
-		return nil, err
+		return nil, newBindError("form", err)
 	}
 
 	// r.PostForm is a map of our POST form values
 	err = formDecoder.Decode(data, req.PostForm)
 	if err != nil {
-		return nil, err
+		return nil, newBindError("form", err)
 	}
 
-	return &TEntity[T]{
-		Data:   *data,
-		Errors: make(map[string]string),
-	}, nil
+	return bound(req, data, "form")
 
 }
 
@@ -62,20 +289,153 @@ func BindForm[T any](req *http.Request) (*TEntity[T], error) {
 //
 // It attempts to decode the JSON body into the specified type.
 //
-// If the decoding fails, it returns an error.
+// If the decoding or the ValidateFunc of WithValidator fails, it returns a
+// *BindError. It is decoded by encoding/json, whose errors carry the fields
+// of type mismatches, with the JsonOptions of WithJsonOptions or
+// WithRouteJsonOptions.
 func BindJson[T any](req *http.Request) (*TEntity[T], error) {
 	data := new(T)
 
-	err := json.NewDecoder(req.Body).Decode(data)
+	err := decodeJson(req.Body, bindOptionsOf(req).json, data)
 	if err != nil {
-		return nil, err
+		return nil, newBindError("json", err)
+	}
+
+	return bound(req, data, "json")
+
+}
+
+// JsonOptions are the options of BindJson, e.g. to harden public API endpoints.
+// They are set by WithJsonOptions for the app, or WithRouteJsonOptions.
+type JsonOptions struct {
+	// DisallowUnknownFields rejects the fields that aren't in the struct.
+	DisallowUnknownFields bool
+	// UseNumber decodes numbers into an interface{} as json.Number instead of float64.
+	UseNumber bool
+	// MaxDepth is the max nesting depth of objects and arrays. It is checked
+	// while the body is read, so it doesn't need MaxSize. It is unlimited if it is 0.
+	MaxDepth int
+	// MaxSize is the max size of the body in bytes. Larger bodies are rejected
+	// with 413 Request Entity Too Large. It is unlimited if it is 0.
+	MaxSize int64
+}
+
+type bindOptionsKey struct{}
+
+// bindOptions are the options of the Bind functions for the route of a request.
+type bindOptions struct {
+	json     *JsonOptions
+	validate ValidateFunc
+	locales  []string
+}
+
+// routeBindOptions returns the bindOptions of the route r, or nil if there is none.
+func (app *App) routeBindOptions(r *Routing) *bindOptions {
+	o := bindOptions{json: app.jsonOptions, validate: app.validate, locales: app.locales}
+	if r.Options != nil && r.Options.jsonOptions != nil {
+		o.json = r.Options.jsonOptions
+	}
+
+	if o.json == nil && o.validate == nil {
+		return nil
+	}
+
+	return &o
+}
+
+// bindOptionsOf returns the bindOptions of the request.
+func bindOptionsOf(req *http.Request) *bindOptions {
+	if o, ok := req.Context().Value(bindOptionsKey{}).(*bindOptions); ok {
+		return o
+	}
+
+	return &bindOptions{}
+}
+
+// bound returns the TEntity of the data bound from the source. It returns a
+// *BindError if the data fails the ValidateFunc of WithValidator.
+func bound[T any](req *http.Request, data *T, source string) (*TEntity[T], error) {
+	if validate := bindOptionsOf(req).validate; validate != nil {
+		if fields := validate(req, data); len(fields) > 0 {
+			return nil, &BindError{Message: "invalid " + source, Source: source, Fields: fields}
+		}
 	}
 
 	return &TEntity[T]{
 		Data:   *data,
 		Errors: make(map[string]string),
+		source: source,
 	}, nil
+}
+
+// decodeJson decodes the JSON of r into v with the options o.
+func decodeJson(r io.Reader, o *JsonOptions, v any) error {
+	if o == nil {
+		return stdjson.NewDecoder(r).Decode(v)
+	}
+
+	if o.MaxSize > 0 {
+		r = http.MaxBytesReader(nil, io.NopCloser(r), o.MaxSize)
+	}
+
+	if o.MaxDepth > 0 {
+		// the depth is checked while the body is streamed, so a deep body
+		// is rejected before it is read into memory
+		r = &depthReader{r: r, max: o.MaxDepth}
+	}
+
+	d := stdjson.NewDecoder(r)
+	if o.DisallowUnknownFields {
+		d.DisallowUnknownFields()
+	}
+	if o.UseNumber {
+		d.UseNumber()
+	}
 
+	return d.Decode(v)
+}
+
+// depthReader returns ErrJsonTooDeep once the nesting depth of objects and
+// arrays in the JSON read from r exceeds max.
+type depthReader struct {
+	r        io.Reader
+	max      int
+	depth    int
+	inString bool
+	escaped  bool
+}
+
+// Read implements io.Reader.
+func (dr *depthReader) Read(p []byte) (int, error) {
+	n, err := dr.r.Read(p)
+
+	for _, c := range p[:n] {
+		if dr.inString {
+			switch {
+			case dr.escaped:
+				dr.escaped = false
+			case c == '\\':
+				dr.escaped = true
+			case c == '"':
+				dr.inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			dr.inString = true
+		case '{', '[':
+			dr.depth++
+			if dr.depth > dr.max {
+				return 0, ErrJsonTooDeep
+			}
+		case '}', ']':
+			dr.depth--
+		}
+	}
+
+	return n, err
 }
 
 // TEntity is a struct that contains the data and errors.
@@ -84,6 +444,9 @@ func BindJson[T any](req *http.Request) (*TEntity[T], error) {
 type TEntity[T any] struct {
 	Data   T                 `json:"data"`
 	Errors map[string]string `json:"errors"`
+
+	source string
+	fields []FieldError
 }
 
 // Validate checks the data against the validation rules and populates errors if any.
@@ -91,23 +454,21 @@ type TEntity[T any] struct {
 // It uses the specified languages to find an appropriate validator and
 // translates the error messages. Returns true if validation passes, otherwise false.
 func (t *TEntity[T]) Validate(languages ...string) bool {
-	validate := findValidator(languages...)
-
-	err := validate.Struct(t.Data)
-	if err == nil {
-		return true
+	t.fields = validateStruct(t.Data, languages...)
+	for _, f := range t.fields {
+		t.Errors[f.Field] = f.Message
 	}
 
-	errs := err.(validator.ValidationErrors)
-
-	for _, err := range errs {
-		n := err.Field()
-		if n == "" {
-			n = err.StructField()
-		}
+	return len(t.fields) == 0
+}
 
-		t.Errors[n] = err.Translate(validate.Translator)
+// Err returns a *BindError of the fields failed by Validate, e.g. missing
+// required fields, so handlers can return it to write 400 Bad Request. It
+// returns nil if the validation passes.
+func (t *TEntity[T]) Err() error {
+	if len(t.fields) == 0 {
+		return nil
 	}
 
-	return false
+	return &BindError{Message: "invalid " + t.source, Source: t.source, Fields: t.fields}
 }
@@ -2,12 +2,14 @@ package xun
 
 import (
 	"bytes"
-
+	stdjson "encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/go-playground/locales/zh"
 	ut "github.com/go-playground/universal-translator"
@@ -180,3 +182,480 @@ func TestBinder(t *testing.T) {
 	}
 
 }
+
+func TestBindError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/errors/400.html": {Data: []byte(`{{ range $k, $v := .FieldErrors }}<p>{{ $k }}: {{ $v }}</p>{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	type Signup struct {
+		Email string `form:"email" json:"email" validate:"required,email"`
+		Age   int    `form:"age" json:"age"`
+	}
+
+	app.Get("/signup", func(c *Context) error {
+		_, err := BindQuery[Signup](c.Request())
+		return err
+	})
+
+	app.Post("/signup", func(c *Context) error {
+		it, err := BindJson[Signup](c.Request())
+		if err != nil {
+			return err
+		}
+
+		if !it.Validate() {
+			return it.Err()
+		}
+		return c.View(it)
+	})
+
+	app.Start()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		accept string
+		want   BindError
+	}{
+		{
+			name:   "query_type_mismatch",
+			method: http.MethodGet,
+			path:   "/signup?email=xun@yaitoo.cn&age=abc",
+			want:   BindError{Message: "invalid query", Source: "query", Fields: []FieldError{{Field: "age", Code: FieldTypeMismatch}}},
+		},
+		{
+			name:   "json_type_mismatch",
+			method: http.MethodPost,
+			path:   "/signup",
+			body:   `{"email":"xun@yaitoo.cn","age":"abc"}`,
+			want:   BindError{Message: "invalid json", Source: "json", Fields: []FieldError{{Field: "age", Code: FieldTypeMismatch}}},
+		},
+		{
+			name:   "json_syntax",
+			method: http.MethodPost,
+			path:   "/signup",
+			body:   `{"email":`,
+			want:   BindError{Message: "invalid json", Source: "json"},
+		},
+		{
+			name:   "json_required",
+			method: http.MethodPost,
+			path:   "/signup",
+			body:   `{"age":18}`,
+			accept: "application/json",
+			want:   BindError{Message: "invalid json", Source: "json", Fields: []FieldError{{Field: "Email", Code: FieldRequired}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, srv.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+			require.Empty(t, resp.Header.Get("X-Log-Id"))
+
+			var got BindError
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&got))
+			require.Equal(t, test.want.Message, got.Message)
+			require.Equal(t, test.want.Source, got.Source)
+			require.Len(t, got.Fields, len(test.want.Fields))
+			for i, f := range test.want.Fields {
+				require.Equal(t, f.Field, got.Fields[i].Field)
+				require.Equal(t, f.Code, got.Fields[i].Code)
+				require.NotEmpty(t, got.Fields[i].Message)
+			}
+		})
+	}
+
+	t.Run("html_view", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/signup?age=abc", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html,application/xhtml+xml,*/*;q=0.8")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(buf), "<p>age: "))
+	})
+}
+
+func TestBindJsonOptions(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithJsonOptions(JsonOptions{DisallowUnknownFields: true}))
+	defer app.Close()
+
+	type Event struct {
+		Name  string         `json:"name"`
+		Attrs map[string]any `json:"attrs"`
+	}
+
+	handle := func(c *Context) error {
+		it, err := BindJson[Event](c.Request())
+		if err != nil {
+			return err
+		}
+
+		_, ok := it.Data.Attrs["n"].(stdjson.Number)
+		return c.View(ok)
+	}
+
+	app.Post("/events", handle)
+	app.Post("/public/events", handle, WithRouteJsonOptions(JsonOptions{UseNumber: true, MaxDepth: 2, MaxSize: 64}))
+
+	app.Start()
+
+	tests := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		want   string
+	}{
+		{"app_ok", "/events", `{"name":"a","attrs":{"n":1}}`, http.StatusOK, "false"},
+		{"app_unknown_field", "/events", `{"name":"a","id":1}`, http.StatusBadRequest, `{"field":"id","code":"unknown_field"`},
+		{"route_use_number", "/public/events", `{"name":"a","id":1,"attrs":{"n":1}}`, http.StatusOK, "true"},
+		{"route_too_deep", "/public/events", `{"name":"[{","attrs":{"n":[1]}}`, http.StatusBadRequest, `"error":"json too deep"`},
+		{"route_too_large", "/public/events", `{"name":"` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge, `"error":"json too large"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := client.Post(srv.URL+test.path, "application/json", strings.NewReader(test.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(buf), test.want)
+		})
+	}
+}
+
+// endlessReader reads the byte endlessly.
+type endlessReader byte
+
+func (r endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestJsonMaxDepth(t *testing.T) {
+	// an endless body is rejected once it is too deep, even without MaxSize
+	err := decodeJson(io.MultiReader(strings.NewReader(`{"a":`), endlessReader('[')), &JsonOptions{MaxDepth: 8}, new(any))
+	require.ErrorIs(t, err, ErrJsonTooDeep)
+
+	// brackets and escaped quotes in strings are not nested
+	var v map[string]string
+	require.NoError(t, decodeJson(strings.NewReader(`{"a":"[[\"{{\\"}`), &JsonOptions{MaxDepth: 1}, &v))
+	require.Equal(t, `[["{{\`, v["a"])
+}
+
+func TestBindPath(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	type Owner struct {
+		Org string `path:"org"`
+	}
+
+	type Repo struct {
+		Owner
+		ID   int    `path:"id"`
+		Name string `path:"name"`
+		Tab  string `form:"tab"`
+	}
+
+	app.Get("/orgs/{org}/repos/{id}/{name...}", func(c *Context) error {
+		it, err := BindPath[Repo](c.Request())
+		if err != nil {
+			return err
+		}
+		return c.View(it.Data)
+	})
+
+	app.Start()
+
+	resp, err := client.Get(srv.URL + "/orgs/yaitoo/repos/42/xun/core?tab=issues")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"Org":"yaitoo","ID":42,"Name":"xun/core","Tab":""}`, string(buf))
+
+	resp, err = client.Get(srv.URL + "/orgs/yaitoo/repos/abc/xun")
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Contains(t, string(buf), `{"field":"id","code":"type_mismatch"`)
+}
+
+func TestBind(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	type UpdateUser struct {
+		ID     int      `path:"id" json:"id"`
+		Tenant string   `header:"X-Tenant-Id" json:"tenant"`
+		Roles  []string `header:"X-Role" json:"roles"`
+		Name   string   `json:"name" form:"name"`
+		Notify bool     `json:"notify" form:"notify"`
+	}
+
+	handle := func(c *Context) error {
+		it, err := Bind[UpdateUser](c)
+		if err != nil {
+			return err
+		}
+		return c.View(it.Data)
+	}
+	app.Get("/users/{id}", handle)
+	app.Put("/users/{id}", handle)
+
+	app.Get("/headers", func(c *Context) error {
+		it, err := BindHeader[UpdateUser](c.Request())
+		if err != nil {
+			return err
+		}
+		return c.View(it.Data)
+	})
+
+	app.Start()
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		want        string
+	}{
+		{"json", http.MethodPut, "/users/42", "application/json; charset=utf-8", `{"id":1,"tenant":"other","name":"xun","notify":true}`,
+			http.StatusOK, `{"id":42,"tenant":"acme","roles":["admin","dev"],"name":"xun","notify":true}`},
+		{"form", http.MethodPut, "/users/42", "application/x-www-form-urlencoded", `name=xun&notify=true`,
+			http.StatusOK, `{"id":42,"tenant":"acme","roles":["admin","dev"],"name":"xun","notify":true}`},
+		{"query", http.MethodGet, "/users/42?name=xun", "", "",
+			http.StatusOK, `{"id":42,"tenant":"acme","roles":["admin","dev"],"name":"xun","notify":false}`},
+		{"unsupported", http.MethodPut, "/users/42", "text/plain", "xun",
+			http.StatusUnsupportedMediaType, `{"error":"unsupported media type","source":"body"}`},
+		{"path_mismatch", http.MethodGet, "/users/abc", "", "",
+			http.StatusBadRequest, `{"error":"invalid path","source":"path","fields":[{"field":"id","code":"type_mismatch","message":"Invalid Integer Value 'abc' Type 'int' Namespace 'id'"}]}`},
+		{"header", http.MethodGet, "/headers", "", "",
+			http.StatusOK, `{"id":0,"tenant":"acme","roles":["admin","dev"],"name":"","notify":false}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(test.method, srv.URL+test.path, strings.NewReader(test.body))
+			require.NoError(t, err)
+			if test.contentType != "" {
+				req.Header.Set("Content-Type", test.contentType)
+			}
+			req.Header.Set("X-Tenant-Id", "acme")
+			req.Header.Add("X-Role", "admin")
+			req.Header.Add("X-Role", "dev")
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, string(buf))
+		})
+	}
+
+	t.Run("forged", func(t *testing.T) {
+		for _, req := range []*http.Request{
+			httptest.NewRequest(http.MethodPut, srv.URL+"/users/42", strings.NewReader(`{"tenant":"admin","roles":["root"],"name":"xun"}`)),
+			httptest.NewRequest(http.MethodGet, srv.URL+"/users/42?Tenant=admin&Roles=root&name=xun", nil),
+		} {
+			req.RequestURI = ""
+			req.Header.Set("Content-Type", "application/json")
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, `{"id":42,"tenant":"","roles":null,"name":"xun","notify":false}`, string(buf))
+		}
+	})
+}
+
+func TestValidator(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/errors/400.html": {Data: []byte(`<ul id="errors">{{ range .Fields }}<li>{{ .Field }} {{ .Code }}</li>{{ end }}</ul>`)},
+	}
+
+	type Login struct {
+		Email  string `form:"email" json:"email" validate:"required,email"`
+		Passwd string `form:"passwd" json:"passwd" validate:"required"`
+	}
+
+	handle := func(c *Context) error {
+		it, err := BindJson[Login](c.Request())
+		if err != nil {
+			return err
+		}
+		return c.View(it.Data.Email)
+	}
+
+	AddValidator(ut.New(zh.New()).GetFallback(), trans.RegisterDefaultTranslations)
+
+	t.Run("struct", func(t *testing.T) {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		app := New(WithMux(mux), WithFsys(fsys), WithValidator(ValidateStruct))
+		defer app.Close()
+
+		app.Post("/login", handle)
+		app.Get("/login", func(c *Context) error {
+			it, err := BindQuery[Login](c.Request())
+			if err != nil {
+				return err
+			}
+			return c.View(it.Data.Email)
+		})
+		app.Start()
+
+		resp, err := client.Post(srv.URL+"/login", "application/json", strings.NewReader(`{"email":"xun@yaitoo.cn","passwd":"123"}`))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/login", strings.NewReader(`{"email":"xun"}`))
+		require.NoError(t, err)
+		req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
+
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.JSONEq(t, `{"error":"invalid json","source":"json","fields":[`+
+			`{"field":"Email","code":"email","message":"Email必须是一个有效的邮箱"},`+
+			`{"field":"Passwd","code":"required","message":"Passwd为必填字段"}]}`, string(buf))
+
+		req, err = http.NewRequest(http.MethodGet, srv.URL+"/login?email=xun", nil)
+		require.NoError(t, err)
+		req.Header.Set("HX-Request", "true")
+
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		buf, err = io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, `<ul id="errors"><li>Email email</li><li>Passwd required</li></ul>`, string(buf))
+	})
+
+	t.Run("locales", func(t *testing.T) {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		app := New(WithMux(mux), WithValidator(ValidateStruct), WithLocales("en", "fr"))
+		defer app.Close()
+
+		app.Post("/login", handle)
+		app.Start()
+
+		// zh is not a locale of the app, so the messages fall back to en
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/login", strings.NewReader(`{"email":"xun"}`))
+		require.NoError(t, err)
+		req.Header.Set("Accept-Language", "zh-CN,zh;q=0.9")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.JSONEq(t, `{"error":"invalid json","source":"json","fields":[`+
+			`{"field":"Email","code":"email","message":"Email must be a valid email address"},`+
+			`{"field":"Passwd","code":"required","message":"Passwd is a required field"}]}`, string(buf))
+	})
+
+	t.Run("func", func(t *testing.T) {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		app := New(WithMux(mux), WithValidator(func(r *http.Request, data any) []FieldError {
+			if it, ok := data.(*Login); ok && strings.HasSuffix(it.Email, "@example.com") {
+				return []FieldError{{Field: "email", Code: "blocked", Message: "email is blocked"}}
+			}
+			return nil
+		}))
+		defer app.Close()
+
+		app.Post("/login", handle)
+		app.Start()
+
+		resp, err := client.Post(srv.URL+"/login", "application/json", strings.NewReader(`{"email":"a@example.com"}`))
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.JSONEq(t, `{"error":"invalid json","source":"json","fields":[{"field":"email","code":"blocked","message":"email is blocked"}]}`, string(buf))
+	})
+}
@@ -0,0 +1,68 @@
+package xun
+
+import (
+	"log/slog"
+	"os/exec"
+
+	"github.com/yaitoo/xun/fsnotify"
+)
+
+// buildHook runs an external build command (e.g. tailwindcss, esbuild) when
+// its source files are changed in development.
+type buildHook struct {
+	patterns []string
+	name     string
+	args     []string
+	trigger  chan struct{}
+}
+
+// match reports whether the changed file is a source of the hook.
+func (h *buildHook) match(name string) bool {
+	for _, p := range h.patterns {
+		if matchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// run executes the build command, and logs its output if it fails.
+func (h *buildHook) run(logger *slog.Logger) {
+	out, err := exec.Command(h.name, h.args...).CombinedOutput()
+	if err != nil {
+		logger.Error("xun: build hook", slog.String("cmd", h.name), slog.Any("err", err), slog.String("output", string(out)))
+		return
+	}
+
+	logger.Info("xun: build hook", slog.String("cmd", h.name))
+}
+
+// startBuildHooks runs all build hooks once, so their outputs are available
+// to the view engines, and then waits for file changes to run them again.
+func (app *App) startBuildHooks() {
+	for _, h := range app.buildHooks {
+		h.run(app.logger)
+		h.trigger = make(chan struct{}, 1)
+
+		go func(h *buildHook) {
+			for range h.trigger {
+				h.run(app.logger)
+			}
+		}(h)
+	}
+}
+
+// triggerBuildHooks schedules the build hooks that watch the changed file.
+// Changes during a running build are coalesced into a single rebuild.
+func (app *App) triggerBuildHooks(event fsnotify.Event) {
+	for _, h := range app.buildHooks {
+		if h.trigger == nil || !h.match(event.Name) {
+			continue
+		}
+
+		select {
+		case h.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun/fsnotify"
+)
+
+func TestBuildCommand(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "src"), os.ModePerm))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "public"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "app.css"), []byte("body { color: red; }"), os.ModePerm))
+
+	script := "cat " + filepath.Join(dir, "src", "app.css") + " > " + filepath.Join(dir, "public", "app.css")
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(os.DirFS(dir)), WithWatch(),
+		WithBuildCommand([]string{"src/*.css"}, "sh", "-c", script))
+
+	app.Start()
+	defer app.Close()
+
+	get := func() string {
+		resp, err := client.Get(srv.URL + "/app.css")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	// outputs of the build command are served by the StaticViewEngine on startup
+	require.Equal(t, "body { color: red; }", get())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "src", "app.css"), []byte("body { color: blue; }"), os.ModePerm))
+
+	// files that are not matched don't trigger the build command
+	app.triggerBuildHooks(fsnotify.Event{Name: "pages/index.html", Op: fsnotify.Write})
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, "body { color: red; }", get())
+
+	app.triggerBuildHooks(fsnotify.Event{Name: "src/app.css", Op: fsnotify.Write})
+	require.Eventually(t, func() bool {
+		return get() == "body { color: blue; }"
+	}, time.Second, 50*time.Millisecond)
+}
+
+func TestBuildCommandDisabledWithoutWatch(t *testing.T) {
+	dir := t.TempDir()
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(os.DirFS(dir)),
+		WithBuildCommand([]string{"src/*.css"}, "sh", "-c", "touch "+filepath.Join(dir, "built")))
+	defer app.Close()
+
+	_, err := os.Stat(filepath.Join(dir, "built"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
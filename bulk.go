@@ -0,0 +1,130 @@
+package xun
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"reflect"
+)
+
+// BulkMaxItems is the max number of items of a Bulk request. Larger requests
+// are rejected with 413 Request Entity Too Large.
+var BulkMaxItems = 1000
+
+// BulkResult is the result of an item of a Bulk request.
+type BulkResult struct {
+	Index  int          `json:"index"`
+	Status int          `json:"status"`
+	Data   any          `json:"data,omitempty"`
+	Error  string       `json:"error,omitempty"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// BulkResponse is the 207 Multi-Status response of a Bulk request.
+type BulkResponse struct {
+	Results []BulkResult `json:"results"`
+}
+
+// Bulk binds the JSON array of the request body, and calls handle with each
+// item in order. The items are validated before they are handled, and the
+// result or error of each item is written to a 207 Multi-Status JSON
+// response, so a failed item doesn't fail the others.
+//
+//	app.Post("/api/users/bulk", func(c *xun.Context) error {
+//		return xun.Bulk(c, func(c *xun.Context, u User) (any, error) {
+//			return db.CreateUser(c.Context(), u)
+//		})
+//	})
+//
+// A *BindError of an item is written with its status and fields, and null
+// items of pointer types are written as 400 without calling handle. Other
+// errors are logged and written as 500 without their messages. The items
+// left when the request is cancelled are written as 503.
+//
+// It returns a *BindError if the body isn't a JSON array, or it has more
+// than BulkMaxItems items.
+func Bulk[T any](c *Context, handle func(c *Context, item T) (any, error)) error {
+	it, err := BindJson[[]T](c.req)
+	if err != nil {
+		return err
+	}
+
+	items := it.Data
+	if len(items) > BulkMaxItems {
+		return &BindError{Message: "too many items", Source: "json", Status: http.StatusRequestEntityTooLarge}
+	}
+
+	languages := c.AcceptLanguage()
+	resp := BulkResponse{Results: make([]BulkResult, 0, len(items))}
+
+	for i, item := range items {
+		if c.Context().Err() != nil {
+			resp.Results = append(resp.Results, BulkResult{Index: i, Status: http.StatusServiceUnavailable, Error: "cancelled"})
+			continue
+		}
+
+		resp.Results = append(resp.Results, c.bulkItem(i, item, languages, func() (any, error) {
+			return handle(c, item)
+		}))
+	}
+
+	buf, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+
+	c.rw.Header().Set("Content-Type", "application/json")
+	c.WriteStatus(http.StatusMultiStatus)
+	_, err = c.rw.Write(buf)
+	return err
+}
+
+// bulkItem validates the item, and returns the BulkResult of handle.
+func (c *Context) bulkItem(i int, item any, languages []string, handle func() (any, error)) BulkResult {
+	err := validateItem(item, languages)
+	var data any
+	if err == nil {
+		data, err = handle()
+	}
+
+	if err == nil {
+		return BulkResult{Index: i, Status: http.StatusOK, Data: data}
+	}
+
+	var be *BindError
+	if errors.As(err, &be) {
+		status := be.Status
+		if status == 0 {
+			status = http.StatusBadRequest
+		}
+		return BulkResult{Index: i, Status: status, Error: be.Message, Fields: be.Fields}
+	}
+
+	c.app.logger.Error("xun: bulk", slog.String("pattern", c.Routing.Pattern), slog.Int("index", i), slog.Any("err", err))
+	return BulkResult{Index: i, Status: http.StatusInternalServerError, Error: http.StatusText(http.StatusInternalServerError)}
+}
+
+// validateItem validates the struct item, and returns a *BindError of its
+// failed fields, or of the item if it is null.
+func validateItem(item any, languages []string) error {
+	if v := reflect.ValueOf(item); v.Kind() == reflect.Pointer && v.IsNil() {
+		return &BindError{Message: "null item", Source: "json"}
+	}
+
+	it := &TEntity[any]{Data: item, Errors: make(map[string]string), source: "json"}
+	if !isStruct(item) || it.Validate(languages...) {
+		return nil
+	}
+
+	return it.Err()
+}
+
+// isStruct reports whether v is a struct or a pointer to a struct.
+func isStruct(v any) bool {
+	t := reflect.TypeOf(v)
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t != nil && t.Kind() == reflect.Struct
+}
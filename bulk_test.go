@@ -0,0 +1,115 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBulk(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	type User struct {
+		Name  string `json:"name" validate:"required"`
+		Email string `json:"email"`
+	}
+
+	app.Post("/users/bulk", func(c *Context) error {
+		return Bulk(c, func(c *Context, u User) (any, error) {
+			switch u.Name {
+			case "taken":
+				return nil, &BindError{Message: "conflict", Source: "json", Status: http.StatusConflict}
+			case "boom":
+				return nil, errors.New("db: connection refused")
+			}
+			return map[string]string{"id": "u-" + u.Name}, nil
+		})
+	})
+
+	app.Start()
+
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		want   string
+	}{
+		{
+			name:   "multi_status",
+			body:   `[{"name":"a"},{"email":"b@yaitoo.cn"},{"name":"taken"},{"name":"boom"}]`,
+			status: http.StatusMultiStatus,
+			want: `{"results":[` +
+				`{"index":0,"status":200,"data":{"id":"u-a"}},` +
+				`{"index":1,"status":400,"error":"invalid json","fields":[{"field":"Name","code":"required","message":"Name is a required field"}]},` +
+				`{"index":2,"status":409,"error":"conflict"},` +
+				`{"index":3,"status":500,"error":"Internal Server Error"}]}`,
+		},
+		{
+			name:   "empty",
+			body:   `[]`,
+			status: http.StatusMultiStatus,
+			want:   `{"results":[]}`,
+		},
+		{
+			name:   "not_array",
+			body:   `{"name":"a"}`,
+			status: http.StatusBadRequest,
+			want:   `{"error":"invalid json","source":"json","fields":[{"field":"","code":"type_mismatch","message":"cannot use object as []xun.User"}]}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := client.Post(srv.URL+"/users/bulk", "application/json", strings.NewReader(test.body))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+			require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, test.want, string(buf))
+		})
+	}
+
+	t.Run("too_many_items", func(t *testing.T) {
+		max := BulkMaxItems
+		BulkMaxItems = 2
+		defer func() { BulkMaxItems = max }()
+
+		resp, err := client.Post(srv.URL+"/users/bulk", "application/json", strings.NewReader(`[{"name":"a"},{"name":"b"},{"name":"c"}]`))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	app.Post("/users/bulk/pointers", func(c *Context) error {
+		return Bulk(c, func(c *Context, u *User) (any, error) {
+			return map[string]string{"id": "u-" + u.Name}, nil
+		})
+	})
+
+	t.Run("null_item", func(t *testing.T) {
+		resp, err := client.Post(srv.URL+"/users/bulk/pointers", "application/json", strings.NewReader(`[{"name":"a"},null]`))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusMultiStatus, resp.StatusCode)
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.JSONEq(t, `{"results":[{"index":0,"status":200,"data":{"id":"u-a"}},{"index":1,"status":400,"error":"null item"}]}`, string(buf))
+	})
+}
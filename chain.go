@@ -3,3 +3,8 @@ package xun
 type chain interface {
 	Next(hf HandleFunc) HandleFunc
 }
+
+// middlewareLister is implemented by chains that expose their middlewares, e.g. for PrintRoutes.
+type middlewareLister interface {
+	middlewareList() []Middleware
+}
@@ -0,0 +1,105 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Clock provides the current time, so it can be replaced in tests.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc is an adapter to allow the use of ordinary functions as Clock.
+type ClockFunc func() time.Time
+
+// Now calls f().
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// LocationCookie is the name of the cookie that c.Location reads the IANA time
+// zone of the client from, e.g. set by `Intl.DateTimeFormat().resolvedOptions().timeZone`.
+var LocationCookie = "tz"
+
+// LocationHeader is the name of the header that c.Location reads the IANA time
+// zone of the client from, e.g. for API clients.
+var LocationHeader = "X-Timezone"
+
+type locationContextKey struct{}
+
+var locations sync.Map // map[string]*time.Location
+
+// Now returns the current time of the Clock set by WithClock.
+func (app *App) Now() time.Time {
+	if app.clock == nil {
+		return time.Now()
+	}
+	return app.clock.Now()
+}
+
+// Now returns the current time of the app clock in the location of the client.
+func (c *Context) Now() time.Time {
+	return c.app.Now().In(c.Location())
+}
+
+// Location returns the time zone of the client. It is resolved from the
+// location set by c.SetLocation, e.g. from the user profile, the
+// LocationCookie and the LocationHeader in order, and falls back to the
+// location set by WithLocation.
+func (c *Context) Location() *time.Location {
+	return c.app.location(c.req)
+}
+
+// SetLocation sets the time zone of the client, e.g. by a middleware that
+// loads the user profile. It takes precedence over the cookie and header, and
+// is also used by template functions.
+func (c *Context) SetLocation(loc *time.Location) {
+	c.WithContext(context.WithValue(c.Context(), locationContextKey{}, loc))
+}
+
+func (app *App) location(r *http.Request) *time.Location {
+	if r != nil {
+		if loc, ok := r.Context().Value(locationContextKey{}).(*time.Location); ok && loc != nil {
+			return loc
+		}
+
+		if cookie, err := r.Cookie(LocationCookie); err == nil {
+			if loc := loadLocation(cookie.Value); loc != nil {
+				return loc
+			}
+		}
+
+		if loc := loadLocation(r.Header.Get(LocationHeader)); loc != nil {
+			return loc
+		}
+	}
+
+	if app.defaultLocation != nil {
+		return app.defaultLocation
+	}
+	return time.Local
+}
+
+// loadLocation loads the location by its IANA name, and caches it. It returns
+// nil if the name is invalid. Only valid names are cached, so the cache is
+// bounded by the tz database.
+func loadLocation(name string) *time.Location {
+	if name == "" || len(name) > 64 {
+		return nil
+	}
+
+	if loc, ok := locations.Load(name); ok {
+		return loc.(*time.Location)
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil || name == "Local" {
+		return nil
+	}
+
+	locations.Store(name, loc)
+	return loc
+}
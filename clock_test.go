@@ -0,0 +1,87 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocation(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+
+	app := New(WithLocation(time.UTC))
+	defer app.Close()
+
+	newContext := func(header ...string) *Context {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		return &Context{app: app, req: req}
+	}
+
+	require.Equal(t, time.UTC, newContext().Location())
+	require.Equal(t, "Asia/Shanghai", newContext(LocationHeader, "Asia/Shanghai").Location().String())
+	require.Equal(t, "Europe/Paris", newContext("Cookie", LocationCookie+"=Europe/Paris", LocationHeader, "Asia/Shanghai").Location().String())
+	require.Equal(t, time.UTC, newContext(LocationHeader, "Mars/Olympus").Location())
+	require.Equal(t, time.UTC, newContext(LocationHeader, "Local").Location())
+
+	c := newContext("Cookie", LocationCookie+"=Europe/Paris")
+	c.SetLocation(tokyo)
+	require.Equal(t, tokyo, c.Location())
+}
+
+func TestClock(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`{{ now.Format "2006-01-02 15:04" }}|{{ .At | formatTime "15:04 MST" }}|{{ (localTime .At).Hour }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithLocation(time.UTC), WithClock(ClockFunc(func() time.Time { return now })))
+	defer app.Close()
+
+	app.Get("/now", func(c *Context) error {
+		return c.View(map[string]string{"now": c.Now().Format(time.RFC3339)})
+	})
+	app.Get("/at", func(c *Context) error {
+		return c.View(map[string]any{"At": now.Add(-time.Hour)}, "index")
+	})
+
+	app.Start()
+
+	get := func(path string) string {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set(LocationHeader, "Asia/Shanghai")
+		req.Header.Set("Accept", "text/html, */*")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	require.Equal(t, `{"now":"2025-01-01T08:00:00+08:00"}
+`, get("/now"))
+	require.Equal(t, `2025-01-01 08:00|07:00 CST|7`, get("/at"))
+
+	// signed URLs expire by the clock
+	u := app.SignURL("/files/a.pdf", time.Minute)
+	req := httptest.NewRequest(http.MethodGet, u, nil)
+	require.NoError(t, app.VerifyURL(req.URL))
+
+	now = now.Add(2 * time.Minute)
+	require.ErrorIs(t, app.VerifyURL(req.URL), ErrURLExpired)
+}
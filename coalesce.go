@@ -0,0 +1,213 @@
+package xun
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// coalesceHeaders are the request headers that make requests of the same URL different.
+var coalesceHeaders = []string{"Accept", "Accept-Language", "HX-Request", "HX-Target", "HX-Trigger", "HX-Boosted"}
+
+// coalescer shares the response of identical concurrent requests.
+type coalescer struct {
+	mu        sync.Mutex
+	calls     map[string]*coalesceCall
+	lastSweep time.Time
+}
+
+// coalesceCall is a response that is being rendered, or that is reused until it expires.
+type coalesceCall struct {
+	done    chan struct{}
+	resp    *recordedResponse
+	expires time.Time
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{
+		calls: make(map[string]*coalesceCall),
+	}
+}
+
+// coalesceKey returns the key of the request, or false if the request could be personalized.
+func coalesceKey(req *http.Request) (string, bool) {
+	if req.Header.Get("Authorization") != "" || req.Header.Get("Cookie") != "" {
+		return "", false
+	}
+
+	var sb strings.Builder
+	sb.WriteString(req.Method)
+	sb.WriteByte(' ')
+	sb.WriteString(req.Host)
+	sb.WriteString(req.URL.RequestURI())
+	for _, h := range coalesceHeaders {
+		sb.WriteByte('\n')
+		sb.WriteString(req.Header.Get(h))
+	}
+
+	return sb.String(), true
+}
+
+// coalesce runs the route r once for identical concurrent requests, and replays its response to all of them.
+func (app *App) coalesce(r *Routing, rw *statsResponseWriter, req *http.Request, msg string) {
+	key, ok := coalesceKey(req)
+	if !ok {
+		app.handle(r, rw, req, msg)
+		return
+	}
+
+	if len(app.layoutRules) > 0 {
+		// the pages of the same URL are rendered with different layouts
+		key += "\n" + app.layoutVariant(req)
+	}
+
+	c := app.coalescer
+	now := time.Now()
+
+	c.mu.Lock()
+	call, ok := c.calls[key]
+	if ok && call.resp != nil && now.After(call.expires) {
+		ok = false
+	}
+
+	if ok {
+		c.mu.Unlock()
+
+		select {
+		case <-call.done:
+		case <-req.Context().Done():
+			return
+		}
+
+		if call.resp == nil {
+			// the response is not shareable
+			app.handle(r, rw, req, msg)
+			return
+		}
+
+		app.replay(r, call.resp, rw, req, msg)
+		return
+	}
+
+	call = &coalesceCall{done: make(chan struct{})}
+	c.calls[key] = call
+	c.sweep(now, r.Options.coalesce)
+	c.mu.Unlock()
+
+	rec := &responseRecorder{header: make(http.Header)}
+	completed := false
+
+	defer func() {
+		c.mu.Lock()
+		if completed && rec.shareable() {
+			call.resp = rec.response()
+			call.expires = time.Now().Add(r.Options.coalesce)
+		} else {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	app.handle(r, &statsResponseWriter{ResponseWriter: rec}, req, msg)
+	completed = true
+
+	rec.response().replay(rw)
+}
+
+// replay writes the shared response to the request with the middlewares of
+// the route r, so it is checked and logged, e.g. by AccessLog, as other requests.
+func (app *App) replay(r *Routing, resp *recordedResponse, rw *statsResponseWriter, req *http.Request, msg string) {
+	rr := *r
+	rr.Handle = func(c *Context) error {
+		resp.replay(c.rw)
+		return nil
+	}
+
+	app.handle(&rr, rw, req, msg)
+}
+
+// sweep deletes expired responses at most once per ttl.
+func (c *coalescer) sweep(now time.Time, ttl time.Duration) {
+	if now.Sub(c.lastSweep) < ttl {
+		return
+	}
+	c.lastSweep = now
+
+	for key, call := range c.calls {
+		if call.resp != nil && now.After(call.expires) {
+			delete(c.calls, key)
+		}
+	}
+}
+
+// recordedResponse is a response recorded by responseRecorder.
+type recordedResponse struct {
+	statusCode int
+	header     http.Header
+	body       []byte
+}
+
+// replay writes the recorded response to w.
+func (resp *recordedResponse) replay(w http.ResponseWriter) {
+	h := w.Header()
+	for k, v := range resp.header {
+		h[k] = v
+	}
+
+	w.WriteHeader(resp.statusCode)
+	w.Write(resp.body) // nolint: errcheck
+}
+
+// responseRecorder is a ResponseWriter that buffers the response in memory.
+type responseRecorder struct {
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+}
+
+// Header returns the header map of the recorded response.
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+// WriteHeader records the status code.
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	if rec.statusCode == 0 {
+		rec.statusCode = statusCode
+	}
+}
+
+// Write records the response body.
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	return rec.body.Write(p)
+}
+
+// Close implements the ResponseWriter interface. It is a no-op.
+func (*responseRecorder) Close() {
+}
+
+// shareable reports whether the recorded response can be reused by other requests.
+func (rec *responseRecorder) shareable() bool {
+	return (rec.statusCode == http.StatusOK || rec.statusCode == 0) && len(rec.header.Values("Set-Cookie")) == 0
+}
+
+// response returns the recorded response.
+func (rec *responseRecorder) response() *recordedResponse {
+	statusCode := rec.statusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+
+	return &recordedResponse{
+		statusCode: statusCode,
+		header:     rec.header.Clone(),
+		body:       rec.body.Bytes(),
+	}
+}
@@ -0,0 +1,179 @@
+package xun
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithCoalesce(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	var renders int32
+	release := make(chan struct{})
+
+	app.Get("/poll", func(c *Context) error {
+		n := atomic.AddInt32(&renders, 1)
+		<-release
+		return c.View(map[string]any{"n": n})
+	}, WithCoalesce(time.Minute))
+
+	app.Get("/private", func(c *Context) error {
+		n := atomic.AddInt32(&renders, 1)
+		c.WriteHeader("Set-Cookie", "sid=1")
+		return c.View(map[string]any{"n": n})
+	}, WithCoalesce(time.Minute))
+
+	app.Start()
+	defer app.Close()
+
+	get := func(path string, header ...string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	var wg sync.WaitGroup
+	bodies := make([]string, 20)
+	for i := range bodies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, bodies[i] = get("/poll")
+		}(i)
+	}
+
+	// wait for all requests to arrive at the leader
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&renders) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&renders))
+	for _, body := range bodies {
+		require.JSONEq(t, `{"n":1}`, body)
+	}
+
+	// reused in ttl window
+	status, body := get("/poll")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"n":1}`, body)
+	require.Equal(t, int32(1), atomic.LoadInt32(&renders))
+
+	// different htmx target is rendered on its own
+	_, body = get("/poll", "HX-Request", "true", "HX-Target", "#list")
+	require.JSONEq(t, `{"n":2}`, body)
+
+	// personalized requests are never shared
+	_, body = get("/poll", "Cookie", "sid=1")
+	require.JSONEq(t, `{"n":3}`, body)
+
+	// responses with Set-Cookie are never shared
+	for i := 4; i < 6; i++ {
+		_, body = get("/private")
+		require.JSONEq(t, `{"n":`+strconv.Itoa(i)+`}`, body)
+	}
+}
+
+func TestWithCoalesceExpires(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	var renders int32
+	app.Get("/poll", func(c *Context) error {
+		return c.View(map[string]any{"n": atomic.AddInt32(&renders, 1)})
+	}, WithCoalesce(20*time.Millisecond))
+
+	app.Start()
+	defer app.Close()
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(srv.URL + "/poll")
+		require.NoError(t, err)
+		resp.Body.Close()
+	}
+	require.Equal(t, int32(1), atomic.LoadInt32(&renders))
+
+	time.Sleep(30 * time.Millisecond)
+
+	resp, err := client.Get(srv.URL + "/poll")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, int32(2), atomic.LoadInt32(&renders))
+}
+
+func TestWithCoalesceAccessLog(t *testing.T) {
+	out := &syncBuffer{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithLogger(slog.New(slog.NewJSONHandler(out, nil))))
+	app.Use(AccessLog())
+
+	var renders int32
+	release := make(chan struct{})
+	app.Get("/poll", func(c *Context) error {
+		atomic.AddInt32(&renders, 1)
+		<-release
+		return c.View(map[string]any{"n": 1})
+	}, WithCoalesce(time.Minute))
+
+	app.Start()
+	defer app.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := client.Get(srv.URL + "/poll")
+			require.NoError(t, err)
+			resp.Body.Close()
+		}()
+	}
+
+	require.Eventually(t, func() bool { return atomic.LoadInt32(&renders) == 1 }, time.Second, time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// reused in ttl window
+	resp, err := client.Get(srv.URL + "/poll")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&renders))
+
+	entries := out.entries(t)
+	require.Len(t, entries, 6)
+	for _, it := range entries {
+		require.Equal(t, "/poll", it["path"])
+		require.Equal(t, float64(http.StatusOK), it["status"])
+		require.Equal(t, float64(len(`{"n":1}`)+1), it["bytes"])
+	}
+}
@@ -1,6 +1,10 @@
 package xun
 
-import "net/http"
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
 
 // Compressor is an interface that defines methods for handling HTTP response compression.
 // Implementations of this interface should provide the specific encoding type they support
@@ -13,3 +17,30 @@ type Compressor interface {
 	AcceptEncoding() string
 	New(rw http.ResponseWriter) ResponseWriter
 }
+
+// acceptsEncoding reports whether the Accept-Encoding header accepts the
+// encoding, e.g. "gzip, br;q=0.8". The encodings with q=0 are refused, and
+// the wildcard "*" accepts the encodings that aren't listed.
+func acceptsEncoding(header, encoding string) bool {
+	wildcard := false
+	for _, it := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(it), ";")
+		name = strings.TrimSpace(name)
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+
+		switch {
+		case strings.EqualFold(name, encoding):
+			return q > 0
+		case name == "*":
+			wildcard = q > 0
+		}
+	}
+
+	return wildcard
+}
@@ -0,0 +1,34 @@
+package xun
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// brotliPool is the pool of the brotli writers of BrotliCompressor.
+var brotliPool = &sync.Pool{
+	New: func() any {
+		// level 5 is a good tradeoff of ratio and speed for dynamic responses
+		return brotli.NewWriterLevel(nil, 5)
+	},
+}
+
+// BrotliCompressor is a struct that provides functionality for compressing data using the Brotli algorithm.
+type BrotliCompressor struct {
+}
+
+// AcceptEncoding returns the encoding type that the BrotliCompressor supports.
+// In this case, it returns the string "br".
+func (c *BrotliCompressor) AcceptEncoding() string {
+	return "br"
+}
+
+// New creates a new ResponseWriter that wraps the provided http.ResponseWriter.
+// It compresses the response with a pooled brotli writer, and sets the
+// "Content-Encoding" header to "br", unless the content type is skipped by
+// CompressSkipTypes.
+func (c *BrotliCompressor) New(rw http.ResponseWriter) ResponseWriter {
+	return newCompressResponseWriter(rw, "br", brotliPool)
+}
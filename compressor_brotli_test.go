@@ -0,0 +1,146 @@
+package xun
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBrotliCompressor(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{0}, 64)...)
+	fsys := fstest.MapFS{
+		"public/skin.css": {
+			Data: bytes.Repeat([]byte("body { color: red; }\n"), 100),
+		},
+		"public/logo.png": {
+			Data: png,
+		},
+		"pages/index.html": {
+			Data: []byte("<html><head><title>index</title></head><body></body></html>"),
+		},
+	}
+
+	m := http.NewServeMux()
+	srv := httptest.NewServer(m)
+	defer srv.Close()
+
+	app := New(WithMux(m), WithFsys(fsys), WithCompressor(&BrotliCompressor{}, &GzipCompressor{}))
+	defer app.Close()
+
+	app.Get("/created", func(c *Context) error {
+		c.WriteStatus(http.StatusCreated)
+		return c.View(map[string]string{"message": "hello"})
+	})
+
+	app.Get("/empty", func(c *Context) error {
+		c.WriteStatus(http.StatusNoContent)
+		return nil
+	})
+
+	app.Get("/sniff", func(c *Context) error {
+		_, err := c.Writer().Write([]byte("<html><body>sniffed</body></html>"))
+		return err
+	})
+
+	app.Start()
+
+	get := func(t *testing.T, path, acceptEncoding string) (*http.Response, []byte) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var r io.Reader = resp.Body
+		switch resp.Header.Get("Content-Encoding") {
+		case "br":
+			r = brotli.NewReader(r)
+		case "gzip":
+			r, err = gzip.NewReader(r)
+			require.NoError(t, err)
+		}
+
+		buf, err := io.ReadAll(r)
+		require.NoError(t, err)
+		return resp, buf
+	}
+
+	t.Run("static", func(t *testing.T) {
+		resp, buf := get(t, "/skin.css", "gzip, br")
+
+		require.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		require.Equal(t, "Accept-Encoding", resp.Header.Get("Vary"))
+		// the length of the file is removed, the length of the compressed body is set by the http.Server
+		require.NotEqual(t, strconv.Itoa(len(buf)), resp.Header.Get("Content-Length"))
+		require.Equal(t, fsys["public/skin.css"].Data, buf)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		resp, buf := get(t, "/", "br")
+
+		require.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		require.Equal(t, fsys["pages/index.html"].Data, buf)
+	})
+
+	t.Run("q", func(t *testing.T) {
+		resp, buf := get(t, "/skin.css", "br;q=0, gzip;q=0.5")
+
+		require.Equal(t, "gzip", resp.Header.Get("Content-Encoding"))
+		require.Equal(t, fsys["public/skin.css"].Data, buf)
+
+		resp, buf = get(t, "/skin.css", "*;q=0")
+		require.Empty(t, resp.Header.Get("Content-Encoding"))
+		require.Equal(t, strconv.Itoa(len(buf)), resp.Header.Get("Content-Length"))
+	})
+
+	t.Run("skip_compressed_types", func(t *testing.T) {
+		resp, buf := get(t, "/logo.png", "br")
+
+		require.Empty(t, resp.Header.Get("Content-Encoding"))
+		require.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		require.Equal(t, strconv.Itoa(len(png)), resp.Header.Get("Content-Length"))
+		require.Equal(t, png, buf)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		resp, buf := get(t, "/created", "br")
+
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		require.JSONEq(t, `{"message":"hello"}`, string(buf))
+
+		resp, buf = get(t, "/empty", "br")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Content-Encoding"))
+		require.Empty(t, buf)
+	})
+
+	t.Run("sniff", func(t *testing.T) {
+		resp, buf := get(t, "/sniff", "br")
+
+		require.Equal(t, "br", resp.Header.Get("Content-Encoding"))
+		require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, "<html><body>sniffed</body></html>", string(buf))
+	})
+}
+
+func TestAcceptsEncoding(t *testing.T) {
+	require.True(t, acceptsEncoding("gzip, deflate, br", "br"))
+	require.True(t, acceptsEncoding("GZIP", "gzip"))
+	require.True(t, acceptsEncoding("*", "br"))
+	require.True(t, acceptsEncoding("gzip;q=0.5", "gzip"))
+	require.False(t, acceptsEncoding("gzip;q=0", "gzip"))
+	require.False(t, acceptsEncoding("*, br;q=0", "br"))
+	require.False(t, acceptsEncoding("", "gzip"))
+	require.False(t, acceptsEncoding("identity", "gzip"))
+}
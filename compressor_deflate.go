@@ -3,8 +3,17 @@ package xun
 import (
 	"compress/flate"
 	"net/http"
+	"sync"
 )
 
+// deflatePool is the pool of the flate writers of DeflateCompressor.
+var deflatePool = &sync.Pool{
+	New: func() any {
+		w, _ := flate.NewWriter(nil, flate.DefaultCompression) //nolint: errcheck because flate.DefaultCompression is a valid compression level
+		return w
+	},
+}
+
 // DeflateCompressor is a struct that provides functionality for compressing data using the DEFLATE algorithm.
 type DeflateCompressor struct {
 }
@@ -15,15 +24,10 @@ func (c *DeflateCompressor) AcceptEncoding() string {
 	return "deflate"
 }
 
-// New creates a new deflateResponseWriter that wraps the provided http.ResponseWriter.
-// It sets the "Content-Encoding" header to "deflate" and initializes a flate.Writer
-// with the default compression level.
+// New creates a new ResponseWriter that wraps the provided http.ResponseWriter.
+// It compresses the response with a pooled flate writer of the default
+// compression level, and sets the "Content-Encoding" header to "deflate",
+// unless the content type is skipped by CompressSkipTypes.
 func (c *DeflateCompressor) New(rw http.ResponseWriter) ResponseWriter {
-	rw.Header().Set("Content-Encoding", "deflate")
-	w, _ := flate.NewWriter(rw, flate.DefaultCompression) //nolint: errcheck because flate.DefaultCompression is a valid compression level
-
-	return &deflateResponseWriter{
-		w:              w,
-		ResponseWriter: rw,
-	}
+	return newCompressResponseWriter(rw, "deflate", deflatePool)
 }
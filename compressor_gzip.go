@@ -3,8 +3,16 @@ package xun
 import (
 	"compress/gzip"
 	"net/http"
+	"sync"
 )
 
+// gzipPool is the pool of the gzip writers of GzipCompressor.
+var gzipPool = &sync.Pool{
+	New: func() any {
+		return gzip.NewWriter(nil)
+	},
+}
+
 // GzipCompressor is a struct that provides methods for compressing and decompressing data using the Gzip algorithm.
 type GzipCompressor struct {
 }
@@ -15,14 +23,10 @@ func (c *GzipCompressor) AcceptEncoding() string {
 	return "gzip"
 }
 
-// New creates a new gzipResponseWriter that wraps the provided http.ResponseWriter.
-// It sets the "Content-Encoding" header to "gzip" and returns the wrapped writer.
+// New creates a new ResponseWriter that wraps the provided http.ResponseWriter.
+// It compresses the response with a pooled gzip writer, and sets the
+// "Content-Encoding" header to "gzip", unless the content type is skipped by
+// CompressSkipTypes.
 func (c *GzipCompressor) New(rw http.ResponseWriter) ResponseWriter {
-	rw.Header().Set("Content-Encoding", "gzip")
-
-	return &gzipResponseWriter{
-		w:              gzip.NewWriter(rw),
-		ResponseWriter: rw,
-	}
-
+	return newCompressResponseWriter(rw, "gzip", gzipPool)
 }
@@ -0,0 +1,246 @@
+package xun
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvKey is the environment variable that selects the environment of LoadConfig, e.g. "production".
+const EnvKey = "XUN_ENV"
+
+// Config is the deployment configuration of an App. It can be embedded in
+// the configuration struct of an application, and loaded by LoadConfig.
+type Config struct {
+	// Addr is the TCP address the server listens on, e.g. ":8080".
+	Addr string `json:"addr" env:"XUN_ADDR"`
+	// TLSCertFile and TLSKeyFile are the certificate and key files of TLS.
+	TLSCertFile string `json:"tls_cert_file" env:"XUN_TLS_CERT_FILE"`
+	TLSKeyFile  string `json:"tls_key_file" env:"XUN_TLS_KEY_FILE"`
+	// LogLevel is the level of the logger, e.g. "debug", "info", "warn" or "error".
+	LogLevel string `json:"log_level" env:"XUN_LOG_LEVEL"`
+	// Watch enables hot reload in development.
+	Watch bool `json:"watch" env:"XUN_WATCH"`
+	// AssetHost is the CDN host of static assets. See WithAssetHost.
+	AssetHost string `json:"asset_host" env:"XUN_ASSET_HOST"`
+	// Fingerprint enables content hash fingerprinting of static assets. See WithFingerprint.
+	Fingerprint bool `json:"fingerprint" env:"XUN_FINGERPRINT"`
+	// TrustedProxies are the IP addresses or CIDR ranges of reverse proxies. See WithTrustedProxies.
+	TrustedProxies []string `json:"trusted_proxies" env:"XUN_TRUSTED_PROXIES"`
+	// BufferPoolSize is the number of buffers kept for rendering views. See WithBufferPool.
+	BufferPoolSize int `json:"buffer_pool_size" env:"XUN_BUFFER_POOL_SIZE"`
+}
+
+// TLS reports whether both TLSCertFile and TLSKeyFile are set.
+func (cfg *Config) TLS() bool {
+	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
+}
+
+// Validate checks the values of the configuration, e.g. a typo of LogLevel.
+// It is called by LoadConfig.
+func (cfg *Config) Validate() error {
+	if cfg.LogLevel != "" {
+		if _, err := cfg.logLevel(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logLevel parses LogLevel.
+func (cfg *Config) logLevel() (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		return level, fmt.Errorf("xun: config log_level %q: %w", cfg.LogLevel, err)
+	}
+	return level, nil
+}
+
+// Options returns the App options driven by the configuration. It returns an
+// error if a value is invalid, see Validate.
+func (cfg *Config) Options() ([]Option, error) {
+	var opts []Option
+
+	if cfg.LogLevel != "" {
+		level, err := cfg.logLevel()
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, WithLogger(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))))
+	}
+
+	if cfg.Watch {
+		opts = append(opts, WithWatch())
+	}
+
+	if cfg.AssetHost != "" {
+		opts = append(opts, WithAssetHost(cfg.AssetHost))
+	}
+
+	if cfg.Fingerprint {
+		opts = append(opts, WithFingerprint())
+	}
+
+	if len(cfg.TrustedProxies) > 0 {
+		opts = append(opts, WithTrustedProxies(cfg.TrustedProxies...))
+	}
+
+	if cfg.BufferPoolSize > 0 {
+		opts = append(opts, WithBufferPool(cfg.BufferPoolSize))
+	}
+
+	return opts, nil
+}
+
+// LoadConfig loads the configuration T from the JSON file name in fsys, and then
+// overrides it with environment variables named by the `env` field tags.
+//
+// If XUN_ENV is set, e.g. "production", the file of the environment, e.g.
+// "config.production.json" for "config.json", is loaded over the file.
+// Missing files are skipped, so fsys can be nil and name can be empty.
+//
+// Supported field types are string, bool, ints, uints, floats, time.Duration
+// and comma-separated []string. Nested and embedded structs are loaded recursively.
+//
+// If T has a Validate method, e.g. by embedding Config, it is called to reject
+// invalid values, e.g. XUN_LOG_LEVEL=warning.
+func LoadConfig[T any](fsys fs.FS, name string) (T, error) {
+	var cfg T
+
+	if fsys != nil && name != "" {
+		names := []string{name}
+		if env := os.Getenv(EnvKey); env != "" {
+			ext := path.Ext(name)
+			names = append(names, strings.TrimSuffix(name, ext)+"."+env+ext)
+		}
+
+		for _, n := range names {
+			buf, err := fs.ReadFile(fsys, n)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					continue
+				}
+				return cfg, err
+			}
+
+			if err := json.Unmarshal(buf, &cfg); err != nil {
+				return cfg, fmt.Errorf("xun: config %s: %w", n, err)
+			}
+		}
+	}
+
+	v := reflect.ValueOf(&cfg).Elem()
+	if v.Kind() != reflect.Struct {
+		return cfg, nil
+	}
+
+	if err := loadEnv(v); err != nil {
+		return cfg, err
+	}
+
+	if vc, ok := any(&cfg).(interface{ Validate() error }); ok {
+		return cfg, vc.Validate()
+	}
+
+	return cfg, nil
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// loadEnv sets the fields of the struct v from environment variables.
+func loadEnv(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		fv := v.Field(i)
+		key := f.Tag.Get("env")
+
+		if key == "" {
+			if fv.Kind() == reflect.Struct {
+				if err := loadEnv(fv); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		s, ok := os.LookupEnv(key)
+		if !ok {
+			continue
+		}
+
+		if err := setField(fv, s); err != nil {
+			return fmt.Errorf("xun: config %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
+
+// setField parses s into the field v.
+func setField(v reflect.Value, s string) error {
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return errors.ErrUnsupported
+		}
+		// the slice is made by its type, e.g. a named type of []string
+		items := reflect.MakeSlice(v.Type(), 0, strings.Count(s, ",")+1)
+		for _, it := range strings.Split(s, ",") {
+			if it = strings.TrimSpace(it); it != "" {
+				items = reflect.Append(items, reflect.ValueOf(it).Convert(v.Type().Elem()))
+			}
+		}
+		v.Set(items)
+	default:
+		return errors.ErrUnsupported
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package xun
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	type Hosts []string
+	type Role string
+
+	type AppConfig struct {
+		Config
+		Name    string        `json:"name" env:"APP_NAME"`
+		Timeout time.Duration `json:"timeout" env:"APP_TIMEOUT"`
+		Ratio   float64       `env:"APP_RATIO"`
+		Workers uint          `env:"APP_WORKERS"`
+		Hosts   Hosts         `env:"APP_HOSTS"`
+		Roles   []Role        `env:"APP_ROLES"`
+	}
+
+	fsys := fstest.MapFS{
+		"config.json": {
+			Data: []byte(`{"addr":":8080","log_level":"debug","name":"xun","trusted_proxies":["10.0.0.0/8"]}`),
+		},
+		"config.production.json": {
+			Data: []byte(`{"addr":":80","fingerprint":true}`),
+		},
+		"invalid.json": {
+			Data: []byte(`{"addr":`),
+		},
+	}
+
+	t.Run("file", func(t *testing.T) {
+		cfg, err := LoadConfig[AppConfig](fsys, "config.json")
+		require.NoError(t, err)
+		require.Equal(t, ":8080", cfg.Addr)
+		require.Equal(t, "debug", cfg.LogLevel)
+		require.Equal(t, "xun", cfg.Name)
+		require.Equal(t, []string{"10.0.0.0/8"}, cfg.TrustedProxies)
+		require.False(t, cfg.Fingerprint)
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		t.Setenv(EnvKey, "production")
+
+		cfg, err := LoadConfig[AppConfig](fsys, "config.json")
+		require.NoError(t, err)
+		require.Equal(t, ":80", cfg.Addr)
+		require.Equal(t, "xun", cfg.Name)
+		require.True(t, cfg.Fingerprint)
+	})
+
+	t.Run("env_vars", func(t *testing.T) {
+		t.Setenv("XUN_ADDR", ":9090")
+		t.Setenv("XUN_WATCH", "true")
+		t.Setenv("XUN_TRUSTED_PROXIES", "127.0.0.1, 10.0.0.0/8")
+		t.Setenv("APP_TIMEOUT", "3s")
+		t.Setenv("APP_RATIO", "0.5")
+		t.Setenv("APP_WORKERS", "4")
+		t.Setenv("APP_HOSTS", "a.example.com,b.example.com")
+		t.Setenv("APP_ROLES", "admin, editor")
+
+		cfg, err := LoadConfig[AppConfig](fsys, "config.json")
+		require.NoError(t, err)
+		require.Equal(t, ":9090", cfg.Addr)
+		require.True(t, cfg.Watch)
+		require.Equal(t, []string{"127.0.0.1", "10.0.0.0/8"}, cfg.TrustedProxies)
+		require.Equal(t, 3*time.Second, cfg.Timeout)
+		require.Equal(t, 0.5, cfg.Ratio)
+		require.Equal(t, uint(4), cfg.Workers)
+		require.Equal(t, Hosts{"a.example.com", "b.example.com"}, cfg.Hosts)
+		require.Equal(t, []Role{"admin", "editor"}, cfg.Roles)
+	})
+
+	t.Run("missing_file", func(t *testing.T) {
+		t.Setenv("XUN_ADDR", ":9090")
+
+		cfg, err := LoadConfig[Config](nil, "")
+		require.NoError(t, err)
+		require.Equal(t, ":9090", cfg.Addr)
+
+		cfg, err = LoadConfig[Config](fsys, "missing.json")
+		require.NoError(t, err)
+		require.Equal(t, ":9090", cfg.Addr)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := LoadConfig[Config](fsys, "invalid.json")
+		require.Error(t, err)
+
+		t.Setenv("XUN_WATCH", "yes")
+		_, err = LoadConfig[Config](nil, "")
+		require.ErrorContains(t, err, "XUN_WATCH")
+	})
+
+	t.Run("invalid_log_level", func(t *testing.T) {
+		t.Setenv("XUN_LOG_LEVEL", "warning")
+		_, err := LoadConfig[Config](nil, "")
+		require.ErrorContains(t, err, `"warning"`)
+
+		cfg := Config{LogLevel: "warning"}
+		_, err = cfg.Options()
+		require.ErrorContains(t, err, `"warning"`)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		cfg := Config{
+			LogLevel:       "warn",
+			AssetHost:      "https://cdn.example.com",
+			Fingerprint:    true,
+			TrustedProxies: []string{"127.0.0.1"},
+			BufferPoolSize: 10,
+		}
+
+		opts, err := cfg.Options()
+		require.NoError(t, err)
+
+		pool := BufPool
+		app := New(WithFsys(fstest.MapFS{}))
+		for _, o := range opts {
+			o(app)
+		}
+
+		// the pool is of the app only
+		require.Same(t, pool, BufPool)
+		require.NotSame(t, BufPool, app.bufferPool())
+
+		require.Equal(t, "https://cdn.example.com", app.assetHost)
+		require.True(t, app.fingerprint)
+		require.Len(t, app.trustedProxies, 1)
+		require.False(t, cfg.TLS())
+	})
+}
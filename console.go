@@ -0,0 +1,158 @@
+package xun
+
+import (
+	"embed"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed console
+var consoleFsys embed.FS
+
+// ConsolePrefix is the URL prefix of the console enabled by WithConsole.
+const ConsolePrefix = "/_xun"
+
+type consoleData struct {
+	Routes      []consoleRoute
+	Navigation  []*Navigation
+	Templates   []consoleTemplate
+	Diagnostics []consoleDiagnostic
+	Caches      []consoleCache
+	Coalesced   int
+	Streams     int
+	Config      []consoleSetting
+}
+
+type consoleRoute struct {
+	Method     string
+	Pattern    string
+	Host       string
+	Name       string
+	Middleware []string
+	Viewers    []string
+}
+
+type consoleTemplate struct {
+	Name   string
+	Viewer string
+}
+
+type consoleDiagnostic struct {
+	File  string
+	Line  int
+	Error string
+}
+
+type consoleCache struct {
+	Name string
+	LoaderStats
+}
+
+type consoleSetting struct {
+	Key   string
+	Value string
+}
+
+// handleConsole registers the console routes behind the auth middleware.
+// It is rendered by a HtmlViewer, or a JsonViewer for "Accept: application/json".
+func (app *App) handleConsole() {
+	t := NewHtmlTemplate("console", "console/index.html")
+	t.funcs = app.funcs
+	t.requestFuncs = app.requestFuncs
+
+	if err := t.Load(consoleFsys, nil); err != nil {
+		app.logger.Error("xun: load console", slog.Any("err", err))
+		return
+	}
+
+	g := app.Group(ConsolePrefix)
+	g.Use(app.console)
+	g.Get("/{$}", func(c *Context) error {
+		return c.View(app.consoleData())
+	}, WithViewer(&HtmlViewer{template: t}, &JsonViewer{}))
+}
+
+// consoleData returns a snapshot of the app for the console.
+func (app *App) consoleData() *consoleData {
+	data := &consoleData{}
+
+	for _, d := range app.TemplateDiagnostics() {
+		data.Diagnostics = append(data.Diagnostics, consoleDiagnostic{File: d.File, Line: d.Line, Error: d.Error()})
+	}
+
+	app.mu.RLock()
+	var navs []*Navigation
+	for _, r := range app.routes {
+		method, host, path := splitPattern(r.Pattern)
+
+		cr := consoleRoute{
+			Method:     method,
+			Pattern:    "/" + path,
+			Host:       host,
+			Name:       r.Options.GetString(NavigationName),
+			Middleware: middlewareNames(r.chain),
+		}
+		for _, v := range r.Viewers {
+			cr.Viewers = append(cr.Viewers, v.MimeType().String())
+		}
+		data.Routes = append(data.Routes, cr)
+
+		if cr.Name != "" {
+			navs = append(navs, &Navigation{
+				Name:   cr.Name,
+				Icon:   r.Options.GetString(NavigationIcon),
+				Access: r.Options.GetString(NavigationAccess),
+				Path:   strings.TrimSuffix(cr.Pattern, "{$}"),
+			})
+		}
+	}
+
+	for name, v := range app.viewers {
+		data.Templates = append(data.Templates, consoleTemplate{Name: name, Viewer: v.MimeType().String()})
+	}
+
+	for _, l := range app.loaders {
+		data.Caches = append(data.Caches, consoleCache{Name: l.name, LoaderStats: l.stats()})
+	}
+
+	var proxies []string
+	for _, p := range app.trustedProxies {
+		proxies = append(proxies, p.String())
+	}
+
+	var compressors []string
+	for _, c := range app.compressors {
+		compressors = append(compressors, c.AcceptEncoding())
+	}
+
+	data.Config = []consoleSetting{
+		{Key: "watch", Value: strconv.FormatBool(app.watch)},
+		{Key: "archive", Value: app.archive},
+		{Key: "fingerprint", Value: strconv.FormatBool(app.fingerprint)},
+		{Key: "asset_host", Value: app.assetHost},
+		{Key: "trusted_proxies", Value: strings.Join(proxies, ",")},
+		{Key: "compressors", Value: strings.Join(compressors, ",")},
+		{Key: "ignores", Value: strings.Join(app.ignores, ",")},
+		{Key: "base_context", Value: strconv.FormatBool(app.baseContext)},
+	}
+	app.mu.RUnlock()
+
+	app.coalescer.mu.Lock()
+	data.Coalesced = len(app.coalescer.calls)
+	app.coalescer.mu.Unlock()
+
+	data.Streams = app.streams.len()
+
+	sort.Slice(data.Routes, func(i, j int) bool {
+		return data.Routes[i].Pattern < data.Routes[j].Pattern
+	})
+	sort.Slice(data.Templates, func(i, j int) bool {
+		return data.Templates[i].Name < data.Templates[j].Name
+	})
+
+	data.Navigation = navTree(navs)
+
+	return data
+}
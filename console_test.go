@@ -0,0 +1,126 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsole(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	auth := func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if _, pwd, ok := c.Request().BasicAuth(); !ok || pwd != "secret" {
+				c.WriteStatus(http.StatusUnauthorized)
+				return ErrCancelled
+			}
+			return next(c)
+		}
+	}
+
+	app := New(WithMux(mux), WithFsys(fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<p>home</p>`)},
+		"views/bad.html":   {Data: []byte(`{{ if }}`)},
+	}), WithConsole(auth))
+	defer app.Close()
+
+	app.Get("/admin/{$}", func(c *Context) error {
+		return nil
+	}, WithNavigation("admin", "gear", "admin"))
+
+	app.Get("/admin/users", func(c *Context) error {
+		return nil
+	}, WithNavigation("users", "person", "admin"))
+
+	app.Get("/stream", func(c *Context) error {
+		c.Writer().(http.Flusher).Flush()
+		<-c.Request().Context().Done()
+		return nil
+	})
+
+	NewLoader(app, func(ctx context.Context, key string) (string, error) {
+		return key, nil
+	})
+
+	app.Start() // nolint: errcheck
+
+	t.Run("unauthorized", func(t *testing.T) {
+		resp, err := client.Get(srv.URL + ConsolePrefix + "/")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+"/stream", nil)
+	require.NoError(t, err)
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	t.Run("html", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+ConsolePrefix+"/", nil)
+		require.NoError(t, err)
+		req.SetBasicAuth("admin", "secret")
+		req.Header.Set("Accept", "text/html")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Contains(t, string(buf), "<h1>xun console</h1>")
+		require.Contains(t, string(buf), "/admin/users")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+ConsolePrefix+"/", nil)
+		require.NoError(t, err)
+		req.SetBasicAuth("admin", "secret")
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var data consoleData
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&data))
+
+		require.Len(t, data.Navigation, 1)
+		require.Equal(t, "admin", data.Navigation[0].Name)
+		require.Equal(t, "/admin/", data.Navigation[0].Path)
+		require.Len(t, data.Navigation[0].Children, 1)
+		require.Equal(t, "users", data.Navigation[0].Children[0].Name)
+
+		require.Contains(t, data.Templates, consoleTemplate{Name: "index", Viewer: "text/html"})
+		require.Len(t, data.Diagnostics, 1)
+		require.Equal(t, "views/bad.html", data.Diagnostics[0].File)
+
+		require.Len(t, data.Caches, 1)
+		require.Equal(t, "string", data.Caches[0].Name)
+
+		require.Equal(t, 1, data.Streams)
+		require.Contains(t, data.Config, consoleSetting{Key: "watch", Value: "false"})
+
+		var patterns []string
+		for _, r := range data.Routes {
+			patterns = append(patterns, r.Method+" "+r.Pattern)
+		}
+		require.Contains(t, patterns, "GET /_xun/{$}")
+		require.Contains(t, patterns, "GET /admin/users")
+	})
+}
@@ -1,8 +1,14 @@
 package xun
 
 import (
+	"bufio"
+	"context"
+	"log/slog"
+	"net"
 	"net/http"
+	"reflect"
 	"strings"
+	"time"
 )
 
 // Context is the primary structure for handling HTTP requests.
@@ -16,6 +22,14 @@ type Context struct {
 
 	writtenStatus bool
 	values        map[string]any
+
+	stats    *statsResponseWriter
+	viewer   Viewer
+	buffered *bufferedResponseWriter
+	oob      []oobFragment
+
+	// accessStart is the time the request is logged from, see AccessLog.
+	accessStart time.Time
 }
 
 // Writer returns the http.ResponseWriter associated with the current context.
@@ -30,6 +44,62 @@ func (c *Context) Request() *http.Request {
 	return c.req
 }
 
+// Hijack lets the handler take over the connection, e.g. for custom protocols
+// or third-party websocket libraries. After a call to Hijack, the HTTP server
+// library will not do anything else with the connection, so the handler
+// should return nil or ErrCancelled.
+//
+// It returns http.ErrNotSupported if the connection doesn't support hijacking, e.g. HTTP/2.
+func (c *Context) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := hijack(c.rw)
+	if err == nil {
+		c.writtenStatus = true
+	}
+
+	return conn, buf, err
+}
+
+// Buffered opts the response into buffering. The hook is called with the
+// buffered response after the handler returns and before it is sent to the
+// client, e.g. to compute an ETag, cache or minify the body. Hooks are called
+// in the order they are registered.
+//
+// Streaming responses bypass buffering automatically, and the hooks are not
+// called: responses that are flushed (e.g. SSE) or hijacked, with a
+// text/event-stream Content-Type, attachment downloads, or bodies larger than 4MB.
+//
+// It should be called by middlewares before calling next(c).
+func (c *Context) Buffered(hook func(b *BufferedResponse)) {
+	if c.buffered == nil {
+		c.buffered = &bufferedResponseWriter{ResponseWriter: c.rw}
+		c.rw = c.buffered
+	}
+
+	c.buffered.hooks = append(c.buffered.hooks, hook)
+}
+
+// Context returns the context.Context of the request. It is a shortcut of c.Request().Context().
+func (c *Context) Context() context.Context {
+	return c.req.Context()
+}
+
+// WithContext replaces the context.Context of the request, e.g. to attach
+// deadlines, tracing spans or values in middlewares. The new context is
+// reflected by c.Request() in downstream middlewares, handlers and viewers.
+//
+// ctx should be derived from c.Context() to keep its cancellation and values.
+func (c *Context) WithContext(ctx context.Context) {
+	c.req = c.req.WithContext(ctx)
+}
+
+// Deadline returns the time when the request should be cancelled, e.g. set by
+// the WithDeadline routing option. ok is false if no deadline is set.
+// Long-running handlers can check it, or select on c.Request().Context().Done(),
+// to stop work cooperatively.
+func (c *Context) Deadline() (deadline time.Time, ok bool) {
+	return c.req.Context().Deadline()
+}
+
 // WriteStatus sets the HTTP status code for the response.
 // It is used to return error or success status codes to the client.
 // The status code will be sent to the client only once the response body is closed.
@@ -68,13 +138,18 @@ func (c *Context) View(data any, options ...string) error {
 
 	v, ok := c.getViewer(name)
 
+	if ro := c.Routing.Options; ro != nil && ro.export != nil {
+		v, ok = ro.export, true // export route registered by WithExport, e.g. /users.csv
+	}
+
 	if !ok {
+	negotiate:
 		for _, accept := range c.Accept() {
 			for _, viewer := range c.Routing.Viewers {
 				if viewer.MimeType().Match(accept) {
 					v = viewer
 					ok = true
-					break
+					break negotiate // the first accepted type in order of preference wins
 				}
 			}
 		}
@@ -86,7 +161,133 @@ func (c *Context) View(data any, options ...string) error {
 		}
 	}
 
-	return v.Render(c.rw, c.req, data)
+	if c.app.watch {
+		c.checkResponseType(data)
+	}
+
+	c.viewer = v
+
+	hv, ok := v.(*HtmlViewer)
+
+	// a ViewModel is unwrapped once for all viewers, so data viewers, e.g. XML
+	// and the exporters of WithExport, never render the data of HTML pages
+	if vm, isVM := data.(ViewModel); isVM {
+		if ok {
+			data = vm.HTMLData()
+		} else {
+			data = vm.JSONData()
+		}
+	}
+
+	var oob [][]byte
+	var err error
+	if ok {
+		if oob, err = c.renderOOBs(); err != nil {
+			return err
+		}
+	}
+
+	if ok && c.Routing.Options != nil && c.Routing.Options.fragmentLayout != "" {
+		err = c.renderFragment(hv, c.Routing.Options.fragmentLayout, data)
+	} else {
+		err = v.Render(c.rw, c.req, data)
+	}
+
+	if err != nil || !ok {
+		return err
+	}
+
+	for _, it := range oob {
+		if _, err := c.rw.Write(it); err != nil {
+			return err
+		}
+	}
+
+	return c.writeDeferred()
+}
+
+// renderFragment renders the htmx fragment of a route marked by WithFragmentLayout.
+// Normal navigations are rendered with the fragment as the "content" block of
+// the layout, so the page is complete without JavaScript.
+func (c *Context) renderFragment(v *HtmlViewer, name string, data any) error {
+	c.rw.Header().Add("Vary", "HX-Request")
+
+	c.app.mu.RLock()
+	layout := c.app.layouts[name]
+	c.app.mu.RUnlock()
+
+	if layout == nil || v.template.layout != "" || c.req.Header.Get("HX-Request") == "true" {
+		return v.Render(c.rw, c.req, data)
+	}
+
+	c.rw.Header().Add("Content-Type", "text/html; charset=utf-8")
+	buf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(buf)
+
+	if err := v.template.executeFragment(buf, c.req, layout, data); err != nil {
+		return err
+	}
+
+	_, err := buf.WriteTo(c.rw)
+	return err
+}
+
+// checkResponseType logs a warning if the data doesn't match the type declared by WithResponseType.
+func (c *Context) checkResponseType(data any) {
+	if c.Routing.Options == nil || data == nil {
+		return
+	}
+
+	rt := c.Routing.Options.ResponseType()
+	if rt == nil {
+		return
+	}
+
+	dt := reflect.TypeOf(data)
+	if dt.AssignableTo(rt) || (dt.Kind() == reflect.Pointer && dt.Elem().AssignableTo(rt)) {
+		return
+	}
+
+	c.app.logger.Warn("xun: response type mismatch", slog.String("route", c.Routing.Pattern),
+		slog.String("want", rt.String()), slog.String("got", dt.String()))
+}
+
+// StatusCode returns the HTTP status code written to the response, or 0 if
+// nothing is written yet. It is useful for logging, metrics and audit
+// middlewares after calling next(c).
+//
+// NOTE: unhandled errors are written as 500 after all middlewares return.
+func (c *Context) StatusCode() int {
+	if b := c.buffered; b != nil && !b.streaming {
+		if b.statusCode == 0 && b.buf.Len() > 0 {
+			return http.StatusOK
+		}
+		return b.statusCode
+	}
+
+	if c.stats == nil {
+		return 0
+	}
+	return c.stats.statusCode
+}
+
+// BytesWritten returns the number of bytes of the response body written by the
+// handler and viewers, before the response is compressed.
+func (c *Context) BytesWritten() int64 {
+	if b := c.buffered; b != nil && !b.streaming {
+		return int64(b.buf.Len())
+	}
+
+	if c.stats == nil {
+		return 0
+	}
+	return c.stats.bytesWritten
+}
+
+// ViewerUsed returns the Viewer negotiated by c.View to render the response,
+// or nil if c.View is not called.
+func (c *Context) ViewerUsed() Viewer {
+	return c.viewer
 }
 
 // getViewer get viewer by name
@@ -109,6 +310,11 @@ func (c *Context) getViewer(name string) (Viewer, bool) {
 // Redirect redirects the user to the given url.
 // It uses the given status code. If the status code is not provided,
 // it uses http.StatusFound (302).
+//
+// htmx requests are redirected by the client instead, because XHR follows
+// redirects transparently: boosted requests are sent HX-Location for a soft
+// navigation, and other htmx requests are sent HX-Redirect for a full page
+// load, with 200 OK.
 func (c *Context) Redirect(url string, statusCode ...int) {
 	if c.app.interceptor != nil {
 		if c.app.interceptor.Redirect(c, url, statusCode...) {
@@ -116,6 +322,17 @@ func (c *Context) Redirect(url string, statusCode ...int) {
 		}
 
 	}
+
+	if hx := c.Htmx(); hx.Request {
+		if hx.Boosted {
+			c.WriteHeader("HX-Location", url)
+		} else {
+			c.WriteHeader("HX-Redirect", url)
+		}
+		c.WriteStatus(http.StatusOK)
+		return
+	}
+
 	c.WriteHeader("Location", url)
 	if len(statusCode) > 0 {
 		c.WriteStatus(statusCode[0])
@@ -126,29 +343,21 @@ func (c *Context) Redirect(url string, statusCode ...int) {
 }
 
 // AcceptLanguage returns a slice of strings representing the languages
-// that the client accepts, in order of preference.
-// The languages are normalized to lowercase and whitespace is trimmed.
+// that the client accepts, in order of preference by their q-values.
+// Whitespace is trimmed, and languages with q=0 are excluded.
 func (c *Context) AcceptLanguage() (languages []string) {
-	accepted := c.req.Header.Get("Accept-Language")
-	if accepted == "" {
-		return
-	}
-	options := strings.Split(accepted, ",")
-	l := len(options)
-	languages = make([]string, l)
-
-	for i := 0; i < l; i++ {
-		locale := strings.SplitN(options[i], ";", 2)
-		languages[i] = strings.Trim(locale[0], " ")
-	}
-	return
+	return parseAcceptLanguage(c.req.Header.Get("Accept-Language"))
 }
 
 // Accept returns a slice of strings representing the media types
 // that the client accepts, in order of preference.
 // The media types are normalized to lowercase and whitespace is trimmed.
 func (c *Context) Accept() (types []MimeType) {
-	accepted := c.req.Header.Get("Accept")
+	return parseAccept(c.req.Header.Get("Accept"))
+}
+
+// parseAccept parses the media types of the Accept header in order of preference.
+func parseAccept(accepted string) (types []MimeType) {
 	if accepted == "" {
 		return
 	}
@@ -1,11 +1,13 @@
 package xun
 
 import (
+	"context"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -235,3 +237,126 @@ func TestMixedViewers(t *testing.T) {
 	})
 
 }
+
+func TestContextResponseState(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`index`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithCompressor(&GzipCompressor{}))
+
+	type state struct {
+		status int
+		bytes  int64
+		viewer Viewer
+	}
+
+	states := make(chan state, 1)
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			require.Equal(t, 0, c.StatusCode())
+			require.Nil(t, c.ViewerUsed())
+
+			err := next(c)
+
+			states <- state{status: c.StatusCode(), bytes: c.BytesWritten(), viewer: c.ViewerUsed()}
+			return err
+		}
+	})
+
+	app.Get("/missing", func(c *Context) error {
+		c.WriteStatus(http.StatusNotFound)
+		return ErrCancelled
+	})
+
+	app.Get("/json", func(c *Context) error {
+		return c.View(map[string]string{"name": "xun"})
+	})
+
+	app.Start()
+	defer app.Close()
+
+	req, err := http.NewRequest("GET", srv.URL+"/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	s := <-states
+	require.Equal(t, http.StatusOK, s.status)
+	require.Equal(t, int64(len("index")), s.bytes)
+	require.IsType(t, &HtmlViewer{}, s.viewer)
+
+	resp, err = client.Get(srv.URL + "/json")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	s = <-states
+	require.Equal(t, http.StatusOK, s.status)
+	require.Equal(t, int64(len(buf)), s.bytes)
+	require.IsType(t, &JsonViewer{}, s.viewer)
+
+	resp, err = client.Get(srv.URL + "/missing")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	s = <-states
+	require.Equal(t, http.StatusNotFound, s.status)
+	require.Equal(t, int64(0), s.bytes)
+	require.Nil(t, s.viewer)
+}
+
+func TestContextWithContext(t *testing.T) {
+	type traceKey struct{}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			c.WithContext(context.WithValue(c.Context(), traceKey{}, "trace-1"))
+			return next(c)
+		}
+	})
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			ctx, cancel := context.WithTimeout(c.Context(), time.Minute)
+			defer cancel()
+			c.WithContext(ctx)
+			return next(c)
+		}
+	})
+
+	app.Get("/", func(c *Context) error {
+		_, ok := c.Request().Context().Deadline()
+		return c.View(map[string]any{
+			"trace":    c.Request().Context().Value(traceKey{}),
+			"deadline": ok,
+		})
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.JSONEq(t, `{"trace":"trace-1","deadline":true}`, string(buf))
+}
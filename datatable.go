@@ -0,0 +1,276 @@
+package xun
+
+import (
+	"html/template"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// TableSize is the default number of rows of a page of a DataTable.
+var TableSize = 20
+
+// TableMaxSize is the max number of rows of a page of a DataTable, that the
+// `size` query parameter is clamped to.
+var TableMaxSize = 100
+
+// TableColumn is a column of a DataTable.
+type TableColumn struct {
+	// Field is the key of the cells of the column in the rows, e.g. "name".
+	Field string
+	// Title is the text of the header of the column.
+	Title string
+	// Sortable allows the rows to be sorted by the column, e.g. `?sort=-name`.
+	Sortable bool
+	// Filters are the operators allowed to filter the rows by the column,
+	// e.g. {"eq", "in"} for `?filter[status][in]=open,closed`.
+	Filters []string
+}
+
+// TableQuery is the query of the rows of a page of a DataTable, parsed from
+// the `page`, `size`, `sort`, `q` and `filter[field][op]` query parameters.
+type TableQuery struct {
+	Page    int
+	Size    int
+	Sort    []SortField
+	Search  string
+	Filters []Filter
+}
+
+// Offset returns the number of rows before the page, e.g. for SQL OFFSET.
+func (q *TableQuery) Offset() int {
+	return (q.Page - 1) * q.Size
+}
+
+// TableSource is the source of the columns and rows of a DataTable, e.g. a
+// database table. Rows returns the rows of the page of the query, and the
+// total number of rows matched by its search and filters.
+type TableSource interface {
+	Columns() []TableColumn
+	Rows(c *Context, q *TableQuery) (rows []map[string]any, total int, err error)
+}
+
+// DataTable is a server-side table of a TableSource with sorting, paging,
+// searching and filtering by query parameters. Its header and pagination
+// links are swapped by htmx, and pushed to the history of the browser.
+type DataTable struct {
+	ID         string
+	Columns    []TableColumn
+	Rows       []map[string]any
+	Query      *TableQuery
+	Pagination Pagination
+
+	path   string
+	values url.Values
+	pool   *BufferPool
+}
+
+// NewDataTable returns the DataTable with the id of the rows of src for the
+// query of the request. It returns a *BindError if the request sorts or
+// filters the rows by columns that don't allow it.
+func NewDataTable(c *Context, id string, src TableSource) (*DataTable, error) {
+	values := c.req.URL.Query()
+	columns := src.Columns()
+
+	spec := &QuerySpec{Filters: make(map[string][]string)}
+	for _, col := range columns {
+		if col.Sortable {
+			spec.Sorts = append(spec.Sorts, col.Field)
+		}
+		if len(col.Filters) > 0 {
+			spec.Filters[col.Field] = col.Filters
+		}
+	}
+
+	aq, err := ParseQuery(values, spec)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &TableQuery{
+		Page:    max(atoi(values.Get("page")), 1),
+		Size:    atoi(values.Get("size")),
+		Sort:    aq.Sort,
+		Search:  values.Get("q"),
+		Filters: aq.Filters,
+	}
+	if q.Size <= 0 {
+		q.Size = TableSize
+	}
+	q.Size = min(q.Size, TableMaxSize)
+	// the offset of the page must not overflow, e.g. of `?page=9223372036854775807`
+	q.Page = min(q.Page, math.MaxInt32/q.Size+1)
+
+	rows, total, err := src.Rows(c, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DataTable{
+		ID:      id,
+		Columns: columns,
+		Rows:    rows,
+		Query:   q,
+		Pagination: Pagination{
+			Page:  q.Page,
+			Size:  q.Size,
+			Total: total,
+			Pages: max((total+q.Size-1)/q.Size, 1),
+		},
+		path:   c.req.URL.Path,
+		values: values,
+		pool:   c.app.bufferPool(),
+	}, nil
+}
+
+// DataTable renders the DataTable with the id of the rows of src. The table
+// is written as an html fragment for the htmx requests that target it, e.g.
+// by its header and pagination links. Otherwise, the view is rendered with
+// the DataTable, and it renders the table by `{{ .HTML }}`.
+//
+//	app.Get("/users", func(c *xun.Context) error {
+//		return c.DataTable("users", userSource{db}, "views/users")
+//	})
+func (c *Context) DataTable(id string, src TableSource, view string) error {
+	t, err := NewDataTable(c, id, src)
+	if err != nil {
+		return err
+	}
+
+	// the table fragment and the page are cached separately
+	c.rw.Header().Add("Vary", "HX-Request")
+	c.rw.Header().Add("Vary", "HX-Target")
+
+	if hx := c.Htmx(); !hx.Request || hx.Target != id {
+		return c.View(t, view)
+	}
+
+	buf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(buf)
+
+	if err := dataTableTemplate.Execute(buf, t); err != nil {
+		return err
+	}
+
+	c.rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.WriteStatus(http.StatusOK)
+	_, err = buf.WriteTo(c.rw)
+	return err
+}
+
+// HTML renders the table by the built-in template.
+func (t *DataTable) HTML() (template.HTML, error) {
+	buf := t.pool.Get()
+	defer t.pool.Put(buf)
+
+	if err := dataTableTemplate.Execute(buf, t); err != nil {
+		return "", err
+	}
+
+	return template.HTML(buf.String()), nil // nolint: gosec
+}
+
+// SortURL returns the URL that sorts the rows by the field, or in descending
+// order if they are sorted by it in ascending order. The page is reset.
+func (t *DataTable) SortURL(field string) string {
+	sort := field
+	if len(t.Query.Sort) > 0 && t.Query.Sort[0].Field == field && !t.Query.Sort[0].Desc {
+		sort = "-" + field
+	}
+
+	return t.url(func(v url.Values) {
+		v.Set("sort", sort)
+		v.Del("page")
+	})
+}
+
+// SortOrder returns the aria-sort of the column of the field: "ascending",
+// "descending", or "" if the rows aren't sorted by it.
+func (t *DataTable) SortOrder(field string) string {
+	if len(t.Query.Sort) == 0 || t.Query.Sort[0].Field != field {
+		return ""
+	}
+
+	if t.Query.Sort[0].Desc {
+		return "descending"
+	}
+	return "ascending"
+}
+
+// PageURL returns the URL of the page.
+func (t *DataTable) PageURL(page int) string {
+	return t.url(func(v url.Values) {
+		if page > 1 {
+			v.Set("page", strconv.Itoa(page))
+		} else {
+			v.Del("page")
+		}
+	})
+}
+
+// FormValues returns the query parameters kept by the search form, e.g. the
+// sort and the filters.
+func (t *DataTable) FormValues() url.Values {
+	v := cloneValues(t.values)
+	v.Del("q")
+	v.Del("page")
+	return v
+}
+
+// Path returns the path of the table, that the search form is sent to.
+func (t *DataTable) Path() string {
+	return t.path
+}
+
+// url returns the URL of the table with the query parameters changed by fn.
+func (t *DataTable) url(fn func(v url.Values)) string {
+	v := cloneValues(t.values)
+	fn(v)
+
+	if len(v) == 0 {
+		return t.path
+	}
+	return t.path + "?" + v.Encode()
+}
+
+// cloneValues returns a copy of the values.
+func cloneValues(values url.Values) url.Values {
+	v := make(url.Values, len(values))
+	for k, items := range values {
+		v[k] = append([]string(nil), items...)
+	}
+	return v
+}
+
+// atoi returns the integer of s, or 0 if it isn't an integer.
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+// dataTableTemplate is the built-in template of DataTable.
+var dataTableTemplate = template.Must(template.New("datatable").Funcs(template.FuncMap{
+	"cell": func(row map[string]any, field string) any {
+		if v, ok := row[field]; ok && v != nil {
+			return v
+		}
+		return ""
+	},
+}).Parse(`<div id="{{ .ID }}" class="datatable">` +
+	`<form hx-get="{{ .Path }}" hx-target="#{{ .ID }}" hx-swap="outerHTML" hx-push-url="true" hx-trigger="submit, input changed delay:300ms">` +
+	`{{ range $k, $items := .FormValues }}{{ range $items }}<input type="hidden" name="{{ $k }}" value="{{ . }}">{{ end }}{{ end }}` +
+	`<input type="search" name="q" value="{{ .Query.Search }}" aria-label="Search">` +
+	`</form>` +
+	`<table><thead><tr>` +
+	`{{ range .Columns }}<th{{ with $.SortOrder .Field }} aria-sort="{{ . }}"{{ end }}>` +
+	`{{ if .Sortable }}{{ $u := $.SortURL .Field }}<a href="{{ $u }}" hx-get="{{ $u }}" hx-target="#{{ $.ID }}" hx-swap="outerHTML" hx-push-url="true">{{ .Title }}</a>{{ else }}{{ .Title }}{{ end }}` +
+	`</th>{{ end }}` +
+	`</tr></thead><tbody>` +
+	`{{ range $row := .Rows }}<tr>{{ range $.Columns }}<td>{{ cell $row .Field }}</td>{{ end }}</tr>{{ end }}` +
+	`</tbody></table>` +
+	`<nav aria-label="Pagination">` +
+	`{{ if .Pagination.HasPrev }}{{ $u := .PageURL .Pagination.Prev }}<a href="{{ $u }}" hx-get="{{ $u }}" hx-target="#{{ .ID }}" hx-swap="outerHTML" hx-push-url="true" rel="prev">Previous</a>{{ end }}` +
+	`<span>{{ .Pagination.Page }} / {{ .Pagination.Pages }}</span>` +
+	`{{ if .Pagination.HasNext }}{{ $u := .PageURL .Pagination.Next }}<a href="{{ $u }}" hx-get="{{ $u }}" hx-target="#{{ .ID }}" hx-swap="outerHTML" hx-push-url="true" rel="next">Next</a>{{ end }}` +
+	`</nav></div>`))
@@ -0,0 +1,140 @@
+package xun
+
+import (
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testTableSource struct {
+	users []map[string]any
+	query *TableQuery
+}
+
+func (s *testTableSource) Columns() []TableColumn {
+	return []TableColumn{
+		{Field: "name", Title: "Name", Sortable: true},
+		{Field: "status", Title: "Status", Filters: []string{"eq"}},
+		{Field: "email", Title: "Email"},
+	}
+}
+
+func (s *testTableSource) Rows(c *Context, q *TableQuery) ([]map[string]any, int, error) {
+	s.query = q
+
+	var rows []map[string]any
+	for _, u := range s.users {
+		if q.Search != "" && !strings.Contains(u["name"].(string), q.Search) {
+			continue
+		}
+		if f, ok := (&Query{Filters: q.Filters}).Filter("status", "eq"); ok && u["status"] != f.Value {
+			continue
+		}
+		rows = append(rows, u)
+	}
+
+	if len(q.Sort) > 0 {
+		sort.SliceStable(rows, func(i, j int) bool {
+			less := rows[i]["name"].(string) < rows[j]["name"].(string)
+			if q.Sort[0].Desc {
+				return !less
+			}
+			return less
+		})
+	}
+
+	page, _ := Paginate(rows, q.Page, q.Size)
+	return page, len(rows), nil
+}
+
+func TestDataTable(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/users.html": {Data: []byte(`<main>{{ .HTML }}</main>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+
+	src := &testTableSource{users: []map[string]any{
+		{"name": "alice", "status": "active", "email": "alice@example.com"},
+		{"name": "bob", "status": "blocked"},
+		{"name": "carol", "status": "active", "email": "carol@example.com"},
+	}}
+
+	app.Get("/users", func(c *Context) error {
+		return c.DataTable("users", src, "views/users")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	get := func(t *testing.T, path string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp, string(buf)
+	}
+
+	t.Run("page", func(t *testing.T) {
+		resp, body := get(t, "/users?sort=-name&size=2")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.True(t, strings.HasPrefix(body, `<main><div id="users" class="datatable">`))
+		require.Contains(t, body, `<input type="hidden" name="size" value="2"><input type="hidden" name="sort" value="-name">`)
+		require.Contains(t, body, `<th aria-sort="descending"><a href="/users?size=2&amp;sort=name" hx-get="/users?size=2&amp;sort=name" hx-target="#users" hx-swap="outerHTML" hx-push-url="true">Name</a></th><th>Status</th><th>Email</th>`)
+		require.Contains(t, body, `<tbody><tr><td>carol</td><td>active</td><td>carol@example.com</td></tr><tr><td>bob</td><td>blocked</td><td></td></tr></tbody>`)
+		require.Contains(t, body, `<span>1 / 2</span><a href="/users?page=2&amp;size=2&amp;sort=-name"`)
+		require.NotContains(t, body, `rel="prev"`)
+		require.Equal(t, []string{"HX-Request", "HX-Target"}, resp.Header.Values("Vary"))
+	})
+
+	t.Run("htmx", func(t *testing.T) {
+		resp, body := get(t, "/users?q=a&filter[status]=active&page=2&size=1", "HX-Request", "true", "HX-Target", "users")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.True(t, strings.HasPrefix(body, `<div id="users" class="datatable">`))
+		require.Contains(t, body, `<tbody><tr><td>carol</td>`)
+		require.Contains(t, body, `<a href="/users?filter%5Bstatus%5D=active&amp;q=a&amp;size=1" hx-get`)
+		require.Contains(t, body, `<span>2 / 2</span>`)
+		require.Equal(t, []string{"HX-Request", "HX-Target"}, resp.Header.Values("Vary"))
+
+		require.Equal(t, &TableQuery{Page: 2, Size: 1, Search: "a", Filters: []Filter{{Field: "status", Op: "eq", Value: "active"}}}, src.query)
+	})
+
+	t.Run("size", func(t *testing.T) {
+		get(t, "/users?size=1000&page=-1")
+		require.Equal(t, TableMaxSize, src.query.Size)
+		require.Equal(t, 1, src.query.Page)
+		require.Equal(t, 0, src.query.Offset())
+
+		// the offset of a huge page doesn't overflow
+		get(t, "/users?page=9223372036854775807")
+		require.Positive(t, src.query.Offset())
+		require.LessOrEqual(t, src.query.Offset(), math.MaxInt32)
+	})
+
+	t.Run("invalid_sort", func(t *testing.T) {
+		resp, _ := get(t, "/users?sort=email")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
@@ -0,0 +1,304 @@
+package xun
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DeferPrefix is the URL prefix of the SSE endpoints of the sections deferred with DeferSSE.
+const DeferPrefix = "/_defer"
+
+// DeferTimeout is how long the loader of a deferred section can run. The page
+// waits for the sections deferred with DeferStream no longer than it, and the
+// result of a section deferred with DeferSSE waits for the client to connect
+// no longer than it.
+var DeferTimeout = time.Minute
+
+// DeferMode is how a deferred section is sent to the client.
+type DeferMode int
+
+const (
+	// DeferStream streams the section over the same response after the page,
+	// and an inline script swaps it with the placeholder.
+	DeferStream DeferMode = iota
+	// DeferSSE sends the section by a follow-up SSE request from the
+	// placeholder. It requires the htmx sse extension, and works with a
+	// Content-Security-Policy that disallows inline scripts.
+	DeferSSE
+)
+
+type deferContextKey struct{}
+
+// deferredSection is a slow section of the page that is loaded in the background.
+type deferredSection struct {
+	id   string
+	name string
+	mode DeferMode
+	used bool // the placeholder is rendered
+
+	done chan struct{}
+	html []byte
+	err  error
+}
+
+// deferSet is the deferred sections of a request.
+type deferSet struct {
+	sections []*deferredSection
+
+	mu     sync.Mutex
+	loaded []*deferredSection // the DeferStream sections in the order they are loaded
+	// ready is signaled when a section is loaded. It is buffered, so the
+	// loaders never block if the sections are not streamed, e.g. if c.View
+	// isn't called or renders the page by a non-HTML viewer.
+	ready chan struct{}
+}
+
+// load adds the loaded section s, and signals ready.
+func (set *deferSet) load(s *deferredSection) {
+	set.mu.Lock()
+	set.loaded = append(set.loaded, s)
+	set.mu.Unlock()
+
+	select {
+	case set.ready <- struct{}{}:
+	default: // signaled already
+	}
+}
+
+// next returns the loaded sections from the index i.
+func (set *deferSet) next(i int) []*deferredSection {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	return set.loaded[i:]
+}
+
+// Defer loads a slow section of the page in the background, so the page is
+// rendered immediately with the placeholder of the `deferred` template
+// function. The section is rendered by the view with the data returned by
+// load, and sent to the client by the mode, DeferStream by default.
+//
+//	c.Defer("stats", "views/stats", func(ctx context.Context) (any, error) {
+//		return db.Stats(ctx)
+//	})
+//	return c.View(data, "views/dashboard")
+//
+//	{{ deferred "stats" }}
+//
+// It should be called before c.View.
+func (c *Context) Defer(name, view string, load func(ctx context.Context) (any, error), mode ...DeferMode) {
+	set, ok := c.req.Context().Value(deferContextKey{}).(*deferSet)
+	if !ok {
+		set = &deferSet{ready: make(chan struct{}, 1)}
+		c.WithContext(context.WithValue(c.Context(), deferContextKey{}, set))
+	}
+
+	s := &deferredSection{
+		id:   newRandomID(),
+		name: name,
+		done: make(chan struct{}),
+	}
+	if len(mode) > 0 {
+		s.mode = mode[0]
+	}
+	set.sections = append(set.sections, s)
+
+	v, _ := c.app.viewers[view].(*HtmlViewer)
+	ctx, r := c.Context(), c.req
+
+	var cancel context.CancelFunc
+	if s.mode == DeferSSE {
+		// the section outlives the page request until the client connects
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), DeferTimeout)
+		c.app.handleDeferred()
+		c.app.deferred.Store(s.id, s)
+		time.AfterFunc(DeferTimeout, func() {
+			c.app.deferred.Delete(s.id)
+		})
+	} else {
+		ctx, cancel = context.WithTimeout(ctx, DeferTimeout)
+	}
+
+	go func() {
+		defer cancel()
+
+		s.html, s.err = s.render(ctx, r, v, view, load)
+		close(s.done)
+
+		if s.mode == DeferStream {
+			set.load(s)
+		}
+	}()
+}
+
+// render loads the data of the section, and renders it by the view.
+func (s *deferredSection) render(ctx context.Context, r *http.Request, v *HtmlViewer, view string, load func(ctx context.Context) (any, error)) ([]byte, error) {
+	if v == nil {
+		return nil, fmt.Errorf("xun: defer %q: view %q not found", s.name, view)
+	}
+
+	data, err := load(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := v.template.execute(&buf, r, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// placeholder returns the placeholder of the section that is swapped with it.
+func (s *deferredSection) placeholder() template.HTML {
+	s.used = true
+
+	if s.mode == DeferSSE {
+		return template.HTML(`<div id="xun-deferred-` + s.id + `" hx-ext="sse" sse-connect="` + DeferPrefix + "/" + s.id + // nolint: gosec
+			`" sse-swap="xun-defer" hx-swap="outerHTML" aria-busy="true"></div>`)
+	}
+
+	return template.HTML(`<div id="xun-deferred-` + s.id + `" aria-busy="true"></div>`) // nolint: gosec
+}
+
+// deferred returns the placeholder of the deferred section name of the request r.
+func deferred(r *http.Request, name string) template.HTML {
+	if r == nil {
+		return ""
+	}
+
+	set, ok := r.Context().Value(deferContextKey{}).(*deferSet)
+	if !ok {
+		return ""
+	}
+
+	for _, s := range set.sections {
+		if s.name == name {
+			return s.placeholder()
+		}
+	}
+
+	return ""
+}
+
+// writeDeferred streams the sections deferred with DeferStream after the page,
+// in the order they are loaded. It waits for them no longer than DeferTimeout,
+// and the placeholders of the sections that are not loaded by then are kept.
+func (c *Context) writeDeferred() error {
+	set, ok := c.req.Context().Value(deferContextKey{}).(*deferSet)
+	if !ok {
+		return nil
+	}
+
+	pending := 0
+	for _, s := range set.sections {
+		if s.mode == DeferStream {
+			pending++
+		}
+	}
+
+	if pending == 0 {
+		return nil
+	}
+
+	flush(c.rw)
+
+	var nonce string
+	if c.app.deferNonce != nil {
+		nonce = ` nonce="` + template.HTMLEscapeString(c.app.deferNonce(c)) + `"`
+	}
+
+	timeout := time.NewTimer(DeferTimeout)
+	defer timeout.Stop()
+
+	for written := 0; written < pending; {
+		select {
+		case <-set.ready:
+		case <-timeout.C:
+			c.app.logger.Warn("xun: defer timeout", slog.Int("pending", pending-written))
+			return nil
+		case <-c.req.Context().Done():
+			return c.req.Context().Err()
+		}
+
+		for _, s := range set.next(written) {
+			written++
+			if !s.used {
+				continue
+			}
+
+			if s.err != nil {
+				c.app.logger.Error("xun: defer", slog.String("name", s.name), slog.Any("err", s.err))
+			}
+
+			_, err := fmt.Fprintf(c.rw, `<template id="xun-defer-%s">%s</template><script%s>(function(){var t=document.getElementById("xun-defer-%s"),p=document.getElementById("xun-deferred-%s");if(!t||!p)return;var e=p.parentNode;p.replaceWith(t.content);t.remove();if(window.htmx)htmx.process(e)})()</script>`,
+				s.id, s.html, nonce, s.id, s.id)
+			if err != nil {
+				return err
+			}
+			flush(c.rw)
+		}
+	}
+
+	return nil
+}
+
+// handleDeferred registers the SSE endpoint of the sections deferred with DeferSSE.
+func (app *App) handleDeferred() {
+	app.deferOnce.Do(func() {
+		app.mux.HandleFunc("GET "+DeferPrefix+"/{id}", app.serveDeferred)
+	})
+}
+
+// serveDeferred sends the section as a `xun-defer` SSE event once it is loaded.
+// A section can only be sent once.
+func (app *App) serveDeferred(w http.ResponseWriter, r *http.Request) {
+	v, ok := app.deferred.LoadAndDelete(r.PathValue("id"))
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s := v.(*deferredSection)
+	select {
+	case <-s.done:
+	case <-r.Context().Done():
+		return
+	}
+
+	if s.err != nil {
+		app.logger.Error("xun: defer", slog.String("name", s.name), slog.Any("err", s.err))
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+
+	var buf bytes.Buffer
+	buf.WriteString("event: xun-defer\n")
+	for _, line := range bytes.Split(s.html, []byte("\n")) {
+		buf.WriteString("data: ")
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	buf.WriteByte('\n')
+
+	w.Write(buf.Bytes()) // nolint: errcheck
+	flush(w)
+}
+
+// newRandomID returns a random id of a deferred section or a job. It is
+// unguessable, so that their endpoints can't be read by other clients.
+func newRandomID() string {
+	buf := make([]byte, 16)
+	rand.Read(buf) // nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
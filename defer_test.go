@@ -0,0 +1,210 @@
+package xun
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefer(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/dashboard.html": {Data: []byte(`<main>{{ deferred "stats" }}{{ deferred "chart" }}</main>`)},
+		"views/stats.html":     {Data: []byte("<b>{{ . }}</b>")},
+		"views/chart.html":     {Data: []byte("<i>\n{{ . }}</i>")},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	release := make(chan struct{})
+
+	app.Get("/stream", func(c *Context) error {
+		c.Defer("stats", "views/stats", func(ctx context.Context) (any, error) {
+			<-release
+			return 42, nil
+		})
+		c.Defer("chart", "views/chart", func(ctx context.Context) (any, error) {
+			return nil, errors.New("failed")
+		})
+		return c.View(nil, "views/dashboard")
+	})
+
+	app.Get("/sse", func(c *Context) error {
+		c.Defer("stats", "views/stats", func(ctx context.Context) (any, error) {
+			return 42, nil
+		}, DeferStream)
+		c.Defer("chart", "views/chart", func(ctx context.Context) (any, error) {
+			return "pie", nil
+		}, DeferSSE)
+		return c.View(nil, "views/dashboard")
+	})
+
+	app.Get("/missing", func(c *Context) error {
+		c.Defer("stats", "views/missing", func(ctx context.Context) (any, error) {
+			return 42, nil
+		})
+		return c.View(nil, "views/dashboard")
+	})
+
+	app.Start()
+
+	get := func(path string) (*http.Response, io.ReadCloser) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp, resp.Body
+	}
+
+	t.Run("stream", func(t *testing.T) {
+		resp, body := get("/stream")
+		defer body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// the page is sent before the slow section is loaded
+		buf := make([]byte, 4096)
+		n, err := body.Read(buf)
+		require.NoError(t, err)
+		page := string(buf[:n])
+		require.Regexp(t, `^<main><div id="xun-deferred-[\w-]+" aria-busy="true"></div><div id="xun-deferred-[\w-]+" aria-busy="true"></div></main>`, page)
+
+		close(release)
+		rest, err := io.ReadAll(body)
+		require.NoError(t, err)
+		page += string(rest)
+
+		ids := regexp.MustCompile(`id="xun-deferred-([\w-]+)"`).FindAllStringSubmatch(page, -1)
+		require.Len(t, ids, 2)
+
+		// failed sections are swapped with empty content
+		require.Contains(t, page, `<template id="xun-defer-`+ids[1][1]+`"></template>`)
+		require.Contains(t, page, `<template id="xun-defer-`+ids[0][1]+`"><b>42</b></template><script>`)
+	})
+
+	t.Run("sse", func(t *testing.T) {
+		resp, body := get("/sse")
+		page, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		m := regexp.MustCompile(`<div id="xun-deferred-([\w-]+)" hx-ext="sse" sse-connect="/_defer/([\w-]+)" sse-swap="xun-defer" hx-swap="outerHTML" aria-busy="true"></div>`).FindStringSubmatch(string(page))
+		require.Len(t, m, 3)
+		require.Equal(t, m[1], m[2])
+		require.Contains(t, string(page), "<b>42</b>")
+
+		resp, body = get("/_defer/" + m[2])
+		event, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+		require.Equal(t, "event: xun-defer\ndata: <i>\ndata: pie</i>\n\n", string(event))
+
+		// a section is sent only once
+		resp, body = get("/_defer/" + m[2])
+		body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("missing_view", func(t *testing.T) {
+		resp, body := get("/missing")
+		page, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Regexp(t, `<template id="xun-defer-[\w-]+"></template>`, string(page))
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		timeout := DeferTimeout
+		DeferTimeout = 50 * time.Millisecond
+		defer func() { DeferTimeout = timeout }()
+
+		hold := make(chan struct{})
+		defer close(hold)
+
+		app.Get("/timeout", func(c *Context) error {
+			c.Defer("stats", "views/stats", func(ctx context.Context) (any, error) {
+				<-hold // ignores the deadline of ctx
+				return 42, nil
+			})
+			c.Defer("chart", "views/chart", func(ctx context.Context) (any, error) {
+				return "pie", nil
+			})
+			return c.View(nil, "views/dashboard")
+		})
+
+		resp, body := get("/timeout")
+		page, err := io.ReadAll(body)
+		body.Close()
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// the page is finished without the section that isn't loaded in time
+		require.Contains(t, string(page), "<i>\npie</i>")
+		require.NotContains(t, string(page), "<b>42</b>")
+	})
+}
+
+func TestDeferNonce(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/dashboard.html": {Data: []byte(`<main>{{ deferred "stats" }}</main>`)},
+		"views/stats.html":     {Data: []byte("<b>{{ . }}</b>")},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithDeferNonce(func(c *Context) string {
+		return c.Request().Header.Get("X-Nonce")
+	}))
+	defer app.Close()
+
+	app.Get("/", func(c *Context) error {
+		c.Defer("stats", "views/stats", func(ctx context.Context) (any, error) {
+			return 42, nil
+		})
+		return c.View(nil, "views/dashboard")
+	})
+
+	app.Start()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("X-Nonce", `r4nd"m`)
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	page, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+
+	require.Contains(t, string(page), `</template><script nonce="r4nd&#34;m">`)
+}
+
+func TestDeferSetLoad(t *testing.T) {
+	set := &deferSet{ready: make(chan struct{}, 1)}
+
+	// the loaders never block without the page that streams the sections
+	for i := 0; i < 3; i++ {
+		set.load(&deferredSection{})
+	}
+
+	require.Len(t, set.next(0), 3)
+	require.Len(t, set.next(2), 1)
+}
@@ -0,0 +1,129 @@
+package xun
+
+import (
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TemplateDiagnostic describes a template that failed to load.
+type TemplateDiagnostic struct {
+	// File is the path of the template in the fs.FS, e.g. "pages/index.html".
+	File string
+	// Line is the line number of the error, or 0 if it is unknown.
+	Line int
+	// Snippet is the source code on Line.
+	Snippet string
+	// Err is the original error returned by the template parser.
+	Err error
+}
+
+// Error implements the error interface, e.g. `pages/index.html:3: unexpected "}" in operand`.
+func (d *TemplateDiagnostic) Error() string {
+	msg := d.Err.Error()
+	if m := reTemplateErr.FindStringSubmatch(msg); m != nil {
+		msg = m[2]
+	}
+
+	if d.Line > 0 {
+		return d.File + ":" + strconv.Itoa(d.Line) + ": " + msg
+	}
+
+	return d.File + ": " + msg
+}
+
+// Unwrap returns the original error.
+func (d *TemplateDiagnostic) Unwrap() error {
+	return d.Err
+}
+
+// TemplateError is returned by App.Start if any templates failed to load.
+// It reports every failed template instead of the first one, so build
+// pipelines can fail fast with actionable output.
+type TemplateError struct {
+	Diagnostics []*TemplateDiagnostic
+}
+
+// Error implements the error interface. Each diagnostic is written on its own line.
+func (e *TemplateError) Error() string {
+	var sb strings.Builder
+	sb.WriteString("xun: ")
+	sb.WriteString(strconv.Itoa(len(e.Diagnostics)))
+	sb.WriteString(" template(s) failed to load")
+	for _, d := range e.Diagnostics {
+		sb.WriteString("\n  ")
+		sb.WriteString(d.Error())
+		if d.Snippet != "" {
+			sb.WriteString("\n    | ")
+			sb.WriteString(d.Snippet)
+		}
+	}
+
+	return sb.String()
+}
+
+// Unwrap returns the diagnostics for errors.Is and errors.As.
+func (e *TemplateError) Unwrap() []error {
+	errs := make([]error, len(e.Diagnostics))
+	for i, d := range e.Diagnostics {
+		errs[i] = d
+	}
+	return errs
+}
+
+// reTemplateErr matches the errors of text/template and html/template parsers,
+// e.g. `template: pages/index:3: unexpected "}" in operand`.
+var reTemplateErr = regexp.MustCompile(`^(?:html/)?template: [^:]*:(\d+):(?:\d+:)? ?(.*)$`)
+
+// diagnose records the error of the template file, or clears it if err is nil.
+func (app *App) diagnose(fsys fs.FS, path string, err error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if err == nil {
+		delete(app.diagnostics, path)
+		return
+	}
+
+	if app.diagnostics == nil {
+		app.diagnostics = make(map[string]*TemplateDiagnostic)
+	}
+
+	d := &TemplateDiagnostic{
+		File: path,
+		Err:  err,
+	}
+
+	if m := reTemplateErr.FindStringSubmatch(err.Error()); m != nil {
+		d.Line, _ = strconv.Atoi(m[1])
+
+		if buf, err := fs.ReadFile(fsys, path); err == nil {
+			lines := strings.Split(string(buf), "\n")
+			if d.Line > 0 && d.Line <= len(lines) {
+				d.Snippet = strings.TrimSpace(lines[d.Line-1])
+			}
+		}
+	}
+
+	app.diagnostics[path] = d
+}
+
+// TemplateDiagnostics returns the diagnostics of all templates that failed to
+// load, sorted by file. It is empty if all templates are loaded.
+func (app *App) TemplateDiagnostics() []*TemplateDiagnostic {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	items := make([]*TemplateDiagnostic, 0, len(app.diagnostics))
+	for _, d := range app.diagnostics {
+		items = append(items, d)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].File < items[j].File
+	})
+
+	return items
+}
@@ -0,0 +1,107 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun/fsnotify"
+)
+
+func TestTemplateDiagnostics(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/nav.html": {Data: []byte("<nav>\n{{ if }}\n</nav>")},
+		"pages/index.html":    {Data: []byte(`<h1>index</h1>`)},
+		"pages/about.html":    {Data: []byte("<h1>\n  {{ .Name }\n</h1>")},
+		"text/robots.txt":     {Data: []byte(`{{ range }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+
+	items := app.TemplateDiagnostics()
+	require.Len(t, items, 3)
+
+	require.Equal(t, "components/nav.html", items[0].File)
+	require.Equal(t, 2, items[0].Line)
+	require.Equal(t, "{{ if }}", items[0].Snippet)
+
+	require.Equal(t, "pages/about.html", items[1].File)
+	require.Equal(t, 2, items[1].Line)
+	require.Equal(t, "{{ .Name }", items[1].Snippet)
+	require.Equal(t, `pages/about.html:2: unexpected "}" in operand`, items[1].Error())
+
+	require.Equal(t, "text/robots.txt", items[2].File)
+	require.Equal(t, 1, items[2].Line)
+
+	err := app.Start()
+	defer app.Close()
+
+	var te *TemplateError
+	require.True(t, errors.As(err, &te))
+	require.Len(t, te.Diagnostics, 3)
+	require.Contains(t, err.Error(), "3 template(s) failed to load")
+	require.Contains(t, err.Error(), "| {{ .Name }")
+
+	var d *TemplateDiagnostic
+	require.True(t, errors.As(err, &d))
+	require.Equal(t, "components/nav.html", d.File)
+
+	// other templates are still loaded
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "<h1>index</h1>", string(buf))
+}
+
+func TestStartWithoutDiagnostics(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<h1>index</h1>`)},
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+	require.Empty(t, app.TemplateDiagnostics())
+	require.NoError(t, app.Start())
+}
+
+func TestDiagnosticsClearedOnWrite(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/nav.html": {Data: []byte("<nav>{{ if }}</nav>")},
+		"views/home.html":     {Data: []byte("<main>{{ .Name }</main>")},
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+	require.Len(t, app.TemplateDiagnostics(), 2)
+
+	var ve *HtmlViewEngine
+	for _, it := range app.engines {
+		if v, ok := it.(*HtmlViewEngine); ok {
+			ve = v
+		}
+	}
+	require.NotNil(t, ve)
+
+	// the templates failed to load are loaded on write once they are fixed
+	fsys["components/nav.html"] = &fstest.MapFile{Data: []byte("<nav></nav>")}
+	require.NoError(t, ve.FileChanged(fsys, app, fsnotify.Event{Name: "components/nav.html", Op: fsnotify.Write}))
+
+	fsys["views/home.html"] = &fstest.MapFile{Data: []byte("<main>{{ .Name }}</main>")}
+	require.NoError(t, ve.FileChanged(fsys, app, fsnotify.Event{Name: "views/home.html", Op: fsnotify.Write}))
+
+	require.Empty(t, app.TemplateDiagnostics())
+	require.Contains(t, app.viewers, "views/home")
+}
@@ -0,0 +1,113 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDrainEvent is the SSE event sent to streaming clients by Shutdown. It
+// asks EventSource clients, e.g. the htmx sse extension, to reconnect in one
+// second, which reaches a new instance behind the load balancer.
+const DefaultDrainEvent = "retry: 1000\nevent: reconnect\ndata: reconnect\n\n"
+
+// drainPollInterval is how often Shutdown checks whether all streams are closed.
+var drainPollInterval = 50 * time.Millisecond
+
+// streamSet is the set of the requests that are streaming.
+type streamSet struct {
+	mu       sync.Mutex
+	items    map[*statsResponseWriter]struct{}
+	draining chan struct{}
+	once     sync.Once
+}
+
+func newStreamSet() *streamSet {
+	return &streamSet{
+		items:    make(map[*statsResponseWriter]struct{}),
+		draining: make(chan struct{}),
+	}
+}
+
+func (s *streamSet) add(w *statsResponseWriter) {
+	s.mu.Lock()
+	s.items[w] = struct{}{}
+	s.mu.Unlock()
+}
+
+func (s *streamSet) remove(w *statsResponseWriter) {
+	s.mu.Lock()
+	delete(s.items, w)
+	s.mu.Unlock()
+}
+
+func (s *streamSet) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.items)
+}
+
+func (s *streamSet) list() []*statsResponseWriter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := make([]*statsResponseWriter, 0, len(s.items))
+	for w := range s.items {
+		items = append(items, w)
+	}
+	return items
+}
+
+// Draining returns a channel that is closed when the App starts shutting
+// down. Long-lived handlers, e.g. SSE and WebSocket loops, should return,
+// or send a close frame, once it is closed.
+func (c *Context) Draining() <-chan struct{} {
+	return c.app.streams.draining
+}
+
+// Shutdown drains the active SSE and WebSocket connections. It marks the app
+// as not ready, so that /readyz fails and load balancers stop sending new
+// requests. Then it sends the drain event to all SSE streams, closes the
+// Draining channel, and waits for their handlers to return, or ctx to be done.
+//
+// It should be called before http.Server.Shutdown, which doesn't wait for
+// hijacked connections and waits for streaming requests until its deadline.
+//
+//	srv.RegisterOnShutdown(func() { app.Shutdown(ctx) })
+func (app *App) Shutdown(ctx context.Context) error {
+	s := app.streams
+	app.ready.Store(false)
+
+	if err := SdNotify(SdNotifyStopping); err != nil {
+		app.logger.Error("xun: sd_notify", slog.Any("err", err))
+	}
+
+	for _, w := range s.list() {
+		w.mu.Lock()
+		if !w.ended && !w.hijacked && strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+			io.WriteString(w.ResponseWriter, app.drainEvent) // nolint: errcheck
+			flush(w.ResponseWriter)
+		}
+		w.mu.Unlock()
+	}
+
+	s.once.Do(func() {
+		close(s.draining)
+	})
+
+	t := time.NewTicker(drainPollInterval)
+	defer t.Stop()
+
+	for s.len() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package xun
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShutdown(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	sse := func(c *Context) error {
+		c.WriteHeader("Content-Type", "text/event-stream")
+		c.Writer().Write([]byte("data: hello\n\n")) // nolint: errcheck
+		c.Writer().(http.Flusher).Flush()
+		return nil
+	}
+
+	app.Get("/events", func(c *Context) error {
+		sse(c) // nolint: errcheck
+		<-c.Request().Context().Done()
+		return nil
+	})
+
+	app.Get("/drain", func(c *Context) error {
+		sse(c) // nolint: errcheck
+		<-c.Draining()
+		return nil
+	})
+
+	read := func(path string) (*http.Response, *bufio.Reader) {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		r := bufio.NewReader(resp.Body)
+		line, err := r.ReadString('\n')
+		require.NoError(t, err)
+		require.Equal(t, "data: hello\n", line)
+		_, err = r.ReadString('\n')
+		require.NoError(t, err)
+		return resp, r
+	}
+
+	events, er := read("/events")
+	drain, dr := read("/drain")
+	defer drain.Body.Close()
+
+	require.Eventually(t, func() bool {
+		return app.streams.len() == 2
+	}, time.Second, 10*time.Millisecond)
+
+	done := make(chan error)
+	go func() {
+		done <- app.Shutdown(context.Background())
+	}()
+
+	for _, r := range []*bufio.Reader{er, dr} {
+		var event string
+		for {
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			event += line
+			if line == "\n" {
+				break
+			}
+		}
+		require.Equal(t, DefaultDrainEvent, event)
+	}
+
+	// the client reconnects to a new instance
+	events.Body.Close()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("shutdown is not completed")
+	}
+	require.Equal(t, 0, app.streams.len())
+}
+
+func TestShutdownTimeout(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithDrainEvent("event: close\ndata: bye\n\n"))
+	defer app.Close()
+
+	release := make(chan struct{})
+	app.Get("/events", func(c *Context) error {
+		c.WriteHeader("Content-Type", "text/event-stream")
+		c.Writer().(http.Flusher).Flush()
+		<-release
+		return nil
+	})
+
+	resp, err := client.Get(srv.URL + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Eventually(t, func() bool {
+		return app.streams.len() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, app.Shutdown(ctx), context.DeadlineExceeded)
+
+	r := bufio.NewReader(resp.Body)
+	line, err := r.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "event: close\n", line)
+
+	close(release)
+}
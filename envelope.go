@@ -0,0 +1,127 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header of the request id that Envelope reads
+// from requests and writes to responses by default, see WithRequestIDHeader.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the max length of the request id that is accepted from requests.
+const maxRequestIDLength = 128
+
+// EnvelopeResponse is the JSON response envelope of JsonViewer enabled by Envelope.
+type EnvelopeResponse struct {
+	Data   any             `json:"data"`
+	Meta   map[string]any  `json:"meta,omitempty"`
+	Errors []EnvelopeError `json:"errors,omitempty"`
+}
+
+// EnvelopeError is an error of EnvelopeResponse.
+type EnvelopeError struct {
+	Message string `json:"message"`
+}
+
+type envelopeContextKey struct{}
+
+type envelopeState struct {
+	id   string
+	meta map[string]any
+}
+
+// Envelope returns a middleware that wraps the responses of JsonViewer in an
+// EnvelopeResponse, e.g. `{"data": ..., "meta": {"request_id": "..."}}`, so the
+// API routes of a group are consistent. An error returned as the view data is
+// written to `errors`, and handlers add metadata, e.g. pagination, by
+// c.SetEnvelopeMeta. The request id is also the X-Log-Id of server errors, so
+// they are found in the logs by the id of the response. A streaming
+// JsonViewer streams the `data` of the envelope.
+//
+// The request id is read from the X-Request-Id header, see WithRequestIDHeader,
+// only if the request is sent by a proxy in WithTrustedProxies and the id is
+// made of [A-Za-z0-9._-]. Otherwise, a new id is generated.
+//
+//	api := app.Group("/api")
+//	api.Use(xun.Envelope())
+func Envelope() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			header := c.app.requestIDHeader
+			id := c.req.Header.Get(header)
+			if !c.app.isTrustedProxy(c.req) || !validRequestID(id) {
+				id = nextLogID()
+			}
+			c.WriteHeader(header, id)
+
+			c.WithContext(context.WithValue(c.Context(), envelopeContextKey{}, &envelopeState{
+				id:   id,
+				meta: map[string]any{"request_id": id},
+			}))
+
+			return next(c)
+		}
+	}
+}
+
+// validRequestID reports whether the request id is safe to be logged and
+// written to responses.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		switch ch := id[i]; {
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z', ch >= '0' && ch <= '9', ch == '.', ch == '_', ch == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// SetEnvelopeMeta sets the metadata key of the EnvelopeResponse, e.g.
+// c.SetEnvelopeMeta("pagination", p). It does nothing if Envelope is not used.
+func (c *Context) SetEnvelopeMeta(key string, value any) {
+	if s, ok := c.req.Context().Value(envelopeContextKey{}).(*envelopeState); ok {
+		s.meta[key] = value
+	}
+}
+
+// requestID returns the request id of Envelope, or a new log id if Envelope
+// is not used by the request r.
+func requestID(r *http.Request) string {
+	if s, ok := r.Context().Value(envelopeContextKey{}).(*envelopeState); ok {
+		return s.id
+	}
+
+	return nextLogID()
+}
+
+// envelope wraps the data in an EnvelopeResponse if Envelope is used by the request r.
+func envelope(r *http.Request, data any) any {
+	if r == nil {
+		return data
+	}
+
+	s, ok := r.Context().Value(envelopeContextKey{}).(*envelopeState)
+	if !ok {
+		return data
+	}
+
+	if _, ok := data.(*EnvelopeResponse); ok {
+		return data
+	}
+
+	e := &EnvelopeResponse{Meta: s.meta}
+	if err, ok := data.(error); ok {
+		e.Errors = []EnvelopeError{{Message: err.Error()}}
+	} else {
+		e.Data = data
+	}
+
+	return e
+}
@@ -0,0 +1,153 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelope(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithTrustedProxies("127.0.0.1", "::1"))
+	defer app.Close()
+
+	app.Get("/users", func(c *Context) error {
+		return c.View([]string{"alice"})
+	})
+
+	api := app.Group("/api")
+	api.Use(Envelope())
+
+	api.Get("/users", func(c *Context) error {
+		items, p := Paginate([]string{"alice", "bob", "carol"}, 2, 2)
+		c.SetEnvelopeMeta("pagination", p)
+		return c.View(items)
+	})
+
+	api.Get("/error", func(c *Context) error {
+		c.WriteStatus(http.StatusBadRequest)
+		return c.View(errors.New("invalid id"))
+	})
+
+	api.Get("/raw", func(c *Context) error {
+		return c.View(&EnvelopeResponse{Data: 1})
+	})
+
+	api.Get("/boom", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	items := make([]int, 10000)
+	for i := range items {
+		items[i] = i
+	}
+
+	api.Get("/items", func(c *Context) error {
+		return c.View(items)
+	})
+
+	api.Get("/items/stream", func(c *Context) error {
+		return c.View(items)
+	}, WithViewer(&JsonViewer{Stream: true}))
+
+	app.Start()
+
+	get := func(path, id string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		if id != "" {
+			req.Header.Set(DefaultRequestIDHeader, id)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(buf)
+	}
+
+	resp, body := get("/users", "")
+	require.Equal(t, "[\"alice\"]\n", body)
+	require.Empty(t, resp.Header.Get(DefaultRequestIDHeader))
+
+	resp, body = get("/api/users", "req-1")
+	require.Equal(t, "req-1", resp.Header.Get(DefaultRequestIDHeader))
+	require.JSONEq(t, `{"data":["carol"],"meta":{"pagination":{"Page":2,"Size":2,"Total":3,"Pages":2},"request_id":"req-1"}}`, body)
+
+	resp, body = get("/api/error", strings.Repeat("x", maxRequestIDLength+1))
+	id := resp.Header.Get(DefaultRequestIDHeader)
+	require.NotEmpty(t, id)
+	require.NotEqual(t, strings.Repeat("x", maxRequestIDLength+1), id)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	require.Equal(t, `{"data":null,"meta":{"request_id":"`+id+`"},"errors":[{"message":"invalid id"}]}`+"\n", body)
+
+	_, body = get("/api/raw", "")
+	require.Equal(t, `{"data":1}`+"\n", body)
+
+	// the log id of server errors is the request id
+	resp, _ = get("/api/boom", "req-2")
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	require.Equal(t, "req-2", resp.Header.Get("X-Log-Id"))
+
+	for _, query := range []string{"", "?pretty=1"} {
+		_, want := get("/api/items"+query, "req-3")
+		_, body = get("/api/items/stream"+query, "req-3")
+		require.Equal(t, want, body)
+	}
+}
+
+func TestEnvelopeRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []Option
+		header   string
+		id       string
+		accepted bool
+	}{
+		{name: "trusted", opts: []Option{WithTrustedProxies("127.0.0.1", "::1")}, header: DefaultRequestIDHeader, id: "req_1.a-B", accepted: true},
+		{name: "untrusted", header: DefaultRequestIDHeader, id: "req-1"},
+		{name: "unsafe", opts: []Option{WithTrustedProxies("127.0.0.1", "::1")}, header: DefaultRequestIDHeader, id: "req 1\"level=ERROR"},
+		{name: "custom_header", opts: []Option{WithTrustedProxies("127.0.0.1", "::1"), WithRequestIDHeader("X-Trace-Id")}, header: "X-Trace-Id", id: "trace-1", accepted: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			app := New(append(test.opts, WithMux(mux))...)
+			defer app.Close()
+
+			app.Use(Envelope())
+			app.Get("/", func(c *Context) error {
+				return c.View(nil)
+			})
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+			require.NoError(t, err)
+			req.Header.Set(test.header, test.id)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			id := resp.Header.Get(test.header)
+			require.NotEmpty(t, id)
+			if test.accepted {
+				require.Equal(t, test.id, id)
+			} else {
+				require.NotEqual(t, test.id, id)
+				require.True(t, validRequestID(id))
+			}
+		})
+	}
+}
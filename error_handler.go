@@ -0,0 +1,161 @@
+package xun
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// ErrorViewPrefix is the prefix of the views that render errors for htmx
+// requests and clients preferring text/html in DefaultErrorHandler, e.g.
+// "views/errors/404" for 404 Not Found.
+const ErrorViewPrefix = "views/errors/"
+
+// HttpError is an error with the status code of its response. Handlers return
+// it instead of writing the status themselves, e.g.
+//
+//	return xun.NewHttpError(http.StatusNotFound, "user not found")
+//
+// The errors with 4xx status codes aren't logged or reported.
+type HttpError struct {
+	Status  int
+	Message string
+	Err     error
+}
+
+// NewHttpError returns an HttpError with the status code and message.
+func NewHttpError(status int, message string) *HttpError {
+	return &HttpError{Status: status, Message: message}
+}
+
+// Error returns the message of the error.
+func (e *HttpError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.Status)
+	}
+
+	if e.Err != nil {
+		return "xun: " + msg + ": " + e.Err.Error()
+	}
+	return "xun: " + msg
+}
+
+// Unwrap returns the underlying error.
+func (e *HttpError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultErrorHandler is the ErrorHandler that writes the error by content
+// negotiation, enabled by WithErrorHandler(xun.DefaultErrorHandler). It
+// renders the view ErrorViewPrefix + status with the ProblemDetails of the
+// error for htmx requests and clients preferring text/html, and writes the
+// ProblemDetails as application/problem+json otherwise:
+//
+//	{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"/users/1"}
+//
+// See NewProblemDetails for the status codes of errors. A *BindError is
+// rendered by the BindErrorView for htmx requests and clients preferring
+// text/html, as it is without an ErrorHandler.
+func DefaultErrorHandler(c *Context, err error) {
+	var be *BindError
+	if errors.As(err, &be) && prefersHtml(c.req) {
+		c.writeBindError(be)
+		return
+	}
+
+	p := NewProblemDetails(c.req, err)
+	p.LogID = c.rw.Header().Get("X-Log-Id")
+
+	if v := c.errorViewer(p.Status); v != nil {
+		buf := c.app.bufferPool().Get()
+		defer c.app.bufferPool().Put(buf)
+
+		if v.template.execute(buf, c.req, p) == nil {
+			c.rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+			c.WriteStatus(p.Status)
+			buf.WriteTo(c.rw) // nolint: errcheck
+			return
+		}
+	}
+
+	c.rw.Header().Set("Content-Type", "application/problem+json")
+	c.WriteStatus(p.Status)
+	(&ProblemViewer{}).Render(c.rw, c.req, p) // nolint: errcheck
+}
+
+// errorStatus returns the status code of the HttpError, or 500 Internal Server Error.
+func errorStatus(err error) int {
+	var he *HttpError
+	if errors.As(err, &he) && he.Status != 0 {
+		return he.Status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// errorViewer returns the error view of the status for htmx requests and clients preferring text/html.
+func (c *Context) errorViewer(status int) *HtmlViewer {
+	if !prefersHtml(c.req) {
+		return nil
+	}
+
+	v, _ := c.app.viewers[ErrorViewPrefix+strconv.Itoa(status)].(*HtmlViewer)
+	return v
+}
+
+// Handler returns the http.Handler of the mux of the app, that passes the 404
+// Not Found and 405 Method Not Allowed of unmatched requests to the
+// ErrorHandler of WithErrorHandler as an *HttpError, e.g. to write them as
+// problem documents too. The mux responds as it is without an ErrorHandler.
+//
+//	http.ListenAndServe(":80", app.Handler())
+func (app *App) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		h, pattern := app.mux.Handler(req)
+		if pattern != "" || app.errorHandler == nil {
+			app.mux.ServeHTTP(w, req)
+			return
+		}
+
+		rec := &unmatchedRecorder{header: make(http.Header)}
+		h.ServeHTTP(rec, req)
+		if rec.status != http.StatusNotFound && rec.status != http.StatusMethodNotAllowed {
+			app.mux.ServeHTTP(w, req) // e.g. the redirect to the canonical path
+			return
+		}
+
+		if allow := rec.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+
+		rw := &statsResponseWriter{ResponseWriter: app.createWriter(req, w)}
+		defer rw.Close()
+
+		c := &Context{req: req, rw: rw, stats: rw, app: app}
+		app.errorHandler(c, &HttpError{Status: rec.status})
+	})
+}
+
+// unmatchedRecorder records the response of the mux for unmatched requests.
+type unmatchedRecorder struct {
+	header http.Header
+	status int
+}
+
+func (r *unmatchedRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *unmatchedRecorder) Write(p []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return len(p), nil
+}
+
+func (r *unmatchedRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+}
@@ -0,0 +1,177 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/errors/404.html": {Data: []byte(`<h1>{{ .Title }}: {{ .Detail }}</h1>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithErrorHandler(DefaultErrorHandler))
+
+	app.Get("/users/{id}", func(c *Context) error {
+		return NewHttpError(http.StatusNotFound, "user not found")
+	})
+
+	app.Get("/boom", func(c *Context) error {
+		return errors.New("db: connection refused")
+	})
+
+	api := app.Group("/api")
+	api.OnError(func(c *Context, err error) {
+		c.WriteStatus(http.StatusBadGateway)
+	})
+	api.Get("/boom", func(c *Context) error {
+		return errors.New("upstream")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	get := func(t *testing.T, path, accept string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp, string(buf)
+	}
+
+	t.Run("json", func(t *testing.T) {
+		resp, body := get(t, "/users/1", "application/json")
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		require.Empty(t, resp.Header.Get("X-Log-Id"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"/users/1"}`, body)
+	})
+
+	t.Run("html", func(t *testing.T) {
+		resp, body := get(t, "/users/1", "text/html")
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, `<h1>Not Found: user not found</h1>`, body)
+	})
+
+	t.Run("internal", func(t *testing.T) {
+		resp, body := get(t, "/boom", "text/html")
+
+		logID := resp.Header.Get("X-Log-Id")
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		require.NotEmpty(t, logID)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/boom","log_id":"`+logID+`"}`, body)
+	})
+
+	t.Run("group", func(t *testing.T) {
+		resp, _ := get(t, "/api/boom", "application/json")
+
+		require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	})
+}
+
+func TestHttpErrorWithoutHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	app.Get("/users/{id}", func(c *Context) error {
+		return NewHttpError(http.StatusNotFound, "user not found")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/users/1")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("X-Log-Id"))
+}
+
+func TestCancelledErrors(t *testing.T) {
+	newApp := func(opts ...Option) (*httptest.Server, *int) {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+
+		calls := 0
+		app := New(append([]Option{WithMux(mux), WithErrorHandler(func(c *Context, err error) {
+			calls++
+			DefaultErrorHandler(c, err)
+		})}, opts...)...)
+
+		app.Get("/cancelled", func(c *Context) error {
+			return ErrCancelled
+		})
+
+		app.Get("/hijacked", func(c *Context) error {
+			conn, buf, err := c.Hijack()
+			if err != nil {
+				return err
+			}
+			defer conn.Close()
+
+			buf.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok") // nolint: errcheck
+			buf.Flush()                                                                            // nolint: errcheck
+			return ErrCancelled
+		})
+
+		app.Start()
+		t.Cleanup(app.Close)
+		t.Cleanup(srv.Close)
+
+		return srv, &calls
+	}
+
+	get := func(t *testing.T, url string) (int, string) {
+		resp, err := client.Get(url)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		srv, calls := newApp()
+
+		status, _ := get(t, srv.URL+"/cancelled")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, 0, *calls)
+	})
+
+	t.Run("opt_in", func(t *testing.T) {
+		srv, calls := newApp(WithCancelledErrors())
+
+		status, _ := get(t, srv.URL+"/cancelled")
+		require.Equal(t, http.StatusServiceUnavailable, status)
+		require.Equal(t, 1, *calls)
+
+		status, body := get(t, srv.URL+"/hijacked")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, "ok", body)
+		require.Equal(t, 1, *calls)
+	})
+}
@@ -4,4 +4,27 @@ import "errors"
 
 var (
 	ErrCancelled = errors.New("xun: request_cancelled")
+
+	ErrInvalidSignature = errors.New("xun: invalid_signature")
+	ErrURLExpired       = errors.New("xun: url_expired")
+
+	ErrUnsupportedListener = errors.New("xun: unsupported_listener")
+
+	ErrMailNotFound = errors.New("xun: mail_not_found")
+	ErrNoMailSender = errors.New("xun: no_mail_sender")
+
+	ErrViewerNotFound = errors.New("xun: viewer_not_found")
+	ErrNoPDFRenderer  = errors.New("xun: no_pdf_renderer")
+
+	ErrUnsupportedTable = errors.New("xun: unsupported_table")
+
+	ErrJsonTooDeep = errors.New("xun: json_too_deep")
+
+	ErrRouteNotFound = errors.New("xun: route_not_found")
+
+	ErrJobCancelled = errors.New("xun: job_cancelled")
+
+	ErrUnsupportedArchive = errors.New("xun: unsupported_archive")
+
+	ErrCrossOriginWebSocket = errors.New("xun: cross_origin_websocket")
 )
@@ -0,0 +1,151 @@
+package antibot
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"html/template"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// TimestampField is the name of the hidden field with the signed rendering time of the form.
+const TimestampField = "_ts"
+
+// Captcha is the interface of captcha providers, e.g. reCAPTCHA, hCaptcha or
+// Turnstile. Verify reads the response token from the submitted form, and
+// reports whether it is solved.
+type Captcha interface {
+	Verify(ctx context.Context, r *http.Request) (bool, error)
+}
+
+// CaptchaFunc is an adapter to allow the use of ordinary functions as Captcha.
+type CaptchaFunc func(ctx context.Context, r *http.Request) (bool, error)
+
+// Verify calls f(ctx, r).
+func (f CaptchaFunc) Verify(ctx context.Context, r *http.Request) (bool, error) {
+	return f(ctx, r)
+}
+
+// Guard protects public forms from bots. Forms render a honeypot field, that
+// is hidden from humans, and the signed rendering time by the `honeypot`
+// template function. Submissions are rejected with 400 Bad Request by the
+// middleware, if:
+//
+//   - the honeypot field is filled.
+//   - the rendering time is missing, tampered or expired.
+//   - the form is submitted faster than the minimum fill time.
+//   - the captcha isn't solved, if a captcha provider is set.
+type Guard struct {
+	key         []byte
+	field       string
+	minFillTime time.Duration
+	maxAge      time.Duration
+	captcha     Captcha
+
+	now func() time.Time
+}
+
+// New creates a Guard.
+func New(opts ...Option) *Guard {
+	g := &Guard{
+		field:       "website",
+		minFillTime: 2 * time.Second,
+		maxAge:      24 * time.Hour,
+		now:         time.Now,
+	}
+
+	for _, o := range opts {
+		o(g)
+	}
+
+	if g.key == nil {
+		g.key = make([]byte, 32)
+		rand.Read(g.key) // nolint: errcheck
+	}
+
+	return g
+}
+
+// FuncMap returns the template function `honeypot`, which renders the honeypot
+// and timestamp fields in a form. It should be added to xun.FuncMap before the
+// App is created.
+//
+//	<form method="post">{{ honeypot }} ... </form>
+func (g *Guard) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"honeypot": g.Field,
+	}
+}
+
+// Field returns the HTML of the honeypot and timestamp fields.
+func (g *Guard) Field() template.HTML {
+	ts := strconv.FormatInt(g.now().Unix(), 10)
+
+	return template.HTML(`<div style="position:absolute;left:-10000px;" aria-hidden="true">` + // nolint: gosec
+		`<input type="text" name="` + template.HTMLEscapeString(g.field) + `" tabindex="-1" autocomplete="off" value="">` +
+		`</div><input type="hidden" name="` + TimestampField + `" value="` + ts + "." + g.sign(ts) + `">`)
+}
+
+func (g *Guard) sign(ts string) string {
+	h := hmac.New(sha256.New, g.key)
+	h.Write([]byte(ts)) // nolint: errcheck
+	return base64.RawURLEncoding.EncodeToString(h.Sum(nil))
+}
+
+// Middleware verifies the submissions of POST, PUT, PATCH and DELETE requests
+// before they are bound by handlers, e.g. with xun.BindForm.
+func (g *Guard) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		req := c.Request()
+		if req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions {
+			return next(c)
+		}
+
+		ok, err := g.verify(req)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			c.WriteStatus(http.StatusBadRequest)
+			return xun.ErrCancelled
+		}
+
+		return next(c)
+	}
+}
+
+// verify reports whether the submission of the request is from a human.
+func (g *Guard) verify(req *http.Request) (bool, error) {
+	if req.PostFormValue(g.field) != "" {
+		return false, nil
+	}
+
+	ts, sig, ok := strings.Cut(req.PostFormValue(TimestampField), ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(g.sign(ts))) {
+		return false, nil
+	}
+
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return false, nil
+	}
+
+	elapsed := g.now().Sub(time.Unix(n, 0))
+	if elapsed < g.minFillTime || elapsed > g.maxAge {
+		return false, nil
+	}
+
+	if g.captcha != nil {
+		return g.captcha.Verify(req.Context(), req)
+	}
+
+	return true, nil
+}
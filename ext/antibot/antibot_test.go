@@ -0,0 +1,117 @@
+package antibot
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestGuard(t *testing.T) {
+	now := time.Now()
+
+	var solved bool
+	var captchaErr error
+
+	g := New(WithMinFillTime(3*time.Second), WithMaxAge(time.Hour), WithCaptcha(CaptchaFunc(func(ctx context.Context, r *http.Request) (bool, error) {
+		return solved && r.PostFormValue("captcha") == "ok", captchaErr
+	})))
+	g.now = func() time.Time { return now }
+
+	xun.FuncMap["honeypot"] = g.FuncMap()["honeypot"]
+	defer delete(xun.FuncMap, "honeypot")
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithFsys(fstest.MapFS{
+		"pages/contact.html": {Data: []byte(`<form method="post">{{ honeypot }}<input name="email"></form>`)},
+	}))
+	defer app.Close()
+
+	type contact struct {
+		Email string `form:"email"`
+	}
+
+	app.Use(g.Middleware)
+	app.Post("/contact", func(c *xun.Context) error {
+		it, err := xun.BindForm[contact](c.Request())
+		if err != nil {
+			return err
+		}
+		return c.View(it.Data.Email)
+	})
+
+	app.Start()
+
+	resp, err := http.Get(srv.URL + "/contact")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Contains(t, string(buf), `<input type="text" name="website" tabindex="-1" autocomplete="off" value="">`)
+	ts := regexp.MustCompile(`name="_ts" value="([^"]+)"`).FindStringSubmatch(string(buf))
+	require.Len(t, ts, 2)
+
+	post := func(form url.Values) (int, string) {
+		resp, err := http.PostForm(srv.URL+"/contact", form)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	form := func(kv ...string) url.Values {
+		v := url.Values{"email": {"xun@yaitoo.cn"}, "captcha": {"ok"}, TimestampField: {ts[1]}}
+		for i := 0; i < len(kv); i += 2 {
+			v.Set(kv[i], kv[i+1])
+		}
+		return v
+	}
+
+	solved = true
+
+	// too fast
+	status, _ := post(form())
+	require.Equal(t, http.StatusBadRequest, status)
+
+	now = now.Add(5 * time.Second)
+
+	status, body := post(form())
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "\"xun@yaitoo.cn\"\n", body)
+
+	for name, f := range map[string]url.Values{
+		"honeypot":  form("website", "https://spam.example.com"),
+		"missing":   form(TimestampField, ""),
+		"tampered":  form(TimestampField, strings.Replace(ts[1], ts[1][:3], "999", 1)),
+		"malformed": form(TimestampField, "abc."+g.sign("abc")),
+		"captcha":   form("captcha", ""),
+	} {
+		status, _ = post(f)
+		require.Equal(t, http.StatusBadRequest, status, name)
+	}
+
+	captchaErr = errors.New("captcha: unavailable")
+	status, _ = post(form())
+	require.Equal(t, http.StatusInternalServerError, status)
+	captchaErr = nil
+
+	// expired
+	now = now.Add(2 * time.Hour)
+	status, _ = post(form())
+	require.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,45 @@
+package antibot
+
+import "time"
+
+// Option is a function type that takes a pointer to Guard as an argument.
+// It is used to configure the Guard with various options.
+type Option func(*Guard)
+
+// WithKey sets the key to sign the rendering time of forms. It should be shared
+// by all instances of the app. A random key is generated by default.
+func WithKey(key []byte) Option {
+	return func(g *Guard) {
+		g.key = key
+	}
+}
+
+// WithField sets the name of the honeypot field. The default name is "website".
+func WithField(name string) Option {
+	return func(g *Guard) {
+		g.field = name
+	}
+}
+
+// WithMinFillTime sets the minimum time between rendering and submitting a form.
+// Faster submissions are rejected. The default is 2 seconds.
+func WithMinFillTime(d time.Duration) Option {
+	return func(g *Guard) {
+		g.minFillTime = d
+	}
+}
+
+// WithMaxAge sets how long a rendered form can be submitted. The default is 24 hours.
+func WithMaxAge(d time.Duration) Option {
+	return func(g *Guard) {
+		g.maxAge = d
+	}
+}
+
+// WithCaptcha sets the captcha provider to verify submissions after the
+// honeypot and fill time checks pass.
+func WithCaptcha(c Captcha) Option {
+	return func(g *Guard) {
+		g.captcha = c
+	}
+}
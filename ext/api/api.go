@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// Verifier verifies the bearer token of a request, and returns its user. The
+// user is set with c.Set(xun.UserKey, user) for handlers and other extensions.
+type Verifier func(ctx context.Context, token string) (any, error)
+
+// Mode is the explicit security model of API routes, e.g. `/api/*`:
+//
+//   - Cookies are removed from requests, so cookie-based sessions can't
+//     authenticate them, and cross-site requests can't forge them (CSRF).
+//   - Requests must be authenticated by an `Authorization: Bearer` token,
+//     or they are rejected with 401 Unauthorized.
+//   - Cross-origin requests are only allowed from the origins set by
+//     WithOrigins, or they are rejected with 403 Forbidden. Credentials are
+//     never allowed.
+type Mode struct {
+	prefix  string
+	verify  Verifier
+	origins map[string]struct{}
+	methods []string
+	headers []string
+	maxAge  time.Duration
+}
+
+// New creates an API mode that authenticates requests by verify.
+func New(verify Verifier, opts ...Option) *Mode {
+	m := &Mode{
+		prefix:  "/api",
+		verify:  verify,
+		origins: make(map[string]struct{}),
+		methods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete},
+		headers: []string{"Authorization", "Content-Type"},
+		maxAge:  10 * time.Minute,
+	}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	m.prefix = strings.TrimSuffix(m.prefix, "/")
+
+	return m
+}
+
+type router interface {
+	HandleFunc(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+	Use(middlewares ...xun.Middleware)
+}
+
+// Register applies the API mode to the router, e.g. app.Group("/api"), and
+// registers the CORS preflight endpoint `OPTIONS {prefix}/{path...}`.
+func (m *Mode) Register(r router, opts ...xun.RoutingOption) {
+	r.Use(m.Middleware)
+	r.HandleFunc(http.MethodOptions+" "+m.prefix+"/{path...}", m.preflight, opts...)
+}
+
+// Middleware enforces the API mode on the requests.
+func (m *Mode) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		req := c.Request()
+
+		if !m.allowOrigin(c) {
+			c.WriteStatus(http.StatusForbidden)
+			return xun.ErrCancelled
+		}
+
+		if req.Method == http.MethodOptions {
+			return next(c)
+		}
+
+		req.Header.Del("Cookie")
+
+		token, ok := bearer(req.Header.Get("Authorization"))
+		if !ok {
+			return m.unauthorized(c)
+		}
+
+		user, err := m.verify(req.Context(), token)
+		if err != nil || user == nil {
+			return m.unauthorized(c)
+		}
+
+		c.Set(xun.UserKey, user)
+
+		return next(c)
+	}
+}
+
+// allowOrigin reports whether the request is same-origin or from an allowed
+// origin, and sets the CORS headers for the latter.
+func (m *Mode) allowOrigin(c *xun.Context) bool {
+	origin := c.Request().Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	h := c.Writer().Header()
+	h.Add("Vary", "Origin")
+
+	if _, ok := m.origins[origin]; ok {
+		h.Set("Access-Control-Allow-Origin", origin)
+		return true
+	}
+
+	return origin == strings.TrimSuffix(c.AbsoluteURL("/"), "/")
+}
+
+// preflight handles the CORS preflight requests of allowed origins.
+func (m *Mode) preflight(c *xun.Context) error {
+	h := c.Writer().Header()
+	if c.Request().Header.Get("Access-Control-Request-Method") == "" {
+		h.Set("Allow", strings.Join(m.methods, ", "))
+		c.WriteStatus(http.StatusNoContent)
+		return nil
+	}
+
+	h.Set("Access-Control-Allow-Methods", strings.Join(m.methods, ", "))
+	h.Set("Access-Control-Allow-Headers", strings.Join(m.headers, ", "))
+	h.Set("Access-Control-Max-Age", strconv.Itoa(int(m.maxAge.Seconds())))
+	c.WriteStatus(http.StatusNoContent)
+	return nil
+}
+
+func (m *Mode) unauthorized(c *xun.Context) error {
+	c.Writer().Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	c.WriteStatus(http.StatusUnauthorized)
+	return xun.ErrCancelled
+}
+
+// bearer returns the token of the Authorization header value.
+func bearer(auth string) (string, bool) {
+	scheme, token, ok := strings.Cut(auth, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestMode(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	m := New(func(ctx context.Context, token string) (any, error) {
+		if token != "abc" {
+			return nil, errors.New("invalid token")
+		}
+		return "xun", nil
+	}, WithOrigins("https://app.example.com"), WithHeaders("X-Request-Id"))
+
+	api := app.Group("/api")
+	m.Register(api)
+
+	api.Get("/me", func(c *xun.Context) error {
+		return c.View(map[string]any{
+			"user":   c.Get(xun.UserKey),
+			"cookie": c.Request().Header.Get("Cookie"),
+		})
+	})
+
+	app.Start()
+
+	do := func(method, origin, auth string, header ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+"/api/me", nil)
+		require.NoError(t, err)
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		for i := 0; i < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		req.Header.Set("Cookie", "session=1")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(buf)
+	}
+
+	t.Run("token", func(t *testing.T) {
+		resp, body := do(http.MethodGet, "", "Bearer abc")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		// cookies are removed
+		require.JSONEq(t, `{"user":"xun","cookie":""}`, body)
+
+		for _, auth := range []string{"", "Bearer", "Basic abc", "Bearer xyz"} {
+			resp, _ = do(http.MethodGet, "", auth)
+			require.Equal(t, http.StatusUnauthorized, resp.StatusCode, auth)
+			require.Equal(t, `Bearer realm="api"`, resp.Header.Get("WWW-Authenticate"))
+		}
+	})
+
+	t.Run("cors", func(t *testing.T) {
+		resp, _ := do(http.MethodGet, "https://app.example.com", "Bearer abc")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Credentials"))
+		require.Equal(t, "Origin", resp.Header.Get("Vary"))
+
+		// same origin
+		resp, _ = do(http.MethodGet, srv.URL, "Bearer abc")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Origin"))
+
+		resp, _ = do(http.MethodGet, "https://evil.example.com", "Bearer abc")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("preflight", func(t *testing.T) {
+		resp, _ := do(http.MethodOptions, "https://app.example.com", "", "Access-Control-Request-Method", "POST")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Equal(t, "https://app.example.com", resp.Header.Get("Access-Control-Allow-Origin"))
+		require.Equal(t, "GET, POST, PUT, PATCH, DELETE", resp.Header.Get("Access-Control-Allow-Methods"))
+		require.Equal(t, "Authorization, Content-Type, X-Request-Id", resp.Header.Get("Access-Control-Allow-Headers"))
+		require.Equal(t, "600", resp.Header.Get("Access-Control-Max-Age"))
+
+		resp, _ = do(http.MethodOptions, "https://evil.example.com", "", "Access-Control-Request-Method", "POST")
+		require.Equal(t, http.StatusForbidden, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Access-Control-Allow-Methods"))
+	})
+}
@@ -0,0 +1,49 @@
+package api
+
+import "time"
+
+// Option is a function type that takes a pointer to Mode as an argument.
+// It is used to configure the Mode with various options.
+type Option func(*Mode)
+
+// WithPrefix sets the path prefix of the API routes. It is used to register the
+// CORS preflight endpoint. The default prefix is "/api".
+func WithPrefix(prefix string) Option {
+	return func(m *Mode) {
+		m.prefix = prefix
+	}
+}
+
+// WithOrigins sets the cross origins that are allowed to call the API, e.g.
+// "https://app.example.com". Only same-origin requests are allowed by default.
+func WithOrigins(origins ...string) Option {
+	return func(m *Mode) {
+		for _, o := range origins {
+			m.origins[o] = struct{}{}
+		}
+	}
+}
+
+// WithMethods sets the methods allowed in CORS preflight requests. The default
+// methods are GET, POST, PUT, PATCH and DELETE.
+func WithMethods(methods ...string) Option {
+	return func(m *Mode) {
+		m.methods = methods
+	}
+}
+
+// WithHeaders sets the request headers allowed in CORS preflight requests
+// besides Authorization and Content-Type.
+func WithHeaders(headers ...string) Option {
+	return func(m *Mode) {
+		m.headers = append(m.headers, headers...)
+	}
+}
+
+// WithMaxAge sets how long the results of CORS preflight requests can be cached.
+// The default is 10 minutes.
+func WithMaxAge(d time.Duration) Option {
+	return func(m *Mode) {
+		m.maxAge = d
+	}
+}
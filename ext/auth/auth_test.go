@@ -0,0 +1,107 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestArgon2id(t *testing.T) {
+	a := &Argon2id{Time: 1, Memory: 8 * 1024, Threads: 1, SaltLen: 16, KeyLen: 32}
+
+	h, err := a.Hash("secret")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(h, "$argon2id$v=19$m=8192,t=1,p=1$"))
+
+	h2, err := a.Hash("secret")
+	require.NoError(t, err)
+	require.NotEqual(t, h, h2) // random salt
+
+	ok, err := a.Verify("secret", h)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = a.Verify("Secret", h)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.False(t, a.NeedsRehash(h))
+	require.True(t, (&Argon2id{Time: 2, Memory: 8 * 1024, Threads: 1, SaltLen: 16, KeyLen: 32}).NeedsRehash(h))
+
+	for _, encoded := range []string{"", "secret", "$argon2i$v=19$m=8192,t=1,p=1$c2FsdA$aGFzaA", "$argon2id$v=18$m=8192,t=1,p=1$c2FsdA$aGFzaA", "$argon2id$v=19$m=8192$c2FsdA$aGFzaA", "$argon2id$v=19$m=8192,t=1,p=1$!$aGFzaA",
+		"$argon2id$v=19$m=8192,t=0,p=1$c2FsdA$aGFzaA", "$argon2id$v=19$m=8192,t=1,p=0$c2FsdA$aGFzaA"} {
+		_, err = a.Verify("secret", encoded)
+		require.ErrorIs(t, err, ErrInvalidHash, encoded)
+		require.True(t, a.NeedsRehash(encoded))
+	}
+
+	// hash by the reference implementation: echo -n password | argon2 somesalt -id -t 2 -m 16 -p 1
+	ok, err = a.Verify("password", "$argon2id$v=19$m=65536,t=2,p=1$c29tZXNhbHQ$CTFhFdXPJO1aFaMaO6Mm5c8y7cJHAph8ArZWb2GRPPc")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestBcrypt(t *testing.T) {
+	b := &Bcrypt{Cost: bcrypt.MinCost}
+
+	h, err := b.Hash("secret")
+	require.NoError(t, err)
+
+	ok, err := b.Verify("secret", h)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = b.Verify("secret2", h)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	_, err = b.Verify("secret", "$2a$bad")
+	require.ErrorIs(t, err, ErrInvalidHash)
+
+	require.False(t, b.NeedsRehash(h))
+	require.True(t, (&Bcrypt{}).NeedsRehash(h))
+
+	// bcrypt hashes are verified and migrated by argon2id
+	ok, rehash, err := VerifyPassword("secret", h)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.True(t, rehash)
+}
+
+func TestPassword(t *testing.T) {
+	h, err := HashPassword("secret")
+	require.NoError(t, err)
+
+	ok, rehash, err := VerifyPassword("secret", h)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.False(t, rehash)
+
+	ok, rehash, err = VerifyPassword("oops", h)
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.False(t, rehash)
+}
+
+func TestToken(t *testing.T) {
+	token, selector, hash, err := NewToken()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(token, selector+"."))
+	require.NotContains(t, hash, token[len(selector)+1:])
+
+	s, h, err := ParseToken(token)
+	require.NoError(t, err)
+	require.Equal(t, selector, s)
+	require.True(t, VerifyToken(h, hash))
+
+	_, h, err = ParseToken(selector + ".forged")
+	require.NoError(t, err)
+	require.False(t, VerifyToken(h, hash))
+
+	for _, token := range []string{"", "abc", ".abc", "abc."} {
+		_, _, err = ParseToken(token)
+		require.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
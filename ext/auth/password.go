@@ -0,0 +1,187 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	ErrInvalidHash  = errors.New("auth: invalid_hash")
+	ErrInvalidToken = errors.New("auth: invalid_token")
+)
+
+// Hasher hashes passwords, and verifies passwords against their hashes.
+type Hasher interface {
+	// Hash returns the encoded hash of the password with a random salt.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash.
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether the encoded hash is created with other
+	// parameters or algorithms, so it should be rehashed after a successful login.
+	NeedsRehash(encoded string) bool
+}
+
+// Argon2id is a Hasher by argon2id, the recommended password hashing algorithm.
+// Hashes are encoded in the PHC string format, e.g.
+// `$argon2id$v=19$m=65536,t=1,p=4$<salt>$<hash>`.
+type Argon2id struct {
+	Time    uint32
+	Memory  uint32 // KiB
+	Threads uint8
+	SaltLen uint32
+	KeyLen  uint32
+}
+
+// DefaultHasher is the Hasher used by HashPassword and VerifyPassword. It is
+// argon2id with the parameters recommended by RFC 9106 for memory-constrained
+// environments.
+var DefaultHasher Hasher = &Argon2id{Time: 3, Memory: 64 * 1024, Threads: 4, SaltLen: 16, KeyLen: 32}
+
+// Hash implements Hasher.
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, a.Time, a.Memory, a.Threads, a.KeyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version, a.Memory, a.Time, a.Threads,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// Verify implements Hasher. It verifies bcrypt hashes too, so passwords can be
+// migrated from bcrypt to argon2id on login.
+func (a *Argon2id) Verify(password, encoded string) (bool, error) {
+	if isBcrypt(encoded) {
+		return (&Bcrypt{}).Verify(password, encoded)
+	}
+
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	other := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, uint32(len(key)))
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+// NeedsRehash implements Hasher.
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	p, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+
+	return p.Time != a.Time || p.Memory != a.Memory || p.Threads != a.Threads ||
+		uint32(len(salt)) != a.SaltLen || uint32(len(key)) != a.KeyLen
+}
+
+func decodeArgon2id(encoded string) (*Argon2id, []byte, []byte, error) {
+	// "", "argon2id", "v=19", "m=65536,t=1,p=4", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	p := &Argon2id{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	// argon2.IDKey panics for them
+	if p.Time < 1 || p.Threads < 1 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return nil, nil, nil, ErrInvalidHash
+	}
+
+	return p, salt, key, nil
+}
+
+// Bcrypt is a Hasher by bcrypt. Passwords longer than 72 bytes are rejected by bcrypt.
+type Bcrypt struct {
+	Cost int
+}
+
+// Hash implements Hasher.
+func (b *Bcrypt) Hash(password string) (string, error) {
+	cost := b.Cost
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+
+	buf, err := bcrypt.GenerateFromPassword([]byte(password), cost)
+	if err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// Verify implements Hasher.
+func (*Bcrypt) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == nil {
+		return true, nil
+	}
+
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+
+	return false, ErrInvalidHash
+}
+
+// NeedsRehash implements Hasher.
+func (b *Bcrypt) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	if err != nil {
+		return true
+	}
+
+	want := b.Cost
+	if want == 0 {
+		want = bcrypt.DefaultCost
+	}
+	return cost != want
+}
+
+func isBcrypt(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") || strings.HasPrefix(encoded, "$2b$") || strings.HasPrefix(encoded, "$2y$")
+}
+
+// HashPassword returns the encoded hash of the password by DefaultHasher.
+func HashPassword(password string) (string, error) {
+	return DefaultHasher.Hash(password)
+}
+
+// VerifyPassword reports whether the password matches the encoded hash by
+// DefaultHasher, and whether the hash should be replaced by HashPassword.
+func VerifyPassword(password, encoded string) (ok bool, rehash bool, err error) {
+	ok, err = DefaultHasher.Verify(password, encoded)
+	if err != nil || !ok {
+		return false, false, err
+	}
+
+	return true, DefaultHasher.NeedsRehash(encoded), nil
+}
@@ -0,0 +1,48 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+)
+
+// NewToken returns a random token for clients, e.g. a remember-me cookie, and
+// its selector and hash to be stored on the server. The token is
+// `<selector>.<validator>`: the selector looks up the stored hash, and only the
+// SHA-256 hash of the validator is stored, so leaked databases can't be used to
+// forge tokens.
+func NewToken() (token, selector, hash string, err error) {
+	buf := make([]byte, 12+32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", "", err
+	}
+
+	selector = base64.RawURLEncoding.EncodeToString(buf[:12])
+	validator := base64.RawURLEncoding.EncodeToString(buf[12:])
+
+	return selector + "." + validator, selector, hashValidator(validator), nil
+}
+
+// ParseToken returns the selector and the validator hash of a token from a
+// client. The hash should be verified by VerifyToken with the stored hash of
+// the selector.
+func ParseToken(token string) (selector, hash string, err error) {
+	selector, validator, ok := strings.Cut(token, ".")
+	if !ok || selector == "" || validator == "" {
+		return "", "", ErrInvalidToken
+	}
+
+	return selector, hashValidator(validator), nil
+}
+
+// VerifyToken reports whether the hash of a token matches the stored hash in constant time.
+func VerifyToken(hash, stored string) bool {
+	return subtle.ConstantTimeCompare([]byte(hash), []byte(stored)) == 1
+}
+
+func hashValidator(validator string) string {
+	sum := sha256.Sum256([]byte(validator))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,219 @@
+package barcode
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// Service generates QR codes and Code 128 barcodes for arbitrary data, e.g.
+// `/barcode/qr.svg?data=https://example.com` or `/barcode/code128.png?data=TICKET-0042`.
+//
+// Generated images are kept in a LRU memory cache, and clients can cache
+// them by the ETag and Cache-Control headers.
+type Service struct {
+	prefix    string
+	level     Level
+	maxSize   int
+	cacheSize int
+	maxAge    time.Duration
+
+	cache *cache
+}
+
+// New creates a barcode service.
+func New(opts ...Option) *Service {
+	s := &Service{
+		prefix:    "/barcode",
+		level:     M,
+		maxSize:   1024,
+		cacheSize: 256,
+		maxAge:    24 * time.Hour,
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	s.prefix = strings.TrimSuffix(s.prefix, "/")
+	s.cache = newCache(s.cacheSize)
+
+	return s
+}
+
+type router interface {
+	Get(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+}
+
+// Register registers the barcode endpoint `GET {prefix}/{format}` on the router.
+// The format is one of "qr.png", "qr.svg", "code128.png" and "code128.svg".
+func (s *Service) Register(r router, opts ...xun.RoutingOption) {
+	r.Get(s.prefix+"/{format}", s.Handle, opts...)
+}
+
+// URL returns the URL of the image of data in the format, e.g. "qr.svg".
+func (s *Service) URL(format, data string) string {
+	return s.prefix + "/" + format + "?data=" + url.QueryEscape(data)
+}
+
+// FuncMap returns the template functions `qrcode` and `barcode`, which render
+// data as an inline SVG QR code and Code 128 barcode. They should be added to
+// xun.FuncMap before the App is created.
+//
+//	{{ qrcode .TicketURL }}
+//	{{ barcode .TicketID }}
+func (s *Service) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"qrcode": func(data string) (template.HTML, error) {
+			q, err := EncodeQR([]byte(data), s.level)
+			if err != nil {
+				return "", err
+			}
+			return template.HTML(q.SVG(4)), nil // nolint: gosec
+		},
+		"barcode": func(data string) (template.HTML, error) {
+			b, err := EncodeCode128(data)
+			if err != nil {
+				return "", err
+			}
+			return template.HTML(b.SVG(2, 40)), nil // nolint: gosec
+		},
+	}
+}
+
+// Handle serves the image of the `data` query parameter. The `scale` query
+// parameter sets the pixels per module, `level` sets the error correction
+// level (L, M, Q or H) of QR codes, and `height` sets the height of barcodes
+// in modules.
+//
+// It responds 400 Bad Request for invalid parameters or data that can't be
+// encoded, and 404 Not Found for an unknown format.
+func (s *Service) Handle(c *xun.Context) error {
+	req := c.Request()
+	query := req.URL.Query()
+
+	format := req.PathValue("format")
+	kind, ext, _ := strings.Cut(format, ".")
+	if (kind != "qr" && kind != "code128") || (ext != "png" && ext != "svg") {
+		c.WriteStatus(http.StatusNotFound)
+		return xun.ErrCancelled
+	}
+
+	data := query.Get("data")
+	if data == "" || len(data) > s.maxSize {
+		c.WriteStatus(http.StatusBadRequest)
+		return xun.ErrCancelled
+	}
+
+	scale, ok := intParam(query.Get("scale"), 4, 1, 32)
+	if !ok {
+		c.WriteStatus(http.StatusBadRequest)
+		return xun.ErrCancelled
+	}
+
+	height, ok := intParam(query.Get("height"), 40, 1, 200)
+	if !ok {
+		c.WriteStatus(http.StatusBadRequest)
+		return xun.ErrCancelled
+	}
+
+	level := s.level
+	if l := query.Get("level"); l != "" {
+		i := strings.Index("LMQH", l)
+		if len(l) != 1 || i < 0 {
+			c.WriteStatus(http.StatusBadRequest)
+			return xun.ErrCancelled
+		}
+		level = Level(i)
+	}
+
+	key := format + "|" + strconv.Itoa(scale) + "|" + strconv.Itoa(int(level)) + "|" + strconv.Itoa(height) + "|" + data
+
+	e, ok := s.cache.Get(key)
+	if !ok {
+		var err error
+		e, err = generate(kind, ext, data, level, scale, height)
+		if err != nil {
+			c.WriteStatus(http.StatusBadRequest)
+			return xun.ErrCancelled
+		}
+		e.key = key
+		s.cache.Set(e)
+	}
+
+	header := c.Writer().Header()
+	header.Set("Content-Type", e.mimeType)
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.maxAge/time.Second)))
+	header.Set("ETag", e.etag)
+
+	http.ServeContent(c.Writer(), req, "", time.Time{}, bytes.NewReader(e.data))
+	return nil
+}
+
+// generate encodes data and renders it in the image format ext.
+func generate(kind, ext, data string, level Level, scale, height int) (*entry, error) {
+	var (
+		buf []byte
+		err error
+	)
+
+	if kind == "qr" {
+		q, e := EncodeQR([]byte(data), level)
+		if e != nil {
+			return nil, e
+		}
+		if ext == "svg" {
+			buf = []byte(q.SVG(scale))
+		} else {
+			buf, err = q.PNG(scale)
+		}
+	} else {
+		b, e := EncodeCode128(data)
+		if e != nil {
+			return nil, e
+		}
+		if ext == "svg" {
+			buf = []byte(b.SVG(scale, height))
+		} else {
+			buf, err = b.PNG(scale, height)
+		}
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	mimeType := "image/png"
+	if ext == "svg" {
+		mimeType = "image/svg+xml"
+	}
+
+	sum := sha256.Sum256(buf)
+
+	return &entry{
+		data:     buf,
+		mimeType: mimeType,
+		etag:     `"` + hex.EncodeToString(sum[:8]) + `"`,
+	}, nil
+}
+
+// intParam parses the query parameter v in [lo, hi], or returns def if it is empty.
+func intParam(v string, def, lo, hi int) (int, bool) {
+	if v == "" {
+		return def, true
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil || n < lo || n > hi {
+		return 0, false
+	}
+	return n, true
+}
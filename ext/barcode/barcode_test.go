@@ -0,0 +1,182 @@
+package barcode
+
+import (
+	"bytes"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+// readCode128 decodes the symbol values of b, and verifies its checksum.
+func readCode128(t *testing.T, b *Code128) []int {
+	t.Helper()
+
+	patterns := make(map[string]int)
+	for i, p := range code128Patterns {
+		patterns[p] = i
+	}
+
+	var widths []byte
+	for x := 0; x < b.Width(); {
+		start := x
+		for x < b.Width() && b.At(x) == b.At(start) {
+			x++
+		}
+		widths = append(widths, byte('0'+x-start))
+	}
+
+	require.True(t, bytes.HasSuffix(widths, []byte(code128Patterns[code128Stop])))
+	widths = widths[:len(widths)-7]
+	require.Zero(t, len(widths)%6)
+
+	var symbols []int
+	for i := 0; i < len(widths); i += 6 {
+		v, ok := patterns[string(widths[i:i+6])]
+		require.True(t, ok)
+		symbols = append(symbols, v)
+	}
+
+	checksum := symbols[0]
+	for i, s := range symbols[1 : len(symbols)-1] {
+		checksum += (i + 1) * s
+	}
+	require.Equal(t, checksum%103, symbols[len(symbols)-1])
+
+	return symbols[:len(symbols)-1]
+}
+
+func TestEncodeCode128(t *testing.T) {
+	t.Run("patterns", func(t *testing.T) {
+		seen := make(map[string]bool)
+		for i, p := range code128Patterns {
+			sum := 0
+			for _, w := range p {
+				sum += int(w - '0')
+			}
+			if i == code128Stop {
+				require.Equal(t, 13, sum)
+			} else {
+				require.Equal(t, 11, sum, "symbol %d", i)
+			}
+			require.False(t, seen[p], "symbol %d", i)
+			seen[p] = true
+		}
+	})
+
+	for _, tc := range []struct {
+		data    string
+		symbols []int
+	}{
+		{data: "Wikipedia", symbols: []int{code128StartB, 55, 73, 75, 73, 80, 69, 68, 73, 65}},
+		{data: "123456", symbols: []int{code128StartC, 12, 34, 56}},
+		{data: "TICKET-00421", symbols: []int{code128StartB, 52, 41, 35, 43, 37, 52, 13, code128CodeC, 0, 42, code128CodeB, 17}},
+		{data: "A12", symbols: []int{code128StartB, 33, 17, 18}},
+	} {
+		t.Run(tc.data, func(t *testing.T) {
+			b, err := EncodeCode128(tc.data)
+			require.NoError(t, err)
+			require.Equal(t, tc.symbols, readCode128(t, b))
+			require.True(t, b.At(0))
+		})
+	}
+
+	_, err := EncodeCode128("xin chào")
+	require.ErrorIs(t, err, ErrInvalidCharacter)
+}
+
+func TestService(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	s := New(WithMaxSize(64))
+
+	xun.FuncMap["qrcode"] = s.FuncMap()["qrcode"]
+	xun.FuncMap["barcode"] = s.FuncMap()["barcode"]
+	defer func() {
+		delete(xun.FuncMap, "qrcode")
+		delete(xun.FuncMap, "barcode")
+	}()
+
+	app := xun.New(xun.WithMux(mux), xun.WithFsys(fstest.MapFS{
+		"pages/ticket.html": {Data: []byte(`<div>{{ qrcode "https://xun.yaitoo.cn" }}{{ barcode "TICKET-0042" }}</div>`)},
+	}))
+	defer app.Close()
+
+	s.Register(app)
+
+	client := &http.Client{}
+
+	get := func(path string) (*http.Response, []byte) {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, buf
+	}
+
+	t.Run("qr_png", func(t *testing.T) {
+		resp, buf := get(s.URL("qr.png", "https://xun.yaitoo.cn") + "&scale=2&level=H")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		require.NotEmpty(t, resp.Header.Get("ETag"))
+
+		img, err := png.Decode(bytes.NewReader(buf))
+		require.NoError(t, err)
+
+		q, err := EncodeQR([]byte("https://xun.yaitoo.cn"), H)
+		require.NoError(t, err)
+		require.Equal(t, (q.Size()+8)*2, img.Bounds().Dx())
+
+		// the top left module of the finder pattern is dark
+		r, _, _, _ := img.At(8, 8).RGBA()
+		require.Zero(t, r)
+		r, _, _, _ = img.At(0, 0).RGBA()
+		require.NotZero(t, r)
+	})
+
+	t.Run("code128_svg", func(t *testing.T) {
+		resp, buf := get(s.URL("code128.svg", "TICKET-0042"))
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "image/svg+xml", resp.Header.Get("Content-Type"))
+		require.True(t, strings.HasPrefix(string(buf), `<svg xmlns="http://www.w3.org/2000/svg"`))
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL+s.URL("code128.svg", "TICKET-0042"), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+		require.Equal(t, 2, s.cache.Len()) // qr.png and code128.svg
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		for path, status := range map[string]int{
+			"/barcode/ean13.png?data=1":              http.StatusNotFound,
+			"/barcode/qr.gif?data=1":                 http.StatusNotFound,
+			"/barcode/qr.png":                        http.StatusBadRequest,
+			"/barcode/qr.png?data=1&scale=100":       http.StatusBadRequest,
+			"/barcode/qr.png?data=1&level=X":         http.StatusBadRequest,
+			"/barcode/code128.png?data=%E2%82%AC":    http.StatusBadRequest,
+			s.URL("qr.svg", strings.Repeat("x", 65)): http.StatusBadRequest,
+		} {
+			resp, _ := get(path)
+			require.Equal(t, status, resp.StatusCode, path)
+		}
+	})
+
+	t.Run("funcs", func(t *testing.T) {
+		resp, buf := get("/ticket")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, 2, strings.Count(string(buf), "<svg "))
+	})
+}
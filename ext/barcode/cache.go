@@ -0,0 +1,74 @@
+package barcode
+
+import (
+	"container/list"
+	"sync"
+)
+
+// entry is a generated image in the cache.
+type entry struct {
+	key      string
+	data     []byte
+	mimeType string
+	etag     string
+}
+
+// cache is a LRU cache of generated images.
+type cache struct {
+	mu    sync.Mutex
+	size  int
+	items map[string]*list.Element
+	ll    *list.List
+}
+
+func newCache(size int) *cache {
+	return &cache{
+		size:  size,
+		items: make(map[string]*list.Element),
+		ll:    list.New(),
+	}
+}
+
+func (c *cache) Get(key string) (*entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	c.ll.MoveToFront(el)
+	return el.Value.(*entry), true
+}
+
+func (c *cache) Set(e *entry) {
+	if c.size <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[e.key]; ok {
+		el.Value = e
+		c.ll.MoveToFront(el)
+		return
+	}
+
+	c.items[e.key] = c.ll.PushFront(e)
+
+	for c.ll.Len() > c.size {
+		el := c.ll.Back()
+		c.ll.Remove(el)
+		delete(c.items, el.Value.(*entry).key)
+	}
+}
+
+// Len returns the number of cached images.
+func (c *cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
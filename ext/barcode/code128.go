@@ -0,0 +1,120 @@
+package barcode
+
+import (
+	"errors"
+)
+
+// ErrInvalidCharacter is returned if the data can't be encoded in Code 128, e.g. non-ASCII characters.
+var ErrInvalidCharacter = errors.New("barcode: invalid_character")
+
+// code128Patterns are the bar and space widths of the Code 128 symbols, starting with a bar.
+var code128Patterns = [107]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+const (
+	code128CodeB  = 100
+	code128CodeC  = 99
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = 106
+)
+
+// Code128 is a Code 128 barcode. Dark modules are true.
+type Code128 struct {
+	modules []bool
+}
+
+// EncodeCode128 encodes the printable ASCII data into a Code 128 barcode. Runs
+// of 4 or more digits are encoded in code set C to keep the barcode short.
+func EncodeCode128(data string) (*Code128, error) {
+	if data == "" {
+		return nil, ErrInvalidCharacter
+	}
+
+	for i := 0; i < len(data); i++ {
+		if data[i] < 32 || data[i] > 126 {
+			return nil, ErrInvalidCharacter
+		}
+	}
+
+	var symbols []int
+
+	set := 0
+	for i := 0; i < len(data); {
+		if n := digits(data[i:]); n >= 4 || (n >= 2 && n == len(data)-i && set == code128CodeC) {
+			n -= n % 2
+			if set != code128CodeC {
+				if i == 0 {
+					symbols = append(symbols, code128StartC)
+				} else {
+					symbols = append(symbols, code128CodeC)
+				}
+				set = code128CodeC
+			}
+
+			for end := i + n; i < end; i += 2 {
+				symbols = append(symbols, int(data[i]-'0')*10+int(data[i+1]-'0'))
+			}
+			continue
+		}
+
+		if set != code128CodeB {
+			if i == 0 {
+				symbols = append(symbols, code128StartB)
+			} else {
+				symbols = append(symbols, code128CodeB)
+			}
+			set = code128CodeB
+		}
+
+		symbols = append(symbols, int(data[i])-32)
+		i++
+	}
+
+	checksum := symbols[0]
+	for i, s := range symbols[1:] {
+		checksum += (i + 1) * s
+	}
+	symbols = append(symbols, checksum%103, code128Stop)
+
+	b := &Code128{}
+	for _, s := range symbols {
+		for i, w := range code128Patterns[s] {
+			for n := 0; n < int(w-'0'); n++ {
+				b.modules = append(b.modules, i%2 == 0)
+			}
+		}
+	}
+
+	return b, nil
+}
+
+// digits returns the number of leading digits of s.
+func digits(s string) int {
+	n := 0
+	for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+		n++
+	}
+	return n
+}
+
+// Width returns the width of the barcode in modules, without the quiet zone.
+func (b *Code128) Width() int {
+	return len(b.modules)
+}
+
+// At reports whether the module at x is a bar.
+func (b *Code128) At(x int) bool {
+	return x >= 0 && x < len(b.modules) && b.modules[x]
+}
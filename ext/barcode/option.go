@@ -0,0 +1,43 @@
+package barcode
+
+import "time"
+
+// Option is a function type that takes a pointer to Service as an argument.
+// It is used to configure the Service with various options.
+type Option func(*Service)
+
+// WithPrefix sets the URL prefix of the barcode endpoint. If not set, it will use "/barcode".
+func WithPrefix(prefix string) Option {
+	return func(s *Service) {
+		s.prefix = prefix
+	}
+}
+
+// WithLevel sets the default error correction level of QR codes. It can be
+// overridden by the `level` query parameter. If not set, it will use M.
+func WithLevel(level Level) Option {
+	return func(s *Service) {
+		s.level = level
+	}
+}
+
+// WithMaxSize sets the maximum length of the data in bytes. If not set, it will use 1024.
+func WithMaxSize(n int) Option {
+	return func(s *Service) {
+		s.maxSize = n
+	}
+}
+
+// WithCacheSize sets the number of generated images kept in memory. If not set, it will use 256.
+func WithCacheSize(n int) Option {
+	return func(s *Service) {
+		s.cacheSize = n
+	}
+}
+
+// WithMaxAge sets the max-age of the Cache-Control header. If not set, it will use 24 hours.
+func WithMaxAge(d time.Duration) Option {
+	return func(s *Service) {
+		s.maxAge = d
+	}
+}
@@ -0,0 +1,225 @@
+package barcode
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readQR decodes the byte mode data of q, and verifies its error correction codewords.
+func readQR(t *testing.T, q *QRCode) []byte {
+	t.Helper()
+
+	// format information
+	bits := 0
+	for i := 0; i <= 5; i++ {
+		if q.At(8, i) {
+			bits |= 1 << i
+		}
+	}
+	if q.At(8, 7) {
+		bits |= 1 << 6
+	}
+	if q.At(8, 8) {
+		bits |= 1 << 7
+	}
+	if q.At(7, 8) {
+		bits |= 1 << 8
+	}
+	for i := 9; i < 15; i++ {
+		if q.At(14-i, 8) {
+			bits |= 1 << i
+		}
+	}
+
+	level, mask := Level(-1), -1
+	for l := L; l <= H; l++ {
+		for m := 0; m < 8; m++ {
+			if formatInfo(l, m) == bits {
+				level, mask = l, m
+			}
+		}
+	}
+	require.Equal(t, q.Level, level)
+	require.GreaterOrEqual(t, mask, 0)
+
+	// function modules
+	fq := &QRCode{Version: q.Version, Level: q.Level, size: q.size}
+	fq.modules = make([][]bool, q.size)
+	fq.isFunction = make([][]bool, q.size)
+	for i := range fq.modules {
+		fq.modules[i] = make([]bool, q.size)
+		fq.isFunction[i] = make([]bool, q.size)
+	}
+	fq.drawFunctionPatterns()
+
+	// data bits in the zigzag order from the bottom right corner
+	var stream []bool
+	for right := q.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < q.size; vert++ {
+			y := vert
+			if upward {
+				y = q.size - 1 - vert
+			}
+			for _, x := range []int{right, right - 1} {
+				if !fq.isFunction[y][x] {
+					stream = append(stream, q.At(x, y) != maskBit(mask, x, y))
+				}
+			}
+		}
+	}
+
+	raw := make([]byte, numRawDataModules(q.Version)/8)
+	for i := range raw {
+		for j := 0; j < 8; j++ {
+			if stream[i*8+j] {
+				raw[i] |= 1 << (7 - j)
+			}
+		}
+	}
+
+	// de-interleave
+	numBlocks := numErrorCorrectionBlocks[level][q.Version]
+	eccLen := eccCodewordsPerBlock[level][q.Version]
+	numShort := numBlocks - len(raw)%numBlocks
+	shortLen := len(raw) / numBlocks
+
+	// short blocks have a padding codeword before their error correction codewords
+	blocks := make([][]byte, numBlocks)
+	for j := range blocks {
+		blocks[j] = make([]byte, shortLen+1)
+	}
+
+	k := 0
+	for i := 0; i < shortLen+1; i++ {
+		for j := 0; j < numBlocks; j++ {
+			if i == shortLen-eccLen && j < numShort {
+				continue
+			}
+			blocks[j][i] = raw[k]
+			k++
+		}
+	}
+	require.Equal(t, len(raw), k)
+
+	for j := 0; j < numShort; j++ {
+		blocks[j] = append(blocks[j][:shortLen-eccLen], blocks[j][shortLen-eccLen+1:]...)
+	}
+
+	var data []byte
+	for _, blk := range blocks {
+		// all syndromes of a valid codeword are zero
+		alpha := byte(1)
+		for i := 0; i < eccLen; i++ {
+			var s byte
+			for _, c := range blk {
+				s = gfMul(s, alpha) ^ c
+			}
+			require.Zero(t, s, "syndrome %d", i)
+			alpha = gfMul(alpha, 2)
+		}
+		data = append(data, blk[:len(blk)-eccLen]...)
+	}
+
+	// byte mode segment
+	var bb bitBuffer
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+	read := func(n int) int {
+		v := 0
+		for i := 0; i < n; i++ {
+			v <<= 1
+			if bb[0] {
+				v |= 1
+			}
+			bb = bb[1:]
+		}
+		return v
+	}
+
+	require.Equal(t, 0x4, read(4))
+	n := read(charCountBits(q.Version))
+	out := make([]byte, n)
+	for i := range out {
+		out[i] = byte(read(8))
+	}
+	return out
+}
+
+func TestEncodeQR(t *testing.T) {
+	t.Run("reed_solomon", func(t *testing.T) {
+		// HELLO WORLD in 1-M
+		data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+		require.Equal(t, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}, rsRemainder(data, rsDivisor(10)))
+	})
+
+	t.Run("bch", func(t *testing.T) {
+		require.Equal(t, "110011000101111", strconv.FormatInt(int64(formatInfo(L, 4)), 2))
+		require.Equal(t, "111110010010100", strconv.FormatInt(int64(versionInfo(7)), 2))
+	})
+
+	t.Run("finder_patterns", func(t *testing.T) {
+		q, err := EncodeQR([]byte("https://xun.yaitoo.cn"), M)
+		require.NoError(t, err)
+
+		want := []string{"#######", "#.....#", "#.###.#", "#.###.#", "#.###.#", "#.....#", "#######"}
+		for _, origin := range [][2]int{{0, 0}, {q.Size() - 7, 0}, {0, q.Size() - 7}} {
+			for y, row := range want {
+				var sb strings.Builder
+				for x := 0; x < 7; x++ {
+					if q.At(origin[0]+x, origin[1]+y) {
+						sb.WriteByte('#')
+					} else {
+						sb.WriteByte('.')
+					}
+				}
+				require.Equal(t, row, sb.String())
+			}
+		}
+	})
+
+	for _, tc := range []struct {
+		name    string
+		size    int
+		level   Level
+		version int
+	}{
+		{name: "v1_l", size: 17, level: L, version: 1},
+		{name: "v2_l", size: 18, level: L, version: 2},
+		{name: "v1_m", size: 14, level: M, version: 1},
+		{name: "v1_q", size: 11, level: Q, version: 1},
+		{name: "v1_h", size: 7, level: H, version: 1},
+		{name: "v2_h", size: 8, level: H, version: 2},
+		{name: "v2_m", size: 26, level: M, version: 2},
+		{name: "v7_q", size: 86, level: Q, version: 7},
+		{name: "v8_q", size: 87, level: Q, version: 8},
+		{name: "v7_h", size: 64, level: H, version: 7},
+		{name: "v10_m", size: 213, level: M, version: 10},
+		{name: "v11_m", size: 214, level: M, version: 11},
+		{name: "v40_l", size: 2953, level: L, version: 40},
+		{name: "v40_h", size: 1273, level: H, version: 40},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			data := bytes.Repeat([]byte("xun-"), tc.size/4+1)[:tc.size]
+
+			q, err := EncodeQR(data, tc.level)
+			require.NoError(t, err)
+			require.Equal(t, tc.version, q.Version)
+			require.Equal(t, tc.version*4+17, q.Size())
+			require.Equal(t, data, readQR(t, q))
+		})
+	}
+
+	t.Run("too_long", func(t *testing.T) {
+		_, err := EncodeQR(make([]byte, 2954), L)
+		require.ErrorIs(t, err, ErrTooLong)
+	})
+}
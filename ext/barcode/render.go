@@ -0,0 +1,122 @@
+package barcode
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+// matrix is a 2D matrix of modules.
+type matrix interface {
+	bounds() (width, height int)
+	dark(x, y int) bool
+}
+
+func (q *QRCode) bounds() (int, int) {
+	return q.size, q.size
+}
+
+func (q *QRCode) dark(x, y int) bool {
+	return q.At(x, y)
+}
+
+// code128Matrix is a Code128 barcode with the height in modules.
+type code128Matrix struct {
+	*Code128
+	height int
+}
+
+func (m code128Matrix) bounds() (int, int) {
+	return m.Width(), m.height
+}
+
+func (m code128Matrix) dark(x, _ int) bool {
+	return m.At(x)
+}
+
+// PNG returns the QR code as a PNG image with scale pixels per module and a 4 modules quiet zone.
+func (q *QRCode) PNG(scale int) ([]byte, error) {
+	return encodePNG(q, scale, 4, 4)
+}
+
+// SVG returns the QR code as a SVG image with scale pixels per module and a 4 modules quiet zone.
+func (q *QRCode) SVG(scale int) string {
+	return encodeSVG(q, scale, 4, 4)
+}
+
+// PNG returns the barcode as a PNG image with scale pixels per module, height
+// in modules and a 10 modules quiet zone.
+func (b *Code128) PNG(scale, height int) ([]byte, error) {
+	return encodePNG(code128Matrix{Code128: b, height: height}, scale, 10, 0)
+}
+
+// SVG returns the barcode as a SVG image with scale pixels per module, height
+// in modules and a 10 modules quiet zone.
+func (b *Code128) SVG(scale, height int) string {
+	return encodeSVG(code128Matrix{Code128: b, height: height}, scale, 10, 0)
+}
+
+func encodePNG(m matrix, scale, quietX, quietY int) ([]byte, error) {
+	if scale < 1 {
+		scale = 1
+	}
+
+	w, h := m.bounds()
+	img := image.NewPaletted(image.Rect(0, 0, (w+quietX*2)*scale, (h+quietY*2)*scale), color.Palette{color.White, color.Black})
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			if !m.dark(x, y) {
+				continue
+			}
+
+			for dy := 0; dy < scale; dy++ {
+				row := ((y+quietY)*scale + dy) * img.Stride
+				for dx := 0; dx < scale; dx++ {
+					img.Pix[row+(x+quietX)*scale+dx] = 1
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeSVG draws the dark modules of each row as a path with horizontal runs.
+func encodeSVG(m matrix, scale, quietX, quietY int) string {
+	if scale < 1 {
+		scale = 1
+	}
+
+	w, h := m.bounds()
+	vw, vh := strconv.Itoa(w+quietX*2), strconv.Itoa(h+quietY*2)
+
+	var sb strings.Builder
+	sb.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="` + strconv.Itoa((w+quietX*2)*scale) + `" height="` + strconv.Itoa((h+quietY*2)*scale) +
+		`" viewBox="0 0 ` + vw + ` ` + vh + `" shape-rendering="crispEdges"><rect width="` + vw + `" height="` + vh + `" fill="#fff"/><path fill="#000" d="`)
+
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; {
+			if !m.dark(x, y) {
+				x++
+				continue
+			}
+
+			start := x
+			for x < w && m.dark(x, y) {
+				x++
+			}
+			sb.WriteString("M" + strconv.Itoa(start+quietX) + " " + strconv.Itoa(y+quietY) + "h" + strconv.Itoa(x-start) + "v1h-" + strconv.Itoa(x-start) + "z")
+		}
+	}
+
+	sb.WriteString(`"/></svg>`)
+	return sb.String()
+}
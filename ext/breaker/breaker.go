@@ -0,0 +1,228 @@
+package breaker
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// State is the state of a Breaker.
+type State int
+
+const (
+	// Closed lets all requests through and records their outcome.
+	Closed State = iota
+	// Open rejects all requests until the open timeout elapses.
+	Open
+	// HalfOpen lets a limited number of probe requests through.
+	HalfOpen
+)
+
+// String returns the name of the state.
+func (s State) String() string {
+	switch s {
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Breaker is a circuit breaker for routes that depend on flaky upstreams.
+//
+// It trips open when the error rate in the current window exceeds the
+// threshold, rejects requests while open, and closes again once enough
+// probe requests succeed in half-open state.
+type Breaker struct {
+	mu sync.Mutex
+
+	errorRate   float64
+	minRequests int
+	window      time.Duration
+	latency     time.Duration
+	openTimeout time.Duration
+	probes      int
+	fallback    string
+
+	state     State
+	total     int
+	failures  int
+	windowAt  time.Time
+	openedAt  time.Time
+	inflight  int
+	successes int
+	// generation is incremented on each state change, so the outcomes of the
+	// requests admitted in a previous state are ignored
+	generation int
+}
+
+// ticket is the state and generation of the breaker a request is admitted in.
+type ticket struct {
+	state      State
+	generation int
+}
+
+// New creates a Breaker with the provided options.
+//
+// By default, the breaker trips at a 50% error rate over at least 10 requests
+// within a 10 seconds window, stays open for 30 seconds, and requires 1
+// successful probe to close.
+func New(opts ...Option) *Breaker {
+	b := &Breaker{
+		errorRate:   0.5,
+		minRequests: 10,
+		window:      10 * time.Second,
+		openTimeout: 30 * time.Second,
+		probes:      1,
+	}
+
+	for _, o := range opts {
+		o(b)
+	}
+
+	if b.probes < 1 {
+		b.probes = 1
+	}
+
+	return b
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.current(time.Now())
+}
+
+// Middleware wraps a HandleFunc with the breaker.
+//
+// A request fails if the handler returns an error other than xun.ErrCancelled,
+// or takes longer than the latency threshold.
+func (b *Breaker) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		t, ok := b.allow()
+		if !ok {
+			return b.reject(c)
+		}
+
+		start := time.Now()
+		failed := true // a panic of next fails the request
+		defer func() {
+			if b.latency > 0 && time.Since(start) > b.latency {
+				failed = true
+			}
+			b.record(t, failed)
+		}()
+
+		err := next(c)
+		failed = err != nil && !errors.Is(err, xun.ErrCancelled)
+
+		return err
+	}
+}
+
+func (b *Breaker) reject(c *xun.Context) error {
+	c.WriteHeader("Retry-After", "1")
+	c.WriteStatus(http.StatusServiceUnavailable)
+
+	if b.fallback == "" {
+		return xun.ErrCancelled
+	}
+
+	return c.View(nil, b.fallback)
+}
+
+// current returns the state at now, moving an expired open breaker to half-open.
+func (b *Breaker) current(now time.Time) State {
+	if b.state == Open && now.Sub(b.openedAt) >= b.openTimeout {
+		b.state = HalfOpen
+		b.generation++
+		b.inflight = 0
+		b.successes = 0
+	}
+
+	return b.state
+}
+
+// allow reports whether the request is admitted, and returns the ticket to
+// record its outcome.
+func (b *Breaker) allow() (ticket, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := ticket{state: b.current(time.Now()), generation: b.generation}
+
+	switch t.state {
+	case Open:
+		return t, false
+	case HalfOpen:
+		if b.inflight+b.successes >= b.probes {
+			return t, false
+		}
+		b.inflight++
+		return t, true
+	default:
+		return t, true
+	}
+}
+
+// record records the outcome of the request admitted with the ticket. It is
+// ignored if the state changed after the request was admitted.
+func (b *Breaker) record(t ticket, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+
+	if t.generation != b.generation || t.state != b.state {
+		return
+	}
+
+	switch b.state {
+	case HalfOpen:
+		b.inflight--
+		if failed {
+			b.trip(now)
+			return
+		}
+
+		b.successes++
+		if b.successes >= b.probes {
+			b.state = Closed
+			b.generation++
+			b.reset(now)
+		}
+	case Closed:
+		if now.Sub(b.windowAt) > b.window {
+			b.reset(now)
+		}
+
+		b.total++
+		if failed {
+			b.failures++
+		}
+
+		if b.total >= b.minRequests && float64(b.failures)/float64(b.total) >= b.errorRate {
+			b.trip(now)
+		}
+	}
+}
+
+func (b *Breaker) trip(now time.Time) {
+	b.state = Open
+	b.generation++
+	b.openedAt = now
+	b.reset(now)
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.total = 0
+	b.failures = 0
+	b.windowAt = now
+}
@@ -0,0 +1,144 @@
+package breaker
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestBreaker(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/unavailable.html": {Data: []byte(`<div>unavailable</div>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithFsys(fsys))
+
+	b := New(WithErrorRate(0.5, 2),
+		WithOpenTimeout(100*time.Millisecond),
+		WithFallback("views/unavailable"))
+
+	upstream := errors.New("upstream: connection refused")
+	healthy := false
+
+	upstreams := app.Group("/upstream")
+	upstreams.Use(b.Middleware)
+	upstreams.Get("/", func(c *xun.Context) error {
+		if !healthy {
+			return upstream
+		}
+		return c.View("ok")
+	})
+
+	go app.Start()
+	defer app.Close()
+
+	get := func() (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/upstream/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html, */*")
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	status, _ := get()
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, Closed, b.State())
+
+	status, _ = get()
+	require.Equal(t, http.StatusInternalServerError, status)
+	require.Equal(t, Open, b.State())
+
+	// open breaker renders fallback without calling the handler
+	healthy = true
+	status, body := get()
+	require.Equal(t, http.StatusServiceUnavailable, status)
+	require.Equal(t, `<div>unavailable</div>`, body)
+
+	time.Sleep(150 * time.Millisecond)
+	require.Equal(t, HalfOpen, b.State())
+
+	// successful probe closes the breaker
+	status, _ = get()
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, Closed, b.State())
+}
+
+func TestBreakerHalfOpenFailure(t *testing.T) {
+	b := New(WithErrorRate(1, 1), WithOpenTimeout(10*time.Millisecond), WithLatency(time.Millisecond))
+
+	tk, ok := b.allow()
+	require.True(t, ok)
+	b.record(tk, true)
+	require.Equal(t, Open, b.State())
+	_, ok = b.allow()
+	require.False(t, ok)
+
+	time.Sleep(20 * time.Millisecond)
+
+	// only one probe is allowed in half-open state
+	probe, ok := b.allow()
+	require.True(t, ok)
+	_, ok = b.allow()
+	require.False(t, ok)
+
+	b.record(probe, true)
+	require.Equal(t, Open, b.State())
+}
+
+func TestBreakerStaleRequests(t *testing.T) {
+	b := New(WithErrorRate(1, 1), WithOpenTimeout(10*time.Millisecond))
+
+	// a slow request is admitted while closed
+	slow, ok := b.allow()
+	require.True(t, ok)
+
+	tk, ok := b.allow()
+	require.True(t, ok)
+	b.record(tk, true)
+	require.Equal(t, Open, b.State())
+
+	time.Sleep(20 * time.Millisecond)
+
+	probe, ok := b.allow()
+	require.True(t, ok)
+	require.Equal(t, HalfOpen, b.State())
+
+	// the slow request isn't counted as the probe
+	b.record(slow, false)
+	require.Equal(t, HalfOpen, b.State())
+	require.Equal(t, 1, b.inflight)
+
+	b.record(probe, false)
+	require.Equal(t, Closed, b.State())
+	require.Equal(t, 0, b.inflight)
+}
+
+func TestBreakerPanic(t *testing.T) {
+	b := New(WithErrorRate(1, 1))
+
+	h := b.Middleware(func(c *xun.Context) error {
+		panic("boom")
+	})
+
+	require.Panics(t, func() {
+		h(&xun.Context{}) // nolint: errcheck
+	})
+	require.Equal(t, Open, b.State())
+}
@@ -0,0 +1,55 @@
+package breaker
+
+import "time"
+
+// Option is a function type that takes a pointer to Breaker as an argument.
+// It is used to configure the Breaker with various options.
+type Option func(*Breaker)
+
+// WithErrorRate sets the failure ratio (0-1) that trips the breaker once at
+// least minRequests requests are recorded in the current window.
+func WithErrorRate(rate float64, minRequests int) Option {
+	return func(b *Breaker) {
+		b.errorRate = rate
+		b.minRequests = minRequests
+	}
+}
+
+// WithWindow sets the duration of the window that failures are counted in.
+func WithWindow(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.window = d
+	}
+}
+
+// WithLatency sets the latency threshold. A request taking longer than d is
+// counted as a failure even if it succeeds.
+func WithLatency(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.latency = d
+	}
+}
+
+// WithOpenTimeout sets how long the breaker stays open before it lets probe
+// requests through.
+func WithOpenTimeout(d time.Duration) Option {
+	return func(b *Breaker) {
+		b.openTimeout = d
+	}
+}
+
+// WithProbes sets how many successful probe requests are required in
+// half-open state before the breaker closes again.
+func WithProbes(n int) Option {
+	return func(b *Breaker) {
+		b.probes = n
+	}
+}
+
+// WithFallback sets the name of the viewer (e.g. "components/unavailable")
+// that is rendered with 503 Service Unavailable while the breaker is open.
+func WithFallback(name string) Option {
+	return func(b *Breaker) {
+		b.fallback = name
+	}
+}
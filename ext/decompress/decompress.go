@@ -0,0 +1,156 @@
+package decompress
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/yaitoo/xun"
+)
+
+// DefaultMaxSize is the default limit of a decompressed request body.
+const DefaultMaxSize int64 = 10 << 20 // 10MB
+
+// Option is a function type that takes a pointer to Options as an argument.
+type Option func(*Options)
+
+// Options holds the configuration of the decompress middleware.
+type Options struct {
+	maxSize int64
+}
+
+// WithMaxSize sets the maximum size of a decompressed request body.
+// It protects handlers from zip bombs.
+func WithMaxSize(n int64) Option {
+	return func(o *Options) {
+		o.maxSize = n
+	}
+}
+
+// New returns a middleware that transparently decompresses gzip/deflate
+// request bodies based on the Content-Encoding header, so handlers can bind
+// them with xun.BindJson or xun.BindForm as usual.
+//
+// Requests with an unsupported Content-Encoding are rejected with
+// 415 Unsupported Media Type, malformed bodies with 400 Bad Request, and
+// bodies exceeding the max size with 413 Request Entity Too Large if the
+// handler returns the binding error.
+func New(opts ...Option) xun.Middleware {
+	o := &Options{
+		maxSize: DefaultMaxSize,
+	}
+
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	return func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			req := c.Request()
+
+			encoding := req.Header.Get("Content-Encoding")
+			if encoding == "" || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			encodings := strings.Split(encoding, ",")
+
+			var body io.Reader = req.Body
+			// encodings are listed in the order in which they were applied
+			for i := len(encodings) - 1; i >= 0; i-- {
+				r, err := newReader(strings.ToLower(strings.TrimSpace(encodings[i])), body)
+				if err != nil {
+					if errors.Is(err, errUnsupported) {
+						c.WriteStatus(http.StatusUnsupportedMediaType)
+					} else {
+						c.WriteStatus(http.StatusBadRequest)
+					}
+					return xun.ErrCancelled
+				}
+				body = r
+			}
+
+			lr := &limitedReader{r: body, c: req.Body, n: o.maxSize}
+			req.Body = lr
+			req.Header.Del("Content-Encoding")
+			req.Header.Del("Content-Length")
+			req.ContentLength = -1
+
+			err := next(c)
+
+			// binders don't always preserve the reader's error, check the reader itself.
+			if lr.exceeded {
+				c.WriteStatus(http.StatusRequestEntityTooLarge)
+				return xun.ErrCancelled
+			}
+
+			return err
+		}
+	}
+}
+
+var (
+	// ErrTooLarge is returned by the request body when the decompressed data exceeds the max size.
+	ErrTooLarge = errors.New("decompress: request body too large")
+
+	errUnsupported = errors.New("decompress: unsupported content encoding")
+)
+
+func newReader(encoding string, r io.Reader) (io.Reader, error) {
+	switch encoding {
+	case "", "identity":
+		return r, nil
+	case "gzip", "x-gzip":
+		return gzip.NewReader(r)
+	case "deflate":
+		// RFC 9110 defines deflate as zlib format, but many clients send raw deflate data.
+		br := bufio.NewReader(r)
+		header, err := br.Peek(2)
+		if err == nil && isZlibHeader(header) {
+			return zlib.NewReader(br)
+		}
+		return flate.NewReader(br), nil
+	}
+
+	return nil, errUnsupported
+}
+
+// isZlibHeader checks the CMF and FLG bytes defined in RFC 1950.
+func isZlibHeader(h []byte) bool {
+	return h[0]&0x0f == 8 && (uint16(h[0])<<8|uint16(h[1]))%31 == 0
+}
+
+// limitedReader reads at most n bytes of decompressed data from r, and closes the original body c.
+type limitedReader struct {
+	r        io.Reader
+	c        io.Closer
+	n        int64
+	exceeded bool
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if l.exceeded {
+		return 0, ErrTooLarge
+	}
+
+	if int64(len(p)) > l.n+1 {
+		p = p[:l.n+1]
+	}
+
+	n, err := l.r.Read(p)
+	if int64(n) > l.n {
+		l.exceeded = true
+		return int(l.n), ErrTooLarge
+	}
+	l.n -= int64(n)
+	return n, err
+}
+
+func (l *limitedReader) Close() error {
+	return l.c.Close()
+}
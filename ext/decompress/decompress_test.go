@@ -0,0 +1,142 @@
+package decompress
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+type user struct {
+	Name string `json:"name"`
+}
+
+func TestDecompress(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	app.Use(New(WithMaxSize(64)))
+
+	app.Post("/users", func(c *xun.Context) error {
+		it, err := xun.BindJson[user](c.Request())
+		if err != nil {
+			return err
+		}
+
+		return c.View(it.Data)
+	})
+
+	go app.Start()
+	defer app.Close()
+
+	payload := []byte(`{"name":"xun"}`)
+
+	gz := func(p []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write(p) // nolint: errcheck
+		w.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		status   int
+	}{
+		{
+			name:   "plain",
+			body:   payload,
+			status: http.StatusOK,
+		},
+		{
+			name:     "gzip",
+			encoding: "gzip",
+			body:     gz(payload),
+			status:   http.StatusOK,
+		},
+		{
+			name:     "zlib_deflate",
+			encoding: "deflate",
+			body: func() []byte {
+				var buf bytes.Buffer
+				w := zlib.NewWriter(&buf)
+				w.Write(payload) // nolint: errcheck
+				w.Close()
+				return buf.Bytes()
+			}(),
+			status: http.StatusOK,
+		},
+		{
+			name:     "raw_deflate",
+			encoding: "deflate",
+			body: func() []byte {
+				var buf bytes.Buffer
+				w, _ := flate.NewWriter(&buf, flate.DefaultCompression)
+				w.Write(payload) // nolint: errcheck
+				w.Close()
+				return buf.Bytes()
+			}(),
+			status: http.StatusOK,
+		},
+		{
+			name:     "unsupported",
+			encoding: "br",
+			body:     payload,
+			status:   http.StatusUnsupportedMediaType,
+		},
+		{
+			name:     "malformed",
+			encoding: "gzip",
+			body:     payload,
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "invalid_json",
+			encoding: "gzip",
+			body:     gz([]byte(`{"name":`)),
+			status:   http.StatusBadRequest,
+		},
+		{
+			name:     "too_large",
+			encoding: "gzip",
+			body:     gz([]byte(`{"name":"` + strings.Repeat("x", 128) + `"}`)),
+			status:   http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/users", bytes.NewReader(test.body))
+			require.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+			if test.encoding != "" {
+				req.Header.Set("Content-Encoding", test.encoding)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+			if test.status != http.StatusOK {
+				return
+			}
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.JSONEq(t, string(payload), string(buf))
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package dedup
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/yaitoo/xun"
+)
+
+// Deduplicator detects duplicate in-flight requests, e.g. from aggressive
+// hx-trigger configurations like "keyup" without a delay.
+//
+// By default, the older request is cancelled once a duplicate arrives, and it
+// writes 204 No Content if nothing is written yet, so that htmx doesn't swap it.
+type Deduplicator struct {
+	mu       sync.Mutex
+	key      func(c *xun.Context) string
+	reject   bool
+	inflight map[string]*call
+}
+
+type call struct {
+	cancel     context.CancelFunc
+	superseded bool
+}
+
+// New creates a Deduplicator with the provided options.
+func New(opts ...Option) *Deduplicator {
+	d := &Deduplicator{
+		key:      DefaultKey,
+		inflight: make(map[string]*call),
+	}
+
+	for _, o := range opts {
+		o(d)
+	}
+
+	return d
+}
+
+// DefaultKey returns the key of htmx requests from the same client and
+// element, i.e. the cookies or the IP of the client, the method, the URL path and
+// the HX-Trigger header. It returns an empty key for other requests.
+func DefaultKey(c *xun.Context) string {
+	r := c.Request()
+	if r.Header.Get("HX-Request") != "true" {
+		return ""
+	}
+
+	trigger := r.Header.Get("HX-Trigger")
+	if trigger == "" {
+		return ""
+	}
+
+	client := r.Header.Get("Cookie")
+	if client == "" {
+		client = c.ClientIP()
+	}
+
+	return client + "\n" + r.Method + " " + r.URL.Path + "\n" + trigger
+}
+
+// Middleware deduplicates the in-flight requests of next.
+func (d *Deduplicator) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		key := d.key(c)
+		if key == "" {
+			return next(c)
+		}
+
+		d.mu.Lock()
+		if older, ok := d.inflight[key]; ok {
+			if d.reject {
+				d.mu.Unlock()
+				c.WriteHeader("Retry-After", "1")
+				c.WriteStatus(http.StatusTooManyRequests)
+				return xun.ErrCancelled
+			}
+
+			older.superseded = true
+			older.cancel()
+		}
+
+		ctx, cancel := context.WithCancel(c.Context())
+		cur := &call{cancel: cancel}
+		d.inflight[key] = cur
+		d.mu.Unlock()
+
+		defer func() {
+			d.mu.Lock()
+			if d.inflight[key] == cur {
+				delete(d.inflight, key)
+			}
+			d.mu.Unlock()
+			cancel()
+		}()
+
+		c.WithContext(ctx)
+		err := next(c)
+
+		d.mu.Lock()
+		superseded := cur.superseded
+		d.mu.Unlock()
+
+		if superseded {
+			if c.StatusCode() == 0 {
+				c.WriteStatus(http.StatusNoContent)
+			}
+			return xun.ErrCancelled
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,133 @@
+package dedup
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestDeduplicator(t *testing.T) {
+	request := func(t *testing.T, url, trigger string) *http.Request {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Trigger", trigger)
+		return req
+	}
+
+	setup := func(t *testing.T, opts ...Option) (*httptest.Server, chan struct{}) {
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+
+		app := xun.New(xun.WithMux(mux))
+		t.Cleanup(app.Close)
+
+		app.Use(New(opts...).Middleware)
+
+		started := make(chan struct{}, 3)
+		app.Get("/search", func(c *xun.Context) error {
+			if c.Request().URL.Query().Get("slow") != "" {
+				started <- struct{}{}
+				select {
+				case <-c.Context().Done():
+					return c.Context().Err()
+				case <-time.After(500 * time.Millisecond):
+				}
+			}
+			return c.View("found")
+		})
+
+		return srv, started
+	}
+
+	client := &http.Client{}
+
+	t.Run("cancel_older", func(t *testing.T) {
+		srv, started := setup(t)
+
+		first := make(chan *http.Response, 1)
+		go func() {
+			resp, err := client.Do(request(t, srv.URL+"/search?slow=1", "q"))
+			if err == nil {
+				resp.Body.Close()
+			}
+			first <- resp
+		}()
+
+		<-started
+
+		resp, err := client.Do(request(t, srv.URL+"/search", "q"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		older := <-first
+		require.NotNil(t, older)
+		require.Equal(t, http.StatusNoContent, older.StatusCode)
+	})
+
+	t.Run("reject_newer", func(t *testing.T) {
+		srv, started := setup(t, WithReject())
+
+		first := make(chan *http.Response, 1)
+		go func() {
+			resp, err := client.Do(request(t, srv.URL+"/search?slow=1", "q"))
+			if err == nil {
+				resp.Body.Close()
+			}
+			first <- resp
+		}()
+
+		<-started
+
+		resp, err := client.Do(request(t, srv.URL+"/search", "q"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+		// another element is not a duplicate
+		resp, err = client.Do(request(t, srv.URL+"/search", "other"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		older := <-first
+		require.NotNil(t, older)
+		require.Equal(t, http.StatusOK, older.StatusCode)
+	})
+
+	t.Run("custom_key", func(t *testing.T) {
+		srv, started := setup(t, WithKey(func(c *xun.Context) string {
+			return c.Request().Header.Get("X-Session")
+		}), WithReject())
+
+		resp, err := client.Get(srv.URL + "/search")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		go func() {
+			req := request(t, srv.URL+"/search?slow=1", "")
+			req.Header.Set("X-Session", "abc")
+			resp, err := client.Do(req)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+
+		<-started
+
+		req := request(t, srv.URL+"/search", "")
+		req.Header.Set("X-Session", "abc")
+		resp, err = client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	})
+}
@@ -0,0 +1,23 @@
+package dedup
+
+import "github.com/yaitoo/xun"
+
+// Option is a function type that takes a pointer to Deduplicator as an argument.
+// It is used to configure the Deduplicator with various options.
+type Option func(*Deduplicator)
+
+// WithKey sets the function that returns the key of duplicate requests. Requests
+// with an empty key are never deduplicated. See DefaultKey.
+func WithKey(fn func(c *xun.Context) string) Option {
+	return func(d *Deduplicator) {
+		d.key = fn
+	}
+}
+
+// WithReject rejects the newer duplicate request with 429 Too Many Requests
+// instead of cancelling the older one.
+func WithReject() Option {
+	return func(d *Deduplicator) {
+		d.reject = true
+	}
+}
@@ -0,0 +1,131 @@
+package highlight
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/alecthomas/chroma/v2"
+	chtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yaitoo/xun"
+)
+
+var (
+	formatter = chtml.New(chtml.WithClasses(true), chtml.PreventSurroundingPre(true))
+
+	themes sync.Map // name => []byte
+)
+
+// Themes returns the names of the Chroma styles, e.g. "github" and "monokai".
+func Themes() []string {
+	return styles.Names()
+}
+
+// Supported reports whether the language, e.g. "go" or "js", has a Chroma lexer.
+func Supported(lang string) bool {
+	return lang != "" && lexers.Get(lang) != nil
+}
+
+// Highlight renders the code as `<pre class="chroma"><code class="language-{lang}">`
+// with the token spans of Chroma, e.g. `<span class="kd">func</span>`. Code of
+// unsupported languages is escaped.
+func Highlight(lang, code string) template.HTML {
+	var buf bytes.Buffer
+	buf.Grow(len(code) * 2)
+
+	buf.WriteString(`<pre class="chroma"><code`)
+	if lang != "" {
+		buf.WriteString(` class="language-` + html.EscapeString(lang) + `"`)
+	}
+	buf.WriteString(">")
+
+	if !format(&buf, lang, code) {
+		buf.WriteString(html.EscapeString(code))
+	}
+
+	buf.WriteString("</code></pre>")
+	return template.HTML(buf.String()) // nolint: gosec
+}
+
+// format writes the tokens of the code to buf, and reports whether it succeeded.
+func format(buf *bytes.Buffer, lang, code string) bool {
+	if !Supported(lang) {
+		return false
+	}
+
+	it, err := chroma.Coalesce(lexers.Get(lang)).Tokenise(nil, code)
+	if err != nil {
+		return false
+	}
+
+	n := buf.Len()
+	if err = formatter.Format(buf, styles.Fallback, it); err != nil {
+		buf.Truncate(n)
+		return false
+	}
+	return true
+}
+
+// FuncMap returns the template function `highlight`. It should be added to
+// xun.FuncMap before the App is created.
+//
+//	{{ .Code | highlight "go" }}
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"highlight": Highlight,
+	}
+}
+
+type router interface {
+	Get(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+}
+
+// Register registers the theme stylesheets `GET {prefix}/{theme}.css` on the
+// router, e.g. Register(app, "/assets/highlight") for `/assets/highlight/github.css`.
+func Register(r router, prefix string, opts ...xun.RoutingOption) {
+	r.Get(strings.TrimSuffix(prefix, "/")+"/{theme}", serveTheme, opts...)
+}
+
+func serveTheme(c *xun.Context) error {
+	name, ok := strings.CutSuffix(c.Request().PathValue("theme"), ".css")
+	if !ok {
+		c.WriteStatus(http.StatusNotFound)
+		return xun.ErrCancelled
+	}
+
+	buf, ok := theme(name)
+	if !ok {
+		c.WriteStatus(http.StatusNotFound)
+		return xun.ErrCancelled
+	}
+
+	c.WriteHeader("Content-Type", "text/css; charset=utf-8")
+	c.WriteHeader("Cache-Control", "public, max-age=86400")
+	_, err := c.Writer().Write(buf)
+	return err
+}
+
+// theme returns the stylesheet of the Chroma style, and caches it.
+func theme(name string) ([]byte, bool) {
+	if v, ok := themes.Load(name); ok {
+		return v.([]byte), true
+	}
+
+	style, ok := styles.Registry[name]
+	if !ok {
+		return nil, false
+	}
+
+	var buf bytes.Buffer
+	if err := formatter.WriteCSS(&buf, style); err != nil {
+		return nil, false
+	}
+
+	v, _ := themes.LoadOrStore(name, buf.Bytes())
+	return v.([]byte), true
+}
@@ -0,0 +1,80 @@
+package highlight
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestHighlight(t *testing.T) {
+	tests := []struct {
+		lang string
+		code string
+		want []string
+	}{
+		{
+			lang: "go",
+			code: "func main() {\n\t// say hi\n\tfmt.Println(\"<hi>\", 42, nil)\n}",
+			want: []string{`<span class="kd">func</span>`, `<span class="c1">// say hi</span>`, `<span class="s">&#34;&lt;hi&gt;&#34;</span>`,
+				`<span class="mi">42</span>`, `<span class="kc">nil</span>`},
+		},
+		{
+			lang: "python",
+			code: `def f(x): return len(x) # tail`,
+			want: []string{`<span class="k">def</span>`, `<span class="k">return</span>`, `<span class="nb">len</span>`, `<span class="c1"># tail</span>`},
+		},
+		{
+			lang: "JS",
+			code: "const a = `x\ny` /* c */ + 1",
+			want: []string{`<span class="kr">const</span>`, `<span class="cm">/* c */</span>`, `<span class="mi">1</span>`},
+		},
+	}
+
+	for _, test := range tests {
+		got := string(Highlight(test.lang, test.code))
+		require.True(t, strings.HasPrefix(got, `<pre class="chroma"><code class="language-`+test.lang+`">`), got)
+		require.True(t, strings.HasSuffix(got, `</code></pre>`), got)
+		for _, want := range test.want {
+			require.Contains(t, got, want, test.lang)
+		}
+	}
+
+	require.Equal(t, `<pre class="chroma"><code class="language-nope">&lt;+&gt;</code></pre>`, string(Highlight("nope", "<+>")))
+	require.Equal(t, `<pre class="chroma"><code>a &amp; b</code></pre>`, string(Highlight("", "a & b")))
+	require.True(t, Supported("Go"))
+	require.False(t, Supported("nope"))
+	require.Contains(t, Themes(), "github")
+	require.Contains(t, Themes(), "monokai")
+}
+
+func TestRegister(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	Register(app, "/assets/highlight/")
+
+	resp, err := http.Get(srv.URL + "/assets/highlight/github.css")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/css; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Contains(t, string(buf), ".chroma .k ")
+
+	for _, path := range []string{"/assets/highlight/nope.css", "/assets/highlight/github"} {
+		resp, err = http.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotFound, resp.StatusCode, path)
+	}
+}
@@ -0,0 +1,169 @@
+package htmx
+
+import (
+	"bytes"
+	"html"
+	"net/http"
+	"strings"
+
+	"github.com/yaitoo/xun"
+	nethtml "golang.org/x/net/html"
+)
+
+// Boost trims the full page responses of boosted navigations, e.g. links
+// with hx-boost, to the content of the target element, so the layout isn't
+// sent and re-rendered on every navigation. The <title> and the <meta> tags
+// of the head are kept, and the metas are swapped out of band, so the tab
+// title and page metadata stay correct.
+//
+// The target is the id of the element that contains the content, e.g.
+// "main", and the response is retargeted to it. If it is empty, the content
+// of <body> is used. Responses of history restore requests, non-HTML
+// responses and pages without the target element are sent as they are.
+func Boost(target string) xun.Middleware {
+	return func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			r := c.Request()
+			if r.Method != http.MethodGet || r.Header.Get(HxBoosted) != "true" || r.Header.Get(HxHistoryRestoreRequest) == "true" {
+				return next(c)
+			}
+
+			c.WriteHeader("Vary", HxBoosted)
+			c.Buffered(func(b *xun.BufferedResponse) {
+				if b.StatusCode() != http.StatusOK || !strings.HasPrefix(b.Header().Get("Content-Type"), "text/html") {
+					return
+				}
+
+				body, ok := trimPage(b.Body(), target)
+				if !ok {
+					return
+				}
+
+				if target != "" {
+					b.Header().Set(HxRetarget, "#"+target)
+					b.Header().Set(HxReswap, "innerHTML")
+				}
+				b.SetBody(body)
+			})
+
+			return next(c)
+		}
+	}
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// trimPage returns the <title>, the <meta> tags of the head as out of band
+// swaps, and the inner HTML of the target element of the page. The markup of
+// the page is kept as it is. ok is false if the target element isn't found.
+func trimPage(page []byte, target string) (body []byte, ok bool) { // skipcq: GO-R1005
+	z := nethtml.NewTokenizer(bytes.NewReader(page))
+
+	var (
+		title, metas       bytes.Buffer
+		inHead             bool
+		titleStart         = -1
+		contentStart       = -1
+		contentEnd         = -1
+		contentTag         string
+		depth, offset, end int
+	)
+
+	for contentEnd < 0 {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+
+		start := offset
+		offset += len(z.Raw())
+		end = offset
+
+		switch tt { // skipcq: CRT-A0014
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			tag := z.Token()
+			name := tag.Data
+
+			if contentStart >= 0 {
+				if name == contentTag && tt == nethtml.StartTagToken {
+					depth++
+				}
+				continue
+			}
+
+			switch name {
+			case "head":
+				inHead = true
+			case "title":
+				titleStart = start
+			case "meta":
+				if inHead {
+					writeMeta(&metas, tag)
+				}
+			}
+
+			if target == "" && name == "body" || target != "" && attr(tag, "id") == target && !voidElements[name] && tt == nethtml.StartTagToken {
+				contentStart, contentTag, depth = offset, name, 1
+			}
+
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			switch {
+			case contentStart >= 0:
+				if string(name) == contentTag {
+					depth--
+					if depth == 0 {
+						contentEnd = start
+					}
+				}
+			case string(name) == "title" && titleStart >= 0:
+				title.Write(page[titleStart:end])
+				titleStart = -1
+			case string(name) == "head":
+				inHead = false
+			}
+		}
+	}
+
+	if contentStart < 0 {
+		return nil, false
+	}
+
+	if contentEnd < 0 { // unclosed
+		contentEnd = len(page)
+	}
+
+	buf := bytes.NewBuffer(make([]byte, 0, title.Len()+metas.Len()+contentEnd-contentStart))
+	buf.Write(title.Bytes())
+	buf.Write(metas.Bytes())
+	buf.Write(page[contentStart:contentEnd])
+
+	return buf.Bytes(), true
+}
+
+// writeMeta writes the meta tag with an out of band swap of the meta with the
+// same name or property, e.g. <meta name="description" content="..." hx-swap-oob="outerHTML:meta[name='description']">.
+func writeMeta(buf *bytes.Buffer, tag nethtml.Token) {
+	for _, key := range []string{"name", "property"} {
+		v := attr(tag, key)
+		if v == "" || strings.ContainsAny(v, `'"\`) {
+			continue
+		}
+
+		buf.WriteString(`<meta ` + key + `="` + html.EscapeString(v) + `" content="` + html.EscapeString(attr(tag, "content")) +
+			`" hx-swap-oob="outerHTML:meta[` + key + `='` + html.EscapeString(v) + `']">`)
+		return
+	}
+}
+
+func attr(tag nethtml.Token, key string) string {
+	for _, a := range tag.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package htmx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestBoost(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html": {Data: []byte(`<!DOCTYPE html><html><head><title>{{ block "title" . }}Xun{{ end }}</title>
+<meta charset="utf-8"><meta name="description" content="{{ block "description" . }}{{ end }}"><script src="/app.js"></script></head>
+<body><nav><div>menu</div></nav><main id="main">{{ block "content" . }}{{ end }}</main></body></html>`)},
+		"pages/users.html": {Data: []byte(`<!--layout:main-->
+{{ define "title" }}Users{{ end }}{{ define "description" }}All &amp; users{{ end }}{{ define "content" }}<div><div>alice</div></div>{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithFsys(fsys))
+	defer app.Close()
+
+	app.Use(Boost("main"))
+	app.Start()
+
+	get := func(headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/users", nil)
+		require.NoError(t, err)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(buf)
+	}
+
+	resp, body := get()
+	require.Contains(t, body, "<nav>")
+	require.Empty(t, resp.Header.Get(HxRetarget))
+
+	resp, body = get(HxRequest, "true", HxBoosted, "true")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "#main", resp.Header.Get(HxRetarget))
+	require.Equal(t, "innerHTML", resp.Header.Get(HxReswap))
+	require.Equal(t, `<title>Users</title>`+
+		`<meta name="description" content="All &amp; users" hx-swap-oob="outerHTML:meta[name='description']">`+
+		`<div><div>alice</div></div>`, body)
+
+	// full page for history restoration
+	_, body = get(HxRequest, "true", HxBoosted, "true", HxHistoryRestoreRequest, "true")
+	require.Contains(t, body, "<nav>")
+}
+
+func TestTrimPage(t *testing.T) {
+	page := []byte(`<html><head><title>Home</title></head><body class="a"><p>hi<br>there</body></html>`)
+
+	body, ok := trimPage(page, "")
+	require.True(t, ok)
+	require.Equal(t, `<title>Home</title><p>hi<br>there`, string(body))
+
+	_, ok = trimPage(page, "main")
+	require.False(t, ok)
+
+	body, ok = trimPage([]byte(`<div id="list"><div>1</div><div>2</div></div><div>after</div>`), "list")
+	require.True(t, ok)
+	require.Equal(t, `<div>1</div><div>2</div>`, string(body))
+}
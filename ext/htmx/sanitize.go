@@ -0,0 +1,196 @@
+package htmx
+
+import (
+	"bytes"
+	"strings"
+
+	"github.com/yaitoo/xun"
+	nethtml "golang.org/x/net/html"
+)
+
+// Attributes is the default allow list of htmx attributes kept by Sanitize.
+var Attributes = []string{
+	"hx-get", "hx-post", "hx-put", "hx-patch", "hx-delete",
+	"hx-trigger", "hx-target", "hx-swap", "hx-swap-oob", "hx-select", "hx-select-oob",
+	"hx-boost", "hx-push-url", "hx-replace-url", "hx-confirm", "hx-prompt",
+	"hx-disable", "hx-disabled-elt", "hx-disinherit", "hx-inherit", "hx-encoding", "hx-ext",
+	"hx-headers", "hx-history", "hx-history-elt", "hx-include", "hx-indicator",
+	"hx-params", "hx-preserve", "hx-request", "hx-sync", "hx-validate", "hx-vals",
+}
+
+// SanitizeOption configures the Sanitize middleware.
+type SanitizeOption func(s *sanitizer)
+
+// WithAttributes replaces the allow list of htmx attributes, e.g. to allow the
+// attributes of htmx extensions.
+func WithAttributes(attrs ...string) SanitizeOption {
+	return func(s *sanitizer) {
+		s.allowed = make(map[string]bool, len(attrs))
+		for _, it := range attrs {
+			s.allowed[strings.ToLower(it)] = true
+		}
+	}
+}
+
+// WithNonce sets the function that returns the CSP nonce of the request.
+// Elements with the nonce attribute of it are trusted, and keep their inline
+// handlers, e.g. hx-on:click, and script expressions.
+func WithNonce(nonce func(c *xun.Context) string) SanitizeOption {
+	return func(s *sanitizer) {
+		s.nonce = nonce
+	}
+}
+
+type sanitizer struct {
+	allowed map[string]bool
+	nonce   func(c *xun.Context) string
+}
+
+// Sanitize hardens rendered HTML against injected markup, e.g. user-provided
+// data rendered by text/template or template.HTML. It is applied to text/html
+// responses:
+//
+//   - inline event handlers, e.g. onclick, are stripped;
+//   - hx-on and hx-vars handlers, hx-trigger filters, and js: expressions of
+//     hx-vals/hx-headers/hx-request are stripped, unless the element is trusted
+//     by WithNonce;
+//   - data-hx-* attributes are normalized to hx-*, and the ones not in the
+//     allow list are stripped;
+//   - javascript: URLs of hx-get/hx-post/hx-put/hx-patch/hx-delete and of
+//     href/xlink:href/src/action, e.g. of SVG <use> and <a>, are stripped.
+func Sanitize(opts ...SanitizeOption) xun.Middleware {
+	s := &sanitizer{}
+	WithAttributes(Attributes...)(s)
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	return func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			c.Buffered(func(b *xun.BufferedResponse) {
+				if !strings.HasPrefix(b.Header().Get("Content-Type"), "text/html") {
+					return
+				}
+
+				var nonce string
+				if s.nonce != nil {
+					nonce = s.nonce(c)
+				}
+
+				if body, changed := s.sanitize(b.Body(), nonce); changed {
+					b.SetBody(body)
+				}
+			})
+
+			return next(c)
+		}
+	}
+}
+
+// sanitize rewrites the tags with disallowed attributes. Other markup is kept
+// as it is. changed is false if nothing is stripped.
+func (s *sanitizer) sanitize(page []byte, nonce string) (body []byte, changed bool) {
+	z := nethtml.NewTokenizer(bytes.NewReader(page))
+
+	var buf bytes.Buffer
+	offset, last := 0, 0
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+
+		start := offset
+		offset += len(z.Raw())
+
+		if tt != nethtml.StartTagToken && tt != nethtml.SelfClosingTagToken {
+			continue
+		}
+
+		tag := z.Token()
+		if !s.sanitizeTag(&tag, nonce) {
+			continue
+		}
+
+		buf.Write(page[last:start])
+		buf.WriteString(tag.String())
+		last = offset
+	}
+
+	if last == 0 {
+		return page, false
+	}
+
+	buf.Write(page[last:])
+	return buf.Bytes(), true
+}
+
+// sanitizeTag strips the disallowed attributes of the tag. It reports whether
+// the tag is changed.
+func (s *sanitizer) sanitizeTag(tag *nethtml.Token, nonce string) bool {
+	trusted := nonce != "" && attr(*tag, "nonce") == nonce
+
+	changed := false
+	attrs := tag.Attr[:0]
+
+	for _, a := range tag.Attr {
+		name := a.Key
+		if strings.HasPrefix(name, "data-hx-") {
+			name = name[5:]
+		}
+
+		keep := true
+		switch {
+		case strings.HasPrefix(name, "on"):
+			keep = false
+		case strings.HasPrefix(name, "hx-on") || name == "hx-vars":
+			keep = trusted
+		case strings.HasPrefix(name, "hx-"):
+			keep = s.allowed[name] && (trusted || safeValue(name, a.Val))
+		case name == "href" || name == "xlink:href" || name == "src" || name == "action" || name == "formaction":
+			keep = !isScriptURL(a.Val)
+		}
+
+		if !keep {
+			changed = true
+			continue
+		}
+
+		if name != a.Key {
+			a.Key = name
+			changed = true
+		}
+		attrs = append(attrs, a)
+	}
+
+	tag.Attr = attrs
+	return changed
+}
+
+// safeValue reports whether the value of the htmx attribute doesn't evaluate
+// scripts.
+func safeValue(name, value string) bool {
+	switch name {
+	case "hx-get", "hx-post", "hx-put", "hx-patch", "hx-delete":
+		return !isScriptURL(value)
+	case "hx-trigger":
+		return !strings.Contains(value, "[")
+	case "hx-vals", "hx-headers", "hx-request":
+		v := strings.ToLower(strings.TrimSpace(value))
+		return !strings.HasPrefix(v, "js:") && !strings.HasPrefix(v, "javascript:")
+	}
+	return true
+}
+
+func isScriptURL(value string) bool {
+	v := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(value))
+
+	return strings.HasPrefix(v, "javascript:") || strings.HasPrefix(v, "vbscript:") || strings.HasPrefix(v, "data:text/html")
+}
@@ -0,0 +1,91 @@
+package htmx
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestSanitize(t *testing.T) {
+	s := &sanitizer{}
+	WithAttributes(Attributes...)(s)
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "unchanged",
+			html: `<div hx-get="/users" hx-target="#list"><b>hi</b><script>var a = "<p onclick=x>"</script></div>`,
+			want: `<div hx-get="/users" hx-target="#list"><b>hi</b><script>var a = "<p onclick=x>"</script></div>`,
+		},
+		{
+			name: "event handlers",
+			html: `<img src="a.png" onerror="alert(1)"><a href="/" OnClick="alert(1)">home</a>`,
+			want: `<img src="a.png"><a href="/">home</a>`,
+		},
+		{
+			name: "hx-on",
+			html: `<button hx-on:click="alert(1)" hx-on::after-request="x()" hx-post="/save">save</button>`,
+			want: `<button hx-post="/save">save</button>`,
+		},
+		{
+			name: "script expressions",
+			html: `<div hx-trigger="click[alert(1)]" hx-vals='js:{a: alert(1)}' hx-vars="a:alert(1)" hx-get="javascript:alert(1)">x</div>`,
+			want: `<div>x</div>`,
+		},
+		{
+			name: "normalize and allow list",
+			html: `<div data-hx-get="/users" hx-unknown="1" data-id="1">x</div>`,
+			want: `<div hx-get="/users" data-id="1">x</div>`,
+		},
+		{
+			name: "javascript urls",
+			html: `<a href=" java	script:alert(1)">x</a><form action="/login"></form>`,
+			want: `<a>x</a><form action="/login"></form>`,
+		},
+		{
+			name: "svg javascript urls",
+			html: `<svg><a xlink:href="javascript:alert(1)"><text>x</text></a><use href="JavaScript:alert(1)"/><use xlink:href="#icon"/></svg>`,
+			want: `<svg><a><text>x</text></a><use/><use xlink:href="#icon"/></svg>`,
+		},
+	}
+
+	for _, test := range tests {
+		body, changed := s.sanitize([]byte(test.html), "")
+		require.Equal(t, test.want, string(body), test.name)
+		require.Equal(t, test.html != test.want, changed, test.name)
+	}
+}
+
+func TestSanitizeMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	app.Use(Sanitize(WithNonce(func(c *xun.Context) string {
+		return "n0nce"
+	})))
+
+	app.Get("/", func(c *xun.Context) error {
+		c.WriteHeader("Content-Type", "text/html; charset=utf-8")
+		_, err := c.Writer().Write([]byte(`<button nonce="n0nce" hx-on:click="save()">save</button><p hx-on:click="steal()" nonce="guess">` + c.Request().URL.Query().Get("name") + `</p>`))
+		return err
+	})
+
+	resp, err := http.Get(srv.URL + "/?name=xun")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.Equal(t, `<button nonce="n0nce" hx-on:click="save()">save</button><p nonce="guess">xun</p>`, string(buf))
+}
@@ -0,0 +1,92 @@
+package htmx
+
+import (
+	"html/template"
+	"strings"
+
+	"github.com/yaitoo/xun"
+)
+
+// FocusEvent is the event triggered by Focus after the swap is settled. Pages
+// should focus the element of its target, e.g.
+//
+//	document.body.addEventListener("xun:focus", e => document.querySelector(e.detail.target)?.focus())
+const FocusEvent = "xun:focus"
+
+// Reswap overrides how the response is swapped, e.g. Reswap(c, "outerHTML", "scroll:top").
+// It replaces the modifiers added by Scroll, Show and FocusScroll.
+func Reswap(c *xun.Context, style string, modifiers ...string) {
+	c.WriteHeader(HxReswap, strings.TrimSpace(style+" "+strings.Join(modifiers, " ")))
+}
+
+// Scroll scrolls the target element, or the element of the selector, to the
+// "top" or "bottom" after the swap, e.g. Scroll(c, "bottom", "#messages").
+func Scroll(c *xun.Context, position string, selector ...string) {
+	addReswap(c, "scroll", position, selector)
+}
+
+// Show scrolls the page to show the top or bottom of the target element, or
+// the element of the selector, after the swap. Show(c, "none") disables it.
+func Show(c *xun.Context, position string, selector ...string) {
+	addReswap(c, "show", position, selector)
+}
+
+// FocusScroll sets whether the focused element is scrolled into view after
+// the swap.
+func FocusScroll(c *xun.Context, enabled bool) {
+	if enabled {
+		addReswap(c, "focus-scroll", "true", nil)
+	} else {
+		addReswap(c, "focus-scroll", "false", nil)
+	}
+}
+
+// Focus moves the focus to the element of the selector after the swap is
+// settled, e.g. the first invalid field of a form, by triggering FocusEvent.
+// Elements in the swapped content can be marked with the `autofocus` template
+// function instead, which htmx focuses natively.
+func Focus(c *xun.Context, selector string) {
+	c.TriggerAfterSettle(FocusEvent, map[string]string{"target": selector})
+}
+
+// addReswap appends a modifier to the HX-Reswap header.
+func addReswap(c *xun.Context, name, value string, selector []string) {
+	modifier := name + ":" + value
+	if len(selector) > 0 && selector[0] != "" {
+		modifier = name + ":" + selector[0] + ":" + value
+	}
+
+	h := c.Writer().Header()
+	if v := h.Get(HxReswap); v != "" {
+		modifier = v + " " + modifier
+	}
+	h.Set(HxReswap, modifier)
+}
+
+// FuncMap returns the template function `autofocus`. It should be added to
+// xun.FuncMap before the App is created.
+//
+// `autofocus` renders the autofocus attribute if no condition is given or any
+// of them is true, so htmx focuses the element once the fragment is swapped in.
+//
+//	<input name="email" {{ autofocus .Errors.Email }}>
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"autofocus": Autofocus,
+	}
+}
+
+// Autofocus returns the autofocus attribute if no condition is given or any of
+// them is true by the template rules, e.g. a non-empty validation error.
+func Autofocus(conditions ...any) template.HTMLAttr {
+	if len(conditions) == 0 {
+		return "autofocus"
+	}
+
+	for _, it := range conditions {
+		if ok, _ := template.IsTrue(it); ok {
+			return "autofocus"
+		}
+	}
+	return ""
+}
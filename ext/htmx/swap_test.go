@@ -0,0 +1,60 @@
+package htmx
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestSwap(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	app.Get("/messages", func(c *xun.Context) error {
+		Scroll(c, "bottom", "#messages")
+		Show(c, "none")
+		FocusScroll(c, true)
+		return c.View(nil)
+	})
+
+	app.Get("/reswap", func(c *xun.Context) error {
+		Scroll(c, "top")
+		Reswap(c, "outerHTML", "scroll:top")
+		return c.View(nil)
+	})
+
+	app.Post("/form", func(c *xun.Context) error {
+		Focus(c, "#email")
+		return c.View(nil)
+	})
+
+	resp, err := http.Get(srv.URL + "/messages")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "scroll:#messages:bottom show:none focus-scroll:true", resp.Header.Get(HxReswap))
+
+	resp, err = http.Get(srv.URL + "/reswap")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "outerHTML scroll:top", resp.Header.Get(HxReswap))
+
+	resp, err = http.Post(srv.URL+"/form", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, `{"xun:focus":{"target":"#email"}}`, resp.Header.Get(HxTriggerAfterSettle))
+}
+
+func TestAutofocus(t *testing.T) {
+	require.Equal(t, template.HTMLAttr("autofocus"), Autofocus())
+	require.Equal(t, template.HTMLAttr(""), Autofocus(false, ""))
+	require.Equal(t, template.HTMLAttr("autofocus"), Autofocus(false, "required"))
+	require.Equal(t, template.HTMLAttr(""), Autofocus(nil))
+}
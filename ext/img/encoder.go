@@ -0,0 +1,53 @@
+package img
+
+import (
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+// Encoder is an interface that encodes an image into a specific format.
+//
+// Only JPEG and PNG are built in, because the standard library doesn't ship
+// WebP or AVIF encoders. Register an Encoder with WithEncoder to serve them
+// to clients that accept them.
+type Encoder interface {
+	// MimeType returns the MIME type of the encoded image, e.g. "image/webp".
+	MimeType() string
+	// Encode writes the image m to w.
+	Encode(w io.Writer, m image.Image) error
+}
+
+// JpegEncoder encodes images as JPEG with the given quality.
+type JpegEncoder struct {
+	Quality int
+}
+
+// MimeType returns "image/jpeg".
+func (*JpegEncoder) MimeType() string {
+	return "image/jpeg"
+}
+
+// Encode writes the image m to w in JPEG format.
+func (e *JpegEncoder) Encode(w io.Writer, m image.Image) error {
+	q := e.Quality
+	if q == 0 {
+		q = jpeg.DefaultQuality
+	}
+	return jpeg.Encode(w, m, &jpeg.Options{Quality: q})
+}
+
+// PngEncoder encodes images as PNG.
+type PngEncoder struct {
+}
+
+// MimeType returns "image/png".
+func (*PngEncoder) MimeType() string {
+	return "image/png"
+}
+
+// Encode writes the image m to w in PNG format.
+func (*PngEncoder) Encode(w io.Writer, m image.Image) error {
+	return png.Encode(w, m)
+}
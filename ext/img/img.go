@@ -0,0 +1,305 @@
+package img
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"image"
+	_ "image/gif"  // register gif decoder
+	_ "image/jpeg" // register jpeg decoder
+	_ "image/png"  // register png decoder
+	"io"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// ErrTooManyPixels is returned if the source image has more pixels than
+// WithMaxPixels allows, so it isn't decoded.
+var ErrTooManyPixels = errors.New("img: too_many_pixels")
+
+// Service is an image service that resizes and crops images from a fs.FS on
+// demand, e.g. `/img/640x480/photos/cat.jpg` or `/img/320x0/photos/cat.jpg`.
+//
+// A zero width or height keeps the aspect ratio of the source image. The
+// output format is negotiated with the Accept header, or set by the `format`
+// query parameter, e.g. `?format=webp`, and resized images are kept in a LRU
+// memory cache.
+type Service struct {
+	fsys   fs.FS
+	root   string
+	prefix string
+
+	fit       Fit
+	sizes     map[string]struct{}
+	maxWidth  int
+	maxHeight int
+	maxPixels int
+	cacheSize int
+	maxAge    time.Duration
+
+	encoders []Encoder
+	jpeg     Encoder
+	png      Encoder
+	cache    *cache
+
+	pictureSizes  string
+	pictureWidths []int
+}
+
+// New creates an image service that loads images from fsys.
+func New(fsys fs.FS, opts ...Option) *Service {
+	s := &Service{
+		fsys:      fsys,
+		root:      "public",
+		prefix:    "/img",
+		fit:       Cover,
+		maxWidth:  4096,
+		maxHeight: 4096,
+		maxPixels: 50_000_000,
+		cacheSize: 256,
+		maxAge:    24 * time.Hour,
+		jpeg:      &JpegEncoder{},
+		png:       &PngEncoder{},
+
+		pictureSizes:  "100vw",
+		pictureWidths: []int{320, 640, 1024, 1920},
+	}
+
+	for _, o := range opts {
+		o(s)
+	}
+
+	s.prefix = strings.TrimSuffix(s.prefix, "/")
+	s.cache = newCache(s.cacheSize)
+
+	return s
+}
+
+type router interface {
+	Get(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+}
+
+// Register registers the image endpoint `GET {prefix}/{size}/{path...}` on the router.
+func (s *Service) Register(r router, opts ...xun.RoutingOption) {
+	r.Get(s.prefix+"/{size}/{path...}", s.Handle, opts...)
+}
+
+// URL returns the URL of the image resized to width x height.
+func (s *Service) URL(name string, width, height int) string {
+	return s.prefix + "/" + strconv.Itoa(width) + "x" + strconv.Itoa(height) + "/" + strings.TrimPrefix(name, "/")
+}
+
+// Handle serves the resized image. It expects `size` and `path` path values.
+//
+// It responds 400 Bad Request for an invalid or disallowed size, 404 Not Found
+// if the image doesn't exist, 415 Unsupported Media Type if the file can't be
+// decoded as an image, and 422 Unprocessable Entity if the image has more
+// pixels than WithMaxPixels allows.
+func (s *Service) Handle(c *xun.Context) error {
+	req := c.Request()
+
+	size := req.PathValue("size")
+	w, h, ok := s.parseSize(size)
+	if !ok {
+		c.WriteStatus(http.StatusBadRequest)
+		return xun.ErrCancelled
+	}
+
+	fit := s.fit
+	if f := Fit(req.URL.Query().Get("fit")); f == Cover || f == Contain {
+		fit = f
+	}
+
+	// the path is validated before it's joined, because path.Join cleans ".."
+	// segments that escape the root, and it shouldn't rely on the ServeMux.
+	p := req.PathValue("path")
+	if !fs.ValidPath(p) {
+		c.WriteStatus(http.StatusBadRequest)
+		return xun.ErrCancelled
+	}
+	name := path.Join(s.root, p)
+
+	fi, err := fs.Stat(s.fsys, name)
+	if err != nil || fi.IsDir() {
+		c.WriteStatus(http.StatusNotFound)
+		return xun.ErrCancelled
+	}
+
+	enc := s.negotiate(req.Header.Get("Accept"))
+	if f := req.URL.Query().Get("format"); f != "" {
+		if enc = s.encoder(f); enc == nil {
+			c.WriteStatus(http.StatusBadRequest)
+			return xun.ErrCancelled
+		}
+	}
+
+	e, err := s.load(name, fi.ModTime(), w, h, fit, enc)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			c.WriteStatus(http.StatusUnsupportedMediaType)
+			return xun.ErrCancelled
+		}
+		if errors.Is(err, ErrTooManyPixels) {
+			c.WriteStatus(http.StatusUnprocessableEntity)
+			return xun.ErrCancelled
+		}
+		return err
+	}
+
+	header := c.Writer().Header()
+	header.Set("Content-Type", e.mimeType)
+	header.Set("Cache-Control", "public, max-age="+strconv.Itoa(int(s.maxAge/time.Second)))
+	header.Set("ETag", e.etag)
+	header.Add("Vary", "Accept")
+
+	http.ServeContent(c.Writer(), req, "", e.modTime, bytes.NewReader(e.data))
+	return nil
+}
+
+// load returns the resized image from the cache, or resizes and caches it.
+// The image is encoded by preferred, or in the format of the source image if it is nil.
+func (s *Service) load(name string, modTime time.Time, w, h int, fit Fit, preferred Encoder) (*entry, error) {
+	key := name + "|" + strconv.Itoa(w) + "x" + strconv.Itoa(h) + "|" + string(fit) + "|"
+	if preferred != nil {
+		if e, ok := s.cache.Get(key + preferred.MimeType()); ok && e.modTime.Equal(modTime) {
+			return e, nil
+		}
+	}
+
+	f, err := s.fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// the dimensions are checked before the pixels are allocated, e.g. for
+	// decompression bombs, and the header read by DecodeConfig is decoded again
+	var head bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(f, &head))
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > s.maxPixels/cfg.Height {
+		return nil, ErrTooManyPixels
+	}
+
+	src, format, err := image.Decode(io.MultiReader(&head, f))
+	if err != nil {
+		return nil, err
+	}
+
+	enc := preferred
+	if enc == nil {
+		enc = s.png
+		if format == "jpeg" {
+			enc = s.jpeg
+		}
+
+		if e, ok := s.cache.Get(key + enc.MimeType()); ok && e.modTime.Equal(modTime) {
+			return e, nil
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := enc.Encode(&buf, transform(src, w, h, fit)); err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+
+	e := &entry{
+		key:      key + enc.MimeType(),
+		data:     buf.Bytes(),
+		mimeType: enc.MimeType(),
+		etag:     `"` + hex.EncodeToString(sum[:8]) + `"`,
+		modTime:  modTime,
+	}
+
+	s.cache.Set(e)
+
+	return e, nil
+}
+
+// negotiate returns the first custom encoder whose MIME type is accepted explicitly by the client.
+func (s *Service) negotiate(accept string) Encoder {
+	if accept == "" {
+		return nil
+	}
+
+	for _, e := range s.encoders {
+		if strings.Contains(accept, e.MimeType()) {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// encoder returns the encoder of the format, e.g. "webp" for "image/webp", or nil if it is not supported.
+func (s *Service) encoder(format string) Encoder {
+	mimeType := "image/" + strings.ToLower(format)
+	for _, e := range s.encoders {
+		if e.MimeType() == mimeType {
+			return e
+		}
+	}
+
+	for _, e := range []Encoder{s.jpeg, s.png} {
+		if e.MimeType() == mimeType {
+			return e
+		}
+	}
+
+	return nil
+}
+
+// parseSize parses a size in `{width}x{height}` format.
+func (s *Service) parseSize(size string) (int, int, bool) {
+	if s.sizes != nil {
+		if _, ok := s.sizes[size]; !ok {
+			return 0, 0, false
+		}
+	}
+
+	ws, hs, ok := strings.Cut(size, "x")
+	if !ok {
+		return 0, 0, false
+	}
+
+	w, err := strconv.Atoi(ws)
+	if err != nil || w < 0 || w > s.maxWidth {
+		return 0, 0, false
+	}
+
+	h, err := strconv.Atoi(hs)
+	if err != nil || h < 0 || h > s.maxHeight {
+		return 0, 0, false
+	}
+
+	return w, h, true
+}
+
+// largestSize returns the largest size allowed by WithSizes and WithMaxSize, by
+// width and then height. It returns false if no size is allowed.
+func (s *Service) largestSize() (int, int, bool) {
+	found := false
+	var mw, mh int
+	for size := range s.sizes {
+		w, h, ok := s.parseSize(size)
+		if !ok {
+			continue
+		}
+		if !found || w > mw || (w == mw && h > mh) {
+			mw, mh, found = w, h, true
+		}
+	}
+	return mw, mh, found
+}
@@ -0,0 +1,201 @@
+package img
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+type webpEncoder struct {
+	PngEncoder
+}
+
+func (*webpEncoder) MimeType() string {
+	return "image/webp"
+}
+
+func TestService(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for y := 0; y < 50; y++ {
+		for x := 0; x < 100; x++ {
+			if x < 50 {
+				src.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				src.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	var pngBuf, jpegBuf bytes.Buffer
+	require.NoError(t, png.Encode(&pngBuf, src))
+	require.NoError(t, jpeg.Encode(&jpegBuf, src, nil))
+
+	fsys := fstest.MapFS{
+		"public/photos/flag.png": {Data: pngBuf.Bytes()},
+		"public/photos/flag.jpg": {Data: jpegBuf.Bytes()},
+		"public/photos/note.txt": {Data: []byte("not an image")},
+		"secret.png":             {Data: pngBuf.Bytes()},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+
+	svc := New(fsys, WithEncoder(&webpEncoder{}), WithMaxSize(1000, 1000))
+	svc.Register(app)
+
+	go app.Start()
+	defer app.Close()
+
+	tests := []struct {
+		name        string
+		url         string
+		accept      string
+		status      int
+		contentType string
+		width       int
+		height      int
+	}{
+		{name: "cover", url: svc.URL("photos/flag.png", 50, 50), status: http.StatusOK, contentType: "image/png", width: 50, height: 50},
+		{name: "keep_ratio_width", url: svc.URL("photos/flag.png", 40, 0), status: http.StatusOK, contentType: "image/png", width: 40, height: 20},
+		{name: "keep_ratio_height", url: svc.URL("photos/flag.png", 0, 10), status: http.StatusOK, contentType: "image/png", width: 20, height: 10},
+		{name: "contain", url: svc.URL("photos/flag.png", 50, 50) + "?fit=contain", status: http.StatusOK, contentType: "image/png", width: 50, height: 25},
+		{name: "upscale", url: svc.URL("photos/flag.png", 200, 100), status: http.StatusOK, contentType: "image/png", width: 200, height: 100},
+		{name: "jpeg", url: svc.URL("photos/flag.jpg", 50, 25), status: http.StatusOK, contentType: "image/jpeg", width: 50, height: 25},
+		{name: "negotiated", url: svc.URL("photos/flag.jpg", 50, 25), accept: "image/avif,image/webp,*/*", status: http.StatusOK, contentType: "image/webp", width: 50, height: 25},
+		{name: "format", url: svc.URL("photos/flag.png", 50, 25) + "?format=webp", status: http.StatusOK, contentType: "image/webp", width: 50, height: 25},
+		{name: "format_over_accept", url: svc.URL("photos/flag.png", 50, 25) + "?format=jpeg", accept: "image/webp", status: http.StatusOK, contentType: "image/jpeg", width: 50, height: 25},
+		{name: "invalid_format", url: svc.URL("photos/flag.png", 50, 25) + "?format=bmp", status: http.StatusBadRequest},
+		{name: "invalid_size", url: "/img/50/photos/flag.png", status: http.StatusBadRequest},
+		{name: "too_large", url: svc.URL("photos/flag.png", 5000, 0), status: http.StatusBadRequest},
+		{name: "not_found", url: svc.URL("photos/missing.png", 50, 50), status: http.StatusNotFound},
+		{name: "not_image", url: svc.URL("photos/note.txt", 50, 50), status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+test.url, nil)
+			require.NoError(t, err)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+			if test.status != http.StatusOK {
+				return
+			}
+
+			require.Equal(t, test.contentType, resp.Header.Get("Content-Type"))
+			require.NotEmpty(t, resp.Header.Get("ETag"))
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			cfg, _, err := image.DecodeConfig(bytes.NewReader(buf))
+			require.NoError(t, err)
+			require.Equal(t, test.width, cfg.Width)
+			require.Equal(t, test.height, cfg.Height)
+		})
+	}
+
+	t.Run("too_many_pixels", func(t *testing.T) {
+		small := New(fsys, WithPrefix("/small"), WithMaxPixels(100*50-1))
+		small.Register(app)
+
+		resp, err := http.Get(srv.URL + small.URL("photos/flag.png", 50, 50))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusUnprocessableEntity, resp.StatusCode)
+
+		// the source image isn't decoded
+		_, err = small.load("public/photos/flag.png", time.Time{}, 50, 50, Cover, nil)
+		require.ErrorIs(t, err, ErrTooManyPixels)
+	})
+
+	t.Run("escaped_path", func(t *testing.T) {
+		// the path value isn't cleaned by the ServeMux
+		app.Get("/raw/{size}/{path...}", func(c *xun.Context) error {
+			c.Request().SetPathValue("path", c.Request().Header.Get("X-Path"))
+			return svc.Handle(c)
+		})
+
+		for _, p := range []string{"../secret.png", "photos/../../secret.png", "/secret.png", "photos/./flag.png"} {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/raw/50x50/x", nil)
+			require.NoError(t, err)
+			req.Header.Set("X-Path", p)
+
+			resp, err := http.DefaultClient.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusBadRequest, resp.StatusCode, p)
+		}
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		n := svc.cache.Len()
+
+		resp, err := http.Get(srv.URL + svc.URL("photos/flag.png", 50, 50))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, n, svc.cache.Len())
+
+		req, err := http.NewRequest(http.MethodGet, srv.URL+svc.URL("photos/flag.png", 50, 50), nil)
+		require.NoError(t, err)
+		req.Header.Set("If-None-Match", resp.Header.Get("ETag"))
+
+		resp, err = http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusNotModified, resp.StatusCode)
+	})
+}
+
+func TestTransform(t *testing.T) {
+	src := image.NewRGBA(image.Rect(0, 0, 100, 50))
+	for x := 0; x < 100; x++ {
+		for y := 0; y < 50; y++ {
+			if x < 50 {
+				src.Set(x, y, color.RGBA{R: 255, A: 255})
+			} else {
+				src.Set(x, y, color.RGBA{B: 255, A: 255})
+			}
+		}
+	}
+
+	// cover crops from the center, so both halves are kept
+	m := transform(src, 2, 2, Cover)
+	require.Equal(t, image.Rect(0, 0, 2, 2), m.Bounds())
+	require.Equal(t, color.RGBA{R: 255, A: 255}, m.At(0, 0))
+	require.Equal(t, color.RGBA{B: 255, A: 255}, m.At(1, 1))
+
+	m = transform(src, 0, 0, Cover)
+	require.Equal(t, src, m)
+}
+
+func TestWithSizes(t *testing.T) {
+	svc := New(fstest.MapFS{}, WithSizes("320x0", "640x480"))
+
+	_, _, ok := svc.parseSize("320x0")
+	require.True(t, ok)
+
+	_, _, ok = svc.parseSize("321x0")
+	require.False(t, ok)
+}
@@ -0,0 +1,97 @@
+package img
+
+import "time"
+
+// Option is a function type that takes a pointer to Service as an argument.
+// It is used to configure the Service with various options.
+type Option func(*Service)
+
+// WithRoot sets the directory in the fs.FS that images are loaded from.
+// If not set, it will use "public".
+func WithRoot(root string) Option {
+	return func(s *Service) {
+		s.root = root
+	}
+}
+
+// WithPrefix sets the URL prefix of the image endpoint. If not set, it will use "/img".
+func WithPrefix(prefix string) Option {
+	return func(s *Service) {
+		s.prefix = prefix
+	}
+}
+
+// WithFit sets the default fit strategy. It can be overridden by the `fit` query parameter.
+// If not set, it will use Cover.
+func WithFit(fit Fit) Option {
+	return func(s *Service) {
+		s.fit = fit
+	}
+}
+
+// WithSizes restricts the sizes that can be requested, e.g. "320x0", "640x480".
+// It prevents clients from generating unlimited variants of an image.
+func WithSizes(sizes ...string) Option {
+	return func(s *Service) {
+		if s.sizes == nil {
+			s.sizes = make(map[string]struct{})
+		}
+
+		for _, size := range sizes {
+			s.sizes[size] = struct{}{}
+		}
+	}
+}
+
+// WithMaxSize sets the maximum width and height that can be requested.
+// If not set, it will use 4096x4096.
+func WithMaxSize(width, height int) Option {
+	return func(s *Service) {
+		s.maxWidth = width
+		s.maxHeight = height
+	}
+}
+
+// WithMaxPixels sets the maximum number of pixels of the source images, that
+// larger images are rejected with 422 Unprocessable Entity before they are
+// decoded. If not set, it will allow 50 megapixels.
+func WithMaxPixels(n int) Option {
+	return func(s *Service) {
+		s.maxPixels = n
+	}
+}
+
+// WithCacheSize sets how many resized images are kept in the memory cache.
+// If not set, it will keep 256 images.
+func WithCacheSize(n int) Option {
+	return func(s *Service) {
+		s.cacheSize = n
+	}
+}
+
+// WithMaxAge sets the max-age of the Cache-Control header. If not set, it will use 24 hours.
+func WithMaxAge(d time.Duration) Option {
+	return func(s *Service) {
+		s.maxAge = d
+	}
+}
+
+// WithEncoder registers encoders for extra formats, e.g. WebP or AVIF.
+// They are preferred over the built-in JPEG and PNG encoders when the client accepts them.
+func WithEncoder(e ...Encoder) Option {
+	return func(s *Service) {
+		s.encoders = append(s.encoders, e...)
+	}
+}
+
+// WithPicture sets the `sizes` attribute and the default widths of pictures
+// rendered by the `picture` template function. If not set, it will use "100vw"
+// and 320, 640, 1024 and 1920.
+func WithPicture(sizes string, widths ...int) Option {
+	return func(s *Service) {
+		s.pictureSizes = sizes
+		if len(widths) > 0 {
+			s.pictureWidths = widths
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package img
+
+import (
+	"html"
+	"html/template"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// FuncMap returns the template function `picture`. It should be added to
+// xun.FuncMap before the App is created.
+//
+//	{{ picture "photos/cat.jpg" "A cat" }}
+//	{{ picture "photos/cat.jpg" "A cat" 480 960 }}
+func (s *Service) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"picture": s.Picture,
+	}
+}
+
+// Picture returns the `<picture>` markup of the image resized to the widths,
+// or the widths set by WithPicture. It has a `<source>` for each format of
+// WithEncoder, e.g. WebP, and an `<img>` fallback in the format of the source
+// image. Widths that can't be requested by WithSizes or WithMaxSize are skipped.
+func (s *Service) Picture(name, alt string, widths ...int) template.HTML {
+	if len(widths) == 0 {
+		widths = s.pictureWidths
+	}
+
+	allowed := make([]int, 0, len(widths))
+	for _, w := range widths {
+		if _, _, ok := s.parseSize(strconv.Itoa(w) + "x0"); ok && w > 0 {
+			allowed = append(allowed, w)
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("<picture>")
+
+	sizes := html.EscapeString(s.pictureSizes)
+	for _, e := range s.encoders {
+		format := strings.TrimPrefix(e.MimeType(), "image/")
+		sb.WriteString(`<source type="` + html.EscapeString(e.MimeType()) + `" srcset="` + s.srcset(name, allowed, format) + `" sizes="` + sizes + `">`)
+	}
+
+	src := s.URL(name, 0, 0)
+	if len(allowed) > 0 {
+		src = s.URL(name, allowed[len(allowed)-1], 0)
+	} else if w, h, ok := s.largestSize(); ok {
+		// 0x0 is rejected if it isn't in WithSizes
+		src = s.URL(name, w, h)
+	}
+
+	sb.WriteString(`<img src="` + html.EscapeString(escapePath(src)) + `"`)
+	if len(allowed) > 0 {
+		sb.WriteString(` srcset="` + s.srcset(name, allowed, "") + `" sizes="` + sizes + `"`)
+	}
+	sb.WriteString(` alt="` + html.EscapeString(alt) + `" loading="lazy" decoding="async"></picture>`)
+
+	return template.HTML(sb.String()) // nolint: gosec
+}
+
+// srcset returns the escaped `srcset` of the image, e.g. "/img/320x0/cat.jpg?format=webp 320w, ...".
+func (s *Service) srcset(name string, widths []int, format string) string {
+	items := make([]string, 0, len(widths))
+	for _, w := range widths {
+		u := escapePath(s.URL(name, w, 0))
+		if format != "" {
+			u += "?format=" + url.QueryEscape(format)
+		}
+		items = append(items, u+" "+strconv.Itoa(w)+"w")
+	}
+
+	return html.EscapeString(strings.Join(items, ", "))
+}
+
+// escapePath escapes the path for URLs, e.g. spaces and commas that break srcset.
+func escapePath(p string) string {
+	return strings.ReplaceAll((&url.URL{Path: p}).EscapedPath(), ",", "%2C")
+}
@@ -0,0 +1,35 @@
+package img
+
+import (
+	"html/template"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPicture(t *testing.T) {
+	svc := New(fstest.MapFS{}, WithEncoder(&webpEncoder{}), WithMaxSize(1000, 1000), WithPicture("(max-width: 640px) 100vw, 640px", 320, 640))
+
+	require.Equal(t, template.HTML(`<picture>`+
+		`<source type="image/webp" srcset="/img/320x0/photos/my%20cat.jpg?format=webp 320w, /img/640x0/photos/my%20cat.jpg?format=webp 640w" sizes="(max-width: 640px) 100vw, 640px">`+
+		`<img src="/img/640x0/photos/my%20cat.jpg" srcset="/img/320x0/photos/my%20cat.jpg 320w, /img/640x0/photos/my%20cat.jpg 640w" sizes="(max-width: 640px) 100vw, 640px" alt="A &#34;cat&#34;" loading="lazy" decoding="async">`+
+		`</picture>`), svc.Picture("photos/my cat.jpg", `A "cat"`))
+
+	// widths over the max size are skipped
+	require.Equal(t, template.HTML(`<picture>`+
+		`<source type="image/webp" srcset="/img/480x0/cat.jpg?format=webp 480w" sizes="(max-width: 640px) 100vw, 640px">`+
+		`<img src="/img/480x0/cat.jpg" srcset="/img/480x0/cat.jpg 480w" sizes="(max-width: 640px) 100vw, 640px" alt="cat" loading="lazy" decoding="async">`+
+		`</picture>`), svc.Picture("cat.jpg", "cat", 480, 2000))
+
+	svc = New(fstest.MapFS{}, WithPrefix("/images/"), WithSizes("320x0"))
+	require.Equal(t, template.HTML(`<picture>`+
+		`<img src="/images/320x0/a%2Cb.png" srcset="/images/320x0/a%2Cb.png 320w" sizes="100vw" alt="" loading="lazy" decoding="async">`+
+		`</picture>`), svc.FuncMap()["picture"].(func(string, string, ...int) template.HTML)("a,b.png", ""))
+
+	// the fallback is the largest allowed size if no width is allowed
+	svc = New(fstest.MapFS{}, WithSizes("320x0", "640x480", "640x0", "9999x0"))
+	require.Equal(t, template.HTML(`<picture>`+
+		`<img src="/img/640x480/cat.jpg" alt="cat" loading="lazy" decoding="async">`+
+		`</picture>`), svc.Picture("cat.jpg", "cat", 480))
+}
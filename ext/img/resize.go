@@ -0,0 +1,103 @@
+package img
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+)
+
+// Fit is the strategy used to fit an image into the requested size.
+type Fit string
+
+const (
+	// Cover scales the image to cover the requested size, and crops the overflow from the center.
+	Cover Fit = "cover"
+	// Contain scales the image to fit inside the requested size, and keeps the aspect ratio.
+	Contain Fit = "contain"
+)
+
+// transform scales src into w x h with the given fit. A zero width or height
+// is calculated from the aspect ratio of src.
+func transform(src image.Image, w, h int, fit Fit) image.Image {
+	b := src.Bounds()
+	sw, sh := b.Dx(), b.Dy()
+
+	if sw == 0 || sh == 0 {
+		return src
+	}
+
+	switch {
+	case w == 0 && h == 0:
+		return src
+	case w == 0:
+		w = max(1, sw*h/sh)
+		return resize(src, b, w, h)
+	case h == 0:
+		h = max(1, sh*w/sw)
+		return resize(src, b, w, h)
+	}
+
+	if fit == Contain {
+		if sw*h > sh*w {
+			return resize(src, b, w, max(1, sh*w/sw))
+		}
+		return resize(src, b, max(1, sw*h/sh), h)
+	}
+
+	// cover: crop the source to the target aspect ratio from the center
+	crop := b
+	if sw*h > sh*w {
+		cw := sh * w / h
+		crop.Min.X = b.Min.X + (sw-cw)/2
+		crop.Max.X = crop.Min.X + cw
+	} else {
+		ch := sw * h / w
+		crop.Min.Y = b.Min.Y + (sh-ch)/2
+		crop.Max.Y = crop.Min.Y + ch
+	}
+
+	return resize(src, crop, w, h)
+}
+
+// resize scales the rect r of src into a w x h image with a box filter.
+// Each target pixel is the average of the source pixels it covers, and the
+// nearest source pixel when upscaling.
+func resize(src image.Image, r image.Rectangle, w, h int) image.Image {
+	rgba := image.NewRGBA(image.Rect(0, 0, r.Dx(), r.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, r.Min, draw.Src)
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	sw, sh := r.Dx(), r.Dy()
+
+	for y := 0; y < h; y++ {
+		y0 := y * sh / h
+		y1 := max(y0+1, (y+1)*sh/h)
+
+		for x := 0; x < w; x++ {
+			x0 := x * sw / w
+			x1 := max(x0+1, (x+1)*sw/w)
+
+			var cr, cg, cb, ca, n uint32
+			for sy := y0; sy < y1; sy++ {
+				i := rgba.PixOffset(x0, sy)
+				for sx := x0; sx < x1; sx++ {
+					cr += uint32(rgba.Pix[i])
+					cg += uint32(rgba.Pix[i+1])
+					cb += uint32(rgba.Pix[i+2])
+					ca += uint32(rgba.Pix[i+3])
+					i += 4
+					n++
+				}
+			}
+
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(cr / n),
+				G: uint8(cg / n),
+				B: uint8(cb / n),
+				A: uint8(ca / n),
+			})
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,159 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// Limit allows Rate requests per Per, with bursts of up to Burst requests.
+// A zero Limit is unlimited.
+type Limit struct {
+	Rate  int
+	Per   time.Duration
+	Burst int
+}
+
+// PerSecond returns a Limit of n requests per second.
+func PerSecond(n int) Limit {
+	return Limit{Rate: n, Per: time.Second, Burst: n}
+}
+
+// PerMinute returns a Limit of n requests per minute.
+func PerMinute(n int) Limit {
+	return Limit{Rate: n, Per: time.Minute, Burst: n}
+}
+
+// PerHour returns a Limit of n requests per hour.
+func PerHour(n int) Limit {
+	return Limit{Rate: n, Per: time.Hour, Burst: n}
+}
+
+func (l Limit) unlimited() bool {
+	return l.Rate <= 0 || l.Per <= 0
+}
+
+// KeyFunc returns the key that a request is counted by, and its Limit. An
+// empty key isn't limited.
+type KeyFunc func(c *xun.Context) (string, Limit)
+
+// ByIP limits requests by the IP of the client.
+func ByIP(limit Limit) KeyFunc {
+	return func(c *xun.Context) (string, Limit) {
+		return "ip:" + c.ClientIP(), limit
+	}
+}
+
+// ByUser limits requests of authenticated users by the user set with
+// c.Set(xun.UserKey, user), e.g. by the sessions or api extensions, and
+// anonymous requests by the IP of the client.
+func ByUser(anonymous, authenticated Limit) KeyFunc {
+	return func(c *xun.Context) (string, Limit) {
+		if user := c.Get(xun.UserKey); user != nil {
+			return "user:" + fmt.Sprint(user), authenticated
+		}
+		return "ip:" + c.ClientIP(), anonymous
+	}
+}
+
+// Limiter limits the request rate of keys by GCRA, a token bucket that only
+// keeps the theoretical arrival time of the next request for each key.
+type Limiter struct {
+	mu    sync.Mutex
+	key   KeyFunc
+	tats  map[string]time.Time
+	calls int
+
+	now func() time.Time
+}
+
+// New creates a Limiter that limits requests by the key function.
+func New(key KeyFunc) *Limiter {
+	return &Limiter{
+		key:  key,
+		tats: make(map[string]time.Time),
+		now:  time.Now,
+	}
+}
+
+// Allow reports whether a request of the key is allowed by the limit, and
+// returns the remaining requests and how long to wait before the next one.
+func (l *Limiter) Allow(key string, limit Limit) (ok bool, remaining int, wait time.Duration) {
+	if limit.unlimited() {
+		return true, math.MaxInt, 0
+	}
+
+	burst := limit.Burst
+	if burst < 1 {
+		burst = 1
+	}
+
+	interval := limit.Per / time.Duration(limit.Rate)
+	tolerance := interval * time.Duration(burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+
+	l.calls++
+	if l.calls%1024 == 0 {
+		l.prune(now)
+	}
+
+	tat, found := l.tats[key]
+	if !found || tat.Before(now) {
+		tat = now
+	}
+
+	next := tat.Add(interval)
+	if allowAt := next.Add(-tolerance); now.Before(allowAt) {
+		return false, 0, allowAt.Sub(now)
+	}
+
+	l.tats[key] = next
+
+	return true, int((now.Sub(next) + tolerance) / interval), 0
+}
+
+// prune deletes the keys that are fully restored.
+func (l *Limiter) prune(now time.Time) {
+	for k, tat := range l.tats {
+		if tat.Before(now) {
+			delete(l.tats, k)
+		}
+	}
+}
+
+// Middleware limits the requests by the key function. Rejected requests are
+// written with 429 Too Many Requests and a Retry-After header, and all
+// limited responses have RateLimit-Limit and RateLimit-Remaining headers.
+//
+// It should be used after authentication middlewares, so the key function
+// can use the authenticated user.
+func (l *Limiter) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		key, limit := l.key(c)
+		if key == "" || limit.unlimited() {
+			return next(c)
+		}
+
+		ok, remaining, wait := l.Allow(key, limit)
+
+		c.WriteHeader("RateLimit-Limit", strconv.Itoa(limit.Rate))
+		c.WriteHeader("RateLimit-Remaining", strconv.Itoa(remaining))
+
+		if !ok {
+			c.WriteHeader("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			c.WriteStatus(http.StatusTooManyRequests)
+			return xun.ErrCancelled
+		}
+
+		return next(c)
+	}
+}
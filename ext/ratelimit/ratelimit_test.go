@@ -0,0 +1,138 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestAllow(t *testing.T) {
+	now := time.Now()
+
+	l := New(nil)
+	l.now = func() time.Time { return now }
+
+	limit := Limit{Rate: 2, Per: time.Second, Burst: 2}
+
+	ok, remaining, _ := l.Allow("k", limit)
+	require.True(t, ok)
+	require.Equal(t, 1, remaining)
+
+	ok, remaining, _ = l.Allow("k", limit)
+	require.True(t, ok)
+	require.Equal(t, 0, remaining)
+
+	ok, _, wait := l.Allow("k", limit)
+	require.False(t, ok)
+	require.Equal(t, 500*time.Millisecond, wait)
+
+	// other keys are counted separately
+	ok, _, _ = l.Allow("other", limit)
+	require.True(t, ok)
+
+	// a token is restored every 500ms
+	now = now.Add(500 * time.Millisecond)
+	ok, remaining, _ = l.Allow("k", limit)
+	require.True(t, ok)
+	require.Equal(t, 0, remaining)
+
+	ok, _, _ = l.Allow("k", Limit{})
+	require.True(t, ok)
+}
+
+func TestByUser(t *testing.T) {
+	now := time.Now()
+
+	l := New(ByUser(PerMinute(1), PerMinute(3)))
+	l.now = func() time.Time { return now }
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	app.Use(func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			if user := c.Request().Header.Get("X-User"); user != "" {
+				c.Set(xun.UserKey, user)
+			}
+			return next(c)
+		}
+	}, l.Middleware)
+
+	app.Get("/", func(c *xun.Context) error {
+		return c.View(nil)
+	})
+
+	app.Start()
+
+	get := func(user string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		require.NoError(t, err)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := get("")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("RateLimit-Limit"))
+	require.Equal(t, "0", resp.Header.Get("RateLimit-Remaining"))
+
+	resp = get("")
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "60", resp.Header.Get("Retry-After"))
+
+	// authenticated users are keyed by user, with their own limit
+	for i := 2; i >= 0; i-- {
+		resp = get("alice")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "3", resp.Header.Get("RateLimit-Limit"))
+		require.Equal(t, string(rune('0'+i)), resp.Header.Get("RateLimit-Remaining"))
+	}
+	require.Equal(t, http.StatusTooManyRequests, get("alice").StatusCode)
+	require.Equal(t, http.StatusOK, get("bob").StatusCode)
+
+	now = now.Add(time.Minute)
+	require.Equal(t, http.StatusOK, get("").StatusCode)
+}
+
+func TestByIPTrustedProxies(t *testing.T) {
+	l := New(ByIP(PerMinute(1)))
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithTrustedProxies("127.0.0.1", "::1"))
+	defer app.Close()
+
+	app.Use(l.Middleware)
+	app.Get("/", func(c *xun.Context) error {
+		return c.View(nil)
+	})
+
+	get := func(ip string) int {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		require.NoError(t, err)
+		req.Header.Set("X-Forwarded-For", ip)
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, http.StatusOK, get("198.51.100.1"))
+	require.Equal(t, http.StatusTooManyRequests, get("198.51.100.1"))
+	require.Equal(t, http.StatusOK, get("198.51.100.2"))
+}
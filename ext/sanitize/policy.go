@@ -0,0 +1,139 @@
+package sanitize
+
+import (
+	"strings"
+)
+
+// Policy is an allow list of the elements, attributes and URL schemes that are
+// kept by Sanitize. Everything else is stripped, and the text of stripped
+// elements is kept, except for elements like <script> and <style>.
+//
+//	p := sanitize.NewPolicy().
+//		AllowElements("p", "b", "i", "a").
+//		AllowAttrs("href").OnElements("a").
+//		AllowURLSchemes("https", "mailto").
+//		RequireNoFollowOnLinks()
+type Policy struct {
+	elements    map[string]map[string]bool
+	globalAttrs map[string]bool
+	schemes     map[string]bool
+	noFollow    bool
+}
+
+// NewPolicy creates an empty Policy that strips all elements.
+func NewPolicy() *Policy {
+	return &Policy{
+		elements:    make(map[string]map[string]bool),
+		globalAttrs: make(map[string]bool),
+		schemes:     make(map[string]bool),
+	}
+}
+
+// StrictPolicy returns a Policy that strips all elements and keeps the text.
+func StrictPolicy() *Policy {
+	return NewPolicy()
+}
+
+// UGCPolicy returns a Policy for user-generated content, e.g. comments and
+// posts rendered from markdown. It allows formatting elements, lists, tables,
+// quotes, code, links and images with http, https and mailto URLs, and adds
+// rel="nofollow noopener" to links.
+func UGCPolicy() *Policy {
+	return NewPolicy().
+		AllowElements("p", "br", "hr", "b", "strong", "i", "em", "u", "s", "del", "ins", "mark", "small", "sub", "sup",
+			"h1", "h2", "h3", "h4", "h5", "h6", "blockquote", "pre", "code", "kbd", "samp", "abbr", "cite", "q",
+			"ul", "ol", "li", "dl", "dt", "dd", "table", "thead", "tbody", "tfoot", "tr", "th", "td", "caption",
+			"figure", "figcaption", "details", "summary", "span", "div", "a", "img").
+		AllowAttrs("title", "lang", "dir").Globally().
+		AllowAttrs("href").OnElements("a").
+		AllowAttrs("src", "alt", "width", "height").OnElements("img").
+		AllowAttrs("cite").OnElements("blockquote", "q").
+		AllowAttrs("colspan", "rowspan").OnElements("td", "th").
+		AllowAttrs("start", "reversed").OnElements("ol").
+		AllowAttrs("open").OnElements("details").
+		AllowURLSchemes("http", "https", "mailto").
+		RequireNoFollowOnLinks()
+}
+
+// AllowElements allows the elements without attributes.
+func (p *Policy) AllowElements(names ...string) *Policy {
+	for _, name := range names {
+		name = strings.ToLower(name)
+		if _, ok := p.elements[name]; !ok {
+			p.elements[name] = make(map[string]bool)
+		}
+	}
+	return p
+}
+
+// AllowAttrs starts to allow the attributes, on the elements of OnElements or
+// on all allowed elements by Globally.
+func (p *Policy) AllowAttrs(attrs ...string) *AttrPolicy {
+	return &AttrPolicy{p: p, attrs: attrs}
+}
+
+// AllowURLSchemes allows the schemes of URLs in href, src and cite attributes,
+// e.g. "https". Relative URLs are always allowed.
+func (p *Policy) AllowURLSchemes(schemes ...string) *Policy {
+	for _, s := range schemes {
+		p.schemes[strings.ToLower(s)] = true
+	}
+	return p
+}
+
+// RequireNoFollowOnLinks adds rel="nofollow noopener" to links, so user
+// content doesn't pass page rank to spam links.
+func (p *Policy) RequireNoFollowOnLinks() *Policy {
+	p.noFollow = true
+	return p
+}
+
+// AttrPolicy allows attributes on elements of a Policy.
+type AttrPolicy struct {
+	p     *Policy
+	attrs []string
+}
+
+// OnElements allows the attributes on the elements. The elements are allowed too.
+func (ap *AttrPolicy) OnElements(names ...string) *Policy {
+	ap.p.AllowElements(names...)
+	for _, name := range names {
+		attrs := ap.p.elements[strings.ToLower(name)]
+		for _, a := range ap.attrs {
+			attrs[strings.ToLower(a)] = true
+		}
+	}
+	return ap.p
+}
+
+// Globally allows the attributes on all allowed elements.
+func (ap *AttrPolicy) Globally() *Policy {
+	for _, a := range ap.attrs {
+		ap.p.globalAttrs[strings.ToLower(a)] = true
+	}
+	return ap.p
+}
+
+func (p *Policy) allowAttr(element, attr string) bool {
+	if strings.HasPrefix(attr, "on") { // event handlers are never allowed
+		return false
+	}
+	return p.globalAttrs[attr] || p.elements[element][attr]
+}
+
+// allowURL reports whether the URL is relative or its scheme is allowed.
+func (p *Policy) allowURL(value string) bool {
+	v := strings.TrimSpace(value)
+	i := strings.IndexAny(v, ":/?#")
+	if i < 0 || v[i] != ':' {
+		return true // relative
+	}
+
+	scheme := strings.ToLower(v[:i])
+	for _, r := range scheme { // a control char or space in the scheme, e.g. "java\tscript:"
+		if r <= ' ' {
+			return false
+		}
+	}
+	return p.schemes[scheme]
+}
@@ -0,0 +1,151 @@
+package sanitize
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// dropped are the elements whose content is stripped with them.
+var dropped = map[string]bool{
+	"script": true, "style": true, "iframe": true, "object": true, "embed": true, "template": true,
+	"noscript": true, "textarea": true, "select": true, "svg": true, "math": true, "title": true, "head": true,
+}
+
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true,
+	"input": true, "link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+var urlAttrs = map[string]bool{"href": true, "src": true, "cite": true, "action": true, "formaction": true}
+
+// Sanitize returns the HTML with the elements and attributes that aren't
+// allowed by the policy stripped. The result is well-formed: unclosed
+// elements are closed, and text is escaped.
+func (p *Policy) Sanitize(s string) string {
+	z := nethtml.NewTokenizer(strings.NewReader(s))
+
+	var (
+		buf       bytes.Buffer
+		open      []string
+		skip      string // the dropped element whose content is skipped
+		skipDepth int
+	)
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+
+		tag := z.Token()
+
+		if skip != "" {
+			switch {
+			case tt == nethtml.StartTagToken && tag.Data == skip:
+				skipDepth++
+			case tt == nethtml.EndTagToken && tag.Data == skip:
+				skipDepth--
+				if skipDepth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch tt { // skipcq: CRT-A0014
+		case nethtml.TextToken:
+			buf.WriteString(html.EscapeString(tag.Data))
+
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if dropped[tag.Data] && tt == nethtml.StartTagToken && !voidElements[tag.Data] {
+				skip, skipDepth = tag.Data, 1
+				continue
+			}
+
+			if _, ok := p.elements[tag.Data]; !ok {
+				continue
+			}
+
+			p.writeTag(&buf, tag)
+			if !voidElements[tag.Data] {
+				if tt == nethtml.SelfClosingTagToken {
+					buf.WriteString("</" + tag.Data + ">")
+				} else {
+					open = append(open, tag.Data)
+				}
+			}
+
+		case nethtml.EndTagToken:
+			i := lastIndex(open, tag.Data)
+			if i < 0 {
+				continue
+			}
+			for j := len(open) - 1; j >= i; j-- {
+				buf.WriteString("</" + open[j] + ">")
+			}
+			open = open[:i]
+		}
+	}
+
+	for j := len(open) - 1; j >= 0; j-- {
+		buf.WriteString("</" + open[j] + ">")
+	}
+
+	return buf.String()
+}
+
+// HTML returns the sanitized HTML as template.HTML, so it isn't escaped again
+// by html/template.
+func (p *Policy) HTML(s string) template.HTML {
+	return template.HTML(p.Sanitize(s)) // nolint: gosec
+}
+
+func (p *Policy) writeTag(buf *bytes.Buffer, tag nethtml.Token) {
+	buf.WriteString("<" + tag.Data)
+
+	for _, a := range tag.Attr {
+		if a.Namespace != "" || !p.allowAttr(tag.Data, a.Key) {
+			continue
+		}
+
+		if urlAttrs[a.Key] && !p.allowURL(a.Val) {
+			continue
+		}
+
+		if a.Key == "rel" && p.noFollow && tag.Data == "a" {
+			continue // replaced by nofollow
+		}
+
+		buf.WriteString(" " + a.Key + `="` + html.EscapeString(a.Val) + `"`)
+	}
+
+	if p.noFollow && tag.Data == "a" {
+		buf.WriteString(` rel="nofollow noopener"`)
+	}
+
+	buf.WriteString(">")
+}
+
+// lastIndex returns the index of the innermost open element.
+func lastIndex(items []string, item string) int {
+	for i := len(items) - 1; i >= 0; i-- {
+		if items[i] == item {
+			return i
+		}
+	}
+	return -1
+}
+
+// FuncMap returns the template function `sanitize` with the policy. It should
+// be added to xun.FuncMap before the App is created.
+//
+//	{{ sanitize .Comment.Body }}
+func FuncMap(p *Policy) template.FuncMap {
+	return template.FuncMap{
+		"sanitize": p.HTML,
+	}
+}
@@ -0,0 +1,83 @@
+package sanitize
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUGCPolicy(t *testing.T) {
+	p := UGCPolicy()
+
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "formatting",
+			html: `<p>Hello <b>world</b> &amp; <i>friends</i></p>`,
+			want: `<p>Hello <b>world</b> &amp; <i>friends</i></p>`,
+		},
+		{
+			name: "scripts",
+			html: `<p onclick="alert(1)">hi<script>alert("<b>")</script><style>p{}</style></p>`,
+			want: `<p>hi</p>`,
+		},
+		{
+			name: "disallowed elements keep text",
+			html: `<form action="/x"><button>click</button></form><custom>me</custom>`,
+			want: `clickme`,
+		},
+		{
+			name: "links",
+			html: `<a href="https://xun.dev" rel="follow" target="_blank">xun</a><a href="javascript:alert(1)">x</a><a href="/docs">docs</a>`,
+			want: `<a href="https://xun.dev" rel="nofollow noopener">xun</a><a rel="nofollow noopener">x</a><a href="/docs" rel="nofollow noopener">docs</a>`,
+		},
+		{
+			name: "images",
+			html: `<img src="data:image/svg+xml;base64,AAA" alt="x" onerror="alert(1)"><img src="/a.png" alt="a">`,
+			want: `<img alt="x"><img src="/a.png" alt="a">`,
+		},
+		{
+			name: "unbalanced",
+			html: `<ul><li><b>one</li><li>two</b></ul></div><p>end`,
+			want: `<ul><li><b>one</b></li><li>two</li></ul><p>end</p>`,
+		},
+		{
+			name: "nested dropped",
+			html: `<svg><svg></svg><text>x</text></svg>after`,
+			want: `after`,
+		},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.want, p.Sanitize(test.html), test.name)
+	}
+}
+
+func TestStrictPolicy(t *testing.T) {
+	require.Equal(t, `hello &lt;world&gt; &amp; you`, StrictPolicy().Sanitize(`<p>hello <b>&lt;world&gt;</b> & you</p>`))
+}
+
+func TestPolicy(t *testing.T) {
+	p := NewPolicy().
+		AllowElements("p").
+		AllowAttrs("class").Globally().
+		AllowAttrs("href").OnElements("a").
+		AllowURLSchemes("mailto")
+
+	require.Equal(t,
+		`<p class="note"><a class="x" href="mailto:xun@yaitoo.cn">mail</a><a>web</a></p>`,
+		p.Sanitize(`<p class="note" id="n"><a class="x" href="mailto:xun@yaitoo.cn">mail</a><a href="https://xun.dev">web</a></p>`))
+}
+
+func TestFuncMap(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(FuncMap(UGCPolicy())).Parse(`<div>{{ sanitize . }}</div>`))
+
+	var buf bytes.Buffer
+	require.NoError(t, tpl.Execute(&buf, `<b>hi</b><script>alert(1)</script>`))
+	require.Equal(t, `<div><b>hi</b></div>`, buf.String())
+}
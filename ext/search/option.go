@@ -0,0 +1,43 @@
+package search
+
+// Option is a function type that takes a pointer to Index as an argument.
+// It is used to configure the Index with various options.
+type Option func(*Index)
+
+// WithExclude skips the pages whose names match any of the patterns, e.g.
+// "admin/*". Patterns are matched by path.Match.
+func WithExclude(patterns ...string) Option {
+	return func(idx *Index) {
+		idx.excludes = append(idx.excludes, patterns...)
+	}
+}
+
+// WithHost indexes the pages of the host, e.g. "abc.com" for pages/@abc.com.
+// Only the pages without host are indexed by default.
+func WithHost(host string) Option {
+	return func(idx *Index) {
+		idx.host = host
+	}
+}
+
+// WithExcerpt sets the maximum length in characters of excerpts. It is 160 by default.
+func WithExcerpt(n int) Option {
+	return func(idx *Index) {
+		idx.excerpt = n
+	}
+}
+
+// WithContent includes the full text of pages in the index, so they can be
+// searched by their content too. It makes the JSON index bigger.
+func WithContent() Option {
+	return func(idx *Index) {
+		idx.content = true
+	}
+}
+
+// WithLimit sets the maximum number of results returned by `GET /search`. It is 10 by default.
+func WithLimit(n int) Option {
+	return func(idx *Index) {
+		idx.limit = n
+	}
+}
@@ -0,0 +1,369 @@
+// Package search builds a search index of the pages of an App, that can be
+// saved as JSON in a build step for client-side search, and served by a
+// server-side `GET /search` handler.
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/yaitoo/xun"
+	nethtml "golang.org/x/net/html"
+)
+
+// Document is an indexed page.
+type Document struct {
+	URL      string   `json:"url"`
+	Title    string   `json:"title"`
+	Headings []string `json:"headings,omitempty"`
+	Excerpt  string   `json:"excerpt,omitempty"`
+	Content  string   `json:"content,omitempty"`
+}
+
+// Result is the result of a query.
+type Result struct {
+	Query     string      `json:"query"`
+	Documents []*Document `json:"documents"`
+}
+
+// Index is a search index of pages.
+type Index struct {
+	Documents []*Document
+
+	excludes []string
+	host     string
+	excerpt  int
+	content  bool
+	limit    int
+
+	texts []text
+}
+
+// text is the lowercase text of a document to match queries.
+type text struct {
+	title    string
+	headings string
+	excerpt  string
+	content  string
+}
+
+func newIndex(opts []Option) *Index {
+	idx := &Index{
+		excerpt: 160,
+		limit:   10,
+	}
+
+	for _, o := range opts {
+		o(idx)
+	}
+
+	return idx
+}
+
+// Build renders the pages of the app, and indexes their title, h1-h3 headings,
+// excerpt and URL. The excerpt is the meta description, or the first
+// paragraph of a page. Pages with wildcards, and pages with
+// `<meta name="robots" content="noindex">` are skipped.
+func Build(app *xun.App, opts ...Option) (*Index, error) {
+	idx := newIndex(opts)
+
+	buf := new(bytes.Buffer)
+	for _, p := range app.Pages() {
+		if p.URL == "" || p.Host != idx.host || idx.excluded(p.Name) {
+			continue
+		}
+
+		req, err := http.NewRequest(http.MethodGet, p.URL, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Host = p.Host
+
+		buf.Reset()
+		if err := p.Render(buf, req, nil); err != nil {
+			return nil, fmt.Errorf("search: %s: %w", p.Name, err)
+		}
+
+		if d := idx.parse(p.URL, buf.String()); d != nil {
+			idx.Documents = append(idx.Documents, d)
+		}
+	}
+
+	idx.prepare()
+	return idx, nil
+}
+
+// Load reads an index written by WriteJSON, e.g. the search.json saved in a build step.
+func Load(r io.Reader, opts ...Option) (*Index, error) {
+	idx := newIndex(opts)
+
+	if err := json.NewDecoder(r).Decode(&idx.Documents); err != nil {
+		return nil, err
+	}
+
+	idx.prepare()
+	return idx, nil
+}
+
+// WriteJSON writes the documents of the index as JSON to w, so it can be
+// consumed by client-side search libraries, e.g. saved as search.json in a
+// build step.
+func (idx *Index) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(idx.Documents)
+}
+
+func (idx *Index) excluded(name string) bool {
+	for _, p := range idx.excludes {
+		if ok, _ := path.Match(p, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (idx *Index) prepare() {
+	idx.texts = make([]text, len(idx.Documents))
+	for i, d := range idx.Documents {
+		idx.texts[i] = text{
+			title:    strings.ToLower(d.Title),
+			headings: strings.ToLower(strings.Join(d.Headings, "\n")),
+			excerpt:  strings.ToLower(d.Excerpt),
+			content:  strings.ToLower(d.Content),
+		}
+	}
+}
+
+// Search returns the documents that contain all words of the query, ranked by
+// where they are found: title, headings, excerpt, then content.
+func (idx *Index) Search(query string) []*Document {
+	words := strings.Fields(strings.ToLower(query))
+	if len(words) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		doc   *Document
+		score int
+	}
+
+	var hits []hit
+	for i, d := range idx.Documents {
+		score := 0
+		for _, w := range words {
+			s := idx.texts[i].score(w)
+			if s == 0 {
+				score = 0
+				break
+			}
+			score += s
+		}
+
+		if score > 0 {
+			hits = append(hits, hit{doc: d, score: score})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].score > hits[j].score
+	})
+
+	items := make([]*Document, len(hits))
+	for i, h := range hits {
+		items[i] = h.doc
+	}
+
+	return items
+}
+
+func (t text) score(word string) int {
+	score := 0
+	if strings.Contains(t.title, word) {
+		score += 8
+	}
+	if strings.Contains(t.headings, word) {
+		score += 4
+	}
+	if strings.Contains(t.excerpt, word) {
+		score += 2
+	}
+	if strings.Contains(t.content, word) {
+		score++
+	}
+	return score
+}
+
+type router interface {
+	Get(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+}
+
+// Register registers `GET /search?q=` that returns the Result of the query,
+// and `GET /search.json` that returns the index for client-side search.
+func (idx *Index) Register(r router, opts ...xun.RoutingOption) {
+	r.Get("/search", idx.Handle, opts...)
+	r.Get("/search.json", idx.HandleJSON, opts...)
+}
+
+// Handle writes the Result of the query `q` with the viewer of the route. It
+// returns at most the number of documents set by WithLimit.
+func (idx *Index) Handle(c *xun.Context) error {
+	q := strings.TrimSpace(c.Request().URL.Query().Get("q"))
+
+	items := idx.Search(q)
+	if len(items) > idx.limit {
+		items = items[:idx.limit]
+	}
+	if items == nil {
+		items = []*Document{}
+	}
+
+	return c.View(Result{Query: q, Documents: items})
+}
+
+// HandleJSON writes the documents of the index as JSON.
+func (idx *Index) HandleJSON(c *xun.Context) error {
+	c.WriteHeader("Content-Type", "application/json")
+	return idx.WriteJSON(c.Writer())
+}
+
+// skipped are elements whose text is not indexed.
+var skipped = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"template": true,
+	"noscript": true,
+}
+
+// parse extracts the document of the rendered page, or returns nil if the
+// page is not indexable.
+func (idx *Index) parse(url, page string) *Document { // skipcq: GO-R1005
+	z := nethtml.NewTokenizer(strings.NewReader(page))
+
+	var (
+		d           = &Document{URL: url}
+		h1          string
+		description string
+		paragraph   string
+		skip        string
+		capture     string // title, h1-h3 or p
+		captured    strings.Builder
+		content     strings.Builder
+	)
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			if skip != "" {
+				continue
+			}
+			if skipped[tok.Data] && tt == nethtml.StartTagToken {
+				skip = tok.Data
+				continue
+			}
+			if tok.Data == "meta" {
+				switch strings.ToLower(attr(tok, "name")) {
+				case "description":
+					description = attr(tok, "content")
+				case "robots":
+					if strings.Contains(strings.ToLower(attr(tok, "content")), "noindex") {
+						return nil
+					}
+				}
+				continue
+			}
+			if capture == "" && (tok.Data == "title" || tok.Data == "h1" || tok.Data == "h2" || tok.Data == "h3" ||
+				(tok.Data == "p" && paragraph == "")) {
+				capture = tok.Data
+				captured.Reset()
+			}
+		case nethtml.EndTagToken:
+			if skip != "" {
+				if tok.Data == skip {
+					skip = ""
+				}
+				continue
+			}
+			if tok.Data != capture {
+				continue
+			}
+
+			s := strings.Join(strings.Fields(captured.String()), " ")
+			switch capture {
+			case "title":
+				d.Title = s
+			case "p":
+				paragraph = s
+			default:
+				if capture == "h1" && h1 == "" {
+					h1 = s
+				}
+				if s != "" {
+					d.Headings = append(d.Headings, s)
+				}
+			}
+			capture = ""
+		case nethtml.TextToken:
+			if skip != "" {
+				continue
+			}
+			if capture != "" {
+				captured.WriteString(tok.Data)
+			}
+			if capture != "title" {
+				content.WriteString(tok.Data)
+				content.WriteByte(' ')
+			}
+		}
+	}
+
+	if d.Title == "" {
+		d.Title = h1
+	}
+
+	if description != "" {
+		d.Excerpt = truncate(strings.Join(strings.Fields(description), " "), idx.excerpt)
+	} else {
+		d.Excerpt = truncate(paragraph, idx.excerpt)
+	}
+
+	if idx.content {
+		d.Content = strings.Join(strings.Fields(content.String()), " ")
+	}
+
+	return d
+}
+
+// truncate cuts s at the last space before n characters, and appends "…".
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if n <= 0 || len(r) <= n {
+		return s
+	}
+
+	cut := string(r[:n])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
+
+func attr(tag nethtml.Token, key string) string {
+	for _, a := range tag.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package search
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+var fsys = fstest.MapFS{
+	"layouts/main.html": {Data: []byte(`<html><head><title>{{ block "title" . }}{{ end }} - Docs</title>{{ block "head" . }}{{ end }}<style>p{color:red}</style></head>` +
+		`<body>{{ block "content" . }}{{ end }}<script>var routing = 1;</script></body></html>`)},
+	"pages/index.html": {Data: []byte(`<!--layout:main-->
+{{ define "title" }}Home{{ end }}
+{{ define "head" }}{{ end }}
+{{ define "content" }}<h1>Welcome</h1><p>Xun is a web framework built on <b>net/http</b>.</p>{{ end }}`)},
+	"pages/docs/routing.html": {Data: []byte(`<!--layout:main-->
+{{ define "title" }}Routing{{ end }}
+{{ define "head" }}<meta name="description" content="How to register   routes and groups.">{{ end }}
+{{ define "content" }}<h1>Routing</h1><h2>Groups</h2><p>Groups share middlewares.</p><h3>Middlewares</h3><h4>Skipped</h4>{{ end }}`)},
+	"pages/docs/views.html":     {Data: []byte(`<h1>Views</h1><p>Views are rendered by the routing viewers, and can be html or json or xml or text templates.</p>`)},
+	"pages/draft.html":          {Data: []byte(`<meta name="robots" content="noindex"><h1>Draft</h1>`)},
+	"pages/admin/index.html":    {Data: []byte(`<h1>Admin</h1>`)},
+	"pages/user/{id}.html":      {Data: []byte(`<h1>User</h1>`)},
+	"pages/@abc.com/index.html": {Data: []byte(`<h1>ABC</h1>`)},
+}
+
+func TestBuild(t *testing.T) {
+	app := xun.New(xun.WithMux(http.NewServeMux()), xun.WithFsys(fsys))
+	defer app.Close()
+
+	idx, err := Build(app, WithExclude("admin/*"), WithExcerpt(40), WithContent())
+	require.NoError(t, err)
+
+	require.Equal(t, []*Document{
+		{URL: "/docs/routing", Title: "Routing - Docs", Headings: []string{"Routing", "Groups", "Middlewares"},
+			Excerpt: "How to register routes and groups.", Content: "Routing Groups Groups share middlewares. Middlewares Skipped"},
+		{URL: "/docs/views", Title: "Views", Headings: []string{"Views"},
+			Excerpt: "Views are rendered by the routing…", Content: "Views Views are rendered by the routing viewers, and can be html or json or xml or text templates."},
+		{URL: "/", Title: "Home - Docs", Headings: []string{"Welcome"},
+			Excerpt: "Xun is a web framework built on…", Content: "Welcome Xun is a web framework built on net/http ."},
+	}, idx.Documents)
+	require.Len(t, idx.Search("skipped"), 1)
+
+	idx, err = Build(app, WithHost("abc.com"))
+	require.NoError(t, err)
+	require.Equal(t, []*Document{{URL: "/", Title: "ABC", Headings: []string{"ABC"}}}, idx.Documents)
+}
+
+func TestSearch(t *testing.T) {
+	app := xun.New(xun.WithMux(http.NewServeMux()), xun.WithFsys(fsys))
+	defer app.Close()
+
+	idx, err := Build(app)
+	require.NoError(t, err)
+
+	urls := func(docs []*Document) []string {
+		items := make([]string, 0, len(docs))
+		for _, d := range docs {
+			items = append(items, d.URL)
+		}
+		return items
+	}
+
+	require.Equal(t, []string{"/docs/routing", "/docs/views"}, urls(idx.Search("ROUTING")))
+	require.Equal(t, []string{"/docs/routing"}, urls(idx.Search("routing groups")))
+	require.Empty(t, idx.Search("routing welcome"))
+	require.Empty(t, idx.Search("  "))
+
+	// content is searched with WithContent only
+	require.Empty(t, idx.Search("skipped"))
+
+	var buf bytes.Buffer
+	require.NoError(t, idx.WriteJSON(&buf))
+
+	loaded, err := Load(&buf, WithLimit(1))
+	require.NoError(t, err)
+	require.Equal(t, idx.Documents, loaded.Documents)
+	require.Equal(t, []string{"/docs/routing", "/docs/views"}, urls(loaded.Search("routing")))
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	server := xun.New(xun.WithMux(mux))
+	defer server.Close()
+	loaded.Register(server)
+	server.Start()
+
+	resp, err := http.Get(srv.URL + "/search?q=routing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var result Result
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Equal(t, "routing", result.Query)
+	require.Equal(t, []string{"/docs/routing"}, urls(result.Documents))
+
+	resp, err = http.Get(srv.URL + "/search?q=nothing")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&result))
+	require.Empty(t, result.Documents)
+
+	resp, err = http.Get(srv.URL + "/search.json")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	var docs []*Document
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&docs))
+	require.Equal(t, idx.Documents, docs)
+}
@@ -0,0 +1,29 @@
+package sentry
+
+import "net/http"
+
+// Option is a function type that takes a pointer to Reporter as an argument.
+// It is used to configure the Reporter with various options.
+type Option func(*Reporter)
+
+// WithEnvironment sets the environment of events, e.g. "production" or "staging".
+func WithEnvironment(env string) Option {
+	return func(r *Reporter) {
+		r.environment = env
+	}
+}
+
+// WithRelease sets the release version of events, e.g. a git commit hash.
+func WithRelease(release string) Option {
+	return func(r *Reporter) {
+		r.release = release
+	}
+}
+
+// WithClient sets the http.Client that events are sent with.
+// If not set, it will use a client with a 10 seconds timeout.
+func WithClient(c *http.Client) Option {
+	return func(r *Reporter) {
+		r.client = c
+	}
+}
@@ -0,0 +1,185 @@
+// Package sentry provides a xun.Reporter that sends unhandled errors and panics
+// to Sentry, or any service compatible with the Sentry envelope API, without
+// depending on the Sentry SDK.
+package sentry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// ErrInvalidDSN is returned by New if the DSN is not in `{scheme}://{key}@{host}/{project}` format.
+var ErrInvalidDSN = errors.New("sentry: invalid_dsn")
+
+// Reporter is a xun.Reporter that sends error reports to Sentry asynchronously.
+type Reporter struct {
+	dsn      string
+	endpoint string
+	auth     string
+
+	client      *http.Client
+	environment string
+	release     string
+
+	wg sync.WaitGroup
+}
+
+// New creates a Reporter from the DSN of a Sentry project,
+// e.g. `https://{key}@o0.ingest.sentry.io/{project}`.
+func New(dsn string, opts ...Option) (*Reporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, ErrInvalidDSN
+	}
+
+	project := strings.Trim(u.Path, "/")
+	if u.User == nil || u.User.Username() == "" || u.Host == "" || project == "" {
+		return nil, ErrInvalidDSN
+	}
+
+	r := &Reporter{
+		dsn:      dsn,
+		endpoint: u.Scheme + "://" + u.Host + "/api/" + project + "/envelope/",
+		auth:     "Sentry sentry_version=7, sentry_client=xun-sentry/1.0, sentry_key=" + u.User.Username(),
+		client:   &http.Client{Timeout: 10 * time.Second},
+	}
+
+	for _, o := range opts {
+		o(r)
+	}
+
+	return r, nil
+}
+
+// Report implements the xun.Reporter interface. The event is sent in background.
+func (r *Reporter) Report(e *xun.ErrorReport) {
+	buf, err := r.envelope(e)
+	if err != nil {
+		return
+	}
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(buf))
+		if err != nil {
+			return
+		}
+		req.Header.Set("Content-Type", "application/x-sentry-envelope")
+		req.Header.Set("X-Sentry-Auth", r.auth)
+
+		resp, err := r.client.Do(req)
+		if err != nil {
+			return
+		}
+		resp.Body.Close()
+	}()
+}
+
+// Wait blocks until all pending events are sent. It should be called before the app exits.
+func (r *Reporter) Wait() {
+	r.wg.Wait()
+}
+
+// envelope builds a Sentry envelope with a single event item.
+func (r *Reporter) envelope(e *xun.ErrorReport) ([]byte, error) {
+	id := make([]byte, 16)
+	rand.Read(id) // nolint: errcheck
+
+	ev := event{
+		EventID:     hex.EncodeToString(id),
+		Timestamp:   time.Now().UTC().Format(time.RFC3339Nano),
+		Level:       "error",
+		Platform:    "go",
+		Environment: r.environment,
+		Release:     r.release,
+		Transaction: e.Route,
+		Tags:        map[string]string{"logid": e.LogID},
+		Extra:       map[string]any{"stack": string(e.Stack)},
+	}
+
+	var ex exception
+	if e.Err != nil {
+		ex.Type = fmt.Sprintf("%T", e.Err)
+		ex.Value = e.Err.Error()
+	} else {
+		ex.Type = "panic"
+		ex.Value = fmt.Sprint(e.Panic)
+	}
+	ev.Exception.Values = []exception{ex}
+
+	if e.User != nil {
+		ev.User = &user{ID: fmt.Sprint(e.User)}
+	}
+
+	if e.Request != nil {
+		u := *e.Request.URL
+		u.RawQuery = e.Redactor.Query(u.RawQuery)
+
+		ev.Request = &request{
+			URL:         u.String(),
+			Method:      e.Request.Method,
+			QueryString: u.RawQuery,
+			Headers:     map[string]string{"User-Agent": e.Request.UserAgent()},
+		}
+	}
+
+	payload, err := json.Marshal(ev)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.Encode(map[string]string{"event_id": ev.EventID, "dsn": r.dsn, "sent_at": ev.Timestamp}) // nolint: errcheck
+	enc.Encode(map[string]any{"type": "event", "length": len(payload)})                          // nolint: errcheck
+	buf.Write(payload)
+	buf.WriteByte('\n')
+
+	return buf.Bytes(), nil
+}
+
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Level       string            `json:"level"`
+	Platform    string            `json:"platform"`
+	Environment string            `json:"environment,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Extra       map[string]any    `json:"extra,omitempty"`
+	User        *user             `json:"user,omitempty"`
+	Request     *request          `json:"request,omitempty"`
+	Exception   struct {
+		Values []exception `json:"values"`
+	} `json:"exception"`
+}
+
+type exception struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type user struct {
+	ID string `json:"id"`
+}
+
+type request struct {
+	URL         string            `json:"url"`
+	Method      string            `json:"method"`
+	QueryString string            `json:"query_string,omitempty"`
+	Headers     map[string]string `json:"headers,omitempty"`
+}
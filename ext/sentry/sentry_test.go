@@ -0,0 +1,106 @@
+package sentry
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestNew(t *testing.T) {
+	_, err := New("https://o0.ingest.sentry.io/1")
+	require.ErrorIs(t, err, ErrInvalidDSN)
+
+	_, err = New("https://key@o0.ingest.sentry.io")
+	require.ErrorIs(t, err, ErrInvalidDSN)
+
+	r, err := New("https://key@o0.ingest.sentry.io/42")
+	require.NoError(t, err)
+	require.Equal(t, "https://o0.ingest.sentry.io/api/42/envelope/", r.endpoint)
+}
+
+func TestReporter(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		auth   string
+		path   string
+		events []map[string]any
+	)
+
+	sentry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		auth = req.Header.Get("X-Sentry-Auth")
+		path = req.URL.Path
+
+		s := bufio.NewScanner(req.Body)
+		var lines []string
+		for s.Scan() {
+			lines = append(lines, s.Text())
+		}
+		require.Len(t, lines, 3)
+
+		var ev map[string]any
+		require.NoError(t, json.Unmarshal([]byte(lines[2]), &ev))
+		events = append(events, ev)
+	}))
+	defer sentry.Close()
+
+	r, err := New(strings.Replace(sentry.URL, "://", "://key@", 1)+"/7", WithEnvironment("test"), WithRelease("v1"))
+	require.NoError(t, err)
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithErrorReporter(r))
+	app.Use(func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			c.Set(xun.UserKey, 1001)
+			return next(c)
+		}
+	})
+	app.Get("/error", func(c *xun.Context) error {
+		return errors.New("db: connection refused")
+	}, xun.WithRedact("token"))
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := http.Get(srv.URL + "/error?page=1&token=secret")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	r.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	require.Equal(t, "/api/7/envelope/", path)
+	require.Contains(t, auth, "sentry_key=key")
+	require.Len(t, events, 1)
+
+	ev := events[0]
+	require.Equal(t, "GET /error", ev["transaction"])
+	require.Equal(t, "test", ev["environment"])
+	require.Equal(t, "v1", ev["release"])
+	require.Equal(t, map[string]any{"id": "1001"}, ev["user"])
+	require.Equal(t, resp.Header.Get("X-Log-Id"), ev["tags"].(map[string]any)["logid"])
+
+	ex := ev["exception"].(map[string]any)["values"].([]any)[0].(map[string]any)
+	require.Equal(t, "db: connection refused", ex["value"])
+
+	req := ev["request"].(map[string]any)
+	require.Equal(t, "GET", req["method"])
+	require.Equal(t, "page=1&token=%5BREDACTED%5D", req["query_string"])
+	require.NotContains(t, req["url"], "secret")
+}
@@ -0,0 +1,45 @@
+package sessions
+
+import "time"
+
+// Option is a function type that takes a pointer to Manager as an argument.
+// It is used to configure the Manager with various options.
+type Option func(*Manager)
+
+// WithStore sets the Store of sessions. MemoryStore is used by default.
+func WithStore(s Store) Option {
+	return func(m *Manager) {
+		m.store = s
+	}
+}
+
+// WithCookie sets the name of the session cookie. The default name is "sid".
+func WithCookie(name string) Option {
+	return func(m *Manager) {
+		m.cookie = name
+	}
+}
+
+// WithMaxAge sets how long a session lasts after it is created. The default is 14 days.
+func WithMaxAge(d time.Duration) Option {
+	return func(m *Manager) {
+		m.maxAge = d
+	}
+}
+
+// WithTouchInterval sets how often the LastSeen of a session is saved. The
+// default is 1 minute.
+func WithTouchInterval(d time.Duration) Option {
+	return func(m *Manager) {
+		m.touchInterval = d
+	}
+}
+
+// WithRemember sets the RememberStore of remember-me tokens, and how long they
+// last. MemoryRememberStore and 30 days are used by default.
+func WithRemember(s RememberStore, maxAge time.Duration) Option {
+	return func(m *Manager) {
+		m.remember = s
+		m.rememberMaxAge = maxAge
+	}
+}
@@ -0,0 +1,289 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yaitoo/xun"
+	"github.com/yaitoo/xun/ext/auth"
+)
+
+// RememberToken is a persistent login of a user on a device. The cookie is
+// `<series>.<token>`: the series stays the same for the device, and the token
+// is rotated each time it is used to log in.
+type RememberToken struct {
+	Series    string
+	UserID    string
+	TokenHash string
+	// PrevHash is the hash of the previous token. It is still accepted within
+	// a grace period after the rotation, for concurrent requests of the device.
+	PrevHash  string
+	RotatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// RememberStore keeps the persistent login tokens.
+type RememberStore interface {
+	// Get returns the token of series, or ErrNotFound.
+	Get(ctx context.Context, series string) (*RememberToken, error)
+	// Save creates or updates the token.
+	Save(ctx context.Context, t *RememberToken) error
+	// Rotate updates the token if the TokenHash of its series is still oldHash,
+	// atomically, e.g. by compare-and-swap. Otherwise, it returns ErrRotated,
+	// or ErrNotFound if the series doesn't exist.
+	Rotate(ctx context.Context, oldHash string, t *RememberToken) error
+	// Delete deletes the token of series. It is not an error if it doesn't exist.
+	Delete(ctx context.Context, series string) error
+	// ForUser returns the tokens of the user.
+	ForUser(ctx context.Context, userID string) ([]*RememberToken, error)
+}
+
+// rememberGrace is how long the previous token of a series is still accepted.
+const rememberGrace = 30 * time.Second
+
+// Remember issues a persistent login token for the current session, e.g. if
+// "remember me" is checked on login. Once the session expires, the token logs
+// the user in with a new session, and it is rotated.
+//
+// If a stolen token is used, the real device presents the old token of the
+// series later. It is detected as theft, and all sessions and tokens of the
+// user are revoked.
+func (m *Manager) Remember(c *xun.Context) error {
+	s := Current(c)
+	if s == nil {
+		return nil
+	}
+
+	token, series, hash, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	t := &RememberToken{
+		Series:    series,
+		UserID:    s.UserID,
+		TokenHash: hash,
+		RotatedAt: now,
+		ExpiresAt: now.Add(m.rememberMaxAge),
+	}
+
+	if err := m.remember.Save(c.Request().Context(), t); err != nil {
+		return err
+	}
+
+	m.setCookie(c, m.rememberCookie, token, t.ExpiresAt)
+	return nil
+}
+
+// recall logs the user in by the remember-me cookie, if the request has no session.
+func (m *Manager) recall(c *xun.Context) error {
+	cookie, err := c.Request().Cookie(m.rememberCookie)
+	if err != nil || cookie.Value == "" {
+		return nil
+	}
+
+	ctx := c.Request().Context()
+
+	series, hash, err := auth.ParseToken(cookie.Value)
+	if err != nil {
+		m.clearCookie(c, m.rememberCookie)
+		return nil
+	}
+
+	t, err := m.remember.Get(ctx, series)
+	if errors.Is(err, ErrNotFound) {
+		m.clearCookie(c, m.rememberCookie)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	now := m.now()
+	if now.After(t.ExpiresAt) {
+		m.clearCookie(c, m.rememberCookie)
+		return m.remember.Delete(ctx, series)
+	}
+
+	switch {
+	case auth.VerifyToken(hash, t.TokenHash):
+		validator, err := m.rotate(ctx, t, now)
+		if errors.Is(err, ErrRotated) {
+			// a concurrent request of the device rotated it first, and sets the new token.
+			break
+		}
+		if err != nil {
+			return err
+		}
+		m.setCookie(c, m.rememberCookie, series+"."+validator, t.ExpiresAt)
+	case t.PrevHash != "" && now.Sub(t.RotatedAt) < rememberGrace && auth.VerifyToken(hash, t.PrevHash):
+		// a concurrent request of the device, the new token is set by the first one.
+	default:
+		m.clearCookie(c, m.rememberCookie)
+		_, err := m.RevokeUser(ctx, t.UserID)
+		return err
+	}
+
+	_, err = m.Login(c, t.UserID)
+	return err
+}
+
+// rotate replaces the token of the series, and returns the new validator for the cookie.
+func (m *Manager) rotate(ctx context.Context, t *RememberToken, now time.Time) (string, error) {
+	token, _, hash, err := auth.NewToken()
+	if err != nil {
+		return "", err
+	}
+
+	oldHash := t.TokenHash
+	t.PrevHash = oldHash
+	t.TokenHash = hash
+	t.RotatedAt = now
+
+	if err := m.remember.Rotate(ctx, oldHash, t); err != nil {
+		return "", err
+	}
+
+	_, validator, _ := strings.Cut(token, ".")
+	return validator, nil
+}
+
+// forget deletes the remember-me token of the request, and clears its cookie.
+func (m *Manager) forget(c *xun.Context) error {
+	cookie, err := c.Request().Cookie(m.rememberCookie)
+	if err != nil {
+		return nil
+	}
+
+	m.clearCookie(c, m.rememberCookie)
+
+	series, _, err := auth.ParseToken(cookie.Value)
+	if err != nil {
+		return nil
+	}
+	return m.remember.Delete(c.Request().Context(), series)
+}
+
+// currentSeries returns the series of the remember-me cookie of the request.
+func (m *Manager) currentSeries(c *xun.Context) string {
+	cookie, err := c.Request().Cookie(m.rememberCookie)
+	if err != nil {
+		return ""
+	}
+	series, _, _ := auth.ParseToken(cookie.Value)
+	return series
+}
+
+// forgetUser deletes the remember-me tokens of the user except the series.
+func (m *Manager) forgetUser(ctx context.Context, userID, except string) error {
+	items, err := m.remember.ForUser(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, it := range items {
+		if it.Series == except {
+			continue
+		}
+		if err := m.remember.Delete(ctx, it.Series); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) setCookie(c *xun.Context, name, value string, expires time.Time) {
+	http.SetCookie(c.Writer(), &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		Expires:  expires,
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(c.AbsoluteURL("/"), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// MemoryRememberStore is a RememberStore in memory. It is the default
+// RememberStore, and it should only be used by a single instance app.
+type MemoryRememberStore struct {
+	mu     sync.Mutex
+	tokens map[string]*RememberToken
+}
+
+// NewMemoryRememberStore creates a MemoryRememberStore.
+func NewMemoryRememberStore() *MemoryRememberStore {
+	return &MemoryRememberStore{tokens: make(map[string]*RememberToken)}
+}
+
+// Get implements RememberStore.
+func (m *MemoryRememberStore) Get(_ context.Context, series string) (*RememberToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tokens[series]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	nt := *t
+	return &nt, nil
+}
+
+// Save implements RememberStore.
+func (m *MemoryRememberStore) Save(_ context.Context, t *RememberToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nt := *t
+	m.tokens[t.Series] = &nt
+	return nil
+}
+
+// Rotate implements RememberStore.
+func (m *MemoryRememberStore) Rotate(_ context.Context, oldHash string, t *RememberToken) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	cur, ok := m.tokens[t.Series]
+	if !ok {
+		return ErrNotFound
+	}
+	if cur.TokenHash != oldHash {
+		return ErrRotated
+	}
+
+	nt := *t
+	m.tokens[t.Series] = &nt
+	return nil
+}
+
+// Delete implements RememberStore.
+func (m *MemoryRememberStore) Delete(_ context.Context, series string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.tokens, series)
+	return nil
+}
+
+// ForUser implements RememberStore.
+func (m *MemoryRememberStore) ForUser(_ context.Context, userID string) ([]*RememberToken, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var items []*RememberToken
+	for _, t := range m.tokens {
+		if t.UserID == userID {
+			nt := *t
+			items = append(items, &nt)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,228 @@
+package sessions
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestRemember(t *testing.T) {
+	now := time.Now()
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	m := New(WithTouchInterval(0))
+	m.now = func() time.Time { return now }
+
+	app.Use(m.Middleware)
+
+	app.Post("/login/{user}", func(c *xun.Context) error {
+		if _, err := m.Login(c, c.Request().PathValue("user")); err != nil {
+			return err
+		}
+		return m.Remember(c)
+	})
+
+	app.Get("/me", func(c *xun.Context) error {
+		if Current(c) == nil {
+			c.WriteStatus(http.StatusUnauthorized)
+			return xun.ErrCancelled
+		}
+		return c.View(c.Get(xun.UserKey))
+	})
+
+	app.Post("/logout", func(c *xun.Context) error {
+		return m.Logout(c)
+	})
+
+	app.Start()
+
+	do := func(method, path string, cookies ...*http.Cookie) (int, map[string]*http.Cookie) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		set := make(map[string]*http.Cookie)
+		for _, c := range resp.Cookies() {
+			set[c.Name] = c
+		}
+		return resp.StatusCode, set
+	}
+
+	ctx := context.Background()
+
+	_, set := do(http.MethodPost, "/login/1")
+	remember := set["remember"]
+	require.NotNil(t, remember)
+	require.True(t, remember.HttpOnly)
+	require.Equal(t, http.SameSiteLaxMode, remember.SameSite)
+
+	// the session is gone, e.g. the browser is restarted
+	status, set := do(http.MethodGet, "/me", remember)
+	require.Equal(t, http.StatusOK, status)
+	require.NotNil(t, set["sid"])
+	rotated := set["remember"]
+	require.NotNil(t, rotated)
+	require.NotEqual(t, remember.Value, rotated.Value)
+
+	items, err := m.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	// a concurrent request with the previous token in the grace period
+	status, set = do(http.MethodGet, "/me", remember)
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, set["remember"])
+
+	// the series is rotated again by the device
+	now = now.Add(time.Minute)
+	status, set = do(http.MethodGet, "/me", rotated)
+	require.Equal(t, http.StatusOK, status)
+	latest := set["remember"]
+
+	// the stolen token was used: the real device presents an old token of the series
+	now = now.Add(time.Minute)
+	status, set = do(http.MethodGet, "/me", rotated)
+	require.Equal(t, http.StatusUnauthorized, status)
+	require.Equal(t, -1, set["remember"].MaxAge)
+
+	// all sessions and tokens of the user are revoked
+	items, err = m.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	status, _ = do(http.MethodGet, "/me", latest)
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	// logout forgets the token
+	_, set = do(http.MethodPost, "/login/2")
+	status, set = do(http.MethodPost, "/logout", set["sid"], set["remember"])
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, -1, set["remember"].MaxAge)
+
+	tokens, err := m.remember.ForUser(ctx, "2")
+	require.NoError(t, err)
+	require.Empty(t, tokens)
+
+	// expired
+	_, set = do(http.MethodPost, "/login/3")
+	now = now.Add(31 * 24 * time.Hour)
+	status, _ = do(http.MethodGet, "/me", set["remember"])
+	require.Equal(t, http.StatusUnauthorized, status)
+}
+
+// barrierRememberStore lets n requests read the token before any of them rotates it.
+type barrierRememberStore struct {
+	*MemoryRememberStore
+	wg sync.WaitGroup
+}
+
+func (s *barrierRememberStore) Get(ctx context.Context, series string) (*RememberToken, error) {
+	t, err := s.MemoryRememberStore.Get(ctx, series)
+	s.wg.Done()
+	s.wg.Wait()
+	return t, err
+}
+
+func TestRememberConcurrent(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	store := &barrierRememberStore{MemoryRememberStore: NewMemoryRememberStore()}
+	m := New(WithTouchInterval(0), WithRemember(store, time.Hour))
+
+	app.Use(m.Middleware)
+
+	app.Post("/login", func(c *xun.Context) error {
+		if _, err := m.Login(c, "1"); err != nil {
+			return err
+		}
+		return m.Remember(c)
+	})
+
+	app.Get("/me", func(c *xun.Context) error {
+		if Current(c) == nil {
+			c.WriteStatus(http.StatusUnauthorized)
+			return xun.ErrCancelled
+		}
+		return c.View(c.Get(xun.UserKey))
+	})
+
+	do := func(method, path string, cookie *http.Cookie) (int, *http.Cookie) {
+		req, err := http.NewRequest(method, srv.URL+path, nil)
+		require.NoError(t, err)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		for _, c := range resp.Cookies() {
+			if c.Name == "remember" {
+				return resp.StatusCode, c
+			}
+		}
+		return resp.StatusCode, nil
+	}
+
+	_, remember := do(http.MethodPost, "/login", nil)
+	require.NotNil(t, remember)
+
+	// two requests of the browser with the same token, e.g. after a restart
+	store.wg.Add(2)
+	var wg sync.WaitGroup
+	statuses := make([]int, 2)
+	cookies := make([]*http.Cookie, 2)
+	for i := range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			statuses[i], cookies[i] = do(http.MethodGet, "/me", remember)
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, []int{http.StatusOK, http.StatusOK}, statuses)
+
+	// only one of them rotates the token, and sets the new cookie
+	var rotated *http.Cookie
+	for _, c := range cookies {
+		if c != nil {
+			require.Nil(t, rotated)
+			rotated = c
+		}
+	}
+	require.NotNil(t, rotated)
+
+	// the rotated token still logs the user in, it isn't taken as theft
+	store.wg.Add(1)
+	status, _ := do(http.MethodGet, "/me", rotated)
+	require.Equal(t, http.StatusOK, status)
+
+	items, err := m.ForUser(context.Background(), "1")
+	require.NoError(t, err)
+	require.NotEmpty(t, items)
+}
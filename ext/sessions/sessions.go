@@ -0,0 +1,272 @@
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// SessionKey is the key of the current session in the Context values.
+const SessionKey = "session"
+
+// Session is a login session of a user on a device.
+type Session struct {
+	// ID identifies the session. It is the hash of the session cookie, so it can
+	// be shown to the user and used to revoke the session without leaking it.
+	ID     string
+	UserID string
+	Values map[string]any
+
+	CreatedAt time.Time
+	LastSeen  time.Time
+	ExpiresAt time.Time
+	UserAgent string
+	IP        string
+}
+
+// Expired reports whether the session is expired at now.
+func (s *Session) Expired(now time.Time) bool {
+	return !s.ExpiresAt.IsZero() && now.After(s.ExpiresAt)
+}
+
+func (s *Session) clone() *Session {
+	ns := *s
+	ns.Values = maps.Clone(s.Values)
+	return &ns
+}
+
+// Manager manages the cookie sessions of users, and lists and revokes them,
+// e.g. for "log out other devices".
+type Manager struct {
+	store         Store
+	cookie        string
+	maxAge        time.Duration
+	touchInterval time.Duration
+
+	remember       RememberStore
+	rememberCookie string
+	rememberMaxAge time.Duration
+
+	now func() time.Time
+}
+
+// New creates a session Manager.
+func New(opts ...Option) *Manager {
+	m := &Manager{
+		cookie:        "sid",
+		maxAge:        14 * 24 * time.Hour,
+		touchInterval: time.Minute,
+
+		rememberCookie: "remember",
+		rememberMaxAge: 30 * 24 * time.Hour,
+
+		now: time.Now,
+	}
+
+	for _, o := range opts {
+		o(m)
+	}
+
+	if m.store == nil {
+		m.store = NewMemoryStore()
+	}
+
+	if m.remember == nil {
+		m.remember = NewMemoryRememberStore()
+	}
+
+	return m
+}
+
+// Current returns the session of the request, or nil if the user isn't logged in.
+func Current(c *xun.Context) *Session {
+	s, _ := c.Get(SessionKey).(*Session)
+	return s
+}
+
+// Middleware loads the session of the request cookie. The session is set with
+// c.Set(sessions.SessionKey, s), and its UserID with c.Set(xun.UserKey, id).
+// The LastSeen of the session is saved once per touch interval.
+//
+// If the request has no session, the user is logged in by the remember-me
+// cookie issued by Remember.
+func (m *Manager) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		cookie, err := c.Request().Cookie(m.cookie)
+		if err != nil || cookie.Value == "" {
+			if err := m.recall(c); err != nil {
+				return err
+			}
+			return next(c)
+		}
+
+		ctx := c.Request().Context()
+
+		s, err := m.store.Get(ctx, hashToken(cookie.Value))
+		if errors.Is(err, ErrNotFound) {
+			m.clearCookie(c, m.cookie)
+			if err := m.recall(c); err != nil {
+				return err
+			}
+			return next(c)
+		}
+		if err != nil {
+			return err
+		}
+
+		if now := m.now(); now.Sub(s.LastSeen) >= m.touchInterval {
+			s.LastSeen = now
+			s.IP = c.ClientIP()
+			if err := m.store.Save(ctx, s); err != nil {
+				return err
+			}
+		}
+
+		c.Set(SessionKey, s)
+		c.Set(xun.UserKey, s.UserID)
+
+		return next(c)
+	}
+}
+
+// Login creates a new session of the user, and sets its cookie. The current
+// session is replaced to prevent session fixation.
+func (m *Manager) Login(c *xun.Context, userID string) (*Session, error) {
+	ctx := c.Request().Context()
+
+	if cur := Current(c); cur != nil {
+		if err := m.store.Delete(ctx, cur.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+
+	now := m.now()
+	s := &Session{
+		ID:        hashToken(token),
+		UserID:    userID,
+		CreatedAt: now,
+		LastSeen:  now,
+		ExpiresAt: now.Add(m.maxAge),
+		UserAgent: c.Request().UserAgent(),
+		IP:        c.ClientIP(),
+	}
+
+	if err := m.store.Save(ctx, s); err != nil {
+		return nil, err
+	}
+
+	m.setCookie(c, m.cookie, token, s.ExpiresAt)
+
+	c.Set(SessionKey, s)
+	c.Set(xun.UserKey, userID)
+
+	return s, nil
+}
+
+// Logout deletes the current session and remember-me token, and clears their cookies.
+func (m *Manager) Logout(c *xun.Context) error {
+	if s := Current(c); s != nil {
+		if err := m.store.Delete(c.Request().Context(), s.ID); err != nil {
+			return err
+		}
+	}
+
+	if err := m.forget(c); err != nil {
+		return err
+	}
+
+	m.clearCookie(c, m.cookie)
+	c.Set(SessionKey, nil)
+	c.Set(xun.UserKey, nil)
+	return nil
+}
+
+// Save saves the changes of the current session, e.g. its Values.
+func (m *Manager) Save(c *xun.Context) error {
+	s := Current(c)
+	if s == nil {
+		return nil
+	}
+	return m.store.Save(c.Request().Context(), s)
+}
+
+// ForUser returns the active sessions of the user, e.g. to list the devices
+// the user is logged in on.
+func (m *Manager) ForUser(ctx context.Context, userID string) ([]*Session, error) {
+	return m.store.ForUser(ctx, userID)
+}
+
+// Revoke deletes the session of id, so its device is logged out on the next request.
+func (m *Manager) Revoke(ctx context.Context, sessionID string) error {
+	return m.store.Delete(ctx, sessionID)
+}
+
+// RevokeUser deletes all sessions and remember-me tokens of the user, e.g.
+// after a password change. It returns the number of revoked sessions.
+func (m *Manager) RevokeUser(ctx context.Context, userID string) (int, error) {
+	if err := m.forgetUser(ctx, userID, ""); err != nil {
+		return 0, err
+	}
+	return m.revoke(ctx, userID, "")
+}
+
+// RevokeOthers deletes all sessions and remember-me tokens of the current user
+// except the current ones, for "log out other devices". It returns the number of revoked sessions.
+func (m *Manager) RevokeOthers(c *xun.Context) (int, error) {
+	s := Current(c)
+	if s == nil {
+		return 0, nil
+	}
+
+	if err := m.forgetUser(c.Request().Context(), s.UserID, m.currentSeries(c)); err != nil {
+		return 0, err
+	}
+	return m.revoke(c.Request().Context(), s.UserID, s.ID)
+}
+
+func (m *Manager) revoke(ctx context.Context, userID, except string) (int, error) {
+	items, err := m.store.ForUser(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, it := range items {
+		if it.ID == except {
+			continue
+		}
+		if err := m.store.Delete(ctx, it.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	return n, nil
+}
+
+func (m *Manager) clearCookie(c *xun.Context, name string) {
+	http.SetCookie(c.Writer(), &http.Cookie{
+		Name:     name,
+		Path:     "/",
+		MaxAge:   -1,
+		HttpOnly: true,
+	})
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,173 @@
+package sessions
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestManager(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	m := New(WithCookie("session_id"), WithTouchInterval(0))
+
+	app.Use(m.Middleware)
+
+	app.Post("/login/{user}", func(c *xun.Context) error {
+		s, err := m.Login(c, c.Request().PathValue("user"))
+		if err != nil {
+			return err
+		}
+		s.Values = map[string]any{"theme": "dark"}
+		if err := m.Save(c); err != nil {
+			return err
+		}
+		return c.View(s.ID)
+	})
+
+	app.Get("/me", func(c *xun.Context) error {
+		s := Current(c)
+		if s == nil {
+			c.WriteStatus(http.StatusUnauthorized)
+			return xun.ErrCancelled
+		}
+		return c.View(map[string]any{"user": c.Get(xun.UserKey), "theme": s.Values["theme"]})
+	})
+
+	app.Post("/logout", func(c *xun.Context) error {
+		return m.Logout(c)
+	})
+
+	app.Post("/sessions/others", func(c *xun.Context) error {
+		n, err := m.RevokeOthers(c)
+		if err != nil {
+			return err
+		}
+		return c.View(n)
+	})
+
+	app.Start()
+
+	newDevice := func(ua string) func(method, path string) (int, string) {
+		jar, err := cookiejar.New(nil)
+		require.NoError(t, err)
+		client := &http.Client{Jar: jar}
+
+		return func(method, path string) (int, string) {
+			req, err := http.NewRequest(method, srv.URL+path, nil)
+			require.NoError(t, err)
+			req.Header.Set("User-Agent", ua)
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			return resp.StatusCode, string(buf)
+		}
+	}
+
+	laptop := newDevice("laptop")
+	phone := newDevice("phone")
+	tablet := newDevice("tablet")
+
+	status, _ := laptop(http.MethodGet, "/me")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	_, laptopID := laptop(http.MethodPost, "/login/1")
+	_, phoneID := phone(http.MethodPost, "/login/1")
+	tablet(http.MethodPost, "/login/2")
+
+	ctx := context.Background()
+
+	items, err := m.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, `"`+items[0].ID+"\"\n", phoneID) // the latest one is first
+	require.Equal(t, "phone", items[0].UserAgent)
+	require.Equal(t, "127.0.0.1", items[0].IP)
+	require.Equal(t, `"`+items[1].ID+"\"\n", laptopID)
+
+	status, body := laptop(http.MethodGet, "/me")
+	require.Equal(t, http.StatusOK, status)
+	require.JSONEq(t, `{"user":"1","theme":"dark"}`, body)
+
+	// log out other devices
+	status, body = laptop(http.MethodPost, "/sessions/others")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "1\n", body)
+
+	status, _ = phone(http.MethodGet, "/me")
+	require.Equal(t, http.StatusUnauthorized, status)
+	status, _ = laptop(http.MethodGet, "/me")
+	require.Equal(t, http.StatusOK, status)
+
+	// login again replaces the current session
+	laptop(http.MethodPost, "/login/1")
+	items, err = m.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	// revoke by id
+	items, err = m.ForUser(ctx, "2")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.NoError(t, m.Revoke(ctx, items[0].ID))
+	status, _ = tablet(http.MethodGet, "/me")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	laptop(http.MethodPost, "/logout")
+	status, _ = laptop(http.MethodGet, "/me")
+	require.Equal(t, http.StatusUnauthorized, status)
+
+	n, err := m.RevokeUser(ctx, "1")
+	require.NoError(t, err)
+	require.Zero(t, n)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	require.NoError(t, s.Save(ctx, &Session{ID: "a", UserID: "1", LastSeen: now, ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, s.Save(ctx, &Session{ID: "b", UserID: "1", LastSeen: now, ExpiresAt: now.Add(-time.Second)}))
+
+	_, err := s.Get(ctx, "b")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	items, err := s.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	// sessions are copied
+	items[0].UserID = "2"
+	it, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, "1", it.UserID)
+
+	require.NoError(t, s.Save(ctx, items[0]))
+	items, err = s.ForUser(ctx, "1")
+	require.NoError(t, err)
+	require.Empty(t, items)
+	items, err = s.ForUser(ctx, "2")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	require.NoError(t, s.Delete(ctx, "a"))
+	require.NoError(t, s.Delete(ctx, "a"))
+	require.Empty(t, s.users)
+}
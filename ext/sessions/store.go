@@ -0,0 +1,139 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned by Store.Get if the session doesn't exist or is expired.
+var ErrNotFound = errors.New("sessions: not_found")
+
+// ErrRotated is returned by RememberStore.Rotate if the token of the series is
+// already rotated, e.g. by a concurrent request of the device.
+var ErrRotated = errors.New("sessions: token_rotated")
+
+// Store keeps sessions, and indexes them by user for listing and revocation.
+// It can be implemented by Redis or a database to share sessions by all
+// instances of the app.
+type Store interface {
+	// Get returns the session of id, or ErrNotFound.
+	Get(ctx context.Context, id string) (*Session, error)
+	// Save creates or updates the session.
+	Save(ctx context.Context, s *Session) error
+	// Delete deletes the session of id. It is not an error if it doesn't exist.
+	Delete(ctx context.Context, id string) error
+	// ForUser returns the active sessions of the user.
+	ForUser(ctx context.Context, userID string) ([]*Session, error)
+}
+
+// MemoryStore is a Store in memory. It is the default Store, and it should only
+// be used by a single instance app.
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]*Session
+	users    map[string]map[string]struct{}
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]*Session),
+		users:    make(map[string]map[string]struct{}),
+	}
+}
+
+// Get implements Store.
+func (m *MemoryStore) Get(_ context.Context, id string) (*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	s, ok := m.sessions[id]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	if s.Expired(time.Now()) {
+		m.delete(id)
+		return nil, ErrNotFound
+	}
+
+	return s.clone(), nil
+}
+
+// Save implements Store.
+func (m *MemoryStore) Save(_ context.Context, s *Session) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if old, ok := m.sessions[s.ID]; ok && old.UserID != s.UserID {
+		m.unindex(old.UserID, s.ID)
+	}
+
+	m.sessions[s.ID] = s.clone()
+
+	if s.UserID != "" {
+		ids, ok := m.users[s.UserID]
+		if !ok {
+			ids = make(map[string]struct{})
+			m.users[s.UserID] = ids
+		}
+		ids[s.ID] = struct{}{}
+	}
+
+	return nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.delete(id)
+	return nil
+}
+
+func (m *MemoryStore) delete(id string) {
+	s, ok := m.sessions[id]
+	if !ok {
+		return
+	}
+
+	delete(m.sessions, id)
+	m.unindex(s.UserID, id)
+}
+
+func (m *MemoryStore) unindex(userID, id string) {
+	if ids, ok := m.users[userID]; ok {
+		delete(ids, id)
+		if len(ids) == 0 {
+			delete(m.users, userID)
+		}
+	}
+}
+
+// ForUser implements Store. Sessions are sorted by LastSeen in descending order.
+func (m *MemoryStore) ForUser(_ context.Context, userID string) ([]*Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	var items []*Session
+	for id := range m.users[userID] {
+		s := m.sessions[id]
+		if s.Expired(now) {
+			m.delete(id)
+			continue
+		}
+		items = append(items, s.clone())
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].LastSeen.After(items[j].LastSeen)
+	})
+
+	return items, nil
+}
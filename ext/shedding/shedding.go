@@ -0,0 +1,74 @@
+package shedding
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// MaxInFlight returns a middleware that limits the number of requests being
+// processed concurrently.
+//
+// At most n requests are handled at the same time. Up to queue requests wait
+// for a free slot for at most timeout. Requests that can't be queued, or time
+// out while waiting, are rejected with 503 Service Unavailable and a
+// Retry-After header, so overload spikes don't pile up template rendering.
+func MaxInFlight(n, queue int, timeout time.Duration) xun.Middleware {
+	if n < 1 {
+		n = 1
+	}
+
+	if queue < 0 {
+		queue = 0
+	}
+
+	slots := make(chan struct{}, n)
+	waiting := make(chan struct{}, queue)
+
+	retryAfter := strconv.Itoa(int(timeout.Round(time.Second) / time.Second))
+	if retryAfter == "0" {
+		retryAfter = "1"
+	}
+
+	reject := func(c *xun.Context) error {
+		c.WriteHeader("Retry-After", retryAfter)
+		c.WriteStatus(http.StatusServiceUnavailable)
+		return xun.ErrCancelled
+	}
+
+	return func(next xun.HandleFunc) xun.HandleFunc {
+		return func(c *xun.Context) error {
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+				return next(c)
+			default:
+			}
+
+			select {
+			case waiting <- struct{}{}:
+			default:
+				// queue is full
+				return reject(c)
+			}
+
+			t := time.NewTimer(timeout)
+			defer t.Stop()
+
+			select {
+			case slots <- struct{}{}:
+				<-waiting
+				defer func() { <-slots }()
+				return next(c)
+			case <-t.C:
+				<-waiting
+				return reject(c)
+			case <-c.Request().Context().Done():
+				<-waiting
+				return xun.ErrCancelled
+			}
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package shedding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestMaxInFlight(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+
+	started := make(chan struct{}, 3)
+	release := make(chan struct{})
+
+	app.Use(MaxInFlight(1, 1, 200*time.Millisecond))
+
+	app.Get("/slow", func(c *xun.Context) error {
+		started <- struct{}{}
+		<-release
+		return c.View(nil)
+	})
+
+	app.Get("/fast", func(c *xun.Context) error {
+		return c.View(nil)
+	})
+
+	go app.Start()
+	defer app.Close()
+
+	client := &http.Client{}
+
+	first := make(chan int, 1)
+	go func() {
+		resp, err := client.Get(srv.URL + "/slow")
+		if err != nil {
+			first <- 0
+			return
+		}
+		resp.Body.Close()
+		first <- resp.StatusCode
+	}()
+
+	<-started
+
+	t.Run("queued_request_times_out", func(t *testing.T) {
+		now := time.Now()
+		resp, err := client.Get(srv.URL + "/fast")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get("Retry-After"))
+		require.GreaterOrEqual(t, time.Since(now), 200*time.Millisecond)
+	})
+
+	t.Run("full_queue_is_rejected", func(t *testing.T) {
+		queued := make(chan int, 1)
+		go func() {
+			resp, err := client.Get(srv.URL + "/fast")
+			if err != nil {
+				queued <- 0
+				return
+			}
+			resp.Body.Close()
+			queued <- resp.StatusCode
+		}()
+
+		// wait for the request above to enter the queue
+		time.Sleep(50 * time.Millisecond)
+
+		resp, err := client.Get(srv.URL + "/fast")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		close(release)
+		require.Equal(t, http.StatusOK, <-queued)
+	})
+
+	require.Equal(t, http.StatusOK, <-first)
+
+	resp, err := client.Get(srv.URL + "/fast")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
@@ -0,0 +1,27 @@
+package taxonomy
+
+// Option is a function type that takes a pointer to Taxonomies as an argument.
+// It is used to configure the Taxonomies with various options.
+type Option func(*Taxonomies)
+
+// WithTaxonomies sets the front matter keys of taxonomies, e.g.
+// WithTaxonomies("tags", "series"). They are "tags" and "categories" by default.
+func WithTaxonomies(names ...string) Option {
+	return func(t *Taxonomies) {
+		t.names = names
+	}
+}
+
+// WithPageSize sets the number of pages per listing page and feed. It is 10 by default.
+func WithPageSize(n int) Option {
+	return func(t *Taxonomies) {
+		t.size = n
+	}
+}
+
+// WithTitle sets the site title used in the titles of feeds, e.g. "go - My Blog".
+func WithTitle(title string) Option {
+	return func(t *Taxonomies) {
+		t.title = title
+	}
+}
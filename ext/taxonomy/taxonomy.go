@@ -0,0 +1,272 @@
+// Package taxonomy groups pages by the terms of their front matter, e.g.
+// `tags: [go, web]`, and serves a listing page and an RSS feed per term.
+package taxonomy
+
+import (
+	"encoding/xml"
+	"html/template"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/yaitoo/xun"
+)
+
+// Term is a term of a taxonomy, e.g. the tag "Web Dev".
+type Term struct {
+	Name  string      // e.g. "Web Dev"
+	Slug  string      // e.g. "web-dev"
+	URL   string      // e.g. "/tags/web-dev"
+	Pages []*xun.Page // sorted by date, newest first
+}
+
+// Taxonomy is a front matter key, e.g. "tags", and its terms.
+type Taxonomy struct {
+	Name  string
+	Terms []*Term // sorted by name
+
+	slugs map[string]*Term
+}
+
+// Term returns the term of the slug, or nil if it doesn't exist.
+func (t *Taxonomy) Term(slug string) *Term {
+	return t.slugs[slug]
+}
+
+// Listing is the data of the listing page of a term.
+type Listing struct {
+	Taxonomy   string
+	Term       *Term
+	Pages      []*xun.Page
+	Pagination xun.Pagination
+}
+
+// Taxonomies groups pages by the terms of their taxonomies.
+type Taxonomies struct {
+	names []string
+	size  int
+	title string
+
+	mu    sync.RWMutex
+	items map[string]*Taxonomy
+}
+
+// New creates Taxonomies of "tags" and "categories".
+func New(opts ...Option) *Taxonomies {
+	t := &Taxonomies{
+		names: []string{"tags", "categories"},
+		size:  10,
+		items: make(map[string]*Taxonomy),
+	}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	return t
+}
+
+// FuncMap returns the template function `taxonomy`. It should be added to
+// xun.FuncMap before the App is created.
+//
+//	{{ range (taxonomy "tags").Terms }}<a href="{{ .URL }}">{{ .Name }} ({{ len .Pages }})</a>{{ end }}
+func (t *Taxonomies) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"taxonomy": t.Taxonomy,
+	}
+}
+
+// Taxonomy returns the taxonomy of the name. It has no terms if no pages are
+// grouped by it.
+func (t *Taxonomies) Taxonomy(name string) *Taxonomy {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if tx, ok := t.items[name]; ok {
+		return tx
+	}
+	return &Taxonomy{Name: name}
+}
+
+// Build groups the pages by the terms of their front matter. Pages with
+// wildcards are skipped.
+func (t *Taxonomies) Build(pages []*xun.Page) {
+	items := make(map[string]*Taxonomy, len(t.names))
+	for _, name := range t.names {
+		tx := &Taxonomy{Name: name, slugs: make(map[string]*Term)}
+
+		for _, p := range pages {
+			if p.URL == "" {
+				continue
+			}
+
+			for _, term := range p.Terms(name) {
+				s := slug(term)
+				if s == "" {
+					continue
+				}
+
+				it, ok := tx.slugs[s]
+				if !ok {
+					it = &Term{Name: term, Slug: s, URL: "/" + name + "/" + s}
+					tx.slugs[s] = it
+					tx.Terms = append(tx.Terms, it)
+				}
+				it.Pages = append(it.Pages, p)
+			}
+		}
+
+		sort.Slice(tx.Terms, func(i, j int) bool {
+			return tx.Terms[i].Slug < tx.Terms[j].Slug
+		})
+
+		for _, it := range tx.Terms {
+			sort.SliceStable(it.Pages, func(i, j int) bool {
+				return it.Pages[i].Date().After(it.Pages[j].Date())
+			})
+		}
+
+		items[name] = tx
+	}
+
+	t.mu.Lock()
+	t.items = items
+	t.mu.Unlock()
+}
+
+type site interface {
+	Get(pattern string, hf xun.HandleFunc, opts ...xun.RoutingOption)
+	Pages() []*xun.Page
+}
+
+// Register groups the pages of the app, and registers the listing pages
+// `GET /{taxonomy}/{term}?page=2` and feeds `GET /{taxonomy}/{term}/feed.xml`
+// of terms. Listing pages are rendered with the Listing by the view of the
+// taxonomy, e.g. views/tags.html, and fall back to the viewers of routes.
+func (t *Taxonomies) Register(app site, opts ...xun.RoutingOption) {
+	t.Build(app.Pages())
+
+	for _, name := range t.names {
+		app.Get("/"+name+"/{term}", t.listing(name), opts...)
+		app.Get("/"+name+"/{term}/feed.xml", t.feed(name), opts...)
+	}
+}
+
+func (t *Taxonomies) listing(name string) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		term := t.Taxonomy(name).Term(c.Request().PathValue("term"))
+		if term == nil {
+			c.WriteStatus(http.StatusNotFound)
+			return xun.ErrCancelled
+		}
+
+		n, err := strconv.Atoi(c.Request().URL.Query().Get("page"))
+		if err != nil {
+			n = 1
+		}
+
+		pages, p := xun.Paginate(term.Pages, n, t.size)
+		if n > p.Pages {
+			c.WriteStatus(http.StatusNotFound)
+			return xun.ErrCancelled
+		}
+
+		return c.View(Listing{
+			Taxonomy:   name,
+			Term:       term,
+			Pages:      pages,
+			Pagination: p,
+		}, "views/"+name)
+	}
+}
+
+type rss struct {
+	XMLName xml.Name `xml:"rss"`
+	Version string   `xml:"version,attr"`
+	Channel channel  `xml:"channel"`
+}
+
+type channel struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	Description string `xml:"description"`
+	Items       []item `xml:"item"`
+}
+
+type item struct {
+	Title       string `xml:"title"`
+	Link        string `xml:"link"`
+	GUID        string `xml:"guid"`
+	PubDate     string `xml:"pubDate,omitempty"`
+	Description string `xml:"description,omitempty"`
+}
+
+func (t *Taxonomies) feed(name string) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		term := t.Taxonomy(name).Term(c.Request().PathValue("term"))
+		if term == nil {
+			c.WriteStatus(http.StatusNotFound)
+			return xun.ErrCancelled
+		}
+
+		title := term.Name
+		if t.title != "" {
+			title += " - " + t.title
+		}
+
+		doc := rss{
+			Version: "2.0",
+			Channel: channel{
+				Title:       title,
+				Link:        c.AbsoluteURL(term.URL),
+				Description: title,
+			},
+		}
+
+		pages, _ := xun.Paginate(term.Pages, 1, t.size)
+		for _, p := range pages {
+			it := item{
+				Title:       p.Title(),
+				Link:        c.AbsoluteURL(p.URL),
+				GUID:        c.AbsoluteURL(p.URL),
+				Description: p.Description(),
+			}
+			if it.Title == "" {
+				it.Title = p.Name
+			}
+			if d := p.Date(); !d.IsZero() {
+				it.PubDate = d.Format(time.RFC1123Z)
+			}
+			doc.Channel.Items = append(doc.Channel.Items, it)
+		}
+
+		c.WriteHeader("Content-Type", "application/rss+xml; charset=utf-8")
+		if _, err := c.Writer().Write([]byte(xml.Header)); err != nil {
+			return err
+		}
+		return xml.NewEncoder(c.Writer()).Encode(doc)
+	}
+}
+
+// slug returns the slug of the term, e.g. "web-dev" for "Web Dev".
+func slug(term string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(term) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		default:
+			dash = true
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,108 @@
+package taxonomy
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestTaxonomies(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/blog/first.html": {Data: []byte(`---
+title: First
+date: 2025-01-01
+description: The first post.
+tags: [Go, Web Dev]
+categories: news
+---
+<h1>First</h1>`)},
+		"pages/blog/second.html": {Data: []byte(`---
+title: Second
+date: 2025-01-02
+tags: [go]
+---
+<h1>Second</h1>`)},
+		"pages/blog/third.html": {Data: []byte(`---
+title: Third
+date: 2025-01-03T10:00:00Z
+tags: go
+---
+<h1>Third</h1>`)},
+		"pages/user/{id}.html": {Data: []byte(`---
+tags: [go]
+---
+<h1>User</h1>`)},
+		"pages/index.html": {Data: []byte(`{{ range (taxonomy "tags").Terms }}<a href="{{ .URL }}">{{ .Name }} {{ len .Pages }}</a>{{ end }}`)},
+		"views/tags.html": {Data: []byte(`<h1>{{ .Term.Name }}</h1>{{ range .Pages }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}` +
+			`{{ if .Pagination.HasNext }}<a href="?page={{ .Pagination.Next }}">next</a>{{ end }}`)},
+	}
+
+	tx := New(WithPageSize(2), WithTitle("Blog"))
+	xun.FuncMap["taxonomy"] = tx.FuncMap()["taxonomy"]
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux), xun.WithFsys(fsys))
+	defer app.Close()
+
+	tx.Register(app)
+	app.Start()
+
+	tags := tx.Taxonomy("tags")
+	require.Len(t, tags.Terms, 2)
+	require.Equal(t, "go", tags.Terms[0].Slug)
+	require.Equal(t, "Go", tags.Terms[0].Name)
+	require.Equal(t, "/tags/web-dev", tags.Terms[1].URL)
+	require.Len(t, tx.Taxonomy("categories").Term("news").Pages, 1)
+	require.Empty(t, tx.Taxonomy("series").Terms)
+
+	get := func(path string) (int, string, string) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, resp.Header.Get("Content-Type"), string(buf)
+	}
+
+	_, _, body := get("/")
+	require.Equal(t, `<a href="/tags/go">Go 3</a><a href="/tags/web-dev">Web Dev 1</a>`, body)
+
+	status, _, body := get("/tags/go")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `<h1>Go</h1><a href="/blog/third">Third</a><a href="/blog/second">Second</a><a href="?page=2">next</a>`, body)
+
+	_, _, body = get("/tags/go?page=2")
+	require.Equal(t, `<h1>Go</h1><a href="/blog/first">First</a>`, body)
+
+	status, _, _ = get("/tags/go?page=3")
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, _, _ = get("/tags/rust")
+	require.Equal(t, http.StatusNotFound, status)
+
+	status, contentType, body := get("/tags/go/feed.xml")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "application/rss+xml; charset=utf-8", contentType)
+
+	var feed rss
+	require.NoError(t, xml.Unmarshal([]byte(body), &feed))
+	require.Equal(t, "Go - Blog", feed.Channel.Title)
+	require.Equal(t, srv.URL+"/tags/go", feed.Channel.Link)
+	require.Equal(t, []item{
+		{Title: "Third", Link: srv.URL + "/blog/third", GUID: srv.URL + "/blog/third", PubDate: "Fri, 03 Jan 2025 10:00:00 +0000"},
+		{Title: "Second", Link: srv.URL + "/blog/second", GUID: srv.URL + "/blog/second", PubDate: "Thu, 02 Jan 2025 00:00:00 +0000"},
+	}, feed.Channel.Items)
+
+	status, _, body = get("/categories/news/feed.xml")
+	require.Equal(t, http.StatusOK, status)
+	require.Contains(t, body, "<description>The first post.</description>")
+}
@@ -0,0 +1,39 @@
+package throttle
+
+import "time"
+
+// Option is a function type that takes a pointer to Throttler as an argument.
+// It is used to configure the Throttler with various options.
+type Option func(*Throttler)
+
+// WithStore sets the Store of the failed attempts. MemoryStore is used by default.
+func WithStore(s Store) Option {
+	return func(t *Throttler) {
+		t.store = s
+	}
+}
+
+// WithFreeAttempts sets how many failed attempts are allowed before a key is
+// locked out. The default is 5.
+func WithFreeAttempts(n int) Option {
+	return func(t *Throttler) {
+		t.freeAttempts = n
+	}
+}
+
+// WithBackoff sets the lockout duration after the free attempts are used. It
+// doubles on each further failure up to max. The defaults are 1 second and 15 minutes.
+func WithBackoff(base, max time.Duration) Option {
+	return func(t *Throttler) {
+		t.base = base
+		t.max = max
+	}
+}
+
+// WithWindow sets how long the failures of a key are kept without further
+// failures. The default is 1 hour.
+func WithWindow(d time.Duration) Option {
+	return func(t *Throttler) {
+		t.window = d
+	}
+}
@@ -0,0 +1,92 @@
+package throttle
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Store keeps the failed attempts of keys. It can be implemented by Redis or
+// a database to share the counters by all instances of the app.
+type Store interface {
+	// Get returns the number of failures of key and the time of the last one.
+	Get(ctx context.Context, key string) (failures int, last time.Time, err error)
+	// Incr records a failure of key at now, and returns the number of failures.
+	// The counter can be expired after ttl without further failures.
+	Incr(ctx context.Context, key string, now time.Time, ttl time.Duration) (int, error)
+	// Reset deletes the failures of key.
+	Reset(ctx context.Context, key string) error
+}
+
+type entry struct {
+	failures int
+	last     time.Time
+	expires  time.Time
+}
+
+// MemoryStore is a Store in memory. It is the default Store, and it should only
+// be used by a single instance app.
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	incrs   int
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*entry)}
+}
+
+// Get implements Store.
+func (s *MemoryStore) Get(_ context.Context, key string) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	e, ok := s.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		return 0, time.Time{}, nil
+	}
+
+	return e.failures, e.last, nil
+}
+
+// Incr implements Store.
+func (s *MemoryStore) Incr(_ context.Context, key string, now time.Time, ttl time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.incrs++
+	if s.incrs%1024 == 0 {
+		s.prune(now)
+	}
+
+	e, ok := s.entries[key]
+	if !ok || now.After(e.expires) {
+		e = &entry{}
+		s.entries[key] = e
+	}
+
+	e.failures++
+	e.last = now
+	e.expires = now.Add(ttl)
+
+	return e.failures, nil
+}
+
+// Reset implements Store.
+func (s *MemoryStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+	return nil
+}
+
+// prune deletes the expired entries.
+func (s *MemoryStore) prune(now time.Time) {
+	for k, e := range s.entries {
+		if now.After(e.expires) {
+			delete(s.entries, k)
+		}
+	}
+}
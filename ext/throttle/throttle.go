@@ -0,0 +1,148 @@
+package throttle
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/yaitoo/xun"
+)
+
+// Throttler protects auth flows from brute-force attacks. It counts the failed
+// attempts of the keys declared by c.ThrottleKey, e.g. the email and IP of a
+// login. Once the free attempts are used, the key is locked out with
+// exponential backoff until the next attempt succeeds or the window elapses.
+type Throttler struct {
+	store        Store
+	freeAttempts int
+	base         time.Duration
+	max          time.Duration
+	window       time.Duration
+
+	now func() time.Time
+
+	// locks serialize the check and the reservation of the attempts of keys
+	locks [64]sync.Mutex
+}
+
+var _ xun.Throttler = (*Throttler)(nil)
+
+// New creates a Throttler.
+func New(opts ...Option) *Throttler {
+	t := &Throttler{
+		freeAttempts: 5,
+		base:         time.Second,
+		max:          15 * time.Minute,
+		window:       time.Hour,
+		now:          time.Now,
+	}
+
+	for _, o := range opts {
+		o(t)
+	}
+
+	if t.store == nil {
+		t.store = NewMemoryStore()
+	}
+
+	return t
+}
+
+// Check returns how long the key is locked out.
+func (t *Throttler) Check(ctx context.Context, key string) (time.Duration, error) {
+	failures, last, err := t.store.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+
+	if wait := last.Add(t.backoff(failures)).Sub(t.now()); wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+// Attempt implements xun.Throttler. It returns how long the key is locked out,
+// or records a failure of the key as the reserved attempt, which is reset by
+// Middleware if the attempt succeeds. The check and the reservation of a key
+// are serialized, so parallel attempts can't bypass the lockout.
+func (t *Throttler) Attempt(ctx context.Context, key string) (time.Duration, error) {
+	mu := t.lock(key)
+	mu.Lock()
+	defer mu.Unlock()
+
+	wait, err := t.Check(ctx, key)
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	return 0, t.Fail(ctx, key)
+}
+
+// lock returns the mutex of the key.
+func (t *Throttler) lock(key string) *sync.Mutex {
+	h := fnv.New32a()
+	h.Write([]byte(key)) // nolint: errcheck
+	return &t.locks[h.Sum32()%uint32(len(t.locks))]
+}
+
+// backoff returns the lockout duration after the failures.
+func (t *Throttler) backoff(failures int) time.Duration {
+	n := failures - t.freeAttempts
+	if n < 0 {
+		return 0
+	}
+
+	d := t.base
+	for i := 0; i < n && d < t.max; i++ {
+		d *= 2
+	}
+
+	return min(d, t.max)
+}
+
+// Fail records a failed attempt of the key.
+func (t *Throttler) Fail(ctx context.Context, key string) error {
+	_, err := t.store.Incr(ctx, key, t.now(), t.window+t.max)
+	return err
+}
+
+// Reset deletes the failed attempts of the key, e.g. after a password reset.
+func (t *Throttler) Reset(ctx context.Context, key string) error {
+	return t.store.Reset(ctx, key)
+}
+
+// Middleware sets the Throttler for c.ThrottleKey, and resets the attempts
+// reserved for the declared keys after the handler returns, if the attempt
+// succeeds. It fails if the handler writes 401 Unauthorized or 403 Forbidden,
+// or returns an error, so the reserved attempts are kept as failures.
+func (t *Throttler) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		c.Set(xun.ThrottlerKey, t)
+
+		err := next(c)
+
+		keys := c.ThrottleKeys()
+		if len(keys) == 0 {
+			return err
+		}
+
+		status := c.StatusCode()
+		if status == http.StatusUnauthorized || status == http.StatusForbidden ||
+			(err != nil && !errors.Is(err, xun.ErrCancelled)) {
+			return err
+		}
+
+		ctx := c.Request().Context()
+		for _, key := range keys {
+			if e := t.Reset(ctx, key); e != nil {
+				return errors.Join(err, e)
+			}
+		}
+
+		return err
+	}
+}
@@ -0,0 +1,184 @@
+package throttle
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+)
+
+func TestBackoff(t *testing.T) {
+	th := New(WithFreeAttempts(3), WithBackoff(time.Second, 10*time.Second))
+
+	for failures, want := range map[int]time.Duration{
+		0:   0,
+		2:   0,
+		3:   time.Second,
+		4:   2 * time.Second,
+		6:   8 * time.Second,
+		7:   10 * time.Second,
+		100: 10 * time.Second,
+	} {
+		require.Equal(t, want, th.backoff(failures), failures)
+	}
+}
+
+func TestThrottler(t *testing.T) {
+	now := time.Now()
+
+	th := New(WithFreeAttempts(2), WithBackoff(time.Second, time.Minute))
+	th.now = func() time.Time { return now }
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	auth := app.Group("/auth")
+	auth.Use(th.Middleware)
+	auth.Post("/login", func(c *xun.Context) error {
+		email := c.Request().PostFormValue("email")
+		if err := c.ThrottleKey("email:" + email); err != nil {
+			return err
+		}
+
+		if c.Request().PostFormValue("password") != "secret" {
+			c.WriteStatus(http.StatusUnauthorized)
+			return xun.ErrCancelled
+		}
+		return c.View(email)
+	})
+
+	app.Start()
+
+	login := func(password string) *http.Response {
+		resp, err := http.PostForm(srv.URL+"/auth/login", url.Values{"email": {"xun@yaitoo.cn"}, "password": {password}})
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	require.Equal(t, http.StatusUnauthorized, login("1").StatusCode)
+	require.Equal(t, http.StatusUnauthorized, login("2").StatusCode)
+
+	// locked out for 1s, even with the right password
+	resp := login("secret")
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusUnauthorized, login("3").StatusCode)
+
+	// backoff is doubled
+	now = now.Add(time.Second)
+	resp = login("secret")
+	require.Equal(t, http.StatusTooManyRequests, resp.StatusCode)
+	require.Equal(t, "1", resp.Header.Get("Retry-After"))
+
+	now = now.Add(time.Second)
+	require.Equal(t, http.StatusOK, login("secret").StatusCode)
+
+	// failures are reset by a successful attempt
+	require.Equal(t, http.StatusUnauthorized, login("4").StatusCode)
+	require.Equal(t, http.StatusOK, login("secret").StatusCode)
+
+	wait, err := th.Check(context.Background(), "email:xun@yaitoo.cn")
+	require.NoError(t, err)
+	require.Zero(t, wait)
+}
+
+func TestParallelAttempts(t *testing.T) {
+	th := New(WithFreeAttempts(2), WithBackoff(time.Minute, time.Hour))
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	release := make(chan struct{})
+	app.Use(th.Middleware)
+	app.Post("/login", func(c *xun.Context) error {
+		if err := c.ThrottleKey("email:xun@yaitoo.cn"); err != nil {
+			return err
+		}
+
+		<-release // all attempts are verified at once
+		c.WriteStatus(http.StatusUnauthorized)
+		return xun.ErrCancelled
+	})
+
+	app.Start()
+
+	var wg sync.WaitGroup
+	statuses := make(chan int, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := http.PostForm(srv.URL+"/login", nil)
+			if err != nil {
+				statuses <- 0
+				return
+			}
+			resp.Body.Close()
+			statuses <- resp.StatusCode
+		}()
+	}
+
+	// the attempts beyond the free ones are locked out before verification
+	counts := make(map[int]int)
+	for i := 0; i < 8; i++ {
+		counts[<-statuses]++
+	}
+	close(release)
+	wg.Wait()
+	close(statuses)
+	for status := range statuses {
+		counts[status]++
+	}
+
+	require.Equal(t, map[int]int{http.StatusUnauthorized: 2, http.StatusTooManyRequests: 8}, counts)
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+	now := time.Now()
+
+	n, err := s.Incr(ctx, "a", now.Add(-2*time.Hour), time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	// expired
+	failures, _, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Zero(t, failures)
+
+	n, err = s.Incr(ctx, "a", now, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	n, err = s.Incr(ctx, "a", now, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	failures, last, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Equal(t, 2, failures)
+	require.Equal(t, now, last)
+
+	require.NoError(t, s.Reset(ctx, "a"))
+	failures, _, err = s.Get(ctx, "a")
+	require.NoError(t, err)
+	require.Zero(t, failures)
+}
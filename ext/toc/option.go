@@ -0,0 +1,22 @@
+package toc
+
+// Option is a function type that takes a pointer to Generator as an argument.
+// It is used to configure the Generator with various options.
+type Option func(*Generator)
+
+// WithLevels sets the heading levels included in the table of contents, e.g.
+// WithLevels(2, 3) for h2 and h3. IDs are added to all headings.
+func WithLevels(min, max int) Option {
+	return func(g *Generator) {
+		g.minLevel = min
+		g.maxLevel = max
+	}
+}
+
+// WithAnchors appends a self link to headings, e.g.
+// `<h2 id="install">Install<a class="anchor" href="#install">#</a></h2>`.
+func WithAnchors(text string) Option {
+	return func(g *Generator) {
+		g.anchor = text
+	}
+}
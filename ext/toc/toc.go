@@ -0,0 +1,225 @@
+package toc
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"strconv"
+	"strings"
+	"unicode"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// Heading is a heading of a page in the table of contents.
+type Heading struct {
+	Level    int
+	ID       string
+	Text     string
+	Children []*Heading
+}
+
+// TOC is a page with IDs added to its headings, and its table of contents.
+type TOC struct {
+	HTML     template.HTML
+	Headings []*Heading
+}
+
+// Generator adds IDs to the headings of rendered pages, e.g. HTML rendered
+// from Markdown, and builds their table of contents.
+type Generator struct {
+	minLevel int
+	maxLevel int
+	anchor   string
+}
+
+// New creates a Generator. The table of contents includes h2 to h4 by default.
+func New(opts ...Option) *Generator {
+	g := &Generator{
+		minLevel: 2,
+		maxLevel: 4,
+	}
+
+	for _, o := range opts {
+		o(g)
+	}
+
+	return g
+}
+
+// FuncMap returns the template function `toc`. It should be added to
+// xun.FuncMap before the App is created.
+//
+//	{{ $toc := toc .Body }}
+//	<nav>{{ range $toc.Headings }}<a href="#{{ .ID }}">{{ .Text }}</a>{{ end }}</nav>
+//	<article>{{ $toc.HTML }}</article>
+func (g *Generator) FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"toc": func(page template.HTML) *TOC {
+			return g.Generate(string(page))
+		},
+	}
+}
+
+// Generate adds IDs to the headings of the page, and returns its table of
+// contents. Existing IDs are kept, and others are generated from the text of
+// headings, e.g. "getting-started" for "Getting Started", and made unique in
+// the page.
+func (g *Generator) Generate(page string) *TOC { // skipcq: GO-R1005
+	z := nethtml.NewTokenizer(strings.NewReader(page))
+
+	var (
+		buf      bytes.Buffer
+		toc      = &TOC{}
+		stack    []*Heading
+		ids      = make(map[string]int)
+		heading  *Heading
+		startTag nethtml.Token
+		inner    bytes.Buffer
+		text     strings.Builder
+	)
+
+	offset := 0
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+
+		// the raw bytes are sliced from the page, because the buffer of the
+		// tokenizer is unescaped in place by z.Token()
+		start := offset
+		offset += len(z.Raw())
+		raw := page[start:offset]
+		tok := z.Token()
+
+		if heading == nil {
+			if level := headingLevel(tok); level > 0 && tt == nethtml.StartTagToken {
+				startTag = tok
+				heading = &Heading{Level: level, ID: attr(tok, "id")}
+				inner.Reset()
+				text.Reset()
+				continue
+			}
+			buf.WriteString(raw)
+			continue
+		}
+
+		if tt == nethtml.EndTagToken && headingLevel(tok) == heading.Level {
+			heading.Text = strings.Join(strings.Fields(text.String()), " ")
+			if heading.ID == "" {
+				heading.ID = slug(heading.Text)
+			}
+			heading.ID = unique(ids, heading.ID)
+			ids[heading.ID]++
+
+			setAttr(&startTag, "id", heading.ID)
+			buf.WriteString(startTag.String())
+			buf.Write(inner.Bytes())
+			if g.anchor != "" {
+				buf.WriteString(`<a class="anchor" href="#` + html.EscapeString(heading.ID) + `">` + html.EscapeString(g.anchor) + `</a>`)
+			}
+			buf.WriteString(raw)
+
+			if heading.Level >= g.minLevel && heading.Level <= g.maxLevel {
+				stack = add(toc, stack, heading)
+			}
+			heading = nil
+			continue
+		}
+
+		if tt == nethtml.TextToken {
+			text.WriteString(tok.Data)
+		}
+		inner.WriteString(raw)
+	}
+
+	if heading != nil { // unclosed heading
+		buf.WriteString(startTag.String())
+		buf.Write(inner.Bytes())
+	}
+
+	toc.HTML = template.HTML(buf.String()) // nolint: gosec
+	return toc
+}
+
+// add adds the heading to the closest parent with a lower level in the stack.
+func add(toc *TOC, stack []*Heading, h *Heading) []*Heading {
+	for len(stack) > 0 && stack[len(stack)-1].Level >= h.Level {
+		stack = stack[:len(stack)-1]
+	}
+
+	if len(stack) == 0 {
+		toc.Headings = append(toc.Headings, h)
+	} else {
+		parent := stack[len(stack)-1]
+		parent.Children = append(parent.Children, h)
+	}
+
+	return append(stack, h)
+}
+
+func headingLevel(tok nethtml.Token) int {
+	name := tok.Data
+	if len(name) == 2 && name[0] == 'h' && name[1] >= '1' && name[1] <= '6' {
+		return int(name[1] - '0')
+	}
+	return 0
+}
+
+func attr(tag nethtml.Token, key string) string {
+	for _, a := range tag.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func setAttr(tag *nethtml.Token, key, val string) {
+	for i, a := range tag.Attr {
+		if a.Key == key {
+			tag.Attr[i].Val = val
+			return
+		}
+	}
+	tag.Attr = append(tag.Attr, nethtml.Attribute{Key: key, Val: val})
+}
+
+// slug returns the ID of the text, e.g. "getting-started" for "Getting Started!".
+func slug(text string) string {
+	var sb strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if dash && sb.Len() > 0 {
+				sb.WriteByte('-')
+			}
+			sb.WriteRune(r)
+			dash = false
+		case r == '_' || r == '-' || unicode.IsSpace(r):
+			dash = true
+		}
+	}
+
+	if sb.Len() == 0 {
+		return "section"
+	}
+	return sb.String()
+}
+
+// unique appends a counter to the id if it is used, e.g. "install-1".
+func unique(ids map[string]int, id string) string {
+	if ids[id] == 0 {
+		return id
+	}
+
+	for n := ids[id]; ; n++ {
+		candidate := id + "-" + strconv.Itoa(n)
+		if ids[candidate] == 0 {
+			ids[id] = n + 1
+			return candidate
+		}
+	}
+}
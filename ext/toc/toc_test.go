@@ -0,0 +1,55 @@
+package toc
+
+import (
+	"bytes"
+	"html/template"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	page := `<h1>Xun</h1><p>intro</p>` +
+		`<h2>Getting Started</h2><h3>Install <code>xun</code></h3><h3 id="run">Run it!</h3>` +
+		`<h2>API</h2><h4>Context</h4><h3>Context</h3><h2>Getting Started</h2>`
+
+	toc := New(WithAnchors("#")).Generate(page)
+
+	require.Equal(t, template.HTML(`<h1 id="xun">Xun<a class="anchor" href="#xun">#</a></h1><p>intro</p>`+
+		`<h2 id="getting-started">Getting Started<a class="anchor" href="#getting-started">#</a></h2>`+
+		`<h3 id="install-xun">Install <code>xun</code><a class="anchor" href="#install-xun">#</a></h3>`+
+		`<h3 id="run">Run it!<a class="anchor" href="#run">#</a></h3>`+
+		`<h2 id="api">API<a class="anchor" href="#api">#</a></h2>`+
+		`<h4 id="context">Context<a class="anchor" href="#context">#</a></h4>`+
+		`<h3 id="context-1">Context<a class="anchor" href="#context-1">#</a></h3>`+
+		`<h2 id="getting-started-1">Getting Started<a class="anchor" href="#getting-started-1">#</a></h2>`), toc.HTML)
+
+	require.Equal(t, []*Heading{
+		{Level: 2, ID: "getting-started", Text: "Getting Started", Children: []*Heading{
+			{Level: 3, ID: "install-xun", Text: "Install xun"},
+			{Level: 3, ID: "run", Text: "Run it!"},
+		}},
+		{Level: 2, ID: "api", Text: "API", Children: []*Heading{
+			{Level: 4, ID: "context", Text: "Context"},
+			{Level: 3, ID: "context-1", Text: "Context"},
+		}},
+		{Level: 2, ID: "getting-started-1", Text: "Getting Started"},
+	}, toc.Headings)
+}
+
+func TestLevels(t *testing.T) {
+	toc := New(WithLevels(1, 1)).Generate(`<h1>你好 World</h1><h2>Sub</h2><h1>!!!</h1>`)
+
+	require.Equal(t, template.HTML(`<h1 id="你好-world">你好 World</h1><h2 id="sub">Sub</h2><h1 id="section">!!!</h1>`), toc.HTML)
+	require.Len(t, toc.Headings, 2)
+	require.Empty(t, toc.Headings[0].Children)
+}
+
+func TestFuncMap(t *testing.T) {
+	tpl := template.Must(template.New("").Funcs(New().FuncMap()).Parse(
+		`{{ $toc := toc . }}<nav>{{ range $toc.Headings }}<a href="#{{ .ID }}">{{ .Text }}</a>{{ end }}</nav><article>{{ $toc.HTML }}</article>`))
+
+	var buf bytes.Buffer
+	require.NoError(t, tpl.Execute(&buf, template.HTML(`<h2>A &amp; B</h2>`)))
+	require.Equal(t, `<nav><a href="#a-b">A &amp; B</a></nav><article><h2 id="a-b">A &amp; B</h2></article>`, buf.String())
+}
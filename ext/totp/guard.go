@@ -0,0 +1,145 @@
+package totp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun"
+	"github.com/yaitoo/xun/ext/sessions"
+)
+
+// VerifiedKey is the key of the Values of a session with the unix time of its
+// last successful two-factor verification.
+const VerifiedKey = "totp_verified_at"
+
+// Guard gatekeeps sensitive route groups, e.g. `/admin` or `/billing`, until
+// the session is verified with a TOTP code.
+type Guard struct {
+	sessions  *sessions.Manager
+	verifyURL string
+	maxAge    time.Duration
+	store     Store
+
+	now func() time.Time
+}
+
+// New creates a Guard. Unverified GET requests are redirected to verifyURL
+// with the `next` query parameter, and other requests are rejected with
+// 403 Forbidden.
+func New(m *sessions.Manager, verifyURL string, opts ...Option) *Guard {
+	g := &Guard{
+		sessions:  m,
+		verifyURL: verifyURL,
+		now:       time.Now,
+	}
+
+	for _, o := range opts {
+		o(g)
+	}
+
+	if g.store == nil {
+		g.store = NewMemoryStore()
+	}
+
+	return g
+}
+
+// Verified reports whether the current session is verified.
+func (g *Guard) Verified(c *xun.Context) bool {
+	s := sessions.Current(c)
+	if s == nil {
+		return false
+	}
+
+	at, ok := unixTime(s.Values[VerifiedKey])
+	if !ok {
+		return false
+	}
+
+	return g.maxAge == 0 || g.now().Sub(time.Unix(at, 0)) < g.maxAge
+}
+
+// unixTime returns the unix time of v, which is an int64 or another numeric
+// kind after the session is decoded, e.g. a float64 of JSON.
+func unixTime(v any) (int64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), true // nolint: gosec
+	case reflect.Float32, reflect.Float64:
+		return int64(rv.Float()), true
+	}
+
+	if n, ok := v.(interface{ Int64() (int64, error) }); ok { // e.g. json.Number
+		at, err := n.Int64()
+		return at, err == nil
+	}
+
+	return 0, false
+}
+
+// Verify validates the code of the user's secret, and marks the current
+// session as verified if it is valid. A code is accepted only once, so it
+// can't be replayed within its period, nor can the codes before it.
+func (g *Guard) Verify(c *xun.Context, secret, code string) (bool, error) {
+	s := sessions.Current(c)
+	if s == nil {
+		return false, nil
+	}
+
+	step, ok := ValidateStep(secret, code, g.now())
+	if !ok {
+		return false, nil
+	}
+
+	ok, err := g.store.Accept(c.Request().Context(), secretKey(secret), step)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if s.Values == nil {
+		s.Values = make(map[string]any)
+	}
+	s.Values[VerifiedKey] = g.now().Unix()
+
+	return true, g.sessions.Save(c)
+}
+
+// secretKey returns the key of the secret in the Store, so the secret itself
+// isn't stored.
+func secretKey(secret string) string {
+	sum := sha256.Sum256([]byte(strings.ToUpper(strings.ReplaceAll(secret, " ", ""))))
+	return hex.EncodeToString(sum[:])
+}
+
+// Middleware rejects requests of unverified sessions. It must be used after
+// the sessions middleware, and requests without sessions are rejected with
+// 401 Unauthorized.
+func (g *Guard) Middleware(next xun.HandleFunc) xun.HandleFunc {
+	return func(c *xun.Context) error {
+		if sessions.Current(c) == nil {
+			c.WriteStatus(http.StatusUnauthorized)
+			return xun.ErrCancelled
+		}
+
+		if g.Verified(c) {
+			return next(c)
+		}
+
+		req := c.Request()
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			c.Redirect(g.verifyURL + "?next=" + url.QueryEscape(req.URL.RequestURI()))
+			return xun.ErrCancelled
+		}
+
+		c.WriteStatus(http.StatusForbidden)
+		return xun.ErrCancelled
+	}
+}
@@ -0,0 +1,23 @@
+package totp
+
+import "time"
+
+// Option is a function type that takes a pointer to Guard as an argument.
+// It is used to configure the Guard with various options.
+type Option func(*Guard)
+
+// WithMaxAge sets how long a verification lasts before the user must verify a
+// code again. It lasts for the whole session by default.
+func WithMaxAge(d time.Duration) Option {
+	return func(g *Guard) {
+		g.maxAge = d
+	}
+}
+
+// WithStore sets the Store of the last accepted time steps of secrets, which
+// rejects replayed codes. MemoryStore is used by default.
+func WithStore(s Store) Option {
+	return func(g *Guard) {
+		g.store = s
+	}
+}
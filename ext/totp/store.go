@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"context"
+	"sync"
+)
+
+// Store keeps the last accepted time step of each secret, so a code can't be
+// replayed within its period. It can be implemented by Redis or a database to
+// share the steps by all instances of the app.
+type Store interface {
+	// Accept records step as the last accepted step of key, and reports whether
+	// it is after the last one. It must be atomic for the key.
+	Accept(ctx context.Context, key string, step int64) (bool, error)
+}
+
+// MemoryStore is a Store in memory. It is the default Store, and it should only
+// be used by a single instance app.
+type MemoryStore struct {
+	mu      sync.Mutex
+	steps   map[string]int64
+	accepts int
+}
+
+// NewMemoryStore creates a MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{steps: make(map[string]int64)}
+}
+
+// Accept implements Store.
+func (s *MemoryStore) Accept(_ context.Context, key string, step int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.accepts++
+	if s.accepts%1024 == 0 {
+		s.prune(step)
+	}
+
+	if last, ok := s.steps[key]; ok && step <= last {
+		return false, nil
+	}
+
+	s.steps[key] = step
+	return true, nil
+}
+
+// prune deletes the steps that are too old to be replayed at step.
+func (s *MemoryStore) prune(step int64) {
+	for k, last := range s.steps {
+		if last < step-2*Skew {
+			delete(s.steps, k)
+		}
+	}
+}
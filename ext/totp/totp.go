@@ -0,0 +1,148 @@
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" // nolint: gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yaitoo/xun/ext/barcode"
+)
+
+// ErrInvalidSecret is returned if a secret isn't base32 encoded.
+var ErrInvalidSecret = errors.New("totp: invalid_secret")
+
+const (
+	// Digits is the number of digits of codes.
+	Digits = 6
+	// Period is how long a code is valid.
+	Period = 30 * time.Second
+	// Skew is the number of periods before and after the current one that are
+	// also accepted, for clock drift between the server and authenticator apps.
+	Skew = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 secret of 160 bits for a user. It
+// should be stored encrypted on the server.
+func GenerateSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return encoding.EncodeToString(buf), nil
+}
+
+// URI returns the provisioning URI of the secret for authenticator apps, e.g.
+// `otpauth://totp/Xun:alice@example.com?secret=...&issuer=Xun`.
+func URI(secret, issuer, account string) string {
+	q := url.Values{}
+	q.Set("secret", secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(Digits))
+	q.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// QR returns the provisioning URI as an inline SVG QR code to be scanned by
+// authenticator apps.
+func QR(uri string) (template.HTML, error) {
+	q, err := barcode.EncodeQR([]byte(uri), barcode.M)
+	if err != nil {
+		return "", err
+	}
+	return template.HTML(q.SVG(4)), nil // nolint: gosec
+}
+
+// FuncMap returns the template functions `totpURI` and `totpQR`. They should be
+// added to xun.FuncMap before the App is created.
+//
+//	{{ totpQR (totpURI .Secret "Xun" .Email) }}
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		"totpURI": URI,
+		"totpQR":  QR,
+	}
+}
+
+// Code returns the code of the secret at t.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return code(key, uint64(t.Unix()/int64(Period.Seconds()))), nil
+}
+
+// Validate reports whether the code of the secret is valid at t, within Skew
+// periods. Codes are compared in constant time.
+//
+// It doesn't protect codes from being replayed within their periods, which
+// Guard.Verify does by its Store, or ValidateStep with the last accepted step.
+func Validate(secret, code string, t time.Time) bool {
+	_, ok := ValidateStep(secret, code, t)
+	return ok
+}
+
+// ValidateStep is Validate, and returns the time step of the code, e.g. to
+// reject the steps that are not after the last accepted one of the secret.
+func ValidateStep(secret, code string, t time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	step := t.Unix() / int64(Period.Seconds())
+
+	matched := int64(-1)
+	for i := -Skew; i <= Skew; i++ {
+		if subtle.ConstantTimeCompare([]byte(code), []byte(codeAt(key, step+int64(i)))) == 1 {
+			matched = step + int64(i)
+		}
+	}
+
+	return matched, matched >= 0
+}
+
+func codeAt(key []byte, step int64) string {
+	if step < 0 {
+		return ""
+	}
+	return code(key, uint64(step))
+}
+
+// code returns the HOTP value (RFC 4226) of the counter.
+func code(key []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	h := hmac.New(sha1.New, key)
+	h.Write(msg[:]) // nolint: errcheck
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	v := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", Digits, v%1000000)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := encoding.DecodeString(strings.TrimRight(secret, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+	return key, nil
+}
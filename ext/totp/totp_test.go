@@ -0,0 +1,201 @@
+package totp
+
+import (
+	"context"
+	"encoding/base32"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/yaitoo/xun"
+	"github.com/yaitoo/xun/ext/sessions"
+)
+
+// the secret of the test vectors in RFC 6238
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	for ts, want := range map[int64]string{
+		59:         "287082",
+		1111111109: "081804",
+		1111111111: "050471",
+		1234567890: "005924",
+		2000000000: "279037",
+	} {
+		code, err := Code(rfcSecret, time.Unix(ts, 0))
+		require.NoError(t, err)
+		require.Equal(t, want, code, ts)
+	}
+
+	_, err := Code("not base32!", time.Now())
+	require.ErrorIs(t, err, ErrInvalidSecret)
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+	require.Len(t, secret, 32)
+
+	now := time.Now()
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+
+	require.True(t, Validate(secret, code, now))
+	require.True(t, Validate(strings.ToLower(secret), code, now))
+	require.True(t, Validate(secret, code, now.Add(Period)))
+	require.False(t, Validate(secret, code, now.Add(3*Period)))
+	require.False(t, Validate(secret, "12345", now))
+	require.False(t, Validate("", code, now))
+
+	require.True(t, Validate(rfcSecret, "287082", time.Unix(59, 0)))
+}
+
+func TestMemoryStore(t *testing.T) {
+	s := NewMemoryStore()
+	ctx := context.Background()
+
+	for _, it := range []struct {
+		step int64
+		want bool
+	}{{10, true}, {10, false}, {9, false}, {11, true}} {
+		ok, err := s.Accept(ctx, "a", it.step)
+		require.NoError(t, err)
+		require.Equal(t, it.want, ok, it.step)
+	}
+
+	ok, err := s.Accept(ctx, "b", 10)
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestUnixTime(t *testing.T) {
+	// the values of sessions decoded by JSON or gob stores
+	for _, v := range []any{int64(42), 42, float64(42), uint32(42), json.Number("42")} {
+		at, ok := unixTime(v)
+		require.True(t, ok, v)
+		require.Equal(t, int64(42), at, v)
+	}
+
+	_, ok := unixTime("42")
+	require.False(t, ok)
+	_, ok = unixTime(nil)
+	require.False(t, ok)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("JBSWY3DPEHPK3PXP", "Xun App", "alice@example.com")
+
+	u, err := url.Parse(uri)
+	require.NoError(t, err)
+	require.Equal(t, "otpauth", u.Scheme)
+	require.Equal(t, "totp", u.Host)
+	require.Equal(t, "/Xun App:alice@example.com", u.Path)
+	require.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	require.Equal(t, "Xun App", u.Query().Get("issuer"))
+	require.Equal(t, "6", u.Query().Get("digits"))
+	require.Equal(t, "30", u.Query().Get("period"))
+
+	svg, err := QR(uri)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(svg), "<svg "))
+}
+
+func TestGuard(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := xun.New(xun.WithMux(mux))
+	defer app.Close()
+
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+
+	m := sessions.New()
+	g := New(m, "/2fa", WithMaxAge(time.Hour))
+	g.now = func() time.Time { return now }
+
+	app.Use(m.Middleware)
+
+	app.Post("/login", func(c *xun.Context) error {
+		_, err := m.Login(c, "1")
+		return err
+	})
+
+	app.Post("/2fa", func(c *xun.Context) error {
+		ok, err := g.Verify(c, secret, c.Request().PostFormValue("code"))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			c.WriteStatus(http.StatusUnprocessableEntity)
+			return xun.ErrCancelled
+		}
+		return nil
+	})
+
+	admin := app.Group("/admin")
+	admin.Use(m.Middleware, g.Middleware)
+	admin.Get("/users", func(c *xun.Context) error {
+		return c.View("users")
+	})
+	admin.Post("/users", func(c *xun.Context) error {
+		return c.View("created")
+	})
+
+	app.Start()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	client := &http.Client{Jar: jar, CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	do := func(method, path string, form url.Values) *http.Response {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/users", nil).StatusCode)
+
+	do(http.MethodPost, "/login", nil)
+
+	resp := do(http.MethodGet, "/admin/users?page=2", nil)
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+	require.Equal(t, "/2fa?next=%2Fadmin%2Fusers%3Fpage%3D2", resp.Header.Get("Location"))
+	require.Equal(t, http.StatusForbidden, do(http.MethodPost, "/admin/users", nil).StatusCode)
+
+	require.Equal(t, http.StatusUnprocessableEntity, do(http.MethodPost, "/2fa", url.Values{"code": {"000000"}}).StatusCode)
+
+	code, err := Code(secret, now)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/2fa", url.Values{"code": {code}}).StatusCode)
+
+	// the code can't be replayed
+	require.Equal(t, http.StatusUnprocessableEntity, do(http.MethodPost, "/2fa", url.Values{"code": {code}}).StatusCode)
+
+	require.Equal(t, http.StatusOK, do(http.MethodGet, "/admin/users", nil).StatusCode)
+	require.Equal(t, http.StatusOK, do(http.MethodPost, "/admin/users", nil).StatusCode)
+
+	items, err := m.ForUser(context.Background(), "1")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, now.Unix(), items[0].Values[VerifiedKey])
+
+	// the verification is expired
+	now = now.Add(2 * time.Hour)
+	require.Equal(t, http.StatusFound, do(http.MethodGet, "/admin/users", nil).StatusCode)
+}
@@ -0,0 +1,160 @@
+package xun
+
+import (
+	"context"
+	stdjson "encoding/json"
+	"net/http"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+type fieldsContextKey struct{}
+
+// fieldSelector is a tree of the selected fields. A nil selector selects all
+// fields of the value.
+type fieldSelector map[string]fieldSelector
+
+// SparseFields returns a middleware that trims the responses of JsonViewer to
+// the fields selected by the query parameter, "fields" by default, e.g.
+// `?fields=name,email,address.city`. Fields are selected by their JSON names,
+// nested fields are selected by dots, and the fields of slices apply to their
+// elements. It applies to the data in the EnvelopeResponse.
+//
+//	api := app.Group("/api")
+//	api.Use(xun.SparseFields())
+func SparseFields(param ...string) Middleware {
+	name := "fields"
+	if len(param) > 0 {
+		name = param[0]
+	}
+
+	return func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if fields := parseFields(c.req.URL.Query()[name]); fields != nil {
+				c.WithContext(context.WithValue(c.Context(), fieldsContextKey{}, fields))
+			}
+			return next(c)
+		}
+	}
+}
+
+// parseFields parses the field selectors, e.g. ["name,address.city", "email"].
+// It returns nil if no field is selected.
+func parseFields(values []string) fieldSelector {
+	var fields fieldSelector
+	for _, v := range values {
+		for _, it := range strings.Split(v, ",") {
+			it = strings.TrimSpace(it)
+			if it == "" {
+				continue
+			}
+
+			if fields == nil {
+				fields = make(fieldSelector)
+			}
+
+			sel := fields
+			names := strings.Split(it, ".")
+			for i, name := range names {
+				sub, ok := sel[name]
+				if ok && sub == nil {
+					break // the whole value is already selected
+				}
+
+				if i == len(names)-1 {
+					sel[name] = nil
+					break
+				}
+
+				if !ok {
+					sub = make(fieldSelector)
+					sel[name] = sub
+				}
+				sel = sub
+			}
+		}
+	}
+	return fields
+}
+
+// selectFields returns the JSON of the data with the fields selected by
+// SparseFields for the request r, or the data if no field is selected.
+func selectFields(r *http.Request, data any) (any, error) {
+	if r == nil {
+		return data, nil
+	}
+
+	fields, ok := r.Context().Value(fieldsContextKey{}).(fieldSelector)
+	if !ok {
+		return data, nil
+	}
+
+	if _, ok := data.(error); ok {
+		return data, nil
+	}
+
+	buf, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	iter := json.BorrowIterator(buf)
+	defer json.ReturnIterator(iter)
+
+	s := json.BorrowStream(nil)
+	defer json.ReturnStream(s)
+
+	writeFields(iter, s, fields)
+	if iter.Error != nil {
+		return nil, iter.Error
+	}
+
+	return stdjson.RawMessage(append([]byte(nil), s.Buffer()...)), nil
+}
+
+// writeFields writes the value of iter to s with the selected fields, in the
+// order of the value.
+func writeFields(iter *jsoniter.Iterator, s *jsoniter.Stream, fields fieldSelector) {
+	switch iter.WhatIsNext() {
+	case jsoniter.ObjectValue:
+		s.WriteObjectStart()
+		first := true
+		iter.ReadMapCB(func(it *jsoniter.Iterator, key string) bool {
+			sub, ok := fields[key]
+			if !ok {
+				it.Skip()
+				return true
+			}
+
+			if !first {
+				s.WriteMore()
+			}
+			first = false
+
+			s.WriteObjectField(key)
+			if sub == nil {
+				s.WriteRaw(string(it.SkipAndReturnBytes()))
+			} else {
+				writeFields(it, s, sub)
+			}
+			return true
+		})
+		s.WriteObjectEnd()
+	case jsoniter.ArrayValue:
+		s.WriteArrayStart()
+		first := true
+		iter.ReadArrayCB(func(it *jsoniter.Iterator) bool {
+			if !first {
+				s.WriteMore()
+			}
+			first = false
+
+			writeFields(it, s, fields)
+			return true
+		})
+		s.WriteArrayEnd()
+	default:
+		s.WriteRaw(string(iter.SkipAndReturnBytes()))
+	}
+}
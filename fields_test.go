@@ -0,0 +1,98 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var regexpRequestID = regexp.MustCompile(`"request_id":"[^"]+"`)
+
+func TestSparseFields(t *testing.T) {
+	type address struct {
+		City    string `json:"city"`
+		Country string `json:"country"`
+	}
+
+	type user struct {
+		ID      int64    `json:"id"`
+		Name    string   `json:"name"`
+		Email   string   `json:"email"`
+		Address *address `json:"address"`
+	}
+
+	users := []user{
+		{ID: 9007199254740993, Name: "alice", Email: "alice@example.com", Address: &address{City: "Hanoi", Country: "VN"}},
+		{ID: 2, Name: "bob", Email: "bob@example.com"},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	api := app.Group("/api")
+	api.Use(SparseFields(), Envelope())
+
+	api.Get("/users", func(c *Context) error {
+		return c.View(users)
+	})
+
+	api.Get("/error", func(c *Context) error {
+		return c.View(errors.New("failed"))
+	})
+
+	api.Get("/stream", func(c *Context) error {
+		return c.View(users)
+	}, WithViewer(&JsonViewer{Stream: true}))
+
+	app.Get("/users", func(c *Context) error {
+		return c.View(users[1])
+	})
+
+	v2 := app.Group("/v2")
+	v2.Use(SparseFields("only"))
+	v2.Get("/users", func(c *Context) error {
+		return c.View(users[1])
+	})
+
+	app.Start()
+
+	get := func(path string) string {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: "/api/users?fields=name,id", want: `{"data":[{"id":9007199254740993,"name":"alice"},{"id":2,"name":"bob"}],"meta":{"request_id":"ID"}}`},
+		{path: "/api/users?fields=name&fields=address.city", want: `{"data":[{"name":"alice","address":{"city":"Hanoi"}},{"name":"bob","address":null}],"meta":{"request_id":"ID"}}`},
+		{path: "/api/users?fields=address.city,address", want: `{"data":[{"address":{"city":"Hanoi","country":"VN"}},{"address":null}],"meta":{"request_id":"ID"}}`},
+		{path: "/api/users?fields=unknown", want: `{"data":[{},{}],"meta":{"request_id":"ID"}}`},
+		{path: "/api/error?fields=name", want: `{"data":null,"meta":{"request_id":"ID"},"errors":[{"message":"failed"}]}`},
+		{path: "/api/stream?fields=name", want: `{"data":[{"name":"alice"},{"name":"bob"}],"meta":{"request_id":"ID"}}`},
+		{path: "/v2/users?only=email", want: `{"email":"bob@example.com"}`},
+		{path: "/users?fields=email", want: `{"id":2,"name":"bob","email":"bob@example.com","address":null}`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			body := get(test.path)
+			body = regexpRequestID.ReplaceAllString(body, `"request_id":"ID"`)
+			require.Equal(t, test.want+"\n", body)
+		})
+	}
+}
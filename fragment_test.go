@@ -0,0 +1,71 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFragmentLayout(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html":       {Data: []byte(`<html><title>Xun</title><main>{{ block "content" . }}{{ end }}</main></html>`)},
+		"views/users/list.html":   {Data: []byte(`<ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>`)},
+		"views/users/detail.html": {Data: []byte("<!--layout:main-->\n" + `{{ define "content" }}<b>{{ . }}</b>{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Get("/users", func(c *Context) error {
+		return c.View([]string{"alice", "bob"}, "views/users/list")
+	}, WithFragmentLayout("main"))
+
+	app.Get("/users/1", func(c *Context) error {
+		return c.View("alice", "views/users/detail")
+	}, WithFragmentLayout("main"))
+
+	app.Get("/users/missing", func(c *Context) error {
+		return c.View([]string{"alice"}, "views/users/list")
+	}, WithFragmentLayout("missing"))
+
+	app.Start()
+
+	get := func(path string, hx bool) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		if hx {
+			req.Header.Set("HX-Request", "true")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		return resp, string(buf)
+	}
+
+	resp, body := get("/users", false)
+	require.Equal(t, `<html><title>Xun</title><main><ul><li>alice</li><li>bob</li></ul></main></html>`, body)
+	require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Equal(t, "HX-Request", resp.Header.Get("Vary"))
+
+	_, body = get("/users", true)
+	require.Equal(t, `<ul><li>alice</li><li>bob</li></ul>`, body)
+
+	// templates with their own layout are rendered as they are
+	_, body = get("/users/1", false)
+	require.Equal(t, `<html><title>Xun</title><main><b>alice</b></main></html>`, body)
+
+	_, body = get("/users/missing", false)
+	require.Equal(t, `<ul><li>alice</li></ul>`, body)
+}
@@ -0,0 +1,65 @@
+package xun
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// splitFrontMatter splits the YAML front matter from the start of a template, e.g.
+//
+//	---
+//	title: Hello
+//	tags: [go, web]
+//	---
+//	<h1>Hello</h1>
+//
+// It returns the template without the front matter.
+func splitFrontMatter(buf []byte) (map[string]any, []byte, error) {
+	rest, ok := cutLine(buf, "---")
+	if !ok {
+		return nil, buf, nil
+	}
+
+	for i := 0; i < len(rest); {
+		end := bytes.IndexByte(rest[i:], '\n')
+		line := rest[i:]
+		if end >= 0 {
+			line = rest[i : i+end]
+		}
+
+		if string(bytes.TrimRight(line, "\r")) == "---" {
+			meta := make(map[string]any)
+			if err := yaml.Unmarshal(rest[:i], &meta); err != nil {
+				return nil, buf, fmt.Errorf("front matter: %w", err)
+			}
+
+			if end < 0 {
+				return meta, nil, nil
+			}
+			return meta, rest[i+end+1:], nil
+		}
+
+		if end < 0 {
+			break
+		}
+		i += end + 1
+	}
+
+	return nil, buf, nil
+}
+
+// cutLine cuts the first line of buf if it is the line.
+func cutLine(buf []byte, line string) ([]byte, bool) {
+	rest, ok := bytes.CutPrefix(buf, []byte(line))
+	if !ok {
+		return buf, false
+	}
+
+	if rest, ok := bytes.CutPrefix(rest, []byte("\r\n")); ok {
+		return rest, true
+	}
+
+	return bytes.CutPrefix(rest, []byte("\n"))
+}
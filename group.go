@@ -7,6 +7,7 @@ import (
 type group struct {
 	prefix      string
 	middlewares []Middleware
+	onError     ErrorHandler
 
 	app *App
 }
@@ -15,6 +16,21 @@ func (g *group) Use(middleware ...Middleware) {
 	g.middlewares = append(g.middlewares, middleware...)
 }
 
+// OnError sets the ErrorHandler of the group. It overrides the default 500
+// Internal Server Error response for unhandled errors of the group's routes.
+func (g *group) OnError(h ErrorHandler) {
+	g.onError = h
+}
+
+func (g *group) handleError(c *Context, err error) bool {
+	if g.onError == nil {
+		return false
+	}
+
+	g.onError(c, err)
+	return true
+}
+
 func (g *group) Get(pattern string, hf HandleFunc, opts ...RoutingOption) {
 	g.HandleFunc(http.MethodGet+" "+g.prefix+pattern, hf, opts...)
 }
@@ -42,3 +58,7 @@ func (g *group) Next(hf HandleFunc) HandleFunc {
 	}
 	return next
 }
+
+func (g *group) middlewareList() []Middleware {
+	return g.middlewares
+}
@@ -1,6 +1,7 @@
 package xun
 
 import (
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -238,3 +239,46 @@ func TestGroupMiddleware(t *testing.T) {
 	require.Equal(t, http.StatusOK, resp.StatusCode)
 	resp.Body.Close()
 }
+
+func TestGroupOnError(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	api := app.Group("/api")
+	api.OnError(func(c *Context, err error) {
+		c.WriteHeader("Content-Type", "application/problem+json")
+		c.WriteStatus(http.StatusBadGateway)
+		c.Writer().Write([]byte(`{"title":"` + err.Error() + `"}`)) // nolint: errcheck
+	})
+
+	api.Get("/users", func(c *Context) error {
+		return errors.New("upstream")
+	})
+
+	web := app.Group("/web")
+	web.Get("/users", func(c *Context) error {
+		return errors.New("upstream")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/api/users")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+	require.NotEmpty(t, resp.Header.Get("X-Log-Id"))
+	require.Equal(t, `{"title":"upstream"}`, string(buf))
+
+	resp, err = client.Get(srv.URL + "/web/users")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
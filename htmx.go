@@ -0,0 +1,56 @@
+package xun
+
+import (
+	"net/url"
+)
+
+// HtmxRequest is the request headers sent by htmx.
+type HtmxRequest struct {
+	// Request is true if it is a htmx request (HX-Request).
+	Request bool
+	// Boosted is true if it is sent by an element using hx-boost (HX-Boosted).
+	Boosted bool
+	// HistoryRestoreRequest is true if it is for history restoration after a
+	// miss in the local history cache (HX-History-Restore-Request).
+	HistoryRestoreRequest bool
+	// CurrentURL is the current URL of the browser (HX-Current-URL).
+	CurrentURL string
+	// Prompt is the user response to an hx-prompt (HX-Prompt).
+	Prompt string
+	// Target is the id of the target element if it exists (HX-Target).
+	Target string
+	// Trigger is the id of the triggered element if it exists (HX-Trigger).
+	Trigger string
+	// TriggerName is the name of the triggered element if it exists (HX-Trigger-Name).
+	TriggerName string
+}
+
+// Htmx returns the htmx headers of the request, so handlers can branch on
+// them without raw header lookups. Values with non-ASCII characters, which
+// htmx sends URI encoded, are decoded.
+func (c *Context) Htmx() HtmxRequest {
+	return HtmxRequest{
+		Request:               c.htmxHeader("HX-Request") == "true",
+		Boosted:               c.htmxHeader("HX-Boosted") == "true",
+		HistoryRestoreRequest: c.htmxHeader("HX-History-Restore-Request") == "true",
+		CurrentURL:            c.htmxHeader("HX-Current-URL"),
+		Prompt:                c.htmxHeader("HX-Prompt"),
+		Target:                c.htmxHeader("HX-Target"),
+		Trigger:               c.htmxHeader("HX-Trigger"),
+		TriggerName:           c.htmxHeader("HX-Trigger-Name"),
+	}
+}
+
+// htmxHeader returns the value of the htmx header key. It is decoded if htmx
+// marks it with the `{key}-URI-AutoEncoded` header.
+func (c *Context) htmxHeader(key string) string {
+	v := c.req.Header.Get(key)
+	if v == "" || c.req.Header.Get(key+"-URI-AutoEncoded") != "true" {
+		return v
+	}
+
+	if s, err := url.QueryUnescape(v); err == nil {
+		return s
+	}
+	return v
+}
@@ -0,0 +1,41 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHtmx(t *testing.T) {
+	t.Run("htmx", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("HX-Request", "true")
+		req.Header.Set("HX-Boosted", "true")
+		req.Header.Set("HX-History-Restore-Request", "true")
+		req.Header.Set("HX-Current-URL", "http://127.0.0.1/users?q=1")
+		req.Header.Set("HX-Prompt", "%E4%BD%A0%E5%A5%BD")
+		req.Header.Set("HX-Prompt-URI-AutoEncoded", "true")
+		req.Header.Set("HX-Target", "users")
+		req.Header.Set("HX-Trigger", "search")
+		req.Header.Set("HX-Trigger-Name", "q")
+
+		c := &Context{req: req}
+		require.Equal(t, HtmxRequest{
+			Request:               true,
+			Boosted:               true,
+			HistoryRestoreRequest: true,
+			CurrentURL:            "http://127.0.0.1/users?q=1",
+			Prompt:                "你好",
+			Target:                "users",
+			Trigger:               "search",
+			TriggerName:           "q",
+		}, c.Htmx())
+	})
+
+	t.Run("browser", func(t *testing.T) {
+		c := &Context{req: httptest.NewRequest(http.MethodGet, "/", nil)}
+		require.Equal(t, HtmxRequest{}, c.Htmx())
+	})
+}
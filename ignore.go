@@ -0,0 +1,71 @@
+package xun
+
+import (
+	"path"
+	"strings"
+)
+
+// isIgnored reports whether the file should be skipped by view engines
+// according to the patterns registered by WithIgnore.
+func (app *App) isIgnored(name string) bool {
+	for _, pattern := range app.ignores {
+		if matchGlob(pattern, name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchGlob reports whether name matches the shell pattern.
+//
+// It supports the syntax of path.Match in each path segment, and `**` that
+// matches zero or more segments. A pattern without any slash is matched
+// against the base name of the file, e.g. `*.swp` matches `pages/.index.html.swp`.
+func matchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		p := patterns[0]
+
+		if p == "**" {
+			// collapse consecutive `**`
+			for len(patterns) > 0 && patterns[0] == "**" {
+				patterns = patterns[1:]
+			}
+
+			if len(patterns) == 0 {
+				return true
+			}
+
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns, names[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(p, names[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		patterns = patterns[1:]
+		names = names[1:]
+	}
+
+	return len(names) == 0
+}
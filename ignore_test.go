@@ -0,0 +1,84 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		matched bool
+	}{
+		{pattern: "**/*.draft.html", name: "pages/post.draft.html", matched: true},
+		{pattern: "**/*.draft.html", name: "pages/blog/post.draft.html", matched: true},
+		{pattern: "**/*.draft.html", name: "post.draft.html", matched: true},
+		{pattern: "**/*.draft.html", name: "pages/post.html", matched: false},
+		{pattern: ".git/**", name: ".git/config", matched: true},
+		{pattern: ".git/**", name: ".git", matched: true},
+		{pattern: ".git/**", name: "public/.git/config", matched: false},
+		{pattern: "*.map", name: "public/js/app.js.map", matched: true},
+		{pattern: "*.map", name: "public/js/app.js", matched: false},
+		{pattern: "public/**/_*", name: "public/css/_partial.css", matched: true},
+		{pattern: "public/*.css", name: "public/css/skin.css", matched: false},
+		{pattern: "[", name: "public/[", matched: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.pattern+"_"+test.name, func(t *testing.T) {
+			require.Equal(t, test.matched, matchGlob(test.pattern, test.name))
+		})
+	}
+}
+
+func TestWithIgnore(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js":           {Data: []byte(`console.log("xun")`)},
+		"public/app.js.map":       {Data: []byte(`{}`)},
+		"public/.git/config":      {Data: []byte(`[core]`)},
+		"pages/index.html":        {Data: []byte(`index`)},
+		"pages/about.draft.html":  {Data: []byte(`draft`)},
+		"pages/.index.html.swp":   {Data: []byte(`swp`)},
+		"views/user.draft.html":   {Data: []byte(`{{`)},
+		"components/nav.wip.html": {Data: []byte(`{{`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys),
+		WithIgnore("**/*.draft.html", "**/*.wip.html", "*.map", "*.swp", "public/.git/**"))
+
+	app.Start()
+	defer app.Close()
+
+	tests := []struct {
+		path   string
+		status int
+	}{
+		{path: "/app.js", status: http.StatusOK},
+		{path: "/app.js.map", status: http.StatusNotFound},
+		{path: "/.git/config", status: http.StatusNotFound},
+		{path: "/", status: http.StatusOK},
+		{path: "/about.draft", status: http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			resp, err := client.Get(srv.URL + test.path)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+		})
+	}
+
+	_, ok := app.viewers["views/user.draft"]
+	require.False(t, ok)
+}
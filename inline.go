@@ -0,0 +1,59 @@
+package xun
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// InlineCSSMaxSize is the max size of the stylesheets embedded by InlineCSS.
+// Larger stylesheets should be linked, so they are cached by browsers.
+var InlineCSSMaxSize = 32 << 10
+
+// InlineCSS returns a `<style>` element with the content of the stylesheet
+// name in the public directory, e.g. the critical CSS of above-the-fold
+// content, so the page renders without a blocking request. The content is
+// cached, unless WithWatch is enabled.
+//
+// It is available as the `inlineCSS` function in templates:
+//
+//	<head>{{ inlineCSS "critical.css" }}</head>
+//
+// It returns an error if the stylesheet isn't found, is larger than
+// InlineCSSMaxSize, or contains a `</style` sequence.
+func (app *App) InlineCSS(name string) (template.HTML, error) {
+	name = path.Clean(strings.TrimPrefix(name, "/"))
+
+	if !app.watch {
+		if v, ok := app.inlined.Load(name); ok {
+			return v.(template.HTML), nil
+		}
+	}
+
+	if app.fsys == nil {
+		return "", fmt.Errorf("xun: inline css %q: %w", name, fs.ErrNotExist)
+	}
+
+	buf, err := fs.ReadFile(app.fsys, "public/"+name)
+	if err != nil {
+		return "", fmt.Errorf("xun: inline css %q: %w", name, err)
+	}
+
+	if len(buf) > InlineCSSMaxSize {
+		return "", fmt.Errorf("xun: inline css %q: %d bytes exceeds %d", name, len(buf), InlineCSSMaxSize)
+	}
+
+	if bytes.Contains(bytes.ToLower(buf), []byte("</style")) {
+		return "", fmt.Errorf("xun: inline css %q: unexpected </style", name)
+	}
+
+	s := template.HTML("<style>" + string(bytes.TrimSpace(buf)) + "</style>") // nolint: gosec
+	if !app.watch {
+		app.inlined.Store(name, s)
+	}
+
+	return s, nil
+}
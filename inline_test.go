@@ -0,0 +1,66 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInlineCSS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/critical.css": {Data: []byte("body{margin:0}\n")},
+		"public/evil.css":     {Data: []byte("</STYLE><script>alert(1)</script>")},
+		"public/large.css":    {Data: []byte(strings.Repeat("a", InlineCSSMaxSize+1))},
+		"pages/index.html":    {Data: []byte(`<head>{{ inlineCSS "critical.css" }}</head>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	app.Start()
+	defer app.Close()
+
+	t.Run("func", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, `<head><style>body{margin:0}</style></head>`, string(buf))
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		fsys["public/critical.css"] = &fstest.MapFile{Data: []byte("body{margin:1px}")}
+
+		s, err := app.InlineCSS("/critical.css")
+		require.NoError(t, err)
+		require.Equal(t, `<style>body{margin:0}</style>`, string(s))
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		_, err := app.InlineCSS("missing.css")
+		require.Error(t, err)
+	})
+
+	t.Run("closing_tag", func(t *testing.T) {
+		_, err := app.InlineCSS("evil.css")
+		require.Error(t, err)
+	})
+
+	t.Run("too_large", func(t *testing.T) {
+		_, err := app.InlineCSS("large.css")
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,287 @@
+package xun
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// JobPrefix is the URL prefix of the status endpoints of the jobs submitted by c.Submit.
+const JobPrefix = "/_jobs"
+
+// JobStatus is the status of a job.
+type JobStatus string
+
+const (
+	JobPending JobStatus = "pending"
+	JobRunning JobStatus = "running"
+	JobDone    JobStatus = "done"
+	JobFailed  JobStatus = "failed"
+)
+
+// Job is the status of a job submitted by c.Submit.
+type Job struct {
+	ID     string    `json:"id"`
+	Status JobStatus `json:"status"`
+	URL    string    `json:"url"`
+	Result any       `json:"result,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// Finished reports whether the job is done or failed.
+func (j Job) Finished() bool {
+	return j.Status == JobDone || j.Status == JobFailed
+}
+
+// jobState is a job waiting for or running on a worker.
+type jobState struct {
+	mu    sync.Mutex
+	job   Job
+	view  string
+	run   func(ctx context.Context) (any, error)
+	chain chain // of the route that submitted the job
+}
+
+// snapshot returns a copy of the job.
+func (s *jobState) snapshot() Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.job
+}
+
+// Submit turns a long handler into submit-now/poll-later. The run function is
+// enqueued on the workers of the app, see WithJobWorkers, and 202 Accepted is
+// written with the status URL of the job at JobPrefix/{id} in the Location
+// header. The status endpoint writes the Job as JSON, or renders the view with
+// the Job for htmx requests and clients preferring text/html. While the job
+// isn't finished, the view is wrapped in a fragment that polls the status by
+// htmx, see WithJobPollInterval.
+//
+//	app.Post("/reports", func(c *xun.Context) error {
+//		return c.Submit("views/report-status", func(ctx context.Context) (any, error) {
+//			return buildReport(ctx)
+//		})
+//	})
+//
+// The status endpoint runs the middlewares of the route that submitted the job,
+// e.g. its authentication. Jobs run with the context of the app, so they are
+// cancelled when the app is closed, and the jobs still queued fail with
+// ErrJobCancelled. Finished jobs are kept for WithJobTTL. If the queue is full,
+// see WithJobQueueSize, 503 Service Unavailable is written.
+func (c *Context) Submit(view string, run func(ctx context.Context) (any, error)) error {
+	id := newRandomID()
+	s := &jobState{
+		job:   Job{ID: id, Status: JobPending, URL: JobPrefix + "/" + id},
+		view:  view,
+		run:   run,
+		chain: c.Routing.chain,
+	}
+
+	app := c.app
+	app.startJobs()
+
+	if app.ctx.Err() != nil {
+		c.WriteStatus(http.StatusServiceUnavailable)
+		return ErrCancelled
+	}
+
+	app.jobStates.Store(id, s)
+	select {
+	case app.jobQueue <- s:
+	default:
+		app.jobStates.Delete(id)
+		c.WriteStatus(http.StatusServiceUnavailable)
+		return ErrCancelled
+	}
+
+	c.WriteHeader("Location", s.job.URL)
+	return app.writeJob(c.rw, c.req, s, http.StatusAccepted)
+}
+
+// startJobs starts the workers and registers the status endpoint of jobs once.
+func (app *App) startJobs() {
+	app.jobOnce.Do(func() {
+		if app.jobQueueSize <= 0 {
+			app.jobQueueSize = 1024
+		}
+		if app.jobTTL <= 0 {
+			app.jobTTL = 10 * time.Minute
+		}
+		if app.jobPollInterval <= 0 {
+			app.jobPollInterval = time.Second
+		}
+
+		app.jobQueue = make(chan *jobState, app.jobQueueSize)
+
+		n := app.jobWorkers
+		if n <= 0 {
+			n = 4
+		}
+		for i := 0; i < n; i++ {
+			go app.runJobs()
+		}
+
+		// routes are read by the console and Navigation while serving
+		app.mu.Lock()
+		app.createHandler("GET "+JobPrefix+"/{id}", app.serveJob, nil, &jobChain{app: app})
+		app.mu.Unlock()
+	})
+}
+
+// runJobs runs the jobs of the queue until the app is closed, and then fails
+// the jobs still queued.
+func (app *App) runJobs() {
+	for {
+		select {
+		case s := <-app.jobQueue:
+			if app.ctx.Err() != nil {
+				app.finishJob(s, nil, ErrJobCancelled)
+				continue
+			}
+			app.runJob(s)
+		case <-app.ctx.Done():
+			for {
+				select {
+				case s := <-app.jobQueue:
+					app.finishJob(s, nil, ErrJobCancelled)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// runJob runs the job.
+func (app *App) runJob(s *jobState) {
+	s.mu.Lock()
+	s.job.Status = JobRunning
+	s.mu.Unlock()
+
+	result, err := func() (result any, err error) {
+		defer func() {
+			if p := recover(); p != nil {
+				err = fmt.Errorf("xun: job panic: %v", p)
+			}
+		}()
+		return s.run(app.ctx)
+	}()
+
+	app.finishJob(s, result, err)
+}
+
+// finishJob sets the result of the job, and deletes its status after the TTL of jobs.
+func (app *App) finishJob(s *jobState, result any, err error) {
+	s.mu.Lock()
+	if err != nil {
+		app.logger.Error("xun: job", slog.String("id", s.job.ID), slog.Any("err", err))
+		s.job.Status = JobFailed
+		s.job.Error = err.Error()
+	} else {
+		s.job.Status = JobDone
+		s.job.Result = result
+	}
+	s.mu.Unlock()
+
+	time.AfterFunc(app.jobTTL, func() {
+		app.jobStates.Delete(s.job.ID)
+	})
+}
+
+// jobOf returns the job of the status request.
+func (app *App) jobOf(r *http.Request) (*jobState, bool) {
+	v, ok := app.jobStates.Load(r.PathValue("id"))
+	if !ok {
+		return nil, false
+	}
+	return v.(*jobState), true
+}
+
+// serveJob writes the status of the job.
+func (app *App) serveJob(c *Context) error {
+	s, ok := app.jobOf(c.req)
+	if !ok {
+		c.WriteStatus(http.StatusNotFound)
+		return ErrCancelled
+	}
+
+	c.WriteHeader("Cache-Control", "no-store")
+	return app.writeJob(c.rw, c.req, s, http.StatusOK)
+}
+
+// writeJob writes the job with the status code, as JSON or rendered by its view.
+func (app *App) writeJob(w http.ResponseWriter, r *http.Request, s *jobState, status int) error {
+	job := s.snapshot()
+
+	var buf bytes.Buffer
+	contentType := "application/json"
+
+	app.mu.RLock()
+	v, ok := app.viewers[s.view].(*HtmlViewer)
+	app.mu.RUnlock()
+
+	if ok && prefersHtml(r) {
+		contentType = "text/html; charset=utf-8"
+		if !job.Finished() {
+			buf.WriteString(`<div hx-get="` + html.EscapeString(job.URL) + `" hx-trigger="every ` + htmxInterval(app.jobPollInterval) + `" hx-swap="outerHTML" aria-busy="true">`)
+		}
+		if err := v.template.execute(&buf, r, job); err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return err
+		}
+		if !job.Finished() {
+			buf.WriteString(`</div>`)
+		}
+	} else if err := json.NewEncoder(&buf).Encode(job); err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return err
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(status)
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+// htmxInterval formats the duration as an htmx interval, e.g. "1s" or "500ms".
+func htmxInterval(d time.Duration) string {
+	if d%time.Second == 0 {
+		return strconv.FormatInt(int64(d/time.Second), 10) + "s"
+	}
+	return strconv.FormatInt(d.Milliseconds(), 10) + "ms"
+}
+
+// jobChain runs the status requests of jobs with the middlewares of the routes
+// that submitted them. Requests of unknown jobs run with the middlewares of the app.
+type jobChain struct {
+	app *App
+}
+
+func (jc *jobChain) of(c *Context) chain {
+	if s, ok := jc.app.jobOf(c.req); ok && s.chain != nil {
+		return s.chain
+	}
+	return jc.app
+}
+
+func (jc *jobChain) Next(hf HandleFunc) HandleFunc {
+	return func(c *Context) error {
+		return jc.of(c).Next(hf)(c)
+	}
+}
+
+func (jc *jobChain) handleError(c *Context, err error) bool {
+	eh, ok := jc.of(c).(errorHandler)
+	return ok && eh.handleError(c, err)
+}
+
+func (jc *jobChain) middlewareList() []Middleware {
+	return jc.app.middlewareList()
+}
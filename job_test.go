@@ -0,0 +1,204 @@
+package xun
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubmit(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/report.html": {Data: []byte(`<p>{{ .Status }}{{ with .Result }}:{{ . }}{{ end }}</p>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithJobWorkers(1))
+	defer app.Close()
+
+	release := make(chan struct{})
+	app.Post("/reports", func(c *Context) error {
+		return c.Submit("views/report", func(ctx context.Context) (any, error) {
+			<-release
+			return "ready", nil
+		})
+	})
+
+	app.Post("/broken", func(c *Context) error {
+		return c.Submit("views/report", func(ctx context.Context) (any, error) {
+			return nil, errors.New("disk full")
+		})
+	})
+
+	app.Start()
+
+	get := func(url string, htmx bool) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	resp, err := client.Post(srv.URL+"/reports", "application/json", nil)
+	require.NoError(t, err)
+	var job Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+	require.Equal(t, job.URL, resp.Header.Get("Location"))
+	require.Equal(t, JobPrefix+"/"+job.ID, job.URL)
+	require.False(t, job.Finished())
+
+	status, body := get(srv.URL+job.URL, true)
+	require.Equal(t, http.StatusOK, status)
+	require.Regexp(t, `^<div hx-get="`+job.URL+`" hx-trigger="every 1s" hx-swap="outerHTML" aria-busy="true"><p>(pending|running)</p></div>$`, body)
+
+	close(release)
+	require.Eventually(t, func() bool {
+		_, body := get(srv.URL+job.URL, false)
+		return body == `{"id":"`+job.ID+`","status":"done","url":"`+job.URL+`","result":"ready"}`+"\n"
+	}, time.Second, 10*time.Millisecond)
+
+	status, body = get(srv.URL+job.URL, true)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `<p>done:ready</p>`, body)
+
+	resp, err = client.Post(srv.URL+"/broken", "application/json", nil)
+	require.NoError(t, err)
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	resp.Body.Close()
+
+	require.Eventually(t, func() bool {
+		_, body := get(srv.URL+job.URL, false)
+		return body == `{"id":"`+job.ID+`","status":"failed","url":"`+job.URL+`","error":"disk full"}`+"\n"
+	}, time.Second, 10*time.Millisecond)
+
+	status, _ = get(srv.URL+JobPrefix+"/missing", false)
+	require.Equal(t, http.StatusNotFound, status)
+}
+
+func TestSubmitMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithJobWorkers(1))
+	defer app.Close()
+
+	admin := app.Group("/admin")
+	admin.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if c.Request().Header.Get("X-Token") != "secret" {
+				c.WriteStatus(http.StatusUnauthorized)
+				return ErrCancelled
+			}
+			return next(c)
+		}
+	})
+
+	admin.Post("/reports", func(c *Context) error {
+		return c.Submit("", func(ctx context.Context) (any, error) {
+			return "ready", nil
+		})
+	})
+
+	do := func(method, url, token string) *http.Response {
+		req, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		if token != "" {
+			req.Header.Set("X-Token", token)
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := do(http.MethodPost, srv.URL+"/admin/reports", "secret")
+	var job Job
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+	resp.Body.Close()
+	require.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	resp = do(http.MethodGet, srv.URL+job.URL, "")
+	resp.Body.Close()
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = do(http.MethodGet, srv.URL+job.URL, "secret")
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "no-store", resp.Header.Get("Cache-Control"))
+}
+
+func TestSubmitClose(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithJobWorkers(1), WithJobQueueSize(4), WithJobPollInterval(500*time.Millisecond))
+
+	running := make(chan struct{}, 1)
+	app.Post("/reports", func(c *Context) error {
+		return c.Submit("", func(ctx context.Context) (any, error) {
+			running <- struct{}{}
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+	})
+
+	submit := func() Job {
+		resp, err := client.Post(srv.URL+"/reports", "application/json", nil)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var job Job
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&job))
+		require.Equal(t, http.StatusAccepted, resp.StatusCode)
+		return job
+	}
+
+	first := submit()
+	<-running
+	queued := submit()
+
+	app.Close()
+
+	require.Eventually(t, func() bool {
+		v, ok := app.jobStates.Load(queued.ID)
+		return ok && v.(*jobState).snapshot().Status == JobFailed
+	}, time.Second, 10*time.Millisecond)
+
+	v, _ := app.jobStates.Load(queued.ID)
+	require.Equal(t, ErrJobCancelled.Error(), v.(*jobState).snapshot().Error)
+
+	v, _ = app.jobStates.Load(first.ID)
+	require.Eventually(t, func() bool {
+		return v.(*jobState).snapshot().Status == JobFailed
+	}, time.Second, 10*time.Millisecond)
+
+	resp, err := client.Post(srv.URL+"/reports", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	require.Equal(t, "500ms", htmxInterval(500*time.Millisecond))
+	require.Equal(t, "2s", htmxInterval(2*time.Second))
+}
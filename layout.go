@@ -0,0 +1,53 @@
+package xun
+
+import (
+	"net/http"
+	"strings"
+)
+
+// LayoutDefault is the variant returned by a LayoutRule to force the default layouts.
+const LayoutDefault = "default"
+
+// LayoutVary are the request headers that the layout variants vary by, e.g.
+// for MobileLayout. They are added to the Vary header of the pages rendered
+// with layouts if WithLayoutRules is used.
+var LayoutVary = []string{"Sec-CH-UA-Mobile", "User-Agent"}
+
+// LayoutRule returns the variant of the page layouts for the request, e.g.
+// "mobile" for layouts/main.mobile.html, or "" to defer to the next rule.
+// If a variant layout doesn't exist, the default one is used.
+type LayoutRule func(r *http.Request) string
+
+// MobileLayout selects the "mobile" variant for phones, detected by the
+// Sec-CH-UA-Mobile client hint or the User-Agent header.
+func MobileLayout(r *http.Request) string {
+	if requestUserAgent(r).IsMobile() {
+		return "mobile"
+	}
+	return ""
+}
+
+// LayoutQuery selects the variant by the query parameter, e.g. ?layout=mobile,
+// so users can override the detected one. It should be the first rule.
+func LayoutQuery(name string) LayoutRule {
+	return func(r *http.Request) string {
+		v := r.URL.Query().Get(name)
+		if strings.ContainsAny(v, "./\\") {
+			return ""
+		}
+		return v
+	}
+}
+
+// layoutVariant returns the variant selected by the first matched layout rule.
+func (app *App) layoutVariant(r *http.Request) string {
+	for _, rule := range app.layoutRules {
+		if v := rule(r); v != "" {
+			if v == LayoutDefault {
+				return ""
+			}
+			return v
+		}
+	}
+	return ""
+}
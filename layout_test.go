@@ -0,0 +1,76 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLayoutRules(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html":        {Data: []byte(`<html><nav>full menu</nav>{{ block "content" . }}{{ end }}</html>`)},
+		"layouts/main.mobile.html": {Data: []byte(`<html><nav>menu</nav>{{ block "content" . }}{{ end }}</html>`)},
+		"layouts/plain.html":       {Data: []byte(`<div>{{ block "content" . }}{{ end }}</div>`)},
+		"pages/index.html": {Data: []byte(`<!--layout:main-->
+{{ define "content" }}<h1>home</h1>{{ end }}`)},
+		"pages/about.html": {Data: []byte(`<!--layout:plain-->
+{{ define "content" }}<h1>about</h1>{{ end }}`)},
+		"views/home.html": {Data: []byte(`<!--layout:main-->
+{{ define "content" }}<h1>home</h1>{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithLayoutRules(LayoutQuery("layout"), MobileLayout))
+	defer app.Close()
+
+	// the responses of layout variants are coalesced separately
+	app.Get("/cached", func(c *Context) error {
+		return c.View(nil, "views/home")
+	}, WithCoalesce(time.Minute))
+
+	app.Start()
+
+	get := func(path string, header ...string) string {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, LayoutVary, resp.Header.Values("Vary"))
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	full := `<html><nav>full menu</nav><h1>home</h1></html>`
+	mobile := `<html><nav>menu</nav><h1>home</h1></html>`
+	chrome := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"
+	iphone := "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1"
+
+	require.Equal(t, full, get("/"))
+	require.Equal(t, mobile, get("/", "User-Agent", iphone))
+	require.Equal(t, mobile, get("/", "User-Agent", chrome, "Sec-CH-UA-Mobile", "?1"))
+
+	// query override
+	require.Equal(t, mobile, get("/?layout=mobile"))
+	require.Equal(t, full, get("/?layout=default", "User-Agent", iphone))
+
+	// fallback to the default layout if the variant doesn't exist
+	require.Equal(t, `<div><h1>about</h1></div>`, get("/about", "User-Agent", iphone))
+	require.Equal(t, full, get("/?layout=../main"))
+
+	require.Equal(t, full, get("/cached"))
+	require.Equal(t, mobile, get("/cached", "User-Agent", iphone))
+}
@@ -0,0 +1,62 @@
+package xun
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"log/slog"
+	"net/url"
+)
+
+// LazyPrefix is the URL prefix of the fragment endpoints registered by app.Lazy.
+const LazyPrefix = "/_lazy"
+
+// Lazy registers a fragment endpoint at LazyPrefix/{name} that renders the view
+// with the data returned by load, e.g. for expensive page sections. The `lazy`
+// template function renders a placeholder that is swapped with the fragment by
+// htmx after first paint, so no route wiring is needed in handlers.
+//
+//	app.Lazy("stats", "views/stats", func(c *xun.Context) (any, error) {
+//		return loadStats(c.Context(), c.Request().URL.Query().Get("id"))
+//	})
+//
+//	{{ lazy "stats" "id" .ID }}
+//
+// It should be called after the App is created, when the view is loaded.
+func (app *App) Lazy(name, view string, load func(c *Context) (any, error), opts ...RoutingOption) {
+	v, ok := app.viewers[view]
+	if !ok {
+		app.logger.Error("xun: lazy view not found", slog.String("name", name), slog.String("view", view))
+		return
+	}
+
+	opts = append([]RoutingOption{WithViewer(v)}, opts...)
+
+	app.Get(LazyPrefix+"/"+url.PathEscape(name), func(c *Context) error {
+		data, err := load(c)
+		if err != nil {
+			return err
+		}
+
+		return c.View(data)
+	}, opts...)
+}
+
+// lazy returns the placeholder of the lazy fragment name. args are key/value
+// pairs of the query string that is sent to the fragment endpoint.
+func lazy(name string, args ...any) (template.HTML, error) {
+	if len(args)%2 != 0 {
+		return "", fmt.Errorf("xun: lazy %q: odd number of query arguments", name)
+	}
+
+	u := LazyPrefix + "/" + url.PathEscape(name)
+	if len(args) > 0 {
+		q := make(url.Values, len(args)/2)
+		for i := 0; i < len(args); i += 2 {
+			q.Add(fmt.Sprint(args[i]), fmt.Sprint(args[i+1]))
+		}
+		u += "?" + q.Encode()
+	}
+
+	return template.HTML(`<div hx-get="` + html.EscapeString(u) + `" hx-trigger="load" hx-swap="outerHTML" aria-busy="true"></div>`), nil // nolint: gosec
+}
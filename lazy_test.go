@@ -0,0 +1,71 @@
+package xun
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLazy(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<main>{{ lazy "stats" "id" 1 "q" "a&b" }}</main>`)},
+		"views/stats.html": {Data: []byte(`<b>{{ .ID }}:{{ .Q }}</b>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Lazy("stats", "views/stats", func(c *Context) (any, error) {
+		q := c.Request().URL.Query()
+		return map[string]string{"ID": q.Get("id"), "Q": q.Get("q")}, nil
+	})
+
+	app.Lazy("failed", "views/stats", func(c *Context) (any, error) {
+		return nil, errors.New("failed")
+	})
+
+	// missing views are not registered
+	app.Lazy("missing", "views/missing", func(c *Context) (any, error) {
+		return nil, nil
+	})
+
+	app.Start()
+
+	get := func(path string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "*/*")
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	status, body := get("/")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `<main><div hx-get="/_lazy/stats?id=1&amp;q=a%26b" hx-trigger="load" hx-swap="outerHTML" aria-busy="true"></div></main>`, body)
+
+	status, body = get("/_lazy/stats?id=1&q=a%26b")
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, `<b>1:a&amp;b</b>`, body)
+
+	status, _ = get("/_lazy/failed")
+	require.Equal(t, http.StatusInternalServerError, status)
+
+	status, _ = get("/_lazy/missing")
+	require.Equal(t, http.StatusNotFound, status)
+
+	_, err := lazy("stats", "id")
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package xun
+
+import (
+	"context"
+	"net"
+	"os"
+	"strconv"
+)
+
+// InheritFdKey is the environment variable of the listener file descriptor
+// that is passed to the new process by Upgrade.
+const InheritFdKey = "XUN_INHERIT_FD"
+
+// ListenOption is a function that configures Listen.
+type ListenOption func(*listenOptions)
+
+type listenOptions struct {
+	reusePort bool
+}
+
+// WithReusePort sets SO_REUSEPORT on the listener, so that the old and new
+// processes can accept connections on the same address during a restart.
+// It is ignored on platforms that don't support it.
+func WithReusePort() ListenOption {
+	return func(o *listenOptions) {
+		o.reusePort = true
+	}
+}
+
+// Listen announces on the TCP address addr, e.g. ":8080". If the process is
+// started by Upgrade, the inherited listener is returned instead, so that no
+// connections are refused during the restart. If the process is socket
+// activated by systemd, the first passed socket is returned.
+//
+//	ln, err := xun.Listen(":8080", xun.WithReusePort())
+//	if err != nil {
+//		panic(err)
+//	}
+//	srv.Serve(ln)
+func Listen(addr string, opts ...ListenOption) (net.Listener, error) {
+	o := &listenOptions{}
+	for _, it := range opts {
+		it(o)
+	}
+
+	if ln, err := inheritedListener(); ln != nil || err != nil {
+		return ln, err
+	}
+
+	listeners, err := SystemdListeners()
+	if err != nil {
+		return nil, err
+	}
+	if len(listeners) > 0 {
+		for _, it := range listeners[1:] {
+			it.Close()
+		}
+		return listeners[0], nil
+	}
+
+	lc := net.ListenConfig{}
+	if o.reusePort {
+		lc.Control = reusePort
+	}
+
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// inheritedListener returns the listener passed by Upgrade, or nil if there is none.
+func inheritedListener() (net.Listener, error) {
+	v := os.Getenv(InheritFdKey)
+	if v == "" {
+		return nil, nil
+	}
+
+	// the listener is inherited once, it must not be inherited by the next upgrade again
+	os.Unsetenv(InheritFdKey) // nolint: errcheck
+
+	fd, err := strconv.Atoi(v)
+	if err != nil {
+		return nil, err
+	}
+
+	f := os.NewFile(uintptr(fd), "listener")
+	defer f.Close()
+
+	return net.FileListener(f)
+}
+
+// Upgrade starts a new process of the current executable with the same
+// arguments, and passes the listener ln to it, e.g. on SIGHUP after the binary
+// is replaced. The new process gets the listener from Listen, and the current
+// process should then stop accepting connections and drain them with
+// app.Shutdown and http.Server.Shutdown.
+func Upgrade(ln net.Listener) (*os.Process, error) {
+	fl, ok := ln.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, ErrUnsupportedListener
+	}
+
+	f, err := fl.File()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+
+	// ExtraFiles[0] is the file descriptor 3 in the new process
+	return os.StartProcess(exe, os.Args, &os.ProcAttr{
+		Env:   append(os.Environ(), InheritFdKey+"=3"),
+		Files: []*os.File{os.Stdin, os.Stdout, os.Stderr, f},
+	})
+}
@@ -0,0 +1,12 @@
+//go:build !(linux || darwin || dragonfly || freebsd || netbsd || openbsd)
+
+package xun
+
+import (
+	"syscall"
+)
+
+// reusePort is a no-op on platforms without SO_REUSEPORT.
+func reusePort(network, address string, c syscall.RawConn) error {
+	return nil
+}
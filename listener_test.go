@@ -0,0 +1,69 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package xun
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen(t *testing.T) {
+	t.Run("reuse_port", func(t *testing.T) {
+		ln, err := Listen("127.0.0.1:0", WithReusePort())
+		require.NoError(t, err)
+		defer ln.Close()
+
+		ln2, err := Listen(ln.Addr().String(), WithReusePort())
+		require.NoError(t, err)
+		defer ln2.Close()
+
+		_, err = Listen(ln.Addr().String())
+		require.Error(t, err)
+	})
+
+	t.Run("inherit", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		f, err := ln.(*net.TCPListener).File()
+		require.NoError(t, err)
+		fd, err := syscall.Dup(int(f.Fd()))
+		require.NoError(t, err)
+		f.Close()
+
+		t.Setenv(InheritFdKey, strconv.Itoa(fd))
+
+		inherited, err := Listen(":0")
+		require.NoError(t, err)
+		defer inherited.Close()
+
+		require.Equal(t, ln.Addr().String(), inherited.Addr().String())
+		require.Empty(t, os.Getenv(InheritFdKey))
+
+		go func() {
+			conn, err := net.Dial("tcp", ln.Addr().String())
+			if err == nil {
+				conn.Close()
+			}
+		}()
+
+		conn, err := inherited.Accept()
+		require.NoError(t, err)
+		conn.Close()
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer ln.Close()
+
+		_, err = Upgrade(struct{ net.Listener }{ln})
+		require.ErrorIs(t, err, ErrUnsupportedListener)
+	})
+}
@@ -0,0 +1,218 @@
+package xun
+
+import (
+	"context"
+	"reflect"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoaderStats is a snapshot of the metrics of a Loader.
+type LoaderStats struct {
+	// Hits is the number of Get calls served from the cache.
+	Hits int64
+	// Misses is the number of Get calls that are not in the cache.
+	Misses int64
+	// Loads is the number of calls of the load function.
+	Loads int64
+	// Errors is the number of load calls that failed.
+	Errors int64
+	// Shared is the number of Get calls that waited for an in-flight load instead of loading.
+	Shared int64
+}
+
+// loaderStats is a Loader registered in the App, e.g. for the console.
+type loaderStats struct {
+	name  string
+	stats func() LoaderStats
+}
+
+// LoaderOption is a function that configures a Loader.
+type LoaderOption func(*loaderOptions)
+
+type loaderOptions struct {
+	ttl time.Duration
+}
+
+// WithLoaderTTL sets how long a loaded value is cached. If not set, values are
+// only shared by concurrent calls and not cached.
+func WithLoaderTTL(ttl time.Duration) LoaderOption {
+	return func(o *loaderOptions) {
+		o.ttl = ttl
+	}
+}
+
+// Loader loads values by key with single-flight and TTL caching, e.g. for
+// expensive template data that is shared by handlers and pages.
+//
+// Concurrent Get calls of the same key share one call of the load function,
+// so that a cache expiry doesn't cause a stampede. Errors are not cached, and
+// a panic of the load function is returned as a *PanicError.
+type Loader[T any] struct {
+	load func(ctx context.Context, key string) (T, error)
+	ttl  time.Duration
+
+	mu      sync.Mutex
+	entries map[string]*loaderEntry[T]
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	hits, misses, loads, errors, shared atomic.Int64
+}
+
+type loaderEntry[T any] struct {
+	done    chan struct{}
+	value   T
+	err     error
+	expires time.Time
+}
+
+// NewLoader creates a Loader with the load function. If app is not nil, the
+// Loader is closed with app.Close, so that in-flight loads are cancelled on shutdown.
+func NewLoader[T any](app *App, load func(ctx context.Context, key string) (T, error), opts ...LoaderOption) *Loader[T] {
+	o := &loaderOptions{}
+	for _, it := range opts {
+		it(o)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	l := &Loader[T]{
+		load:    load,
+		ttl:     o.ttl,
+		entries: make(map[string]*loaderEntry[T]),
+		ctx:     ctx,
+		cancel:  cancel,
+	}
+
+	if app != nil {
+		app.OnClose(l.Close)
+
+		app.mu.Lock()
+		app.loaders = append(app.loaders, loaderStats{name: reflect.TypeFor[T]().String(), stats: l.Stats})
+		app.mu.Unlock()
+	}
+
+	return l
+}
+
+// Get returns the value of key from the cache, or loads it.
+//
+// The load function is called with a context that keeps the values of ctx, but
+// is only cancelled when the Loader is closed, because its result is shared
+// by other callers. Get returns early with ctx.Err() if ctx is done before the
+// value is loaded.
+func (l *Loader[T]) Get(ctx context.Context, key string) (T, error) {
+	l.mu.Lock()
+	e, ok := l.entries[key]
+	if ok {
+		select {
+		case <-e.done:
+			if time.Now().Before(e.expires) {
+				l.mu.Unlock()
+				l.hits.Add(1)
+				return e.value, nil
+			}
+			ok = false
+		default:
+			l.shared.Add(1)
+		}
+	}
+
+	if !ok {
+		l.misses.Add(1)
+		e = &loaderEntry[T]{done: make(chan struct{})}
+		l.entries[key] = e
+
+		go l.run(ctx, key, e)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-e.done:
+		return e.value, e.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// run calls the load function and completes the entry.
+func (l *Loader[T]) run(ctx context.Context, key string, e *loaderEntry[T]) {
+	lc, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	defer cancel()
+
+	stop := context.AfterFunc(l.ctx, cancel)
+	defer stop()
+
+	l.loads.Add(1)
+	e.value, e.err = l.call(lc, key)
+
+	l.mu.Lock()
+	if e.err != nil {
+		l.errors.Add(1)
+		// the key may be forgotten and loaded again during the load
+		if l.entries[key] == e {
+			delete(l.entries, key)
+		}
+	} else {
+		e.expires = time.Now().Add(l.ttl)
+		l.sweep()
+	}
+	l.mu.Unlock()
+
+	close(e.done)
+}
+
+// call calls the load function, and returns its panic as a *PanicError, so
+// the waiting callers are not blocked forever.
+func (l *Loader[T]) call(ctx context.Context, key string) (value T, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = &PanicError{Value: p, Stack: debug.Stack()}
+		}
+	}()
+
+	return l.load(ctx, key)
+}
+
+// sweep deletes the expired values. It must be called with l.mu held.
+func (l *Loader[T]) sweep() {
+	now := time.Now()
+	for key, e := range l.entries {
+		select {
+		case <-e.done:
+			if now.After(e.expires) {
+				delete(l.entries, key)
+			}
+		default:
+		}
+	}
+}
+
+// Forget deletes the cached value of key, so the next Get loads it again.
+func (l *Loader[T]) Forget(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.entries, key)
+}
+
+// Stats returns a snapshot of the metrics of the Loader.
+func (l *Loader[T]) Stats() LoaderStats {
+	return LoaderStats{
+		Hits:   l.hits.Load(),
+		Misses: l.misses.Load(),
+		Loads:  l.loads.Load(),
+		Errors: l.errors.Load(),
+		Shared: l.shared.Load(),
+	}
+}
+
+// Close cancels the context of in-flight loads. It is safe to call Close more than once.
+func (l *Loader[T]) Close() {
+	l.cancel()
+}
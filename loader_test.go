@@ -0,0 +1,180 @@
+package xun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoader(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+
+	l := NewLoader(nil, func(ctx context.Context, key string) (string, error) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		return "user:" + key, nil
+	}, WithLoaderTTL(time.Minute))
+	defer l.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := l.Get(context.Background(), "1")
+			require.NoError(t, err)
+			require.Equal(t, "user:1", v)
+		}()
+	}
+
+	require.Eventually(t, func() bool { return l.Stats().Misses+l.Stats().Shared == 10 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	v, err := l.Get(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, "user:1", v)
+
+	s := l.Stats()
+	require.Equal(t, int64(1), s.Loads)
+	require.Equal(t, int64(1), s.Misses)
+	require.Equal(t, int64(9), s.Shared)
+	require.Equal(t, int64(1), s.Hits)
+
+	l.Forget("1")
+	_, err = l.Get(context.Background(), "1")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLoaderErrorAndExpiry(t *testing.T) {
+	var calls int32
+	errFailed := errors.New("failed")
+
+	l := NewLoader(nil, func(ctx context.Context, key string) (int32, error) {
+		n := atomic.AddInt32(&calls, 1)
+		if key == "bad" {
+			return 0, errFailed
+		}
+		return n, nil
+	}, WithLoaderTTL(20*time.Millisecond))
+
+	_, err := l.Get(context.Background(), "bad")
+	require.ErrorIs(t, err, errFailed)
+	_, err = l.Get(context.Background(), "bad")
+	require.ErrorIs(t, err, errFailed)
+	require.Equal(t, int64(2), l.Stats().Errors)
+
+	v, err := l.Get(context.Background(), "ok")
+	require.NoError(t, err)
+	require.Equal(t, int32(3), v)
+
+	time.Sleep(30 * time.Millisecond)
+
+	v, err = l.Get(context.Background(), "ok")
+	require.NoError(t, err)
+	require.Equal(t, int32(4), v)
+}
+
+func TestLoaderPanic(t *testing.T) {
+	l := NewLoader(nil, func(ctx context.Context, key string) (string, error) {
+		panic("boom")
+	})
+	defer l.Close()
+
+	_, err := l.Get(context.Background(), "1")
+
+	var pe *PanicError
+	require.ErrorAs(t, err, &pe)
+	require.Equal(t, "boom", pe.Value)
+	require.Equal(t, int64(1), l.Stats().Errors)
+}
+
+func TestLoaderForgetFailing(t *testing.T) {
+	failing := make(chan struct{})
+	release := make(chan struct{})
+
+	var calls int32
+	l := NewLoader(nil, func(ctx context.Context, key string) (string, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			<-failing
+			return "", errors.New("failed")
+		}
+		<-release
+		return "user:" + key, nil
+	}, WithLoaderTTL(time.Minute))
+	defer l.Close()
+
+	done := make(chan error)
+	go func() {
+		_, err := l.Get(context.Background(), "1")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return l.Stats().Loads == 1 }, time.Second, time.Millisecond)
+
+	// the key is loaded again while the first load is failing
+	l.Forget("1")
+	go func() {
+		_, err := l.Get(context.Background(), "1")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return l.Stats().Loads == 2 }, time.Second, time.Millisecond)
+
+	close(failing)
+	require.Error(t, <-done)
+
+	// the failing load doesn't delete the new load
+	go func() {
+		_, err := l.Get(context.Background(), "1")
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return l.Stats().Shared == 1 }, time.Second, time.Millisecond)
+
+	close(release)
+	require.NoError(t, <-done)
+	require.NoError(t, <-done)
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestLoaderClosedWithApp(t *testing.T) {
+	app := New(WithMux(http.NewServeMux()))
+
+	type traceKey struct{}
+
+	started := make(chan struct{})
+	var trace any
+
+	l := NewLoader(app, func(ctx context.Context, key string) (string, error) {
+		if key == "slow" {
+			trace = ctx.Value(traceKey{})
+			close(started)
+		}
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+
+	errs := make(chan error, 1)
+	go func() {
+		_, err := l.Get(context.WithValue(context.Background(), traceKey{}, "t1"), "slow")
+		errs <- err
+	}()
+
+	<-started
+	app.Close()
+
+	require.ErrorIs(t, <-errs, context.Canceled)
+	require.Equal(t, "t1", trace)
+
+	// loads are cancelled after the app is closed
+	_, err := l.Get(context.Background(), "other")
+	require.ErrorIs(t, err, context.Canceled)
+}
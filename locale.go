@@ -0,0 +1,141 @@
+package xun
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const localeKey = "locale"
+
+// Locale returns the locale negotiated between the Accept-Language header and
+// the locales set by WithLocales, e.g. "zh-Hant" for "zh-Hant-TW". It falls
+// back to the default locale, or the most preferred language of the client if
+// no locales are set. It is negotiated once per request.
+func (c *Context) Locale() string {
+	if locale, ok := c.Get(localeKey).(string); ok {
+		return locale
+	}
+
+	locale := negotiateLocale(c.AcceptLanguage(), c.app.locales)
+	c.Set(localeKey, locale)
+	return locale
+}
+
+// negotiateLocale returns the locale of the languages supported by the
+// locales, the default locale if none of them is matched, or the most
+// preferred language if there are no locales.
+func negotiateLocale(languages []string, locales []string) string {
+	if len(locales) == 0 {
+		if len(languages) > 0 && languages[0] != "*" {
+			return languages[0]
+		}
+		return ""
+	}
+
+	if matched, ok := MatchLocale(languages, locales); ok {
+		return matched
+	}
+
+	return locales[0]
+}
+
+// MatchLocale returns the first supported locale matched by the language
+// ranges in order of preference, by the lookup scheme of RFC 4647. A range is
+// truncated subtag by subtag until it is matched, e.g. "zh-Hant-TW" is tried
+// with "zh-Hant" and "zh". Locales are compared case-insensitively, and "_"
+// is equivalent to "-", e.g. "pt_BR" matches "pt-br".
+func MatchLocale(ranges []string, supported []string) (string, bool) {
+	for _, r := range ranges {
+		if r == "*" {
+			continue
+		}
+
+		for tag := normalizeLocale(r); tag != ""; tag = truncateLocale(tag) {
+			for _, locale := range supported {
+				if normalizeLocale(locale) == tag {
+					return locale, true
+				}
+			}
+		}
+	}
+
+	return "", false
+}
+
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+}
+
+// truncateLocale removes the last subtag of the tag, and the single-character
+// subtag before it, e.g. "x" of "de-ch-x-phonebk".
+func truncateLocale(tag string) string {
+	i := strings.LastIndexByte(tag, '-')
+	if i < 0 {
+		return ""
+	}
+
+	tag = tag[:i]
+	if i = strings.LastIndexByte(tag, '-'); i >= 0 && len(tag)-i == 2 {
+		tag = tag[:i]
+	}
+
+	return tag
+}
+
+// parseAcceptLanguage parses the languages of an Accept-Language header, e.g.
+// "da, en-GB;q=0.8, en;q=0.7", and sorts them by their q-values.
+func parseAcceptLanguage(accepted string) []string {
+	if accepted == "" {
+		return nil
+	}
+
+	type language struct {
+		tag string
+		q   float64
+	}
+
+	options := strings.Split(accepted, ",")
+	items := make([]language, 0, len(options))
+
+	for _, option := range options {
+		tag, params, _ := strings.Cut(option, ";")
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(k, "q") {
+				if f, err := strconv.ParseFloat(v, 64); err == nil {
+					q = f
+				}
+			}
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		items = append(items, language{tag: tag, q: q})
+	}
+
+	slices.SortStableFunc(items, func(a, b language) int {
+		switch {
+		case a.q > b.q:
+			return -1
+		case a.q < b.q:
+			return 1
+		}
+		return 0
+	})
+
+	languages := make([]string, len(items))
+	for i, it := range items {
+		languages[i] = it.tag
+	}
+
+	return languages
+}
@@ -0,0 +1,62 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptLanguage(t *testing.T) {
+	require.Empty(t, parseAcceptLanguage(""))
+	require.Equal(t, []string{"da", "en-GB", "en"}, parseAcceptLanguage("da, en-GB;q=0.8, en;q=0.7"))
+	require.Equal(t, []string{"zh-TW", "en", "zh", "*"}, parseAcceptLanguage("zh;q=0.5, en;q=0.8, zh-TW, fr;q=0, *;q=0.1"))
+}
+
+func TestMatchLocale(t *testing.T) {
+	supported := []string{"en", "zh-Hant", "pt_BR", "de-CH"}
+
+	tests := []struct {
+		ranges []string
+		want   string
+		ok     bool
+	}{
+		{ranges: []string{"zh-Hant-TW"}, want: "zh-Hant", ok: true},
+		{ranges: []string{"pt-br"}, want: "pt_BR", ok: true},
+		{ranges: []string{"de-CH-x-phonebk"}, want: "de-CH", ok: true},
+		{ranges: []string{"fr", "en-US"}, want: "en", ok: true},
+		{ranges: []string{"zh-Hans-CN", "*"}},
+		{ranges: []string{"de"}},
+	}
+
+	for _, test := range tests {
+		got, ok := MatchLocale(test.ranges, supported)
+		require.Equal(t, test.ok, ok, test.ranges)
+		require.Equal(t, test.want, got, test.ranges)
+	}
+}
+
+func TestLocale(t *testing.T) {
+	newContext := func(app *App, accepted string) *Context {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if accepted != "" {
+			req.Header.Set("Accept-Language", accepted)
+		}
+		return &Context{app: app, req: req}
+	}
+
+	app := New(WithLocales("en", "zh-Hant", "fr"))
+	defer app.Close()
+
+	require.Equal(t, "zh-Hant", newContext(app, "ja;q=0.9, zh-Hant-TW;q=0.8, fr;q=0.5").Locale())
+	require.Equal(t, "fr", newContext(app, "de, fr-CA;q=0.5").Locale())
+	require.Equal(t, "en", newContext(app, "ja").Locale())
+	require.Equal(t, "en", newContext(app, "").Locale())
+
+	app = New()
+	defer app.Close()
+
+	require.Equal(t, "ja", newContext(app, "en;q=0.5, ja").Locale())
+	require.Equal(t, "", newContext(app, "").Locale())
+}
@@ -0,0 +1,124 @@
+package xun
+
+import (
+	"context"
+	"html"
+	"strings"
+)
+
+// Mail is an email message rendered by app.Mail.
+type Mail struct {
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	// Subject is rendered from the "subject" block of the template if it is empty.
+	Subject string
+	// HTML is the body rendered from the template.
+	HTML string
+	// Text is the plain text body rendered from the "text" block of the template, if it is defined.
+	Text string
+	// Lang selects the localized template, e.g. "emails/welcome.vi.html" for "vi".
+	Lang   string
+	Header map[string]string
+}
+
+// MailSender sends rendered emails, e.g. by SMTP or the API of an email service.
+type MailSender interface {
+	Send(ctx context.Context, m *Mail) error
+}
+
+// MailSenderFunc is an adapter to allow the use of ordinary functions as MailSender.
+type MailSenderFunc func(ctx context.Context, m *Mail) error
+
+// Send calls f(ctx, m).
+func (f MailSenderFunc) Send(ctx context.Context, m *Mail) error {
+	return f(ctx, m)
+}
+
+// RenderMail renders the email template name in the "emails" directory with
+// data into m, e.g. "welcome" for "emails/welcome.html". Email templates
+// support layouts and components like pages.
+//
+// If m.Lang is set, the localized template, e.g. "emails/welcome.vi.html",
+// is preferred. The subject and plain text body are rendered from the
+// "subject" and "text" blocks if they are defined:
+//
+//	<!--layout:email-->
+//	{{ define "subject" }}Welcome, {{ .Name }}{{ end }}
+//	{{ define "text" }}Hi {{ .Name }}, thanks for signing up.{{ end }}
+//	{{ define "content" }}<p>Hi {{ .Name }}, thanks for signing up.</p>{{ end }}
+func (app *App) RenderMail(name string, m *Mail, data any) error {
+	t, err := app.mailTemplate(name, m.Lang)
+	if err != nil {
+		return err
+	}
+
+	buf := app.bufferPool().Get()
+	defer app.bufferPool().Put(buf)
+
+	if m.Subject == "" {
+		ok, err := t.executeTemplate(buf, "subject", data)
+		if err != nil {
+			return err
+		}
+		if ok {
+			m.Subject = strings.TrimSpace(html.UnescapeString(buf.String()))
+		}
+		buf.Reset()
+	}
+
+	ok, err := t.executeTemplate(buf, "text", data)
+	if err != nil {
+		return err
+	}
+	if ok {
+		m.Text = strings.TrimSpace(html.UnescapeString(buf.String()))
+	}
+	buf.Reset()
+
+	if err := t.execute(buf, nil, data); err != nil {
+		return err
+	}
+	m.HTML = buf.String()
+
+	return nil
+}
+
+// Mail renders the email template name with data into m, and sends it by the
+// MailSender set by WithMailSender. See RenderMail.
+func (app *App) Mail(ctx context.Context, name string, m *Mail, data any) error {
+	if app.mailSender == nil {
+		return ErrNoMailSender
+	}
+
+	if err := app.RenderMail(name, m, data); err != nil {
+		return err
+	}
+
+	return app.mailSender.Send(ctx, m)
+}
+
+// mailTemplate returns the template of the email name, preferring the localized one.
+func (app *App) mailTemplate(name, lang string) (*HtmlTemplate, error) {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	var names []string
+	if lang != "" {
+		names = append(names, "emails/"+name+"."+lang)
+		if i := strings.IndexAny(lang, "-_"); i > 0 { // en-US => en
+			names = append(names, "emails/"+name+"."+lang[:i])
+		}
+	}
+	names = append(names, "emails/"+name)
+
+	for _, n := range names {
+		if v, ok := app.viewers[n].(*HtmlViewer); ok {
+			return v.template, nil
+		}
+	}
+
+	return nil, ErrMailNotFound
+}
@@ -0,0 +1,63 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMail(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/email.html": {Data: []byte(`<html><body>{{ block "content" . }}{{ end }}</body></html>`)},
+		"emails/welcome.html": {Data: []byte(`<!--layout:email-->
+{{ define "subject" }}Welcome, {{ .Name }} & friends{{ end }}
+{{ define "text" }}Hi {{ .Name }}, thanks for signing up.{{ end }}
+{{ define "content" }}<p>Hi {{ .Name }}, thanks for signing up.</p>{{ end }}`)},
+		"emails/welcome.vi.html": {Data: []byte(`<!--layout:email-->
+{{ define "subject" }}Xin chào {{ .Name }}{{ end }}
+{{ define "content" }}<p>Xin chào {{ .Name }}</p>{{ end }}`)},
+	}
+
+	var sent []*Mail
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys), WithMailSender(MailSenderFunc(func(ctx context.Context, m *Mail) error {
+		sent = append(sent, m)
+		return nil
+	})))
+	defer app.Close()
+
+	data := map[string]string{"Name": "<Xun>"}
+
+	t.Run("render", func(t *testing.T) {
+		m := &Mail{To: []string{"xun@example.com"}}
+		require.NoError(t, app.Mail(context.Background(), "welcome", m, data))
+
+		require.Len(t, sent, 1)
+		require.Equal(t, "Welcome, <Xun> & friends", m.Subject)
+		require.Equal(t, "Hi <Xun>, thanks for signing up.", m.Text)
+		require.Equal(t, `<html><body><p>Hi &lt;Xun&gt;, thanks for signing up.</p></body></html>`, m.HTML)
+	})
+
+	t.Run("lang", func(t *testing.T) {
+		m := &Mail{Lang: "vi-VN"}
+		require.NoError(t, app.RenderMail("welcome", m, data))
+		require.Equal(t, "Xin chào <Xun>", m.Subject)
+		require.Empty(t, m.Text)
+		require.Equal(t, `<html><body><p>Xin chào &lt;Xun&gt;</p></body></html>`, m.HTML)
+
+		m = &Mail{Lang: "fr", Subject: "Bienvenue"}
+		require.NoError(t, app.RenderMail("welcome", m, data))
+		require.Equal(t, "Bienvenue", m.Subject)
+		require.Contains(t, m.HTML, "thanks for signing up")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		require.ErrorIs(t, app.RenderMail("missing", &Mail{}, nil), ErrMailNotFound)
+
+		app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+		defer app.Close()
+		require.ErrorIs(t, app.Mail(context.Background(), "welcome", &Mail{}, data), ErrNoMailSender)
+	})
+}
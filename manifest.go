@@ -0,0 +1,84 @@
+package xun
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// fingerprint returns the fingerprinted name of a static file by inserting
+// the content hash before its extension, e.g. "css/skin.css" => "css/skin.3f2a1b9c.css".
+func fingerprint(fsys fs.FS, path, name string) (string, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	ext := filepath.Ext(name)
+	return strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(h.Sum(nil))[:8] + ext, nil
+}
+
+// setManifest maps the logical name of a static file to its fingerprinted name.
+// The manifest file of WithManifestFile is updated once the app is started,
+// e.g. for the files changed in development.
+func (app *App) setManifest(name, fingerprinted string) {
+	app.mu.Lock()
+	if app.manifest == nil {
+		app.manifest = make(map[string]string)
+	}
+	app.manifest["/"+name] = "/" + fingerprinted
+	app.mu.Unlock()
+
+	if app.manifestFile != "" && app.ready.Load() {
+		if err := app.writeManifestFile(); err != nil {
+			app.logger.Error("xun: manifest", slog.String("file", app.manifestFile), slog.Any("err", err))
+		}
+	}
+}
+
+// Manifest returns a copy of the asset manifest that maps logical asset names
+// to fingerprinted paths, e.g. "/app.js" => "/app.3f2a1b9c.js".
+// It is empty unless WithFingerprint is enabled.
+func (app *App) Manifest() map[string]string {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	m := make(map[string]string, len(app.manifest))
+	for k, v := range app.manifest {
+		m[k] = v
+	}
+
+	return m
+}
+
+// WriteManifest writes the asset manifest as JSON to w, so it can be consumed by
+// external tools, e.g. saved as manifest.json in a build step.
+func (app *App) WriteManifest(w io.Writer) error {
+	return json.NewEncoder(w).Encode(app.Manifest())
+}
+
+// writeManifestFile writes the asset manifest to the file of WithManifestFile.
+func (app *App) writeManifestFile() error {
+	var buf bytes.Buffer
+	if err := app.WriteManifest(&buf); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(app.manifestFile), 0o755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(app.manifestFile, buf.Bytes(), 0o644) // nolint: gosec
+}
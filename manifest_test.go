@@ -0,0 +1,113 @@
+package xun
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js":       {Data: []byte(`console.log("xun")`)},
+		"public/css/skin.css": {Data: []byte(`body{}`)},
+		"public/index.html":   {Data: []byte(`<html></html>`)},
+		"pages/about.html":    {Data: []byte(`<script src="{{ asset "app.js" }}"></script>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithFingerprint())
+	app.Start()
+	defer app.Close()
+
+	m := app.Manifest()
+	require.Len(t, m, 2)
+	require.Regexp(t, `^/app\.[0-9a-f]{8}\.js$`, m["/app.js"])
+	require.Regexp(t, `^/css/skin\.[0-9a-f]{8}\.css$`, m["/css/skin.css"])
+	require.Equal(t, m["/app.js"], app.AssetURL("app.js"))
+
+	resp, err := client.Get(srv.URL + m["/app.js"])
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `console.log("xun")`, string(buf))
+	require.Equal(t, "public, max-age=31536000, immutable", resp.Header.Get("Cache-Control"))
+
+	resp, err = client.Get(srv.URL + "/app.js")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Empty(t, resp.Header.Get("Cache-Control"))
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/about", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `<script src="`+m["/app.js"]+`"></script>`, string(buf))
+
+	var out bytes.Buffer
+	require.NoError(t, app.WriteManifest(&out))
+
+	var written map[string]string
+	require.NoError(t, json.Unmarshal(out.Bytes(), &written))
+	require.Equal(t, m, written)
+}
+
+func TestManifestFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js": {Data: []byte(`console.log("xun")`)},
+	}
+
+	name := filepath.Join(t.TempDir(), "dist", "manifest.json")
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys), WithFingerprint(), WithManifestFile(name))
+	require.NoError(t, app.Start())
+	defer app.Close()
+
+	read := func() map[string]string {
+		buf, err := os.ReadFile(name)
+		require.NoError(t, err)
+
+		var m map[string]string
+		require.NoError(t, json.Unmarshal(buf, &m))
+		return m
+	}
+
+	m := read()
+	require.Len(t, m, 1)
+	require.Equal(t, app.Manifest(), m)
+
+	// the file is updated with the changed files
+	app.setManifest("skin.css", "skin.3f2a1b9c.css")
+	require.Equal(t, "/skin.3f2a1b9c.css", read()["/skin.css"])
+}
+
+func TestWithoutFingerprint(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.js": {Data: []byte(`console.log("xun")`)},
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+
+	require.Empty(t, app.Manifest())
+	require.Equal(t, "/app.js", app.AssetURL("app.js"))
+}
@@ -28,7 +28,14 @@ func NewMimeType(t string) MimeType {
 
 }
 
+// Match reports whether the MIME type is acceptable for the accept type.
+// Wildcards are matched, and "text/xml" and "application/xml" are treated as
+// equivalent (RFC 7303).
 func (m *MimeType) Match(accept MimeType) bool {
+	if m.isXml() && accept.isXml() {
+		return true
+	}
+
 	if m.Type != accept.Type && (m.Type != "*" && accept.Type != "*") {
 		return false
 	}
@@ -40,6 +47,11 @@ func (m *MimeType) Match(accept MimeType) bool {
 	return false
 }
 
+// isXml reports whether the MIME type is "text/xml" or "application/xml".
+func (m *MimeType) isXml() bool {
+	return m.SubType == "xml" && (m.Type == "text" || m.Type == "application")
+}
+
 func (m *MimeType) String() string {
 	return m.Type + "/" + m.SubType
 }
@@ -57,3 +69,39 @@ func GetMimeType(file string, buf []byte) (MimeType, string) {
 	}
 	return NewMimeType(mt[:i]), mt[i:]
 }
+
+// contentType returns the MIME type registered by WithMimeTypes for the extension of the file.
+// It returns an empty string if no custom MIME type is registered.
+func (app *App) contentType(file string) string {
+	if app.mimeTypes == nil {
+		return ""
+	}
+
+	return app.mimeTypes[strings.ToLower(filepath.Ext(file))]
+}
+
+// acceptsHtml reports whether the client prefers text/html to JSON by the
+// Accept header. Wildcards are skipped, so API clients sending `*/*` get JSON.
+func acceptsHtml(r *http.Request) bool {
+	for _, accept := range parseAccept(r.Header.Get("Accept")) {
+		if accept.Type == "*" || accept.SubType == "*" {
+			continue
+		}
+
+		if jsonViewerMime.Match(accept) {
+			return false
+		}
+
+		if htmlViewerMime.Match(accept) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prefersHtml reports whether the request is sent by htmx, or the client
+// prefers text/html to JSON.
+func prefersHtml(r *http.Request) bool {
+	return r.Header.Get("HX-Request") == "true" || acceptsHtml(r)
+}
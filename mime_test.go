@@ -2,7 +2,10 @@ package xun
 
 import (
 	"mime"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stretchr/testify/require"
 )
@@ -103,3 +106,43 @@ func TestMime(t *testing.T) {
 	}
 
 }
+
+func TestWithMimeTypes(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/app.wasm":         {Data: []byte{0x00, 0x61, 0x73, 0x6d}},
+		"public/site.webmanifest": {Data: []byte(`{"name":"xun"}`)},
+		"public/skin.css":         {Data: []byte(`body { color: red; }`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithMimeTypes(map[string]string{
+		".WASM":       "application/wasm",
+		"webmanifest": "application/manifest+json",
+	}))
+
+	app.Start()
+	defer app.Close()
+
+	tests := []struct {
+		path        string
+		contentType string
+	}{
+		{path: "/app.wasm", contentType: "application/wasm"},
+		{path: "/site.webmanifest", contentType: "application/manifest+json"},
+		{path: "/skin.css", contentType: "text/css; charset=utf-8"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path, func(t *testing.T) {
+			resp, err := client.Get(srv.URL + test.path)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, test.contentType, resp.Header.Get("Content-Type"))
+		})
+	}
+}
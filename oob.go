@@ -0,0 +1,125 @@
+package xun
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+)
+
+// oobFragment is a fragment that is swapped out of band by c.OOB.
+type oobFragment struct {
+	name string
+	data any
+	swap string
+}
+
+// OOB appends the fragment of the template name, a component or a view,
+// rendered with the data after the main content of htmx responses, so a
+// handler can update several regions of the page, e.g. a flash message and a
+// counter. The `hx-swap-oob` attribute is added to its root element with the
+// swap, "true" by default, e.g. "outerHTML:#flash" or "beforeend:#messages".
+//
+//	c.OOB("components/flash", flash)
+//	c.OOB("components/cart-count", cart, "innerHTML:#cart-count")
+//	return c.View(items, "views/cart/items")
+//
+// Fragments are only rendered for htmx requests with HtmlViewer, and the
+// components the client doesn't have access to are omitted. See WithAccess.
+func (c *Context) OOB(name string, data any, swap ...string) {
+	f := oobFragment{name: name, data: data, swap: "true"}
+	if len(swap) > 0 && swap[0] != "" {
+		f.swap = swap[0]
+	}
+
+	c.oob = append(c.oob, f)
+}
+
+// renderOOBs renders the fragments of c.OOB before the main content is
+// written, so errors can still be written as usual.
+func (c *Context) renderOOBs() ([][]byte, error) {
+	if len(c.oob) == 0 || !c.Htmx().Request {
+		return nil, nil
+	}
+
+	items := make([][]byte, 0, len(c.oob))
+	for _, f := range c.oob {
+		buf, err := c.app.renderOOB(c, f.name, f.data)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, addSwapOOB(buf, f.swap))
+	}
+
+	return items, nil
+}
+
+// renderOOB renders the component or view name with the data.
+func (app *App) renderOOB(c *Context, name string, data any) ([]byte, error) {
+	app.mu.RLock()
+	_, ok := app.components[name]
+	app.mu.RUnlock()
+
+	if ok {
+		s, err := app.component(c.req, name, data)
+		return []byte(s), err
+	}
+
+	v, ok := app.viewers[name].(*HtmlViewer)
+	if !ok {
+		return nil, fmt.Errorf("xun: oob %q not found", name)
+	}
+
+	var buf bytes.Buffer
+	if err := v.template.execute(&buf, c.req, data); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// addSwapOOB adds the hx-swap-oob attribute to the root element of the
+// fragment, unless it has one already.
+func addSwapOOB(fragment []byte, swap string) []byte {
+	i := 0
+	for {
+		n := bytes.IndexByte(fragment[i:], '<')
+		if n < 0 {
+			return fragment
+		}
+		i += n
+
+		if bytes.HasPrefix(fragment[i:], []byte("<!--")) {
+			end := bytes.Index(fragment[i:], []byte("-->"))
+			if end < 0 {
+				return fragment
+			}
+			i += end + 3
+			continue
+		}
+		break
+	}
+
+	end := bytes.IndexByte(fragment[i:], '>')
+	if end < 0 {
+		return fragment
+	}
+	end += i
+
+	if bytes.Contains(fragment[i:end], []byte("hx-swap-oob")) {
+		return fragment
+	}
+
+	if end > i && fragment[end-1] == '/' {
+		end-- // self-closing tag, e.g. <img />
+	}
+
+	buf := make([]byte, 0, len(fragment)+len(swap)+15)
+	buf = append(buf, fragment[:end]...)
+	buf = append(buf, ` hx-swap-oob="`...)
+	buf = append(buf, html.EscapeString(swap)...)
+	buf = append(buf, '"')
+	buf = append(buf, fragment[end:]...)
+
+	return buf
+}
@@ -0,0 +1,74 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOOB(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/flash.html": {Data: []byte(`<!-- flash --><div id="flash">{{ . }}</div>`)},
+		"components/count.html": {Data: []byte(`<span>{{ . }}</span>`)},
+		"components/admin.html": {Data: []byte("---\naccess: admin\n---\n<b>admin</b>")},
+		"views/items.html":      {Data: []byte(`<ul>{{ range . }}<li>{{ . }}</li>{{ end }}</ul>`)},
+		"views/hr.html":         {Data: []byte(`<hr/>`)},
+		"views/oob.html":        {Data: []byte(`<p hx-swap-oob="afterend:#x">oob</p>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Get("/items", func(c *Context) error {
+		c.OOB("components/flash", "Saved")
+		c.OOB("components/count", 2, "innerHTML:#count")
+		c.OOB("components/admin", nil)
+		c.OOB("views/hr", nil)
+		c.OOB("views/oob", nil)
+		return c.View([]string{"a", "b"}, "views/items")
+	})
+
+	app.Get("/missing", func(c *Context) error {
+		c.OOB("components/missing", nil)
+		return c.View([]string{"a"}, "views/items")
+	})
+
+	app.Start()
+
+	get := func(path string, hx bool) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		if hx {
+			req.Header.Set("HX-Request", "true")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	_, body := get("/items", true)
+	require.Equal(t, `<ul><li>a</li><li>b</li></ul>`+
+		`<div id="flash" hx-swap-oob="true">Saved</div>`+
+		`<span hx-swap-oob="innerHTML:#count">2</span>`+
+		`<hr hx-swap-oob="true"/>`+
+		`<p hx-swap-oob="afterend:#x">oob</p>`, body)
+
+	// fragments are not rendered for normal navigations
+	_, body = get("/items", false)
+	require.Equal(t, `<ul><li>a</li><li>b</li></ul>`, body)
+
+	status, _ := get("/missing", true)
+	require.Equal(t, http.StatusInternalServerError, status)
+}
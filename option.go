@@ -1,9 +1,13 @@
 package xun
 
 import (
+	"context"
 	"io/fs"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"strings"
+	"time"
 )
 
 // Option is a function that takes a pointer to an App and modifies it.
@@ -38,6 +42,15 @@ func WithFsys(fsys fs.FS) Option {
 	}
 }
 
+// WithFsysArchive sets a zip or tar.gz archive as the fs.FS for the App.
+// It enables single-artifact deployments, e.g. `app.zip` built by CI, beyond go:embed.
+// The archive is loaded into memory when the App is created. See OpenArchive.
+func WithFsysArchive(name string) Option {
+	return func(app *App) {
+		app.archive = name
+	}
+}
+
 // WithHandlerViewers sets the Viewer for a route handler.
 // If not set, it will use JsonViewer.
 func WithHandlerViewers(v ...Viewer) Option {
@@ -46,6 +59,15 @@ func WithHandlerViewers(v ...Viewer) Option {
 	}
 }
 
+// WithExporter registers the viewer v to export route data with the extension
+// ext by WithExport, e.g. WithExporter("tsv", &TsvViewer{}). "csv" and
+// "xlsx" are registered with CsvViewer and XlsxViewer by default.
+func WithExporter(ext string, v Viewer) Option {
+	return func(app *App) {
+		app.exporters[ext] = v
+	}
+}
+
 // WithViewEngines sets the ViewEngines for the App.
 // If not set, it will use the default ViewEngines.
 func WithViewEngines(ve ...ViewEngine) Option {
@@ -85,3 +107,376 @@ func WithCompressor(c ...Compressor) Option {
 		app.compressors = c
 	}
 }
+
+// WithMimeTypes registers custom MIME types by file extension for static files.
+// The extension may be given with or without the leading dot, e.g. ".wasm" or "wasm".
+//
+// It overrides the host OS mime table, so files like `.wasm`, `.mjs`, `.avif`
+// or `.webmanifest` are always served with the correct Content-Type.
+//
+//	xun.WithMimeTypes(map[string]string{
+//		".wasm":        "application/wasm",
+//		".webmanifest": "application/manifest+json",
+//	})
+func WithMimeTypes(types map[string]string) Option {
+	return func(app *App) {
+		if app.mimeTypes == nil {
+			app.mimeTypes = make(map[string]string)
+		}
+
+		for ext, mt := range types {
+			ext = strings.ToLower(ext)
+			if !strings.HasPrefix(ext, ".") {
+				ext = "." + ext
+			}
+			app.mimeTypes[ext] = mt
+		}
+	}
+}
+
+// WithIgnore sets glob patterns of files that are skipped by view engines
+// when they scan the fs.FS, e.g. editor temp files, source maps or
+// partials-in-progress, instead of exposing them as routes.
+//
+// Patterns use the syntax of path.Match in each path segment, `**` matches
+// zero or more directories, and a pattern without any slash matches the base
+// name of files in any directory.
+//
+//	xun.WithIgnore("**/*.draft.html", ".git/**", "*.map", "*.swp")
+func WithIgnore(patterns ...string) Option {
+	return func(app *App) {
+		app.ignores = append(app.ignores, patterns...)
+	}
+}
+
+// WithSignKey sets the secret key used by SignURL and VerifySignedURL.
+// It should be set in production. If not set, a random key is generated on
+// startup with a warning, so signed URLs, e.g. of emails, are only valid for
+// the current process, and are rejected by other instances.
+func WithSignKey(key []byte) Option {
+	return func(app *App) {
+		app.signKey = key
+	}
+}
+
+// WithAssetHost sets the host that asset URLs are prefixed with, e.g. "https://cdn.example.com".
+// It is used by the `asset` template function and AssetURL in production, and
+// ignored when WithWatch is enabled, so assets are served locally in development.
+func WithAssetHost(host string) Option {
+	return func(app *App) {
+		app.assetHost = strings.TrimSuffix(host, "/")
+	}
+}
+
+// WithPreconnect declares the external origins of the app, e.g. the CDN of
+// assets, fonts and analytics. The responses of html requests include a
+// `Link: <origin>; rel=preconnect` header, and the `preconnect` template
+// function emits the matching tags, so browsers open the connections early.
+// The connections are opened without CORS, see WithPreconnectCrossOrigin for
+// the origins of fonts and the resources fetched with CORS.
+//
+//	xun.WithPreconnect("https://cdn.example.com")
+func WithPreconnect(origins ...string) Option {
+	return func(app *App) {
+		app.addPreconnects("", origins)
+	}
+}
+
+// WithPreconnectCrossOrigin declares the external origins like WithPreconnect,
+// but the connections are opened with the CORS mode crossOrigin, "anonymous"
+// or "use-credentials", e.g. for fonts, or scripts with a crossorigin
+// attribute. The Link header and the tags use the same mode, otherwise the
+// browser can't reuse the connections.
+//
+//	xun.WithPreconnectCrossOrigin("anonymous", "https://fonts.gstatic.com")
+func WithPreconnectCrossOrigin(crossOrigin string, origins ...string) Option {
+	return func(app *App) {
+		app.addPreconnects(crossOrigin, origins)
+	}
+}
+
+// WithBuildCommand registers an external build command (e.g. tailwindcss, esbuild)
+// that is run on startup and whenever a file matched by the glob patterns is changed.
+// It is only enabled with WithWatch, so frontend tooling integrates in development
+// without a separate dev server.
+//
+// The command should write its outputs to the public directory, so they are served
+// by the StaticViewEngine. The patterns must not match the outputs, otherwise the
+// command is triggered by its own outputs.
+//
+//	xun.WithBuildCommand([]string{"**/*.html", "src/*.css"}, "npx", "tailwindcss", "-i", "./src/app.css", "-o", "./app/public/app.css")
+func WithBuildCommand(patterns []string, name string, args ...string) Option {
+	return func(app *App) {
+		app.buildHooks = append(app.buildHooks, &buildHook{
+			patterns: patterns,
+			name:     name,
+			args:     args,
+		})
+	}
+}
+
+// WithFingerprint enables content hash fingerprinting of static files.
+//
+// Each file in the public directory is also served by its fingerprinted name,
+// e.g. "/app.3f2a1b9c.js" for "/app.js", with an immutable Cache-Control header.
+// The `asset` template function and AssetURL resolve logical names with the
+// manifest, which can be exported by WriteManifest or WithManifestFile.
+func WithFingerprint() Option {
+	return func(app *App) {
+		app.fingerprint = true
+	}
+}
+
+// WithManifestFile writes the asset manifest of WithFingerprint as JSON to the
+// file name, e.g. "dist/manifest.json", when the app is started, so external
+// tools can consume it. The file is rewritten when the static files are
+// changed in development.
+func WithManifestFile(name string) Option {
+	return func(app *App) {
+		app.manifestFile = name
+	}
+}
+
+// WithErrorReporter sets the Reporter that unhandled errors and panics of
+// requests are reported to, e.g. Sentry. If not set, nothing is reported.
+func WithErrorReporter(r Reporter) Option {
+	return func(app *App) {
+		app.reporter = r
+	}
+}
+
+// WithTrustedProxies sets the IP addresses or CIDR ranges of the reverse proxies,
+// e.g. "10.0.0.0/8". X-Forwarded-Proto and X-Forwarded-Host are only trusted
+// in c.AbsoluteURL, and X-Forwarded-For in c.ClientIP, if the request is sent
+// by them. Invalid entries are ignored.
+func WithTrustedProxies(proxies ...string) Option {
+	return func(app *App) {
+		for _, it := range proxies {
+			if p, err := netip.ParsePrefix(it); err == nil {
+				app.trustedProxies = append(app.trustedProxies, p.Masked())
+			} else if a, err := netip.ParseAddr(it); err == nil {
+				app.trustedProxies = append(app.trustedProxies, netip.PrefixFrom(a, a.BitLen()))
+			}
+		}
+	}
+}
+
+// WithBaseContext sets the app-level context that every request context is
+// derived from, e.g. a context carrying loggers and cancelled by shutdown
+// signals. Handlers can observe the server shutdown with c.Context().Done().
+//
+// The values of the request context take precedence over the base context.
+func WithBaseContext(ctx context.Context) Option {
+	return func(app *App) {
+		app.ctx = ctx
+		app.baseContext = true
+	}
+}
+
+// WithBaseContextFunc sets a function that returns the base context of each
+// request, e.g. with a logger of the tenant host. The request context is also
+// cancelled when the App is closed. See WithBaseContext.
+func WithBaseContextFunc(fn func(r *http.Request) context.Context) Option {
+	return func(app *App) {
+		app.baseContextFunc = fn
+		app.baseContext = true
+	}
+}
+
+// WithConsole enables the development console at /_xun, which shows the
+// routes, navigation tree, templates, cache stats, active SSE/WebSocket
+// connections and config of the App. The auth middleware is required to
+// protect it, e.g. with basic auth or an admin session check.
+func WithConsole(auth Middleware) Option {
+	return func(app *App) {
+		app.console = auth
+	}
+}
+
+// WithDrainEvent sets the SSE event that is sent to streaming clients by
+// app.Shutdown, e.g. "event: close\ndata: bye\n\n". See DefaultDrainEvent.
+func WithDrainEvent(event string) Option {
+	return func(app *App) {
+		app.drainEvent = event
+	}
+}
+
+// WithReadyz registers the readiness endpoint "GET /readyz", which writes 200 OK
+// once app.Start has completed all warmup tasks, and 503 Service Unavailable
+// before that and after app.Shutdown is called.
+func WithReadyz() Option {
+	return func(app *App) {
+		app.readyz = true
+	}
+}
+
+// WithMailSender sets the MailSender of app.Mail.
+func WithMailSender(s MailSender) Option {
+	return func(app *App) {
+		app.mailSender = s
+	}
+}
+
+// WithPDFRenderer sets the PDFRenderer of c.ViewPDF.
+func WithPDFRenderer(r PDFRenderer) Option {
+	return func(app *App) {
+		app.pdfRenderer = r
+	}
+}
+
+// WithLayoutRules sets the rules that select a variant of the page layouts by
+// the request, e.g. layouts/main.mobile.html for phones. The first rule that
+// returns a variant wins.
+func WithLayoutRules(rules ...LayoutRule) Option {
+	return func(app *App) {
+		app.layoutRules = append(app.layoutRules, rules...)
+	}
+}
+
+// WithLocales sets the locales supported by the app, that c.Locale negotiates
+// the Accept-Language header against. The first one is the default locale.
+func WithLocales(locales ...string) Option {
+	return func(app *App) {
+		app.locales = append(app.locales, locales...)
+	}
+}
+
+// WithClock sets the Clock of app.Now and c.Now, e.g. a fixed time in tests.
+func WithClock(clock Clock) Option {
+	return func(app *App) {
+		app.clock = clock
+	}
+}
+
+// WithLocation sets the default time zone of c.Location, if the client doesn't
+// provide one. It defaults to time.Local.
+func WithLocation(loc *time.Location) Option {
+	return func(app *App) {
+		app.defaultLocation = loc
+	}
+}
+
+// WithAccess sets the AccessFunc that checks the access of the client, e.g.
+// by the permissions of the current user. It is used by the `nav` and
+// `component` template functions to omit the navigation items and components
+// that the client can't access, complementing route-level checks.
+func WithAccess(fn AccessFunc) Option {
+	return func(app *App) {
+		app.access = fn
+	}
+}
+
+// WithJsonOptions sets the JsonOptions of BindJson for all routes, e.g. to
+// disallow unknown fields and limit the size of bodies of public APIs. It can
+// be overridden per route by WithRouteJsonOptions.
+func WithJsonOptions(o JsonOptions) Option {
+	return func(app *App) {
+		app.jsonOptions = &o
+	}
+}
+
+// WithValidator sets the ValidateFunc that validates the data bound by
+// BindQuery, BindForm, BindJson and Bind, e.g. WithValidator(xun.ValidateStruct)
+// for the `validate` tags of go-playground/validator. The failed fields are
+// returned as a *BindError, which is written as 400 Bad Request.
+func WithValidator(fn ValidateFunc) Option {
+	return func(app *App) {
+		app.validate = fn
+	}
+}
+
+// WithErrorHandler sets the ErrorHandler of the unhandled errors of all routes,
+// e.g. WithErrorHandler(xun.DefaultErrorHandler) to write them by content
+// negotiation. The OnError handlers of groups take precedence over it.
+func WithErrorHandler(h ErrorHandler) Option {
+	return func(app *App) {
+		app.errorHandler = h
+	}
+}
+
+// WithCancelledErrors passes ErrCancelled to the ErrorHandler of the app if
+// nothing is written yet, e.g. to write 503 problem+json by the
+// DefaultErrorHandler. By default ErrCancelled is never passed to it, because
+// handlers usually write the response before they return it.
+func WithCancelledErrors() Option {
+	return func(app *App) {
+		app.cancelledErrors = true
+	}
+}
+
+// WithoutRecovery disables the Recovery middleware that is installed on all
+// routes by default, so panics close the connection and are logged by the
+// http.Server, e.g. to recover them by another middleware.
+func WithoutRecovery() Option {
+	return func(app *App) {
+		app.noRecovery = true
+	}
+}
+
+// WithRequestIDHeader sets the header of the request id that Envelope reads
+// from trusted proxies and writes to responses, DefaultRequestIDHeader by default.
+func WithRequestIDHeader(name string) Option {
+	return func(app *App) {
+		app.requestIDHeader = name
+	}
+}
+
+// WithJobWorkers sets the number of workers that run the jobs submitted by
+// c.Submit, 4 by default.
+func WithJobWorkers(n int) Option {
+	return func(app *App) {
+		app.jobWorkers = n
+	}
+}
+
+// WithJobQueueSize sets the max number of jobs submitted by c.Submit that wait
+// for workers, 1024 by default.
+func WithJobQueueSize(n int) Option {
+	return func(app *App) {
+		app.jobQueueSize = n
+	}
+}
+
+// WithJobTTL sets how long the status of a finished job is kept, 10 minutes by default.
+func WithJobTTL(d time.Duration) Option {
+	return func(app *App) {
+		app.jobTTL = d
+	}
+}
+
+// WithJobPollInterval sets the interval of the htmx polling of unfinished jobs,
+// 1 second by default.
+func WithJobPollInterval(d time.Duration) Option {
+	return func(app *App) {
+		app.jobPollInterval = d
+	}
+}
+
+// WithComponentAccess sets the access required by the component name, e.g.
+// WithComponentAccess("components/admin/menu", "admin:view"). It overrides the
+// `access` of the front matter of the component.
+func WithComponentAccess(name, access string) Option {
+	return func(app *App) {
+		if app.componentAccesses == nil {
+			app.componentAccesses = make(map[string]string)
+		}
+		app.componentAccesses[name] = access
+	}
+}
+
+// WithBufferPool sets the size of the BufferPool of the app for rendering
+// views, instead of sharing BufPool with other apps.
+func WithBufferPool(size int) Option {
+	return func(app *App) {
+		app.bufPool = NewBufferPool(size)
+	}
+}
+
+// WithDeferNonce sets the function that returns the CSP nonce of the request,
+// e.g. the nonce of its Content-Security-Policy header. It is set on the inline
+// scripts that swap the sections deferred with DeferStream.
+func WithDeferNonce(nonce func(c *Context) string) Option {
+	return func(app *App) {
+		app.deferNonce = nonce
+	}
+}
@@ -0,0 +1,323 @@
+package xun
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Page is a page of the "pages" directory loaded by the HtmlViewEngine.
+type Page struct {
+	// Name is the name of the page, e.g. "blog/hello" for pages/blog/hello.html.
+	Name string
+	// Pattern is the route pattern of the page, e.g. "GET /blog/hello".
+	Pattern string
+	// Host is the host of the page, e.g. "abc.com" for pages/@abc.com/index.html.
+	Host string
+	// URL is the path of the page, e.g. "/blog/hello". It is empty if the page
+	// has wildcards, e.g. pages/user/{id}.html.
+	URL string
+
+	viewer *HtmlViewer
+}
+
+// Render renders the page with the given data to w, e.g. to build a search index.
+func (p *Page) Render(w io.Writer, r *http.Request, data any) error {
+	return p.viewer.template.execute(w, r, data)
+}
+
+// Meta returns the YAML front matter of the page, e.g.
+//
+//	---
+//	title: Hello
+//	date: 2025-01-02
+//	tags: [go, web]
+//	---
+func (p *Page) Meta() map[string]any {
+	return p.viewer.template.meta
+}
+
+// Title returns the `title` of the front matter.
+func (p *Page) Title() string {
+	s, _ := p.Meta()["title"].(string)
+	return s
+}
+
+// Description returns the `description` of the front matter.
+func (p *Page) Description() string {
+	s, _ := p.Meta()["description"].(string)
+	return s
+}
+
+// Date returns the `date` of the front matter, e.g. 2025-01-02 or
+// 2025-01-02T15:04:05Z. It is zero if the page has no date.
+func (p *Page) Date() time.Time {
+	switch v := p.Meta()["date"].(type) {
+	case time.Time:
+		return v
+	case string:
+		for _, layout := range []string{time.RFC3339, time.DateTime, time.DateOnly} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// Terms returns the terms of the taxonomy in the front matter, e.g.
+// Terms("tags") for `tags: [go, web]` or `tags: go`.
+func (p *Page) Terms(taxonomy string) []string {
+	switch v := p.Meta()[taxonomy].(type) {
+	case string:
+		if v != "" {
+			return []string{v}
+		}
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, it := range v {
+			if it != nil {
+				items = append(items, fmt.Sprint(it))
+			}
+		}
+		return items
+	}
+	return nil
+}
+
+// Weight returns the `weight` of the front matter, e.g. the order of a chapter
+// in docs. It is 0 if the page has no weight.
+func (p *Page) Weight() int {
+	switch v := p.Meta()["weight"].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	}
+	return 0
+}
+
+// Tags returns the `tags` of the front matter.
+func (p *Page) Tags() []string {
+	return p.Terms("tags")
+}
+
+// Categories returns the `categories` of the front matter.
+func (p *Page) Categories() []string {
+	return p.Terms("categories")
+}
+
+// Prev returns the page before the page in its collection, i.e. the pages in
+// the same directory ordered as app.Collection does, or nil if it is the first.
+func (p *Page) Prev() *Page {
+	items := p.siblings()
+	for i, it := range items {
+		if it == p && i > 0 {
+			return items[i-1]
+		}
+	}
+	return nil
+}
+
+// Next returns the page after the page in its collection, or nil if it is the last.
+func (p *Page) Next() *Page {
+	items := p.siblings()
+	for i, it := range items {
+		if it == p && i+1 < len(items) {
+			return items[i+1]
+		}
+	}
+	return nil
+}
+
+// Related returns at most n pages that share the most tags with the page, newest first.
+func (p *Page) Related(n int) []*Page {
+	tags := make(map[string]bool)
+	for _, t := range p.Tags() {
+		tags[strings.ToLower(t)] = true
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	type hit struct {
+		page  *Page
+		count int
+	}
+
+	var hits []hit
+	for _, it := range p.viewer.app.Collection("") {
+		if it == p {
+			continue
+		}
+
+		count := 0
+		for _, t := range it.Tags() {
+			if tags[strings.ToLower(t)] {
+				count++
+			}
+		}
+		if count > 0 {
+			hits = append(hits, hit{page: it, count: count})
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].count > hits[j].count
+	})
+
+	items := make([]*Page, 0, min(n, len(hits)))
+	for i := 0; i < len(hits) && i < n; i++ {
+		items = append(items, hits[i].page)
+	}
+	return items
+}
+
+// siblings returns the collection of the directory of the page.
+func (p *Page) siblings() []*Page {
+	dir := path.Dir(p.Name)
+	if dir == "." {
+		dir = ""
+	}
+
+	var items []*Page
+	for _, it := range p.viewer.app.Collection(dir) {
+		if path.Dir(it.Name) == path.Dir(p.Name) {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+// PageList is a page of a content collection, e.g. a page of blog posts.
+type PageList struct {
+	Pages      []*Page
+	Pagination Pagination
+}
+
+// addPage registers the page of the name, e.g. "blog/index", that is served on
+// the host and path.
+func (app *App) addPage(name, host, path, pattern string, v *HtmlViewer) {
+	if path == "" {
+		path = "/"
+	} else if strings.ContainsRune(path, '{') {
+		path = ""
+	}
+
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	if app.pages == nil {
+		app.pages = make(map[string]*Page)
+	}
+
+	app.pages[name] = &Page{
+		Name:    name,
+		Pattern: pattern,
+		Host:    host,
+		URL:     path,
+		viewer:  v,
+	}
+}
+
+// Pages returns all pages loaded from the "pages" directory, sorted by name.
+func (app *App) Pages() []*Page {
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	items := make([]*Page, 0, len(app.pages))
+	for _, p := range app.pages {
+		items = append(items, p)
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].Name < items[j].Name
+	})
+
+	return items
+}
+
+// Collection returns the pages under the directory of pages, e.g. "blog" for
+// pages/blog/*.html, sorted by weight, then by date, newest first. Pages
+// without weight are after weighted pages. Index pages, e.g. the listing page
+// pages/blog/index.html, and pages with wildcards are skipped.
+//
+// It is also available as the `pages` template function, and `paginate`
+// returns a PageList of it by the `page` query parameter of the request:
+//
+//	{{ $list := paginate (pages "blog") 10 }}
+//	{{ range $list.Pages }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}
+//	{{ if $list.Pagination.HasNext }}<a href="?page={{ $list.Pagination.Next }}">Older</a>{{ end }}
+func (app *App) Collection(dir string) []*Page {
+	prefix := strings.Trim(dir, "/")
+	if prefix != "" {
+		prefix += "/"
+	}
+
+	var items []*Page
+	for _, p := range app.Pages() {
+		if p.URL == "" || p.Name == "index" || strings.HasSuffix(p.Name, "/index") || !strings.HasPrefix(p.Name, prefix) {
+			continue
+		}
+		items = append(items, p)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		wi, wj := items[i].Weight(), items[j].Weight()
+		if wi != wj {
+			return wj == 0 || (wi != 0 && wi < wj)
+		}
+		return items[i].Date().After(items[j].Date())
+	})
+
+	return items
+}
+
+// pageOf returns the page of the request, or nil if it isn't a page or the
+// page has wildcards.
+func (app *App) pageOf(r *http.Request) *Page {
+	if r == nil || r.URL == nil {
+		return nil
+	}
+
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	var page *Page
+	for _, p := range app.pages {
+		if p.URL != r.URL.Path {
+			continue
+		}
+		if p.Host == host {
+			return p
+		}
+		if p.Host == "" {
+			page = p
+		}
+	}
+	return page
+}
+
+// paginate returns the PageList of the page set by the `page` query parameter of r.
+func paginate(r *http.Request, pages []*Page, size int) PageList {
+	n := 1
+	if r != nil {
+		if v, err := strconv.Atoi(r.URL.Query().Get("page")); err == nil {
+			n = v
+		}
+	}
+
+	items, p := Paginate(pages, n, size)
+	return PageList{Pages: items, Pagination: p}
+}
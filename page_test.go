@@ -0,0 +1,194 @@
+package xun
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPages(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html": {Data: []byte(`<html>{{ block "content" . }}{{ end }}</html>`)},
+		"pages/index.html":  {Data: []byte(`<h1>home</h1>`)},
+		"pages/blog/index.html": {Data: []byte(`<!--layout:main-->
+{{ define "content" }}<h1>blog</h1>{{ end }}`)},
+		"pages/blog/hello.html":       {Data: []byte(`<h1>hello</h1>`)},
+		"pages/user/{id}.html":        {Data: []byte(`<h1>user</h1>`)},
+		"pages/@abc.com/index.html":   {Data: []byte(`<h1>abc</h1>`)},
+		"pages/@abc.com/contact.html": {Data: []byte(`<h1>contact</h1>`)},
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+	defer app.Close()
+
+	pages := app.Pages()
+	require.Len(t, pages, 6)
+
+	urls := make(map[string][3]string)
+	for _, p := range pages {
+		urls[p.Name] = [3]string{p.Host, p.URL, p.Pattern}
+	}
+
+	require.Equal(t, map[string][3]string{
+		"index":            {"", "/", "GET /{$}"},
+		"blog/index":       {"", "/blog/", "GET /blog/{$}"},
+		"blog/hello":       {"", "/blog/hello", "GET /blog/hello"},
+		"user/{id}":        {"", "", "GET /user/{id}"},
+		"@abc.com/index":   {"abc.com", "/", "GET abc.com/{$}"},
+		"@abc.com/contact": {"abc.com", "/contact", "GET abc.com/contact"},
+	}, urls)
+
+	require.Equal(t, "@abc.com/contact", pages[0].Name)
+	require.Equal(t, "user/{id}", pages[5].Name)
+
+	var buf bytes.Buffer
+	require.NoError(t, pages[3].Render(&buf, nil, nil))
+	require.Equal(t, "<html><h1>blog</h1></html>", buf.String())
+}
+
+func TestFrontMatter(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html": {Data: []byte(`<html>{{ block "content" . }}{{ end }}</html>`)},
+		"pages/hello.html": {Data: []byte("---\r\ntitle: Hello\r\ndescription: The first post.\r\ndate: 2025-01-02\r\nlayout: main\r\ntags: [go, web, 2025]\r\ncategories: news\r\n---\r\n" +
+			`{{ define "content" }}<h1>hello</h1>{{ end }}`)},
+		"pages/dated.html":   {Data: []byte("---\ndate: \"2025-01-02 15:04:05\"\n---\n<h1>dated</h1>")},
+		"pages/empty.html":   {Data: []byte("---\n---\n")},
+		"pages/plain.html":   {Data: []byte("---<h1>plain</h1>")},
+		"pages/invalid.html": {Data: []byte("---\ntitle: [\n---\n<h1>invalid</h1>")},
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fsys))
+	defer app.Close()
+
+	pages := make(map[string]*Page)
+	for _, p := range app.Pages() {
+		pages[p.Name] = p
+	}
+
+	p := pages["hello"]
+	require.Equal(t, "Hello", p.Title())
+	require.Equal(t, "The first post.", p.Description())
+	require.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), p.Date())
+	require.Equal(t, []string{"go", "web", "2025"}, p.Tags())
+	require.Equal(t, []string{"news"}, p.Categories())
+	require.Empty(t, p.Terms("series"))
+
+	var buf bytes.Buffer
+	require.NoError(t, p.Render(&buf, nil, nil))
+	require.Equal(t, "<html><h1>hello</h1></html>", buf.String())
+
+	require.Equal(t, time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC), pages["dated"].Date())
+	require.Empty(t, pages["empty"].Meta())
+	require.True(t, pages["empty"].Date().IsZero())
+
+	buf.Reset()
+	require.NoError(t, pages["plain"].Render(&buf, nil, nil))
+	require.Equal(t, "---<h1>plain</h1>", buf.String())
+
+	require.NotContains(t, pages, "invalid")
+	require.Len(t, app.TemplateDiagnostics(), 1)
+}
+
+func TestCollection(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/blog/index.html": {Data: []byte(`{{ $list := paginate (pages "blog") 2 }}` +
+			`{{ range $list.Pages }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}` +
+			`{{ with $list.Pagination }}{{ if .HasPrev }}<a href="?page={{ .Prev }}">newer</a>{{ end }}{{ if .HasNext }}<a href="?page={{ .Next }}">older</a>{{ end }}{{ end }}`)},
+		"pages/blog/first.html":    {Data: []byte("---\ntitle: First\ndate: 2025-01-01\n---\n")},
+		"pages/blog/second.html":   {Data: []byte("---\ntitle: Second\ndate: 2025-01-02\n---\n")},
+		"pages/blog/2024/old.html": {Data: []byte("---\ntitle: Old\ndate: 2024-06-01\n---\n")},
+		"pages/blog/{slug}.html":   {Data: []byte("---\ntitle: Slug\n---\n")},
+		"pages/about.html":         {Data: []byte("---\ntitle: About\n---\n")},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Start()
+
+	titles := func(pages []*Page) []string {
+		items := make([]string, 0, len(pages))
+		for _, p := range pages {
+			items = append(items, p.Title())
+		}
+		return items
+	}
+
+	require.Equal(t, []string{"Second", "First", "Old"}, titles(app.Collection("blog")))
+	require.Equal(t, []string{"Old"}, titles(app.Collection("/blog/2024/")))
+	require.Equal(t, []string{"Second", "First", "Old", "About"}, titles(app.Collection("")))
+
+	get := func(path string) string {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	require.Equal(t, `<a href="/blog/second">Second</a><a href="/blog/first">First</a><a href="?page=2">older</a>`, get("/blog/"))
+	require.Equal(t, `<a href="/blog/2024/old">Old</a><a href="?page=1">newer</a>`, get("/blog/?page=2"))
+	require.Equal(t, `<a href="/blog/2024/old">Old</a><a href="?page=1">newer</a>`, get("/blog/?page=5"))
+}
+
+func TestPageNavigation(t *testing.T) {
+	nav := `{{ with page }}<h1>{{ .Title }}</h1>` +
+		`{{ with .Prev }}<a rel="prev" href="{{ .URL }}">{{ .Title }}</a>{{ end }}` +
+		`{{ with .Next }}<a rel="next" href="{{ .URL }}">{{ .Title }}</a>{{ end }}` +
+		`{{ range .Related 2 }}<a href="{{ .URL }}">{{ .Title }}</a>{{ end }}{{ end }}`
+
+	fsys := fstest.MapFS{
+		"pages/blog/first.html":   {Data: []byte("---\ntitle: First\ndate: 2025-01-01\ntags: [go, web]\n---\n" + nav)},
+		"pages/blog/second.html":  {Data: []byte("---\ntitle: Second\ndate: 2025-01-02\ntags: [go]\n---\n" + nav)},
+		"pages/blog/third.html":   {Data: []byte("---\ntitle: Third\ndate: 2025-01-03\ntags: [web, go]\n---\n" + nav)},
+		"pages/docs/install.html": {Data: []byte("---\ntitle: Install\nweight: 1\n---\n" + nav)},
+		"pages/docs/usage.html":   {Data: []byte("---\ntitle: Usage\nweight: 2\n---\n" + nav)},
+		"pages/docs/faq.html":     {Data: []byte("---\ntitle: FAQ\ndate: 2025-01-05\n---\n" + nav)},
+		"pages/index.html":        {Data: []byte(`{{ if page }}{{ page.Name }}{{ end }}`)},
+		"pages/user/{id}.html":    {Data: []byte(`{{ if page }}page{{ else }}none{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Start()
+
+	get := func(path string) string {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	require.Equal(t, `<h1>Third</h1><a rel="next" href="/blog/second">Second</a>`+
+		`<a href="/blog/first">First</a><a href="/blog/second">Second</a>`, get("/blog/third"))
+	require.Equal(t, `<h1>Second</h1><a rel="prev" href="/blog/third">Third</a><a rel="next" href="/blog/first">First</a>`+
+		`<a href="/blog/third">Third</a><a href="/blog/first">First</a>`, get("/blog/second"))
+	require.Equal(t, `<h1>First</h1><a rel="prev" href="/blog/second">Second</a>`+
+		`<a href="/blog/third">Third</a><a href="/blog/second">Second</a>`, get("/blog/first"))
+
+	// weighted pages are first
+	require.Equal(t, `<h1>Install</h1><a rel="next" href="/docs/usage">Usage</a>`, get("/docs/install"))
+	require.Equal(t, `<h1>Usage</h1><a rel="prev" href="/docs/install">Install</a><a rel="next" href="/docs/faq">FAQ</a>`, get("/docs/usage"))
+	require.Equal(t, `<h1>FAQ</h1><a rel="prev" href="/docs/usage">Usage</a>`, get("/docs/faq"))
+
+	require.Equal(t, "index", get("/"))
+	require.Equal(t, "none", get("/user/1"))
+}
@@ -0,0 +1,61 @@
+package xun
+
+// Pagination is the position of a page in a paginated list, e.g. a list of
+// blog posts. Pages are numbered from 1.
+type Pagination struct {
+	Page  int // the current page
+	Size  int // the number of items per page
+	Total int // the number of items
+	Pages int // the number of pages
+}
+
+// Paginate returns the items of the page, and its Pagination. The page is
+// clamped to the first and last pages.
+func Paginate[T any](items []T, page, size int) ([]T, Pagination) {
+	if size <= 0 {
+		size = len(items)
+	}
+
+	p := Pagination{
+		Page:  page,
+		Size:  size,
+		Total: len(items),
+		Pages: 1,
+	}
+
+	if size > 0 && len(items) > size {
+		p.Pages = (len(items) + size - 1) / size
+	}
+
+	if p.Page > p.Pages {
+		p.Page = p.Pages
+	}
+	if p.Page < 1 {
+		p.Page = 1
+	}
+
+	start := min((p.Page-1)*size, len(items))
+	end := min(start+size, len(items))
+
+	return items[start:end], p
+}
+
+// HasPrev reports whether there is a page before the current page.
+func (p Pagination) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext reports whether there is a page after the current page.
+func (p Pagination) HasNext() bool {
+	return p.Page < p.Pages
+}
+
+// Prev returns the number of the previous page.
+func (p Pagination) Prev() int {
+	return max(p.Page-1, 1)
+}
+
+// Next returns the number of the next page.
+func (p Pagination) Next() int {
+	return min(p.Page+1, p.Pages)
+}
@@ -0,0 +1,44 @@
+package xun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name  string
+		page  int
+		size  int
+		items []int
+		want  Pagination
+		prev  bool
+		next  bool
+	}{
+		{name: "first", page: 1, size: 2, items: []int{1, 2}, want: Pagination{Page: 1, Size: 2, Total: 5, Pages: 3}, next: true},
+		{name: "middle", page: 2, size: 2, items: []int{3, 4}, want: Pagination{Page: 2, Size: 2, Total: 5, Pages: 3}, prev: true, next: true},
+		{name: "last", page: 3, size: 2, items: []int{5}, want: Pagination{Page: 3, Size: 2, Total: 5, Pages: 3}, prev: true},
+		{name: "after_last", page: 9, size: 2, items: []int{5}, want: Pagination{Page: 3, Size: 2, Total: 5, Pages: 3}, prev: true},
+		{name: "before_first", page: 0, size: 2, items: []int{1, 2}, want: Pagination{Page: 1, Size: 2, Total: 5, Pages: 3}, next: true},
+		{name: "no_size", page: 1, size: 0, items: items, want: Pagination{Page: 1, Size: 5, Total: 5, Pages: 1}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, p := Paginate(items, test.page, test.size)
+			require.Equal(t, test.items, got)
+			require.Equal(t, test.want, p)
+			require.Equal(t, test.prev, p.HasPrev())
+			require.Equal(t, test.next, p.HasNext())
+		})
+	}
+
+	got, p := Paginate([]int{}, 1, 10)
+	require.Empty(t, got)
+	require.Equal(t, Pagination{Page: 1, Size: 10, Pages: 1}, p)
+	require.Equal(t, 1, p.Prev())
+	require.Equal(t, 1, p.Next())
+}
@@ -0,0 +1,82 @@
+package xun
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Param returns the value of the path wildcard name, e.g. "id" of
+// `GET /users/{id}`. It is the same as c.Request().PathValue(name).
+func (c *Context) Param(name string) string {
+	return c.req.PathValue(name)
+}
+
+// ParamInt returns the value of the path wildcard name as an int. It returns
+// a *BindError if the value is missing or isn't an integer, so handlers can
+// return it to write 400 Bad Request.
+//
+//	id, err := c.ParamInt("id")
+//	if err != nil {
+//		return err
+//	}
+func (c *Context) ParamInt(name string) (int, error) {
+	v := c.Param(name)
+	if v == "" {
+		return 0, newParamError(name, FieldRequired, "missing "+name)
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, newParamError(name, FieldTypeMismatch, "cannot use "+strconv.Quote(v)+" as int")
+	}
+
+	return n, nil
+}
+
+// ParamUUID returns the value of the path wildcard name as a lowercase UUID,
+// e.g. "6ba7b810-9dad-11d1-80b4-00c04fd430c8". It returns a *BindError if the
+// value is missing or isn't a UUID in the 8-4-4-4-12 format.
+func (c *Context) ParamUUID(name string) (string, error) {
+	v := c.Param(name)
+	if v == "" {
+		return "", newParamError(name, FieldRequired, "missing "+name)
+	}
+
+	if !isUUID(v) {
+		return "", newParamError(name, FieldTypeMismatch, "cannot use "+strconv.Quote(v)+" as uuid")
+	}
+
+	return strings.ToLower(v), nil
+}
+
+// newParamError returns a BindError of the path wildcard name.
+func newParamError(name, code, msg string) *BindError {
+	return &BindError{
+		Message: "invalid path",
+		Source:  "path",
+		Fields:  []FieldError{{Field: name, Code: code, Message: msg}},
+	}
+}
+
+// isUUID reports whether s is a UUID in the 8-4-4-4-12 format.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			c := s[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
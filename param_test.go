@@ -0,0 +1,70 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParam(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	app.Get("/users/{id}", func(c *Context) error {
+		id, err := c.ParamInt("id")
+		if err != nil {
+			return err
+		}
+		return c.View(strings.Repeat("+", id) + c.Param("id"))
+	})
+
+	app.Get("/orders/{id}", func(c *Context) error {
+		id, err := c.ParamUUID("id")
+		if err != nil {
+			return err
+		}
+		return c.View(id)
+	})
+
+	app.Get("/missing", func(c *Context) error {
+		_, err := c.ParamInt("id")
+		return err
+	})
+
+	app.Start()
+
+	tests := []struct {
+		name   string
+		path   string
+		status int
+		body   string
+	}{
+		{"int", "/users/3", http.StatusOK, "+++3"},
+		{"int_mismatch", "/users/abc", http.StatusBadRequest, `"code":"type_mismatch"`},
+		{"int_missing", "/missing", http.StatusBadRequest, `"code":"required"`},
+		{"uuid", "/orders/6BA7B810-9DAD-11D1-80B4-00C04FD430C8", http.StatusOK, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{"uuid_mismatch", "/orders/6ba7b810-9dad-11d1-80b4", http.StatusBadRequest, `{"field":"id","code":"type_mismatch"`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resp, err := client.Get(srv.URL + test.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Contains(t, string(buf), test.body)
+		})
+	}
+}
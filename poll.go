@@ -0,0 +1,46 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// PollInterval is how often c.Poll calls its check function.
+var PollInterval = 250 * time.Millisecond
+
+// Poll waits for data to be available for long-polling clients, e.g. htmx
+// requests with hx-trigger="load" when SSE and WebSockets are blocked by proxies.
+//
+// check is called every PollInterval until it returns true, and the data is
+// rendered by c.View. If timeout elapses, or the app starts shutting down,
+// 204 No Content is written, so that htmx doesn't swap and the client polls
+// again. If ctx is done, e.g. the client is gone, it returns ErrCancelled.
+func (c *Context) Poll(ctx context.Context, check func() (any, bool), timeout time.Duration) error {
+	if data, ok := check(); ok {
+		return c.View(data)
+	}
+
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if data, ok := check(); ok {
+				return c.View(data)
+			}
+		case <-deadline.C:
+			c.WriteStatus(http.StatusNoContent)
+			return nil
+		case <-c.Draining():
+			c.WriteStatus(http.StatusNoContent)
+			return nil
+		case <-ctx.Done():
+			return ErrCancelled
+		}
+	}
+}
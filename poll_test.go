@@ -0,0 +1,77 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPoll(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	interval := PollInterval
+	PollInterval = 10 * time.Millisecond
+	defer func() {
+		PollInterval = interval
+	}()
+
+	var messages atomic.Int32
+	app.Get("/messages", func(c *Context) error {
+		return c.Poll(c.Context(), func() (any, bool) {
+			n := messages.Load()
+			return n, n > 0
+		}, 200*time.Millisecond)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		now := time.Now()
+		resp, err := client.Get(srv.URL + "/messages")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.GreaterOrEqual(t, time.Since(now), 200*time.Millisecond)
+	})
+
+	t.Run("data", func(t *testing.T) {
+		time.AfterFunc(50*time.Millisecond, func() {
+			messages.Store(3)
+		})
+
+		resp, err := client.Get(srv.URL + "/messages")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "3\n", string(buf))
+	})
+
+	t.Run("shutdown", func(t *testing.T) {
+		messages.Store(0)
+
+		time.AfterFunc(50*time.Millisecond, func() {
+			app.Shutdown(context.Background()) // nolint: errcheck
+		})
+
+		now := time.Now()
+		resp, err := client.Get(srv.URL + "/messages")
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+		require.Less(t, time.Since(now), 200*time.Millisecond)
+	})
+}
@@ -0,0 +1,80 @@
+package xun
+
+import (
+	"html"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// preconnect is an origin declared by WithPreconnect or WithPreconnectCrossOrigin.
+type preconnect struct {
+	origin string
+	// crossOrigin is the CORS mode of the connection, e.g. "anonymous" for
+	// fonts, or empty for the resources fetched without CORS.
+	crossOrigin string
+}
+
+// addPreconnects adds the origins with the CORS mode crossOrigin.
+func (app *App) addPreconnects(crossOrigin string, origins []string) {
+	for _, origin := range origins {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin != "" {
+			app.preconnects = append(app.preconnects, preconnect{origin: origin, crossOrigin: crossOrigin})
+		}
+	}
+	app.preconnectHeader = app.preconnectLink()
+}
+
+// preconnectLink returns the Link header value of the origins declared by WithPreconnect.
+func (app *App) preconnectLink() string {
+	items := make([]string, 0, len(app.preconnects))
+	for _, p := range app.preconnects {
+		item := "<" + p.origin + ">; rel=preconnect"
+		switch p.crossOrigin {
+		case "":
+		case "anonymous":
+			item += "; crossorigin"
+		default:
+			item += `; crossorigin="` + p.crossOrigin + `"`
+		}
+		items = append(items, item)
+	}
+
+	return strings.Join(items, ", ")
+}
+
+// Preconnect returns the `<link rel="preconnect">` and `<link rel="dns-prefetch">`
+// tags of the origins declared by WithPreconnect, for the head of layouts.
+//
+// It is available as the `preconnect` function in templates:
+//
+//	<head>{{ preconnect }}</head>
+func (app *App) Preconnect() template.HTML {
+	var sb strings.Builder
+	for _, p := range app.preconnects {
+		href := html.EscapeString(p.origin)
+		sb.WriteString(`<link rel="preconnect" href="` + href + `"`)
+		switch p.crossOrigin {
+		case "":
+		case "anonymous":
+			sb.WriteString(` crossorigin`)
+		default:
+			sb.WriteString(` crossorigin="` + html.EscapeString(p.crossOrigin) + `"`)
+		}
+		sb.WriteString(`>`)
+		sb.WriteString(`<link rel="dns-prefetch" href="` + href + `">`)
+	}
+
+	return template.HTML(sb.String()) // nolint: gosec
+}
+
+// writePreconnect adds the preconnect Link header to the responses of html
+// requests, so browsers open the connections before the page is parsed.
+func (app *App) writePreconnect(w http.ResponseWriter, req *http.Request) {
+	if app.preconnectHeader == "" || !acceptsHtml(req) {
+		return
+	}
+
+	w.Header().Add("Link", app.preconnectHeader)
+}
@@ -0,0 +1,67 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreconnect(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<head>{{ preconnect }}</head>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithPreconnect("https://cdn.example.com/"),
+		WithPreconnectCrossOrigin("anonymous", "https://fonts.gstatic.com"),
+		WithPreconnectCrossOrigin("use-credentials", "https://api.example.com"))
+
+	app.Get("/api/users", func(c *Context) error {
+		return c.View([]string{"xun"})
+	})
+
+	app.Start()
+	defer app.Close()
+
+	t.Run("html", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		// the header and the tags open the same connections
+		require.Equal(t, `<https://cdn.example.com>; rel=preconnect, `+
+			`<https://fonts.gstatic.com>; rel=preconnect; crossorigin, `+
+			`<https://api.example.com>; rel=preconnect; crossorigin="use-credentials"`, resp.Header.Get("Link"))
+		require.Equal(t, `<head>`+
+			`<link rel="preconnect" href="https://cdn.example.com"><link rel="dns-prefetch" href="https://cdn.example.com">`+
+			`<link rel="preconnect" href="https://fonts.gstatic.com" crossorigin><link rel="dns-prefetch" href="https://fonts.gstatic.com">`+
+			`<link rel="preconnect" href="https://api.example.com" crossorigin="use-credentials"><link rel="dns-prefetch" href="https://api.example.com">`+
+			`</head>`, string(buf))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/users", nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Empty(t, resp.Header.Get("Link"))
+	})
+}
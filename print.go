@@ -0,0 +1,85 @@
+package xun
+
+import (
+	"context"
+	"io"
+)
+
+// PrintLayout is the layout of the print-optimized HTML rendered by c.ViewPrint and c.ViewPDF.
+const PrintLayout = "layouts/print"
+
+// PDFRenderer converts print-optimized HTML to PDF, e.g. with a headless
+// browser or a wkhtmltopdf process.
+type PDFRenderer interface {
+	RenderPDF(ctx context.Context, w io.Writer, html []byte) error
+}
+
+// PDFRendererFunc is an adapter to allow the use of ordinary functions as PDFRenderer.
+type PDFRendererFunc func(ctx context.Context, w io.Writer, html []byte) error
+
+// RenderPDF calls f(ctx, w, html).
+func (f PDFRendererFunc) RenderPDF(ctx context.Context, w io.Writer, html []byte) error {
+	return f(ctx, w, html)
+}
+
+// ViewPrint renders the page, e.g. "invoices/detail" for "pages/invoices/detail.html",
+// with layouts/print.html instead of its own layout, e.g. for invoices and
+// reports. If layouts/print.html doesn't exist, the own layout is used.
+func (c *Context) ViewPrint(data any, page string) error {
+	buf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(buf)
+
+	if err := c.renderPrint(buf, data, page); err != nil {
+		return err
+	}
+
+	c.WriteHeader("Content-Type", "text/html; charset=utf-8")
+	_, err := buf.WriteTo(c.rw)
+	return err
+}
+
+// ViewPDF renders the print-optimized HTML of the page like ViewPrint, and
+// converts it to PDF by the PDFRenderer set by WithPDFRenderer.
+func (c *Context) ViewPDF(data any, page string) error {
+	if c.app.pdfRenderer == nil {
+		return ErrNoPDFRenderer
+	}
+
+	buf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(buf)
+
+	if err := c.renderPrint(buf, data, page); err != nil {
+		return err
+	}
+
+	pdf := c.app.bufferPool().Get()
+	defer c.app.bufferPool().Put(pdf)
+
+	if err := c.app.pdfRenderer.RenderPDF(c.Context(), pdf, buf.Bytes()); err != nil {
+		return err
+	}
+
+	c.WriteHeader("Content-Type", "application/pdf")
+	_, err := pdf.WriteTo(c.rw)
+	return err
+}
+
+// renderPrint renders the print-optimized HTML of the page to w.
+func (c *Context) renderPrint(w io.Writer, data any, page string) error {
+	c.app.mu.RLock()
+	v, ok := c.app.viewers[page].(*HtmlViewer)
+	layout := c.app.layouts[PrintLayout]
+	c.app.mu.RUnlock()
+
+	if !ok {
+		return ErrViewerNotFound
+	}
+
+	c.viewer = v
+
+	if layout == nil {
+		return v.template.execute(w, c.req, data)
+	}
+
+	return v.template.executeLayout(w, c.req, layout, data)
+}
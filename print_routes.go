@@ -0,0 +1,137 @@
+package xun
+
+import (
+	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// PrintRoutes writes a table of the registered routes to w, with the method,
+// pattern, host, name, middlewares and navigation of each route, followed by
+// an example curl command per route, e.g. for onboarding docs and ops runbooks.
+//
+// The name and navigation are read from the routing metadata set by
+// WithNavigation, and the request body of the curl command is generated
+// from the type declared by WithRequestType.
+func (app *App) PrintRoutes(w io.Writer) error {
+	app.mu.RLock()
+	routes := make([]*Routing, 0, len(app.routes))
+	for _, r := range app.routes {
+		routes = append(routes, r)
+	}
+	app.mu.RUnlock()
+
+	sort.Slice(routes, func(i, j int) bool {
+		return routes[i].Pattern < routes[j].Pattern
+	})
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	io.WriteString(tw, "METHOD\tPATTERN\tHOST\tNAME\tMIDDLEWARE\tNAV\n") // nolint: errcheck
+
+	for _, r := range routes {
+		method, host, path := splitPattern(r.Pattern)
+
+		io.WriteString(tw, strings.Join([]string{ // nolint: errcheck
+			orDash(method),
+			"/" + path,
+			orDash(host),
+			orDash(r.Options.GetString(NavigationName)),
+			orDash(strings.Join(middlewareNames(r.chain), ",")),
+			orDash(strings.TrimSpace(r.Options.GetString(NavigationIcon) + " " + r.Options.GetString(NavigationAccess))),
+		}, "\t")+"\n")
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, r := range routes {
+		if _, err := io.WriteString(w, "\n# "+r.Pattern+"\n"+curlExample(r)+"\n"); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// middlewareNames returns the function names of the middlewares of the chain c, e.g. "cors.New".
+func middlewareNames(c chain) []string {
+	ml, ok := c.(middlewareLister)
+	if !ok {
+		return nil
+	}
+
+	var names []string
+	for _, m := range ml.middlewareList() {
+		names = append(names, funcName(m))
+	}
+	return names
+}
+
+var reFuncSuffix = regexp.MustCompile(`(\.func\d+)+(\.\d+)*$`)
+
+// funcName returns the short name of the function fn without its package path and closure suffixes.
+func funcName(fn any) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	if f == nil {
+		return "?"
+	}
+
+	name := f.Name()
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	return reFuncSuffix.ReplaceAllString(name, "")
+}
+
+var rePathValue = regexp.MustCompile(`\{([^}.]*)(\.\.\.)?\}`)
+
+// curlExample returns an example curl command of the route r. Wildcards in
+// the pattern are replaced with their names, e.g. "/users/{id}" with "/users/:id".
+func curlExample(r *Routing) string {
+	method, host, path := splitPattern(r.Pattern)
+	if host == "" {
+		host = "localhost"
+	}
+
+	path = rePathValue.ReplaceAllStringFunc("/"+path, func(s string) string {
+		m := rePathValue.FindStringSubmatch(s)
+		if m[1] == "$" {
+			return ""
+		}
+		return ":" + m[1]
+	})
+
+	var sb strings.Builder
+	sb.WriteString("curl")
+	if method != "" && method != "GET" {
+		sb.WriteString(" -X " + method)
+	}
+
+	if len(r.Viewers) > 0 {
+		if accept := r.Viewers[0].MimeType().String(); accept != "*/*" {
+			sb.WriteString(" -H 'Accept: " + accept + "'")
+		}
+	}
+
+	if t := r.Options.RequestType(); t != nil {
+		if buf, err := json.Marshal(reflect.New(t).Interface()); err == nil {
+			sb.WriteString(" -H 'Content-Type: application/json' -d '" + string(buf) + "'")
+		}
+	}
+
+	sb.WriteString(" 'http://" + host + path + "'")
+	return sb.String()
+}
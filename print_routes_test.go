@@ -0,0 +1,63 @@
+package xun
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func logging(next HandleFunc) HandleFunc {
+	return func(c *Context) error {
+		return next(c)
+	}
+}
+
+func TestPrintRoutes(t *testing.T) {
+	type User struct {
+		Name string `json:"name"`
+	}
+
+	mux := http.NewServeMux()
+	app := New(WithMux(mux), WithFsys(fstest.MapFS{
+		"public/app.js": {Data: []byte("console.log(1)")},
+	}))
+
+	app.Use(logging)
+
+	app.Get("/users/{id}", func(c *Context) error {
+		return nil
+	}, WithNavigation("user", "person", "admin"))
+
+	admin := app.Group("/admin")
+	admin.Use(func(next HandleFunc) HandleFunc {
+		return next
+	})
+	admin.Post("/users", func(c *Context) error {
+		return nil
+	}, WithViewer(&JsonViewer{}), WithRequestType[User]())
+
+	app.Get("abc.com/files/{path...}", func(c *Context) error {
+		return nil
+	})
+
+	var buf bytes.Buffer
+	require.NoError(t, app.PrintRoutes(&buf))
+
+	out := buf.String()
+	lines := strings.Split(out, "\n")
+
+	require.Regexp(t, `^METHOD\s+PATTERN\s+HOST\s+NAME\s+MIDDLEWARE\s+NAV$`, lines[0])
+	require.Regexp(t, `^GET\s+/app.js\s+-\s+-\s+xun.logging\s+-$`, lines[1])
+	require.Regexp(t, `^GET\s+/users/\{id\}\s+-\s+user\s+xun.logging\s+person admin$`, lines[2])
+	require.Regexp(t, `^GET\s+/files/\{path...\}\s+abc.com\s+-\s+xun.logging\s+-$`, lines[3])
+	require.Regexp(t, `^POST\s+/admin/users\s+-\s+-\s+xun.TestPrintRoutes\s+-$`, lines[4])
+
+	require.Contains(t, out, "# GET /users/{id}\ncurl -H 'Accept: application/json' 'http://localhost/users/:id'\n")
+	require.Contains(t, out, "# GET abc.com/files/{path...}\ncurl -H 'Accept: application/json' 'http://abc.com/files/:path'\n")
+	require.Contains(t, out, "# GET /app.js\ncurl 'http://localhost/app.js'\n")
+	require.Contains(t, out, `curl -X POST -H 'Accept: application/json' -H 'Content-Type: application/json' -d '{"name":""}' 'http://localhost/admin/users'`)
+}
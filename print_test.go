@@ -0,0 +1,80 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewPDF(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/total.html": {Data: []byte(`<b>{{ .Total }}</b>`)},
+		"layouts/home.html":     {Data: []byte(`<html><nav>menu</nav>{{ block "content" . }}{{ end }}</html>`)},
+		"layouts/print.html":    {Data: []byte(`<html><style>@page { size: A4 }</style>{{ block "content" . }}default{{ end }}{{ block "footer" . }}page footer{{ end }}</html>`)},
+		"pages/invoices/{id}.html": {Data: []byte(`<!--layout:home-->
+{{ define "content" }}<h1>Invoice {{ .ID }}</h1>{{ block "components/total" . }}{{ end }}{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var printed string
+	app := New(WithMux(mux), WithFsys(fsys), WithPDFRenderer(PDFRendererFunc(func(ctx context.Context, w io.Writer, html []byte) error {
+		printed = string(html)
+		_, err := w.Write([]byte("%PDF-1.7"))
+		return err
+	})))
+	defer app.Close()
+
+	data := map[string]any{"ID": 1, "Total": 100}
+
+	app.Get("/invoices/{id}/print", func(c *Context) error {
+		return c.ViewPrint(data, "invoices/{id}")
+	})
+	app.Get("/invoices/{id}/pdf", func(c *Context) error {
+		return c.ViewPDF(data, "invoices/{id}")
+	})
+	app.Get("/missing/pdf", func(c *Context) error {
+		return c.ViewPDF(data, "missing")
+	})
+
+	get := func(path string) (*http.Response, string) {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(buf)
+	}
+
+	resp, body := get("/invoices/1")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, `<html><nav>menu</nav><h1>Invoice </h1><b></b></html>`, body)
+
+	want := `<html><style>@page { size: A4 }</style><h1>Invoice 1</h1><b>100</b>page footer</html>`
+
+	resp, body = get("/invoices/1/print")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/html; charset=utf-8", resp.Header.Get("Content-Type"))
+	require.Equal(t, want, body)
+
+	resp, body = get("/invoices/1/pdf")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "application/pdf", resp.Header.Get("Content-Type"))
+	require.Equal(t, "%PDF-1.7", body)
+	require.Equal(t, want, printed)
+
+	resp, _ = get("/missing/pdf")
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	// the own layout is used without layouts/print.html
+	delete(app.layouts, PrintLayout)
+	_, body = get("/invoices/1/print")
+	require.Equal(t, `<html><nav>menu</nav><h1>Invoice 1</h1><b>100</b></html>`, body)
+}
@@ -0,0 +1,100 @@
+package xun
+
+import (
+	"errors"
+	"net/http"
+)
+
+// ProblemDetails is the RFC 7807 problem document of an error, written as
+// application/problem+json by the ProblemViewer, e.g.
+//
+//	{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found","instance":"/users/1"}
+//
+// LogID and Fields are extension members of server errors and bind errors.
+type ProblemDetails struct {
+	Type     string       `json:"type"`
+	Title    string       `json:"title"`
+	Status   int          `json:"status"`
+	Detail   string       `json:"detail,omitempty"`
+	Instance string       `json:"instance,omitempty"`
+	LogID    string       `json:"log_id,omitempty"`
+	Fields   []FieldError `json:"fields,omitempty"`
+}
+
+// NewProblemDetails returns the ProblemDetails of the error of the request:
+//
+//   - *BindError: its Status or 400 Bad Request, with its message and fields.
+//   - *HttpError: its Status, with its message, e.g. 404 Not Found and 405
+//     Method Not Allowed of unmatched requests.
+//   - ErrCancelled: 503 Service Unavailable.
+//   - other errors: 500 Internal Server Error. Their messages aren't written,
+//     as they may leak internals.
+func NewProblemDetails(r *http.Request, err error) *ProblemDetails {
+	p := &ProblemDetails{Type: "about:blank", Status: http.StatusInternalServerError}
+	if r != nil {
+		p.Instance = r.URL.Path
+	}
+
+	var be *BindError
+	var he *HttpError
+
+	switch {
+	case errors.As(err, &be):
+		p.Status = http.StatusBadRequest
+		if be.Status != 0 {
+			p.Status = be.Status
+		}
+		p.Detail = be.Message
+		p.Fields = be.Fields
+	case errors.As(err, &he):
+		if he.Status != 0 {
+			p.Status = he.Status
+		}
+		p.Detail = he.Message
+	case errors.Is(err, ErrCancelled):
+		p.Status = http.StatusServiceUnavailable
+	}
+
+	p.Title = http.StatusText(p.Status)
+	return p
+}
+
+// ProblemViewer is a viewer that writes a ProblemDetails, or the
+// ProblemDetails of an error, as JSON.
+//
+// It sets the Content-Type header to "application/problem+json".
+type ProblemViewer struct {
+}
+
+var problemViewerMime = &MimeType{Type: "application", SubType: "problem+json"}
+
+// MimeType returns the MIME type of the problem document.
+//
+// It returns "application/problem+json".
+func (*ProblemViewer) MimeType() *MimeType {
+	return problemViewerMime
+}
+
+// Render renders the ProblemDetails, or the ProblemDetails of the error, as
+// JSON to the http.ResponseWriter. The status code isn't written, so it
+// should be written by the Status of the ProblemDetails before.
+func (*ProblemViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
+	switch it := data.(type) {
+	case ProblemDetails:
+		data = &it
+	case error:
+		data = NewProblemDetails(r, it)
+	}
+
+	bp := bufPoolOf(r)
+	buf := bp.Get()
+	defer bp.Put(buf)
+
+	if err := json.NewEncoder(buf).Encode(data); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	_, err := buf.WriteTo(w)
+	return err
+}
@@ -0,0 +1,143 @@
+package xun
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewProblemDetails(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/users/1", nil)
+
+	tests := []struct {
+		name string
+		err  error
+		want ProblemDetails
+	}{
+		{
+			name: "http_error",
+			err:  fmt.Errorf("users: %w", NewHttpError(http.StatusNotFound, "user not found")),
+			want: ProblemDetails{Type: "about:blank", Title: "Not Found", Status: http.StatusNotFound, Detail: "user not found", Instance: "/users/1"},
+		},
+		{
+			name: "bind_error",
+			err:  &BindError{Message: "invalid json", Source: "json", Fields: []FieldError{{Field: "age", Code: FieldTypeMismatch, Message: "cannot use string as int"}}},
+			want: ProblemDetails{Type: "about:blank", Title: "Bad Request", Status: http.StatusBadRequest, Detail: "invalid json", Instance: "/users/1",
+				Fields: []FieldError{{Field: "age", Code: FieldTypeMismatch, Message: "cannot use string as int"}}},
+		},
+		{
+			name: "bind_error_status",
+			err:  &BindError{Message: "json too large", Source: "json", Status: http.StatusRequestEntityTooLarge},
+			want: ProblemDetails{Type: "about:blank", Title: "Request Entity Too Large", Status: http.StatusRequestEntityTooLarge, Detail: "json too large", Instance: "/users/1"},
+		},
+		{
+			name: "cancelled",
+			err:  ErrCancelled,
+			want: ProblemDetails{Type: "about:blank", Title: "Service Unavailable", Status: http.StatusServiceUnavailable, Instance: "/users/1"},
+		},
+		{
+			name: "internal",
+			err:  errors.New("db: connection refused"),
+			want: ProblemDetails{Type: "about:blank", Title: "Internal Server Error", Status: http.StatusInternalServerError, Instance: "/users/1"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, &test.want, NewProblemDetails(req, test.err))
+		})
+	}
+}
+
+func TestProblemHandler(t *testing.T) {
+	mux := http.NewServeMux()
+	app := New(WithMux(mux), WithErrorHandler(DefaultErrorHandler), WithCancelledErrors())
+
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	app.Get("/users/{id}", func(c *Context) error {
+		return c.View(map[string]string{"id": c.Request().PathValue("id")})
+	})
+
+	app.Post("/users", func(c *Context) error {
+		_, err := BindJson[struct{ Age int }](c.Request())
+		return err
+	})
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if c.Request().Header.Get("X-Reject") != "" {
+				return ErrCancelled
+			}
+			return next(c)
+		}
+	})
+	app.Get("/rejected", func(c *Context) error {
+		return c.View(nil)
+	})
+
+	app.Start()
+	defer app.Close()
+
+	do := func(t *testing.T, method, path, body string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp, string(buf)
+	}
+
+	t.Run("matched", func(t *testing.T) {
+		resp, body := do(t, http.MethodGet, "/users/1", "")
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, `{"id":"1"}`, body)
+	})
+
+	t.Run("not_found", func(t *testing.T) {
+		resp, body := do(t, http.MethodGet, "/missing", "")
+
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		require.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"instance":"/missing"}`, body)
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		resp, body := do(t, http.MethodDelete, "/users/1", "")
+
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Allow"), http.MethodGet)
+		require.JSONEq(t, `{"type":"about:blank","title":"Method Not Allowed","status":405,"instance":"/users/1"}`, body)
+	})
+
+	t.Run("bind_error", func(t *testing.T) {
+		resp, body := do(t, http.MethodPost, "/users", `{"Age":"x"}`, "Content-Type", "application/json")
+
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, "application/problem+json", resp.Header.Get("Content-Type"))
+		require.Contains(t, body, `"fields":[{"field":"Age","code":"type_mismatch"`)
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		resp, body := do(t, http.MethodGet, "/rejected", "", "X-Reject", "1")
+
+		require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		require.JSONEq(t, `{"type":"about:blank","title":"Service Unavailable","status":503,"instance":"/rejected"}`, body)
+	})
+}
@@ -0,0 +1,230 @@
+package xun
+
+import (
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// QuerySpecKey is the key of the QuerySpec of WithQuerySpec in the routing metadata.
+const QuerySpecKey = "query_spec"
+
+// QuerySpec declares the filters and sorts accepted by an API route, e.g.
+// `?filter[status]=open&filter[age][gte]=18&sort=-created_at&fields=id,name`.
+type QuerySpec struct {
+	// Filters are the operators allowed by the filterable fields, e.g.
+	// {"status": {"eq", "in"}, "age": {"gte", "lte"}}. "eq" is the operator
+	// of `filter[status]=open`.
+	Filters map[string][]string
+	// Sorts are the sortable fields.
+	Sorts []string
+}
+
+// Query is the API query of a request parsed by c.Query.
+type Query struct {
+	Filters []Filter
+	Sort    []SortField
+	Fields  []string
+}
+
+// Filter is a filter of Query, e.g. `filter[age][gte]=18`.
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+}
+
+// SortField is a field of the `sort` of Query, e.g. "-created_at" for descending order.
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// Values returns the comma-separated values of the filter, e.g. for `filter[status][in]=open,closed`.
+func (f Filter) Values() []string {
+	return strings.Split(f.Value, ",")
+}
+
+// Filter returns the filter of the field with the op, e.g. q.Filter("status", "eq").
+func (q *Query) Filter(field, op string) (Filter, bool) {
+	for _, f := range q.Filters {
+		if f.Field == field && f.Op == op {
+			return f, true
+		}
+	}
+
+	return Filter{}, false
+}
+
+// WithQuerySpec declares the QuerySpec of the route in the routing metadata,
+// e.g. for OpenAPI generation by QuerySpec.Parameters. c.Query rejects the
+// filters and sorts that aren't declared.
+func WithQuerySpec(spec QuerySpec) RoutingOption {
+	return WithMetadata(QuerySpecKey, &spec)
+}
+
+// QuerySpec returns the QuerySpec declared by WithQuerySpec, or nil if it is not declared.
+func (ro *RoutingOptions) QuerySpec() *QuerySpec {
+	spec, _ := ro.metadata[QuerySpecKey].(*QuerySpec)
+	return spec
+}
+
+// Query parses the API query of the request, e.g.
+// `?filter[status]=open&sort=-created_at&fields=id,name`. It returns a
+// *BindError if a filter or sort isn't declared by the QuerySpec of the route,
+// so handlers can return it to write 400 Bad Request. All filters and sorts
+// are accepted if the route has no QuerySpec.
+//
+//	q, err := c.Query()
+//	if err != nil {
+//		return err
+//	}
+//	if f, ok := q.Filter("status", "eq"); ok {
+//		// ...
+//	}
+func (c *Context) Query() (*Query, error) {
+	var spec *QuerySpec
+	if c.Routing.Options != nil {
+		spec = c.Routing.Options.QuerySpec()
+	}
+
+	return ParseQuery(c.req.URL.Query(), spec)
+}
+
+// ParseQuery parses the API query of the values, and checks it by the spec if it isn't nil.
+func ParseQuery(values url.Values, spec *QuerySpec) (*Query, error) {
+	q := &Query{}
+	var fields []FieldError
+
+	for k, items := range values {
+		field, op, ok := parseFilterKey(k)
+		if !ok {
+			continue
+		}
+
+		if spec != nil && !spec.allowFilter(field, op) {
+			fields = append(fields, FieldError{Field: k, Code: FieldUnknown, Message: "unknown filter " + field + " " + op})
+			continue
+		}
+
+		for _, v := range items {
+			q.Filters = append(q.Filters, Filter{Field: field, Op: op, Value: v})
+		}
+	}
+
+	sort.SliceStable(q.Filters, func(i, j int) bool {
+		if q.Filters[i].Field != q.Filters[j].Field {
+			return q.Filters[i].Field < q.Filters[j].Field
+		}
+		return q.Filters[i].Op < q.Filters[j].Op
+	})
+
+	for _, it := range splitList(values["sort"]) {
+		s := SortField{Field: strings.TrimPrefix(it, "-"), Desc: strings.HasPrefix(it, "-")}
+		if spec != nil && !slices.Contains(spec.Sorts, s.Field) {
+			fields = append(fields, FieldError{Field: "sort", Code: FieldUnknown, Message: "unknown sort " + s.Field})
+			continue
+		}
+		q.Sort = append(q.Sort, s)
+	}
+
+	q.Fields = splitList(values["fields"])
+
+	if len(fields) > 0 {
+		sort.SliceStable(fields, func(i, j int) bool { return fields[i].Field < fields[j].Field })
+		return nil, &BindError{Message: "invalid query", Source: "query", Fields: fields}
+	}
+
+	return q, nil
+}
+
+// Parameters returns the OpenAPI parameter objects of the spec, e.g.
+// `{"name": "filter[status]", "in": "query", ...}`, ordered by name.
+func (spec *QuerySpec) Parameters() []map[string]any {
+	var params []map[string]any
+
+	names := make([]string, 0, len(spec.Filters))
+	for field := range spec.Filters {
+		names = append(names, field)
+	}
+	sort.Strings(names)
+
+	for _, field := range names {
+		for _, op := range spec.Filters[field] {
+			name := "filter[" + field + "]"
+			if op != "eq" {
+				name += "[" + op + "]"
+			}
+
+			params = append(params, map[string]any{
+				"name":        name,
+				"in":          "query",
+				"description": "filter " + field + " by " + op,
+				"schema":      map[string]any{"type": "string"},
+			})
+		}
+	}
+
+	if len(spec.Sorts) > 0 {
+		enum := make([]string, 0, len(spec.Sorts)*2)
+		for _, s := range spec.Sorts {
+			enum = append(enum, s, "-"+s)
+		}
+
+		params = append(params, map[string]any{
+			"name":        "sort",
+			"in":          "query",
+			"description": "comma-separated fields to sort by, prefixed by - for descending order",
+			"style":       "form",
+			"explode":     false,
+			"schema":      map[string]any{"type": "array", "items": map[string]any{"type": "string", "enum": enum}},
+		})
+	}
+
+	return params
+}
+
+// allowFilter reports whether the filter of the field with the op is declared.
+func (spec *QuerySpec) allowFilter(field, op string) bool {
+	ops, ok := spec.Filters[field]
+	return ok && slices.Contains(ops, op)
+}
+
+// parseFilterKey parses the field and op of a filter key, e.g. "filter[age][gte]".
+func parseFilterKey(k string) (field string, op string, ok bool) {
+	rest, ok := strings.CutPrefix(k, "filter[")
+	if !ok {
+		return "", "", false
+	}
+
+	field, rest, ok = strings.Cut(rest, "]")
+	if !ok || field == "" {
+		return "", "", false
+	}
+
+	if rest == "" {
+		return field, "eq", true
+	}
+
+	op, ok = strings.CutPrefix(rest, "[")
+	if !ok || !strings.HasSuffix(op, "]") || len(op) == 1 {
+		return "", "", false
+	}
+
+	return field, op[:len(op)-1], true
+}
+
+// splitList returns the trimmed items of comma-separated values.
+func splitList(values []string) []string {
+	var items []string
+	for _, v := range values {
+		for _, it := range strings.Split(v, ",") {
+			if it = strings.TrimSpace(it); it != "" {
+				items = append(items, it)
+			}
+		}
+	}
+
+	return items
+}
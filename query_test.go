@@ -0,0 +1,88 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseQuery(t *testing.T) {
+	values, err := url.ParseQuery("filter[status]=open&filter[age][gte]=18&filter[tags][in]=go,web&filter[]=x&filter[a][=y&sort=-created_at,name&fields=id,%20name")
+	require.NoError(t, err)
+
+	q, err := ParseQuery(values, nil)
+	require.NoError(t, err)
+
+	require.Equal(t, []Filter{
+		{Field: "age", Op: "gte", Value: "18"},
+		{Field: "status", Op: "eq", Value: "open"},
+		{Field: "tags", Op: "in", Value: "go,web"},
+	}, q.Filters)
+	require.Equal(t, []SortField{{Field: "created_at", Desc: true}, {Field: "name"}}, q.Sort)
+	require.Equal(t, []string{"id", "name"}, q.Fields)
+
+	f, ok := q.Filter("tags", "in")
+	require.True(t, ok)
+	require.Equal(t, []string{"go", "web"}, f.Values())
+
+	_, ok = q.Filter("status", "ne")
+	require.False(t, ok)
+
+	spec := &QuerySpec{
+		Filters: map[string][]string{"status": {"eq", "in"}, "age": {"gte"}},
+		Sorts:   []string{"created_at"},
+	}
+
+	_, err = ParseQuery(values, spec)
+	var be *BindError
+	require.ErrorAs(t, err, &be)
+	require.Equal(t, []FieldError{
+		{Field: "filter[tags][in]", Code: FieldUnknown, Message: "unknown filter tags in"},
+		{Field: "sort", Code: FieldUnknown, Message: "unknown sort name"},
+	}, be.Fields)
+
+	params := spec.Parameters()
+	require.Len(t, params, 4)
+	require.Equal(t, "filter[age][gte]", params[0]["name"])
+	require.Equal(t, "filter[status]", params[1]["name"])
+	require.Equal(t, "filter[status][in]", params[2]["name"])
+	require.Equal(t, "sort", params[3]["name"])
+}
+
+func TestContextQuery(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	app.Get("/issues", func(c *Context) error {
+		q, err := c.Query()
+		if err != nil {
+			return err
+		}
+		return c.View(q)
+	}, WithQuerySpec(QuerySpec{Filters: map[string][]string{"status": {"eq"}}, Sorts: []string{"created_at"}}))
+
+	app.Start()
+
+	resp, err := client.Get(srv.URL + "/issues?filter[status]=open&sort=-created_at")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"Filters":[{"Field":"status","Op":"eq","Value":"open"}],"Sort":[{"Field":"created_at","Desc":true}],"Fields":null}`, string(buf))
+
+	resp, err = client.Get(srv.URL + "/issues?filter[owner]=me")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
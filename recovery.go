@@ -0,0 +1,54 @@
+package xun
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicError is the error of a panic recovered by Recovery.
+type PanicError struct {
+	// Value is the value recovered from the panic.
+	Value any
+	// Stack is the stack trace of the goroutine when it panicked.
+	Stack []byte
+}
+
+// Error returns the message of the panic.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("xun: panic: %v", e.Value)
+}
+
+// Recovery returns a middleware that recovers the panics of the next handlers
+// and returns them as a *PanicError, so they are logged with the stack,
+// reported, and written as 500 Internal Server Error by the ErrorHandler
+// instead of closing the connection. http.ErrAbortHandler is panicked again to
+// abort the response.
+//
+// It is installed on all routes by default, and disabled by WithoutRecovery.
+func Recovery() Middleware {
+	return func(next HandleFunc) HandleFunc {
+		return func(c *Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					if p == http.ErrAbortHandler { // nolint: errorlint
+						panic(p)
+					}
+					err = &PanicError{Value: p, Stack: debug.Stack()}
+				}
+			}()
+
+			return next(c)
+		}
+	}
+}
+
+// panicStack returns the stack of the PanicError in err, or nil.
+func panicStack(err error) []byte {
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		return pe.Stack
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithErrorHandler(DefaultErrorHandler))
+
+	app.Get("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	admin := app.Group("/admin")
+	admin.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			panic("middleware")
+		}
+	})
+	admin.Get("/users", func(c *Context) error {
+		return c.View(nil)
+	})
+
+	app.Get("/abort", func(c *Context) error {
+		panic(http.ErrAbortHandler)
+	})
+
+	app.Start()
+	defer app.Close()
+
+	get := func(t *testing.T, path string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "application/json")
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	t.Run("handler", func(t *testing.T) {
+		resp := get(t, "/panic")
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		logID := resp.Header.Get("X-Log-Id")
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+		require.NotEmpty(t, logID)
+		require.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/panic","log_id":"`+logID+`"}`, string(buf))
+	})
+
+	t.Run("group_middleware", func(t *testing.T) {
+		resp := get(t, "/admin/users")
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+"/abort", nil)
+		require.NoError(t, err)
+
+		_, err = http.DefaultTransport.RoundTrip(req)
+		require.Error(t, err)
+	})
+}
+
+func TestWithoutRecovery(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithoutRecovery())
+
+	app.Get("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/panic", nil)
+	require.NoError(t, err)
+
+	// the connection is closed by the http.Server
+	_, err = http.DefaultTransport.RoundTrip(req)
+	require.Error(t, err)
+}
+
+func TestPanicError(t *testing.T) {
+	err := &PanicError{Value: "boom"}
+	require.Equal(t, "xun: panic: boom", err.Error())
+	require.Equal(t, http.StatusInternalServerError, errorStatus(err))
+}
@@ -0,0 +1,211 @@
+package xun
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// Redacted is the value that replaces redacted fields.
+const Redacted = "[REDACTED]"
+
+// redactJSON keeps big numbers as they are, and sorts keys for stable logs.
+var redactJSON = jsoniter.Config{UseNumber: true, SortMapKeys: true}.Froze()
+
+// Redactor masks the values of sensitive fields in query strings, forms, JSON
+// bodies and headers, e.g. password and token, before they are logged. Field
+// names are matched case-insensitively at any depth of JSON objects.
+//
+// A nil Redactor doesn't redact anything.
+type Redactor struct {
+	fields map[string]struct{}
+}
+
+// NewRedactor returns a Redactor that redacts the given fields.
+func NewRedactor(fields ...string) *Redactor {
+	r := &Redactor{fields: make(map[string]struct{}, len(fields))}
+	for _, f := range fields {
+		r.fields[strings.ToLower(f)] = struct{}{}
+	}
+	return r
+}
+
+// Match reports whether the field name should be redacted.
+func (r *Redactor) Match(name string) bool {
+	if r == nil {
+		return false
+	}
+
+	_, ok := r.fields[strings.ToLower(name)]
+	return ok
+}
+
+func (r *Redactor) empty() bool {
+	return r == nil || len(r.fields) == 0
+}
+
+// Values returns a copy of v with the redacted fields masked.
+func (r *Redactor) Values(v url.Values) url.Values {
+	if r.empty() {
+		return v
+	}
+
+	nv := make(url.Values, len(v))
+	for k, items := range v {
+		if r.Match(k) {
+			nv[k] = []string{Redacted}
+			continue
+		}
+		nv[k] = items
+	}
+	return nv
+}
+
+// Query returns the query string with the redacted fields masked.
+func (r *Redactor) Query(raw string) string {
+	if r.empty() || raw == "" {
+		return raw
+	}
+
+	v, err := url.ParseQuery(raw)
+	if err != nil {
+		return Redacted
+	}
+
+	return r.Values(v).Encode()
+}
+
+// Header returns a copy of h with the redacted headers masked.
+func (r *Redactor) Header(h http.Header) http.Header {
+	if r.empty() {
+		return h
+	}
+
+	nh := make(http.Header, len(h))
+	for k, items := range h {
+		if r.Match(k) {
+			nh[k] = []string{Redacted}
+			continue
+		}
+		nh[k] = items
+	}
+	return nh
+}
+
+// JSON returns the JSON document buf with the redacted fields masked. It
+// returns Redacted if buf isn't valid JSON, because it can't be redacted.
+func (r *Redactor) JSON(buf []byte) []byte {
+	if r.empty() || len(buf) == 0 {
+		return buf
+	}
+
+	var v any
+	if err := redactJSON.Unmarshal(buf, &v); err != nil {
+		return []byte(Redacted)
+	}
+
+	nb, err := redactJSON.Marshal(r.redactAny(v))
+	if err != nil {
+		return []byte(Redacted)
+	}
+
+	return nb
+}
+
+func (r *Redactor) redactAny(v any) any {
+	switch it := v.(type) {
+	case map[string]any:
+		for k, v := range it {
+			if r.Match(k) {
+				it[k] = Redacted
+				continue
+			}
+			it[k] = r.redactAny(v)
+		}
+	case []any:
+		for i, v := range it {
+			it[i] = r.redactAny(v)
+		}
+	}
+
+	return v
+}
+
+// Body returns the request body with the redacted fields masked by its content
+// type. Form and JSON bodies are redacted, and other bodies are returned as they are.
+func (r *Redactor) Body(contentType string, body []byte) []byte {
+	if r.empty() {
+		return body
+	}
+
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		return []byte(r.Query(string(body)))
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return r.JSON(body)
+	}
+
+	return body
+}
+
+// Redactor returns the Redactor of the route declared by WithRedact. It is nil
+// if no fields are redacted, and a nil Redactor doesn't redact anything.
+func (c *Context) Redactor() *Redactor {
+	if c.Routing.Options == nil {
+		return nil
+	}
+	return c.Routing.Options.redactor
+}
+
+// DumpRequest returns the HTTP/1.x wire representation of the request for
+// debugging, with the fields declared by WithRedact masked in its query
+// string, headers and form or JSON body. The body is restored, so it can
+// still be read by the handler.
+func (c *Context) DumpRequest() ([]byte, error) {
+	req := c.req
+	rd := c.Redactor()
+
+	var body []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	var b bytes.Buffer
+
+	uri := req.URL.EscapedPath()
+	if req.URL.RawQuery != "" {
+		uri += "?" + rd.Query(req.URL.RawQuery)
+	}
+	fmt.Fprintf(&b, "%s %s HTTP/%d.%d\r\n", req.Method, uri, req.ProtoMajor, req.ProtoMinor)
+	fmt.Fprintf(&b, "Host: %s\r\n", req.Host)
+
+	h := rd.Header(req.Header)
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		for _, v := range h[k] {
+			fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+		}
+	}
+	b.WriteString("\r\n")
+	b.Write(rd.Body(req.Header.Get("Content-Type"), body))
+
+	return b.Bytes(), nil
+}
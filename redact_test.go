@@ -0,0 +1,104 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactor(t *testing.T) {
+	r := NewRedactor("password", "Token")
+
+	require.True(t, r.Match("PASSWORD"))
+	require.False(t, r.Match("name"))
+
+	v := url.Values{"name": {"xun"}, "password": {"123"}, "token": {"a", "b"}}
+	require.Equal(t, url.Values{"name": {"xun"}, "password": {Redacted}, "token": {Redacted}}, r.Values(v))
+	require.Equal(t, []string{"123"}, v["password"]) // the input is not changed
+
+	require.Equal(t, "name=xun&token=%5BREDACTED%5D", r.Query("name=xun&token=abc"))
+
+	h := http.Header{"Token": {"abc"}, "Accept": {"*/*"}}
+	require.Equal(t, http.Header{"Token": {Redacted}, "Accept": {"*/*"}}, r.Header(h))
+
+	require.Equal(t, `{"id":12345678901234567890,"user":{"name":"xun","password":"[REDACTED]"},"users":[{"token":"[REDACTED]"}]}`,
+		string(r.JSON([]byte(`{"users":[{"token":"abc"}],"user":{"password":{"hash":"x"},"name":"xun"},"id":12345678901234567890}`))))
+	require.Equal(t, Redacted, string(r.JSON([]byte(`{"password":`))))
+
+	require.Equal(t, "password=%5BREDACTED%5D", string(r.Body("application/x-www-form-urlencoded", []byte("password=123"))))
+	require.Equal(t, `{"password":"[REDACTED]"}`, string(r.Body("application/merge-patch+json; charset=utf-8", []byte(`{"password":"123"}`))))
+	require.Equal(t, "password=123", string(r.Body("text/plain", []byte("password=123"))))
+
+	var nr *Redactor
+	require.False(t, nr.Match("password"))
+	require.Equal(t, "password=123", nr.Query("password=123"))
+	require.Equal(t, `{"password":"123"}`, string(nr.JSON([]byte(`{"password":"123"}`))))
+}
+
+func TestDumpRequest(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	var dump []byte
+	var body []byte
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			var err error
+			dump, err = c.DumpRequest()
+			if err != nil {
+				return err
+			}
+			return next(c)
+		}
+	})
+
+	app.Post("/login", func(c *Context) error {
+		var err error
+		body, err = io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		return c.View(nil)
+	}, WithRedact("password", "authorization"))
+
+	app.Post("/echo", func(c *Context) error {
+		return c.View(nil)
+	})
+
+	app.Start()
+
+	req, err := http.NewRequest(http.MethodPost, srv.URL+"/login?next=/admin&password=1", strings.NewReader(`{"email":"xun@yaitoo.cn","password":"123"}`))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer abc")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	s := string(dump)
+	require.True(t, strings.HasPrefix(s, "POST /login?next=%2Fadmin&password=%5BREDACTED%5D HTTP/1.1\r\n"))
+	require.Contains(t, s, "Authorization: [REDACTED]\r\n")
+	require.Contains(t, s, "Content-Type: application/json\r\n")
+	require.True(t, strings.HasSuffix(s, "\r\n\r\n"+`{"email":"xun@yaitoo.cn","password":"[REDACTED]"}`))
+	require.NotContains(t, s, "123")
+
+	// the body is restored for the handler
+	require.Equal(t, `{"email":"xun@yaitoo.cn","password":"123"}`, string(body))
+
+	resp, err = client.Post(srv.URL+"/echo", "application/x-www-form-urlencoded", strings.NewReader("password=123"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.True(t, strings.HasSuffix(string(dump), "\r\n\r\npassword=123"))
+}
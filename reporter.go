@@ -0,0 +1,68 @@
+package xun
+
+import (
+	"errors"
+	"net/http"
+	"runtime/debug"
+)
+
+// UserKey is the key of the current user in the Context values. Authentication
+// middlewares should set it with c.Set(xun.UserKey, user), so it can be used
+// by other extensions, e.g. the error reporter.
+const UserKey = "user"
+
+// ErrorReport describes an unhandled error or a panic of a request.
+type ErrorReport struct {
+	// Err is the unhandled error returned by the handler, or nil if it is a panic.
+	Err error
+	// Panic is the value recovered from a panic, or nil if it is an error.
+	Panic any
+	// Stack is the stack trace of the goroutine when the error is reported.
+	Stack []byte
+	// LogID is the X-Log-Id written to the client and the logger.
+	LogID string
+	// Route is the routing pattern of the request, e.g. "GET /users/{id}".
+	Route string
+	// User is the value of UserKey in the Context values, or nil if it is not set.
+	User any
+	// Request is the HTTP request, its context can be used to propagate traces.
+	Request *http.Request
+	// Redactor masks the fields declared by WithRedact, it should be applied
+	// to the request data sent to external services.
+	Redactor *Redactor
+}
+
+// Reporter is an interface that reports unhandled errors and panics to external
+// services, e.g. Sentry. Report is called on the request goroutine, so slow
+// implementations should send the report asynchronously.
+type Reporter interface {
+	Report(r *ErrorReport)
+}
+
+// nopReporter is the default Reporter that does nothing.
+type nopReporter struct{}
+
+// Report implements the Reporter interface and does nothing.
+func (nopReporter) Report(*ErrorReport) {}
+
+// report sends an unhandled error or a panic of the request to the error reporter.
+// A *PanicError is reported as the panic with its stack.
+func (app *App) report(c *Context, err error, p any, logID string) {
+	stack := debug.Stack()
+
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		err, p, stack = nil, pe.Value, pe.Stack
+	}
+
+	app.reporter.Report(&ErrorReport{
+		Err:      err,
+		Panic:    p,
+		Stack:    stack,
+		LogID:    logID,
+		Route:    c.Routing.Pattern,
+		User:     c.Get(UserKey),
+		Request:  c.req,
+		Redactor: c.Redactor(),
+	})
+}
@@ -0,0 +1,98 @@
+package xun
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testReporter struct {
+	mu      sync.Mutex
+	reports []*ErrorReport
+}
+
+func (r *testReporter) Report(e *ErrorReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reports = append(r.reports, e)
+}
+
+func (r *testReporter) last() *ErrorReport {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.reports) == 0 {
+		return nil
+	}
+	return r.reports[len(r.reports)-1]
+}
+
+func TestWithErrorReporter(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	r := &testReporter{}
+	app := New(WithMux(mux), WithErrorReporter(r))
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			c.Set(UserKey, "alice")
+			return next(c)
+		}
+	})
+
+	errFailed := errors.New("failed")
+
+	app.Get("/error", func(c *Context) error {
+		return errFailed
+	})
+
+	app.Post("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	app.Get("/cancelled", func(c *Context) error {
+		c.WriteStatus(http.StatusBadRequest)
+		return ErrCancelled
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/error")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	e := r.last()
+	require.NotNil(t, e)
+	require.ErrorIs(t, e.Err, errFailed)
+	require.Nil(t, e.Panic)
+	require.Equal(t, resp.Header.Get("X-Log-Id"), e.LogID)
+	require.Equal(t, "GET /error", e.Route)
+	require.Equal(t, "alice", e.User)
+	require.NotEmpty(t, e.Stack)
+	require.Equal(t, "/error", e.Request.URL.Path)
+
+	resp, err = client.Post(srv.URL+"/panic", "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+
+	e = r.last()
+	require.NotNil(t, e)
+	require.Nil(t, e.Err)
+	require.Equal(t, "boom", e.Panic)
+	require.Equal(t, resp.Header.Get("X-Log-Id"), e.LogID)
+	require.Equal(t, "POST /panic", e.Route)
+	require.Contains(t, string(e.Stack), "reporter_test.go")
+
+	resp, err = client.Get(srv.URL + "/cancelled")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Len(t, r.reports, 2)
+}
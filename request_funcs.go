@@ -0,0 +1,30 @@
+package xun
+
+import (
+	"html/template"
+	"net/http"
+)
+
+// requestFuncs is a map of template functions that are bound to the current
+// request when a template is executed, e.g. `urlAbs`.
+type requestFuncs map[string]func(r *http.Request) any
+
+// placeholders returns the functions that templates are parsed with.
+// They are replaced by the functions bound to the request before execution.
+func (fm requestFuncs) placeholders() template.FuncMap {
+	funcs := make(template.FuncMap, len(fm))
+	for name := range fm {
+		funcs[name] = func(...any) any { return nil }
+	}
+	return funcs
+}
+
+// bind returns the functions bound to the request r. r is nil if the template
+// is executed without a request.
+func (fm requestFuncs) bind(r *http.Request) template.FuncMap {
+	funcs := make(template.FuncMap, len(fm))
+	for name, fn := range fm {
+		funcs[name] = fn(r)
+	}
+	return funcs
+}
@@ -0,0 +1,207 @@
+package xun
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ResourceLister is implemented by resources that list their items.
+type ResourceLister interface {
+	List(c *Context) (any, error)
+}
+
+// ResourceShower is implemented by resources that show an item by its id.
+type ResourceShower interface {
+	Show(c *Context, id string) (any, error)
+}
+
+// ResourceCreator is implemented by resources that create items, e.g. bound by Bind.
+type ResourceCreator interface {
+	Create(c *Context) (any, error)
+}
+
+// ResourceUpdater is implemented by resources that update an item by its id.
+type ResourceUpdater interface {
+	Update(c *Context, id string) (any, error)
+}
+
+// ResourceDeleter is implemented by resources that delete an item by its id.
+type ResourceDeleter interface {
+	Delete(c *Context, id string) error
+}
+
+// ResourceNavigation is implemented by resources that are listed in the
+// navigation by their list route, see WithNavigation.
+type ResourceNavigation interface {
+	Navigation() (name, icon, access string)
+}
+
+// Resource registers the RESTful routes of the resource res at the prefix,
+// e.g. "/users", for the methods it implements:
+//
+//	GET    /users           List    views/users/index  users.index
+//	GET    /users/new               views/users/form   users.new
+//	POST   /users           Create                     users.create
+//	GET    /users/{id}      Show    views/users/show   users.show
+//	GET    /users/{id}/edit Show    views/users/form   users.edit
+//	PUT    /users/{id}      Update                     users.update
+//	DELETE /users/{id}      Delete                     users.delete
+//
+// The data is rendered by the views for clients accepting text/html, and as
+// JSON otherwise. Creates, updates and deletes of html clients are redirected
+// to the list or the item, and the deletes of htmx requests respond 200 OK
+// with an empty body, so the element of the item is swapped out. JSON clients
+// get 201 Created, 200 OK and 204 No Content. The form routes are only
+// registered if the form view exists. Return an *HttpError to respond e.g.
+// 404 Not Found, and the *BindError of Bind to respond with the fields.
+//
+// The opts are applied to all routes, e.g. middlewares of the group.
+func (app *App) Resource(prefix string, res any, opts ...RoutingOption) {
+	prefix = "/" + strings.Trim(prefix, "/")
+	name := strings.ReplaceAll(strings.Trim(prefix, "/"), "/", ".")
+	view := func(action string) string {
+		return "views/" + strings.Trim(prefix, "/") + "/" + action
+	}
+
+	route := func(action string) []RoutingOption {
+		return append([]RoutingOption{WithName(name + "." + action)}, opts...)
+	}
+
+	_, hasForm := app.viewers[view("form")]
+
+	if it, ok := res.(ResourceLister); ok {
+		ro := route("index")
+		if nav, ok := res.(ResourceNavigation); ok {
+			ro = append(ro, WithNavigation(nav.Navigation()))
+		}
+
+		app.Get(prefix, func(c *Context) error {
+			data, err := it.List(c)
+			if err != nil {
+				return err
+			}
+			return c.View(data, view("index"))
+		}, ro...)
+	}
+
+	if it, ok := res.(ResourceCreator); ok {
+		if hasForm {
+			app.Get(prefix+"/new", func(c *Context) error {
+				return c.View(nil, view("form"))
+			}, route("new")...)
+		}
+
+		app.Post(prefix, func(c *Context) error {
+			data, err := it.Create(c)
+			if err != nil {
+				return err
+			}
+
+			if prefersHtml(c.req) {
+				c.Redirect(prefix, http.StatusSeeOther)
+				return nil
+			}
+
+			return c.viewStatus(http.StatusCreated, data)
+		}, route("create")...)
+	}
+
+	it, canShow := res.(ResourceShower)
+	if canShow {
+		app.Get(prefix+"/{id}", func(c *Context) error {
+			data, err := it.Show(c, c.req.PathValue("id"))
+			if err != nil {
+				return err
+			}
+			return c.View(data, view("show"))
+		}, route("show")...)
+	}
+
+	if up, ok := res.(ResourceUpdater); ok {
+		if canShow && hasForm {
+			app.Get(prefix+"/{id}/edit", func(c *Context) error {
+				data, err := it.Show(c, c.req.PathValue("id"))
+				if err != nil {
+					return err
+				}
+				return c.View(data, view("form"))
+			}, route("edit")...)
+		}
+
+		app.Put(prefix+"/{id}", func(c *Context) error {
+			id := c.req.PathValue("id")
+			data, err := up.Update(c, id)
+			if err != nil {
+				return err
+			}
+
+			if prefersHtml(c.req) {
+				c.Redirect(prefix+"/"+url.PathEscape(id), http.StatusSeeOther)
+				return nil
+			}
+
+			return c.View(data)
+		}, route("update")...)
+	}
+
+	if del, ok := res.(ResourceDeleter); ok {
+		app.Delete(prefix+"/{id}", func(c *Context) error {
+			if err := del.Delete(c, c.req.PathValue("id")); err != nil {
+				return err
+			}
+
+			switch {
+			case c.Htmx().Request:
+				c.WriteStatus(http.StatusOK)
+			case prefersHtml(c.req):
+				c.Redirect(prefix, http.StatusSeeOther)
+			default:
+				c.WriteStatus(http.StatusNoContent)
+			}
+			return nil
+		}, route("delete")...)
+	}
+}
+
+// viewStatus renders the data by c.View with the status code, which is written
+// on the first write of the viewer, after it sets the Content-Type.
+func (c *Context) viewStatus(status int, data any) error {
+	rw := c.rw
+	sw := &statusWriter{ResponseWriter: rw, status: status}
+
+	c.rw = sw
+	defer func() { c.rw = rw }()
+
+	err := c.View(data)
+	if err == nil && sw.status != 0 {
+		c.rw = rw
+		c.WriteStatus(status)
+	}
+	return err
+}
+
+// statusWriter writes its status instead of the implicit 200 OK of the first Write.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader writes the status code, so the status of the writer is dropped.
+func (w *statusWriter) WriteHeader(statusCode int) {
+	w.status = 0
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write writes the status of the writer before the first write.
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status != 0 {
+		w.WriteHeader(w.status)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,188 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testUser struct {
+	ID   string `json:"id" form:"id"`
+	Name string `json:"name" form:"name"`
+}
+
+type testUserResource struct {
+	mu    sync.Mutex
+	users map[string]*testUser
+}
+
+func (r *testUserResource) List(c *Context) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return []*testUser{r.users["1"]}, nil
+}
+
+func (r *testUserResource) Show(c *Context, id string) (any, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	u, ok := r.users[id]
+	if !ok {
+		return nil, NewHttpError(http.StatusNotFound, "user not found")
+	}
+	return u, nil
+}
+
+func (r *testUserResource) Create(c *Context) (any, error) {
+	it, err := Bind[testUser](c)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.users[it.Data.ID] = &it.Data
+	return &it.Data, nil
+}
+
+func (r *testUserResource) Update(c *Context, id string) (any, error) {
+	it, err := Bind[testUser](c)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	it.Data.ID = id
+	r.users[id] = &it.Data
+	return &it.Data, nil
+}
+
+func (r *testUserResource) Delete(c *Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, id)
+	return nil
+}
+
+func (r *testUserResource) Navigation() (name, icon, access string) {
+	return "Users", "users", ""
+}
+
+func TestResource(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/users/index.html": {Data: []byte(`{{ range . }}<li>{{ .Name }}</li>{{ end }}`)},
+		"views/users/show.html":  {Data: []byte(`<h1>{{ .Name }}</h1>`)},
+		"views/users/form.html":  {Data: []byte(`<form>{{ if . }}{{ .Name }}{{ end }}</form>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+
+	app.Resource("/users", &testUserResource{users: map[string]*testUser{"1": {ID: "1", Name: "xun"}}})
+
+	app.Start()
+	defer app.Close()
+
+	noRedirect := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+
+	do := func(t *testing.T, method, path, accept, body string, headers ...string) (*http.Response, string) {
+		req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+
+		resp, err := noRedirect.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp, string(buf)
+	}
+
+	t.Run("list", func(t *testing.T) {
+		_, body := do(t, http.MethodGet, "/users", "text/html", "")
+		require.Equal(t, `<li>xun</li>`, body)
+
+		_, body = do(t, http.MethodGet, "/users", "application/json", "")
+		require.JSONEq(t, `[{"id":"1","name":"xun"}]`, body)
+	})
+
+	t.Run("show", func(t *testing.T) {
+		_, body := do(t, http.MethodGet, "/users/1", "text/html", "")
+		require.Equal(t, `<h1>xun</h1>`, body)
+
+		resp, _ := do(t, http.MethodGet, "/users/2", "application/json", "")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("forms", func(t *testing.T) {
+		_, body := do(t, http.MethodGet, "/users/new", "text/html", "")
+		require.Equal(t, `<form></form>`, body)
+
+		_, body = do(t, http.MethodGet, "/users/1/edit", "text/html", "")
+		require.Equal(t, `<form>xun</form>`, body)
+	})
+
+	t.Run("create", func(t *testing.T) {
+		resp, body := do(t, http.MethodPost, "/users", "application/json", `{"id":"2","name":"go"}`, "Content-Type", "application/json")
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+		require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+		require.JSONEq(t, `{"id":"2","name":"go"}`, body)
+
+		resp, _ = do(t, http.MethodPost, "/users", "text/html", `id=3&name=htmx`, "Content-Type", "application/x-www-form-urlencoded")
+		require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		require.Equal(t, "/users", resp.Header.Get("Location"))
+
+		resp, _ = do(t, http.MethodPost, "/users", "application/json", `{"id":1}`, "Content-Type", "application/json")
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("update", func(t *testing.T) {
+		resp, body := do(t, http.MethodPut, "/users/2", "application/json", `{"name":"golang"}`, "Content-Type", "application/json")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.JSONEq(t, `{"id":"2","name":"golang"}`, body)
+
+		resp, _ = do(t, http.MethodPut, "/users/a%20b", "text/html", `name=space`, "Content-Type", "application/x-www-form-urlencoded")
+		require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		require.Equal(t, "/users/a%20b", resp.Header.Get("Location"))
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		resp, _ := do(t, http.MethodDelete, "/users/2", "application/json", "")
+		require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+		resp, body := do(t, http.MethodDelete, "/users/3", "text/html", "", "HX-Request", "true")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Empty(t, body)
+	})
+
+	t.Run("routes", func(t *testing.T) {
+		u, err := app.URL("users.show", "id", "1")
+		require.NoError(t, err)
+		require.Equal(t, "/users/1", u)
+
+		nav := app.Navigation(httptest.NewRequest(http.MethodGet, "/", nil))
+		require.Len(t, nav, 1)
+		require.Equal(t, "Users", nav[0].Name)
+		require.Equal(t, "/users", nav[0].Path)
+	})
+}
@@ -1,14 +1,49 @@
 package xun
 
 import (
+	"bufio"
+	"net"
 	"net/http"
 )
 
 // ResponseWriter is an interface that extends the standard http.ResponseWriter
 // interface with an additional Close method. It is used to write HTTP responses
 // and perform any necessary cleanup or finalization when the response is complete.
+//
+// The built-in writers also implement http.Flusher, http.Hijacker and
+// http.Pusher by passing through to the underlying http.ResponseWriter, and
+// Unwrap for http.ResponseController.
 type ResponseWriter interface {
 	http.ResponseWriter
 
 	Close()
 }
+
+// hijack takes over the connection of the underlying http.ResponseWriter.
+// It returns http.ErrNotSupported if it doesn't implement http.Hijacker.
+func hijack(rw http.ResponseWriter) (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rw.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+
+	return h.Hijack()
+}
+
+// push initiates an HTTP/2 server push on the underlying http.ResponseWriter.
+// It returns http.ErrNotSupported if it doesn't implement http.Pusher.
+func push(rw http.ResponseWriter, target string, opts *http.PushOptions) error {
+	p, ok := rw.(http.Pusher)
+	if !ok {
+		return http.ErrNotSupported
+	}
+
+	return p.Push(target, opts)
+}
+
+// flush sends any buffered data of the underlying http.ResponseWriter to the client.
+func flush(rw http.ResponseWriter) {
+	if f, ok := rw.(http.Flusher); ok {
+		f.Flush()
+	}
+}
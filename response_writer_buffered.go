@@ -0,0 +1,158 @@
+package xun
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// maxBufferedBody is the maximum size of a buffered response body. A larger
+// response is streamed to the client without calling the buffered hooks.
+const maxBufferedBody = 4 << 20
+
+// BufferedResponse is a response that is buffered in memory before it is sent
+// to the client. Hooks registered by c.Buffered can modify it, e.g. to set
+// an ETag header, cache or minify the body.
+type BufferedResponse struct {
+	w *bufferedResponseWriter
+}
+
+// StatusCode returns the status code of the response. It is 200 if the handler doesn't set it.
+func (b *BufferedResponse) StatusCode() int {
+	if b.w.statusCode == 0 {
+		return http.StatusOK
+	}
+	return b.w.statusCode
+}
+
+// SetStatusCode overrides the status code of the response, e.g. 304 Not Modified.
+func (b *BufferedResponse) SetStatusCode(code int) {
+	b.w.statusCode = code
+}
+
+// Header returns the header map of the response.
+func (b *BufferedResponse) Header() http.Header {
+	return b.w.Header()
+}
+
+// Body returns the response body. It must not be modified, use SetBody instead.
+func (b *BufferedResponse) Body() []byte {
+	return b.w.buf.Bytes()
+}
+
+// SetBody replaces the response body.
+func (b *BufferedResponse) SetBody(body []byte) {
+	b.w.buf.Reset()
+	b.w.buf.Write(body)
+}
+
+// bufferedResponseWriter buffers the response until the handler returns, unless
+// it is a streaming response. A response is streamed, and the hooks are
+// skipped, if it is flushed or hijacked, if its Content-Type is
+// text/event-stream, if it is an attachment download, or if its body exceeds
+// maxBufferedBody.
+type bufferedResponseWriter struct {
+	http.ResponseWriter
+
+	hooks      []func(b *BufferedResponse)
+	statusCode int
+	buf        bytes.Buffer
+	streaming  bool
+}
+
+// WriteHeader records the status code until the response is sent.
+func (w *bufferedResponseWriter) WriteHeader(statusCode int) {
+	if w.streaming {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+}
+
+// Write buffers the data, or writes it to the client if the response is streamed.
+func (w *bufferedResponseWriter) Write(p []byte) (int, error) {
+	if !w.streaming && (w.isStream() || w.buf.Len()+len(p) > maxBufferedBody) {
+		w.stream()
+	}
+
+	if w.streaming {
+		return w.ResponseWriter.Write(p)
+	}
+
+	return w.buf.Write(p)
+}
+
+// isStream reports whether the response should not be buffered by its headers.
+func (w *bufferedResponseWriter) isStream() bool {
+	h := w.Header()
+	return strings.HasPrefix(h.Get("Content-Type"), "text/event-stream") ||
+		strings.HasPrefix(h.Get("Content-Disposition"), "attachment")
+}
+
+// stream switches to streaming. The buffered data is written to the client, and hooks are skipped.
+func (w *bufferedResponseWriter) stream() {
+	if w.streaming {
+		return
+	}
+	w.streaming = true
+
+	if w.statusCode != 0 {
+		w.ResponseWriter.WriteHeader(w.statusCode)
+	}
+
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes()) // nolint: errcheck
+		w.buf.Reset()
+	}
+}
+
+// Flush switches to streaming, and sends any buffered data to the client.
+// It implements the http.Flusher interface.
+func (w *bufferedResponseWriter) Flush() {
+	w.stream()
+	flush(w.ResponseWriter)
+}
+
+// Hijack lets the caller take over the connection. The buffered data is discarded.
+// It implements the http.Hijacker interface.
+func (w *bufferedResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.streaming = true
+	return hijack(w.ResponseWriter)
+}
+
+// Push initiates an HTTP/2 server push.
+// It implements the http.Pusher interface.
+func (w *bufferedResponseWriter) Push(target string, opts *http.PushOptions) error {
+	return push(w.ResponseWriter, target, opts)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *bufferedResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// finish runs the hooks and sends the buffered response to the client.
+func (w *bufferedResponseWriter) finish() {
+	if w.streaming {
+		return
+	}
+	w.streaming = true
+
+	b := &BufferedResponse{w: w}
+	for _, hook := range w.hooks {
+		hook(b)
+	}
+
+	if w.statusCode != 0 {
+		w.ResponseWriter.WriteHeader(w.statusCode)
+	}
+
+	if w.buf.Len() > 0 {
+		w.ResponseWriter.Write(w.buf.Bytes()) // nolint: errcheck
+	}
+}
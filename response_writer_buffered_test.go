@@ -0,0 +1,123 @@
+package xun
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func etag(next HandleFunc) HandleFunc {
+	return func(c *Context) error {
+		c.Buffered(func(b *BufferedResponse) {
+			if b.StatusCode() != http.StatusOK {
+				return
+			}
+
+			sum := sha256.Sum256(b.Body())
+			tag := `"` + hex.EncodeToString(sum[:8]) + `"`
+			b.Header().Set("ETag", tag)
+
+			if c.Request().Header.Get("If-None-Match") == tag {
+				b.SetStatusCode(http.StatusNotModified)
+				b.SetBody(nil)
+			}
+		})
+		return next(c)
+	}
+}
+
+func minify(next HandleFunc) HandleFunc {
+	return func(c *Context) error {
+		c.Buffered(func(b *BufferedResponse) {
+			b.SetBody([]byte(strings.Join(strings.Fields(string(b.Body())), " ")))
+		})
+		return next(c)
+	}
+}
+
+func TestBuffered(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithCompressor(&GzipCompressor{}))
+	app.Use(etag, minify)
+
+	var status int
+	var written int64
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			err := next(c)
+			status, written = c.StatusCode(), c.BytesWritten()
+			return err
+		}
+	})
+
+	app.Get("/page", func(c *Context) error {
+		c.WriteHeader("Content-Type", "text/plain")
+		_, err := c.Writer().Write([]byte("hello    \n   xun"))
+		return err
+	})
+
+	app.Get("/events", func(c *Context) error {
+		c.WriteHeader("Content-Type", "text/event-stream")
+		c.Writer().Write([]byte("data: 1\n\n")) // nolint: errcheck
+		return http.NewResponseController(c.Writer()).Flush()
+	})
+
+	app.Get("/download", func(c *Context) error {
+		c.WriteHeader("Content-Disposition", `attachment; filename="a.txt"`)
+		_, err := c.Writer().Write([]byte("a   b"))
+		return err
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/page")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "hello xun", string(buf))
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, int64(len("hello    \n   xun")), written)
+
+	tag := resp.Header.Get("ETag")
+	require.NotEmpty(t, tag)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/page", nil)
+	require.NoError(t, err)
+	req.Header.Set("If-None-Match", tag)
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotModified, resp.StatusCode)
+
+	resp, err = client.Get(srv.URL + "/events")
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, "data: 1\n\n", string(buf))
+	require.Empty(t, resp.Header.Get("ETag"))
+
+	resp, err = client.Get(srv.URL + "/download")
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, "a   b", string(buf))
+	require.Empty(t, resp.Header.Get("ETag"))
+}
@@ -0,0 +1,189 @@
+package xun
+
+import (
+	"bufio"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// CompressSkipTypes are the content types that the compressors skip, as they
+// are compressed already, e.g. images and archives, or streamed. The types
+// ending with "/" match all their subtypes.
+var CompressSkipTypes = []string{
+	"image/png", "image/jpeg", "image/gif", "image/webp", "image/avif",
+	"video/", "audio/", "font/woff", "font/woff2",
+	"application/zip", "application/gzip", "application/x-gzip", "application/x-bzip2",
+	"application/x-xz", "application/x-7z-compressed", "application/x-rar-compressed",
+	"application/zstd", "application/pdf", "application/wasm",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"text/event-stream",
+}
+
+// encoder is a streaming compression writer that is reused by Reset, e.g.
+// *gzip.Writer, *flate.Writer and *brotli.Writer.
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+// compressResponseWriter is a custom http.ResponseWriter that wraps the
+// standard ResponseWriter and compresses the response by a pooled encoder.
+// The status code is held until the body is written, so the response is only
+// compressed if its content type is compressible.
+type compressResponseWriter struct {
+	http.ResponseWriter
+
+	encoding string
+	pool     *sync.Pool
+	enc      encoder
+
+	status   int
+	decided  bool
+	hijacked bool
+}
+
+// newCompressResponseWriter returns a compressResponseWriter of the encoding
+// with the encoders of the pool.
+func newCompressResponseWriter(rw http.ResponseWriter, encoding string, pool *sync.Pool) *compressResponseWriter {
+	return &compressResponseWriter{
+		ResponseWriter: rw,
+		encoding:       encoding,
+		pool:           pool,
+	}
+}
+
+// WriteHeader holds the status code until the body is written. The
+// informational status codes are sent to the client immediately.
+func (w *compressResponseWriter) WriteHeader(statusCode int) {
+	if statusCode < http.StatusOK {
+		w.ResponseWriter.WriteHeader(statusCode)
+		return
+	}
+
+	if w.status == 0 && !w.decided {
+		w.status = statusCode
+	}
+}
+
+// Write writes the data to the encoder, or to the underlying writer if the
+// response isn't compressed. The content type is detected from the data if
+// it isn't set, as it can't be detected from the compressed data.
+func (w *compressResponseWriter) Write(p []byte) (int, error) {
+	if !w.decided {
+		if w.Header().Get("Content-Type") == "" && len(p) > 0 {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.decide()
+	}
+
+	if w.enc == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.enc.Write(p)
+}
+
+// decide compresses the response if its status code and content type allow
+// it, and writes the status code.
+func (w *compressResponseWriter) decide() {
+	w.decided = true
+
+	status := w.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	h := w.Header()
+	if status != http.StatusNoContent && status != http.StatusPartialContent && status != http.StatusNotModified &&
+		h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+
+		w.enc = w.pool.Get().(encoder)
+		w.enc.Reset(w.ResponseWriter)
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Close closes the encoder, ensuring that the compressed data is written, and
+// puts it back to the pool. The status code is written if there is no body.
+func (w *compressResponseWriter) Close() {
+	if w.hijacked {
+		return
+	}
+
+	if !w.decided {
+		w.decided = true
+		if w.status != 0 {
+			w.ResponseWriter.WriteHeader(w.status)
+		}
+		return
+	}
+
+	if w.enc != nil {
+		w.enc.Close()
+		w.enc.Reset(io.Discard)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
+
+// Flush sends any buffered data to the client.
+// It implements the http.Flusher interface.
+func (w *compressResponseWriter) Flush() {
+	if !w.decided {
+		w.decide()
+	}
+
+	if w.enc != nil {
+		w.enc.Flush() // nolint: errcheck
+	}
+	flush(w.ResponseWriter)
+}
+
+// Hijack lets the caller take over the connection.
+// It implements the http.Hijacker interface.
+func (w *compressResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, buf, err := hijack(w.ResponseWriter)
+	if err == nil {
+		// nothing should be written to the response after the connection is hijacked
+		w.hijacked = true
+	}
+	return conn, buf, err
+}
+
+// Push initiates an HTTP/2 server push.
+// It implements the http.Pusher interface.
+func (w *compressResponseWriter) Push(target string, opts *http.PushOptions) error {
+	return push(w.ResponseWriter, target, opts)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *compressResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// compressible reports whether the content type isn't skipped by CompressSkipTypes.
+func compressible(contentType string) bool {
+	if contentType == "" {
+		return false
+	}
+
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, it := range CompressSkipTypes {
+		if mt == it || (strings.HasSuffix(it, "/") && strings.HasPrefix(mt, it)) {
+			return false
+		}
+	}
+
+	return true
+}
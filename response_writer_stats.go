@@ -0,0 +1,101 @@
+package xun
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// statsResponseWriter wraps the ResponseWriter of a request to record the
+// status code and the number of bytes written by viewers and handlers.
+type statsResponseWriter struct {
+	ResponseWriter
+
+	statusCode   int
+	bytesWritten int64
+
+	// streams tracks the requests that are streaming, e.g. SSE and WebSocket
+	// connections. A request starts streaming when it flushes or hijacks.
+	streams   *streamSet
+	streaming bool
+	hijacked  bool
+	ended     bool
+	// mu serializes the writes of the handler and the drain event of Shutdown.
+	mu sync.Mutex
+}
+
+// stream marks the request as streaming.
+func (w *statsResponseWriter) stream() {
+	if w.streaming || w.streams == nil {
+		return
+	}
+	w.streaming = true
+	w.streams.add(w)
+}
+
+// endStream unmarks the request as streaming once the handler returns.
+func (w *statsResponseWriter) endStream() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.ended = true
+	if w.streaming {
+		w.streaming = false
+		w.streams.remove(w)
+	}
+}
+
+// WriteHeader records the status code and sends it to the underlying writer.
+func (w *statsResponseWriter) WriteHeader(statusCode int) {
+	if w.statusCode == 0 {
+		w.statusCode = statusCode
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Write records the number of bytes written and writes them to the underlying writer.
+func (w *statsResponseWriter) Write(p []byte) (int, error) {
+	if w.statusCode == 0 {
+		w.statusCode = http.StatusOK
+	}
+
+	w.mu.Lock()
+	n, err := w.ResponseWriter.Write(p)
+	w.mu.Unlock()
+
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+// Flush sends any buffered data to the client.
+// It implements the http.Flusher interface.
+func (w *statsResponseWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.stream()
+	flush(w.ResponseWriter)
+}
+
+// Hijack lets the caller take over the connection.
+// It implements the http.Hijacker interface.
+func (w *statsResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.hijacked = true
+	w.stream()
+	return hijack(w.ResponseWriter)
+}
+
+// Push initiates an HTTP/2 server push.
+// It implements the http.Pusher interface.
+func (w *statsResponseWriter) Push(target string, opts *http.PushOptions) error {
+	return push(w.ResponseWriter, target, opts)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *statsResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
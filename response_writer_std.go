@@ -1,6 +1,10 @@
 package xun
 
-import "net/http"
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
 
 // stdResponseWriter is a wrapper around http.ResponseWriter to implement the ResponseWriter interface.
 type stdResponseWriter struct {
@@ -11,3 +15,26 @@ type stdResponseWriter struct {
 // It is a no-op for the standard response writer.
 func (*stdResponseWriter) Close() {
 }
+
+// Flush sends any buffered data to the client.
+// It implements the http.Flusher interface.
+func (w *stdResponseWriter) Flush() {
+	flush(w.ResponseWriter)
+}
+
+// Hijack lets the caller take over the connection.
+// It implements the http.Hijacker interface.
+func (w *stdResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return hijack(w.ResponseWriter)
+}
+
+// Push initiates an HTTP/2 server push.
+// It implements the http.Pusher interface.
+func (w *stdResponseWriter) Push(target string, opts *http.PushOptions) error {
+	return push(w.ResponseWriter, target, opts)
+}
+
+// Unwrap returns the underlying http.ResponseWriter for http.ResponseController.
+func (w *stdResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
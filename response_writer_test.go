@@ -0,0 +1,99 @@
+package xun
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponseWriterPassthrough(t *testing.T) {
+	compressors := []struct {
+		name       string
+		compressor Compressor
+	}{
+		{name: "std"},
+		{name: "gzip", compressor: &GzipCompressor{}},
+		{name: "deflate", compressor: &DeflateCompressor{}},
+	}
+
+	for _, test := range compressors {
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+
+			opts := []Option{WithMux(mux)}
+			if test.compressor != nil {
+				opts = append(opts, WithCompressor(test.compressor))
+			}
+
+			app := New(opts...)
+
+			app.Get("/flush", func(c *Context) error {
+				_, ok := c.Writer().(http.Flusher)
+				require.True(t, ok)
+				_, ok = c.Writer().(http.Pusher)
+				require.True(t, ok)
+
+				c.Writer().Write([]byte("data")) // nolint: errcheck
+				return http.NewResponseController(c.Writer()).Flush()
+			})
+
+			app.Get("/hijack", func(c *Context) error {
+				conn, buf, err := c.Hijack()
+				if err != nil {
+					return err
+				}
+				defer conn.Close()
+
+				buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: echo\r\nConnection: Upgrade\r\n\r\n") // nolint: errcheck
+				buf.Flush()                                                                                         // nolint: errcheck
+
+				line, err := buf.ReadString('\n')
+				if err != nil {
+					return nil
+				}
+				buf.WriteString("echo: " + line) // nolint: errcheck
+				buf.Flush()                      // nolint: errcheck
+
+				return nil
+			})
+
+			app.Start()
+			defer app.Close()
+
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/flush", nil)
+			require.NoError(t, err)
+			req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+			require.NoError(t, err)
+			defer conn.Close()
+
+			_, err = io.WriteString(conn, "GET /hijack HTTP/1.1\r\nHost: localhost\r\nAccept-Encoding: gzip, deflate\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+			require.NoError(t, err)
+
+			r := bufio.NewReader(conn)
+			resp, err = http.ReadResponse(r, nil)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+
+			_, err = io.WriteString(conn, "hello\n")
+			require.NoError(t, err)
+
+			line, err := r.ReadString('\n')
+			require.NoError(t, err)
+			require.Equal(t, "echo: hello\n", line)
+		})
+	}
+}
@@ -11,4 +11,15 @@ type Router interface {
 	Delete(pattern string, h HandleFunc, opts ...RoutingOption)
 	HandleFunc(pattern string, h HandleFunc, opts ...RoutingOption)
 	Use(middlewares ...Middleware)
+	OnError(h ErrorHandler)
+}
+
+// ErrorHandler is a function that handles the unhandled errors of route
+// handlers, e.g. to render a problem+json response or an error page.
+// The X-Log-Id header is set before it is called for server errors.
+type ErrorHandler func(c *Context, err error)
+
+// errorHandler is implemented by routers that handle the unhandled errors of their routes.
+type errorHandler interface {
+	handleError(c *Context, err error) bool
 }
@@ -1,5 +1,10 @@
 package xun
 
+import (
+	"mime"
+	"net/http"
+)
+
 // Routing represents a single route in the router.
 type Routing struct {
 	Pattern string
@@ -11,5 +16,55 @@ type Routing struct {
 }
 
 func (r *Routing) Next(ctx *Context) error {
+	if r.Options != nil && (len(r.Options.accepts) > 0 || len(r.Options.contentTypes) > 0) {
+		return r.chain.Next(r.negotiate)(ctx)
+	}
 	return r.chain.Next(r.Handle)(ctx)
 }
+
+// negotiate rejects the request with 415 Unsupported Media Type if its body
+// isn't declared by WithAccepts, or with 406 Not Acceptable if none of the
+// content types declared by WithContentType is acceptable by the client.
+// Otherwise, it runs the handler.
+func (r *Routing) negotiate(c *Context) error {
+	if len(r.Options.accepts) > 0 && hasBody(c.req) {
+		mt, _, _ := mime.ParseMediaType(c.req.Header.Get("Content-Type"))
+		ct := NewMimeType(mt)
+		// the Content-Type of a body is concrete, wildcards only belong to Accept
+		if ct.Type == "*" || ct.SubType == "*" || !matchMimeTypes(r.Options.accepts, ct) {
+			c.WriteStatus(http.StatusUnsupportedMediaType)
+			return ErrCancelled
+		}
+	}
+
+	if len(r.Options.contentTypes) > 0 && c.req.Header.Get("Accept") != "" {
+		ok := false
+		for _, accept := range c.Accept() {
+			if matchMimeTypes(r.Options.contentTypes, accept) {
+				ok = true
+				break
+			}
+		}
+
+		if !ok {
+			c.WriteStatus(http.StatusNotAcceptable)
+			return ErrCancelled
+		}
+	}
+
+	return r.Handle(c)
+}
+
+// hasBody reports whether the request has a body, or its Content-Type is set.
+func hasBody(req *http.Request) bool {
+	return req.ContentLength != 0 || req.Header.Get("Content-Type") != ""
+}
+
+func matchMimeTypes(types []MimeType, mt MimeType) bool {
+	for _, it := range types {
+		if it.Match(mt) {
+			return true
+		}
+	}
+	return false
+}
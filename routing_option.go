@@ -1,9 +1,27 @@
 package xun
 
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
 // RoutingOptions holds metadata and a viewer for routing configuration.
 type RoutingOptions struct {
 	metadata map[string]any
 	viewers  []Viewer
+	deadline time.Duration
+	coalesce time.Duration
+	exports  []string
+	export   Viewer
+	redactor *Redactor
+
+	accepts      []MimeType
+	contentTypes []MimeType
+
+	fragmentLayout string
+	jsonOptions    *JsonOptions
+	name           string
 }
 
 // Get returns the value associated with the given name from the routing metadata.
@@ -44,6 +62,20 @@ func (ro *RoutingOptions) GetInt(name string) int {
 	return v
 }
 
+// RequestType returns the type of the request body declared by WithRequestType,
+// or nil if it is not declared.
+func (ro *RoutingOptions) RequestType() reflect.Type {
+	t, _ := ro.metadata[RequestType].(reflect.Type)
+	return t
+}
+
+// ResponseType returns the type of the response data declared by WithResponseType,
+// or nil if it is not declared.
+func (ro *RoutingOptions) ResponseType() reflect.Type {
+	t, _ := ro.metadata[ResponseType].(reflect.Type)
+	return t
+}
+
 // RoutingOption is a function that takes a pointer to RoutingOptions and
 // modifies it. It is used to customize the behavior of the router when
 // adding routes.
@@ -53,6 +85,9 @@ const (
 	NavigationName   = "name"
 	NavigationIcon   = "icon"
 	NavigationAccess = "access"
+
+	RequestType  = "request_type"
+	ResponseType = "response_type"
 )
 
 // WithMetadata adds a key-value pair to the routing metadata.
@@ -67,6 +102,15 @@ func WithMetadata(key string, value any) RoutingOption {
 	}
 }
 
+// WithName sets the name of the route, e.g. WithName("user.show"), so its URL
+// is built by app.URL and the `url` template function instead of hardcoding
+// the path.
+func WithName(name string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.name = name
+	}
+}
+
 // WithNavigation adds navigation-related metadata to the routing options.
 // It sets the name, icon, and access level for the navigation element.
 func WithNavigation(name, icon, access string) RoutingOption {
@@ -87,3 +131,110 @@ func WithViewer(v ...Viewer) RoutingOption {
 		ro.viewers = v
 	}
 }
+
+// WithDeadline sets the time budget of the route handler, e.g. a long one for
+// exports and a short one for htmx swaps. The request context is cancelled
+// once the deadline is exceeded, and it is exposed to handlers by c.Deadline.
+//
+// If the handler returns context.DeadlineExceeded, 503 Service Unavailable is
+// written to the client.
+func WithDeadline(d time.Duration) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.deadline = d
+	}
+}
+
+// WithRequestType declares T as the type of the request body in the routing
+// metadata, e.g. for OpenAPI generation and typed test clients.
+func WithRequestType[T any]() RoutingOption {
+	return WithMetadata(RequestType, reflect.TypeFor[T]())
+}
+
+// WithResponseType declares T as the type of the data rendered by the route
+// handler in the routing metadata. If WithWatch is enabled, a warning is logged
+// when the handler renders data of another type.
+func WithResponseType[T any]() RoutingOption {
+	return WithMetadata(ResponseType, reflect.TypeFor[T]())
+}
+
+// WithExport registers sibling routes of a GET route to export its data by
+// extension, e.g. `GET /users.csv` for `GET /users` with WithExport("csv").
+// The sibling routes run the same handler, and c.View renders the data with
+// the viewer registered for the extension by WithExporter instead.
+//
+// For `GET /users/{$}`, the sibling route is `GET /users.csv`, and it is
+// `GET /index.csv` for `GET /{$}`.
+func WithExport(exts ...string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.exports = exts
+	}
+}
+
+// WithRedact masks the values of the fields in query strings, forms, JSON
+// bodies and headers of the route, e.g. WithRedact("password", "token") on
+// auth routes. It is applied by c.DumpRequest and the error reporter, and
+// logging middlewares should apply c.Redactor to the data they log.
+func WithRedact(fields ...string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.redactor = NewRedactor(fields...)
+	}
+}
+
+// WithAccepts declares the content types of request bodies accepted by the
+// route, e.g. WithAccepts("application/json"). Requests with a body of other
+// types are rejected with 415 Unsupported Media Type before the handler runs.
+func WithAccepts(types ...string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		for _, t := range types {
+			ro.accepts = append(ro.accepts, NewMimeType(t))
+		}
+	}
+}
+
+// WithContentType declares the content types of responses rendered by the
+// route, e.g. WithContentType("application/json", "text/csv"). Requests that
+// accept none of them are rejected with 406 Not Acceptable before the handler runs.
+func WithContentType(types ...string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		for _, t := range types {
+			ro.contentTypes = append(ro.contentTypes, NewMimeType(t))
+		}
+	}
+}
+
+// WithCoalesce enables single-flight coalescing of identical concurrent GET and
+// HEAD requests, e.g. htmx polling endpoints. Only one request renders the
+// response, and a 200 OK response is reused for ttl by requests with the same
+// URL, host and Accept/HX-* headers. The middlewares still run for each
+// request, e.g. AccessLog.
+//
+// It should only be used on routes that render the same content for all
+// clients. Requests with a Cookie or Authorization header, and responses
+// with a Set-Cookie header, are never shared.
+func WithCoalesce(ttl time.Duration) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.coalesce = ttl
+	}
+}
+
+// WithFragmentLayout marks the route as an htmx fragment, e.g. a view without
+// a layout for hx-get. Normal navigations, without the HX-Request header, are
+// rendered with the fragment as the "content" block of the layout instead, e.g.
+// WithFragmentLayout("main") for layouts/main.html, so the route remains usable
+// without JavaScript.
+func WithFragmentLayout(layout string) RoutingOption {
+	return func(ro *RoutingOptions) {
+		if !strings.HasPrefix(layout, "layouts/") {
+			layout = "layouts/" + layout
+		}
+		ro.fragmentLayout = layout
+	}
+}
+
+// WithRouteJsonOptions sets the JsonOptions of BindJson for the route. It
+// overrides the JsonOptions of WithJsonOptions.
+func WithRouteJsonOptions(o JsonOptions) RoutingOption {
+	return func(ro *RoutingOptions) {
+		ro.jsonOptions = &o
+	}
+}
@@ -1,10 +1,14 @@
 package xun
 
 import (
+	"bytes"
 	"encoding/xml"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -155,3 +159,166 @@ func TestRoutingOption(t *testing.T) {
 	require.Equal(t, "xml_name", xo.Name)
 	require.EqualValues(t, 100, xo.Icon)
 }
+
+func TestWithDeadline(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	app.Get("/swap", func(c *Context) error {
+		select {
+		case <-time.After(time.Second):
+			return c.View(nil)
+		case <-c.Request().Context().Done():
+			return c.Request().Context().Err()
+		}
+	}, WithDeadline(50*time.Millisecond))
+
+	app.Get("/export", func(c *Context) error {
+		deadline, ok := c.Deadline()
+		return c.View(map[string]any{
+			"ok":     ok,
+			"budget": time.Until(deadline) > time.Minute,
+		})
+	}, WithDeadline(time.Hour))
+
+	app.Get("/none", func(c *Context) error {
+		_, ok := c.Deadline()
+		return c.View(map[string]any{"ok": ok})
+	})
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + "/swap")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	resp, err = client.Get(srv.URL + "/export")
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.JSONEq(t, `{"ok":true,"budget":true}`, string(buf))
+
+	resp, err = client.Get(srv.URL + "/none")
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.JSONEq(t, `{"ok":false}`, string(buf))
+}
+
+func TestWithRequestResponseType(t *testing.T) {
+	type createUser struct {
+		Name string `json:"name"`
+	}
+
+	type user struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	var logs bytes.Buffer
+	app := New(WithMux(mux), WithWatch(), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+
+	app.Post("/users", func(c *Context) error {
+		return c.View(&user{ID: 1, Name: "xun"})
+	}, WithRequestType[createUser](), WithResponseType[user]())
+
+	app.Get("/users/{id}", func(c *Context) error {
+		return c.View(map[string]any{"id": 1})
+	}, WithResponseType[user]())
+
+	app.Start()
+	defer app.Close()
+
+	r := app.routes["POST /users"]
+	require.Equal(t, reflect.TypeFor[createUser](), r.Options.RequestType())
+	require.Equal(t, reflect.TypeFor[user](), r.Options.ResponseType())
+
+	r = app.routes["GET /users/{id}"]
+	require.Nil(t, r.Options.RequestType())
+
+	resp, err := client.Post(srv.URL+"/users", "application/json", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NotContains(t, logs.String(), "response type mismatch")
+
+	resp, err = client.Get(srv.URL + "/users/1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Contains(t, logs.String(), "response type mismatch")
+	require.Contains(t, logs.String(), "want=xun.user")
+}
+
+func TestWithAcceptsContentType(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	var called int
+	var middlewares int
+
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			middlewares++
+			return next(c)
+		}
+	})
+
+	app.Post("/users", func(c *Context) error {
+		called++
+		return c.View(map[string]any{"id": 1})
+	}, WithAccepts("application/json", "application/x-www-form-urlencoded"), WithContentType("application/json", "text/*"))
+
+	app.Start()
+
+	tests := []struct {
+		contentType string
+		accept      string
+		body        string
+		status      int
+	}{
+		{contentType: "application/json; charset=utf-8", accept: "application/json", body: `{}`, status: http.StatusOK},
+		{contentType: "application/x-www-form-urlencoded", accept: "text/csv, */*;q=0.8", body: `id=1`, status: http.StatusOK},
+		{accept: "", status: http.StatusOK},
+		{contentType: "text/xml", accept: "application/json", body: `<user/>`, status: http.StatusUnsupportedMediaType},
+		{contentType: "", accept: "application/json", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{contentType: "*/*", accept: "application/json", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{contentType: "application/*", accept: "application/json", body: `{}`, status: http.StatusUnsupportedMediaType},
+		{contentType: "application/json", accept: "image/png", body: `{}`, status: http.StatusNotAcceptable},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/users", strings.NewReader(test.body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", test.contentType)
+		if test.contentType == "" {
+			req.Header.Del("Content-Type")
+		}
+		req.Header.Set("Accept", test.accept)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, test.status, resp.StatusCode, test)
+	}
+
+	// middlewares run, the handler doesn't run for rejected requests
+	require.Equal(t, len(tests), middlewares)
+	require.Equal(t, 3, called)
+}
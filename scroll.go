@@ -0,0 +1,94 @@
+package xun
+
+import (
+	"html"
+	"html/template"
+	"io"
+	"net/http"
+)
+
+// ScrollFunc loads the items of an infinite scroll list after the cursor, and
+// returns the cursor of the next items, or "" if there are no more items.
+// The cursor is "" for the first items.
+type ScrollFunc func(c *Context, cursor string) (items any, next string, err error)
+
+// ScrollPage is a page of an infinite scroll list rendered by c.InfiniteScroll.
+type ScrollPage struct {
+	Items  any    `json:"items"`
+	Cursor string `json:"cursor,omitempty"`
+	Next   string `json:"next,omitempty"`
+
+	nextURL string
+}
+
+// HasNext reports whether there are more items after the page.
+func (p *ScrollPage) HasNext() bool {
+	return p.Next != ""
+}
+
+// NextURL returns the URL of the next page, or "" if it is the last page.
+func (p *ScrollPage) NextURL() string {
+	return p.nextURL
+}
+
+// Sentinel returns the element that loads the next page by htmx when it is
+// revealed, and is swapped with it, or "" if it is the last page.
+func (p *ScrollPage) Sentinel() template.HTML {
+	if !p.HasNext() {
+		return ""
+	}
+
+	return template.HTML(`<div hx-get="` + html.EscapeString(p.nextURL) + `" hx-trigger="revealed" hx-swap="outerHTML" aria-busy="true"></div>`) // nolint: gosec
+}
+
+// InfiniteScroll renders a page of an infinite scroll list with the items
+// loaded after the `cursor` query parameter. The first page is rendered by
+// the view with the ScrollPage, and it renders the items followed by the
+// sentinel of the next page. The next pages are requested by htmx when the
+// sentinel is revealed, and they are rendered by the fragment, a component or
+// a view, with the ScrollPage and followed by the updated sentinel, so they
+// replace the revealed sentinel.
+//
+//	app.Get("/posts", func(c *xun.Context) error {
+//		return c.InfiniteScroll("views/posts", "components/posts", func(c *xun.Context, cursor string) (any, string, error) {
+//			return db.Posts(c.Context(), cursor, 20)
+//		})
+//	})
+//
+//	views/posts.html:      <div id="posts">{{ component "components/posts" . }}{{ .Sentinel }}</div>
+//	components/posts.html: {{ range .Items }}<article>{{ .Title }}</article>{{ end }}
+//
+// The ScrollPage is written as JSON to clients that don't accept text/html.
+func (c *Context) InfiniteScroll(view, fragment string, load ScrollFunc) error {
+	cursor := c.req.URL.Query().Get("cursor")
+
+	items, next, err := load(c, cursor)
+	if err != nil {
+		return err
+	}
+
+	p := &ScrollPage{Items: items, Cursor: cursor, Next: next}
+	if next != "" {
+		q := c.req.URL.Query()
+		q.Set("cursor", next)
+		p.nextURL = c.req.URL.Path + "?" + q.Encode()
+	}
+
+	c.rw.Header().Add("Vary", "HX-Request")
+	if cursor == "" || !c.Htmx().Request {
+		return c.View(p, view)
+	}
+
+	buf, err := c.app.renderOOB(c, fragment, p)
+	if err != nil {
+		return err
+	}
+
+	c.rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.WriteStatus(http.StatusOK)
+	if _, err = c.rw.Write(buf); err != nil {
+		return err
+	}
+	_, err = io.WriteString(c.rw, string(p.Sentinel()))
+	return err
+}
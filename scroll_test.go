@@ -0,0 +1,77 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfiniteScroll(t *testing.T) {
+	fsys := fstest.MapFS{
+		"views/posts.html":      {Data: []byte(`<div id="posts">{{ component "components/posts" . }}{{ .Sentinel }}</div>`)},
+		"components/posts.html": {Data: []byte(`{{ range .Items }}<p>{{ . }}</p>{{ end }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Get("/posts", func(c *Context) error {
+		return c.InfiniteScroll("views/posts", "components/posts", func(c *Context, cursor string) (any, string, error) {
+			start, _ := strconv.Atoi(cursor)
+			items := []int{start + 1, start + 2}
+			if start >= 4 {
+				return items, "", nil
+			}
+			return items, strconv.Itoa(start + 2), nil
+		})
+	})
+
+	app.Start()
+
+	get := func(path string, htmx bool, accept string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		if htmx {
+			req.Header.Set("HX-Request", "true")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	t.Run("page", func(t *testing.T) {
+		status, body := get("/posts?tag=go", false, "text/html")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<div id="posts"><p>1</p><p>2</p><div hx-get="/posts?cursor=2&amp;tag=go" hx-trigger="revealed" hx-swap="outerHTML" aria-busy="true"></div></div>`, body)
+	})
+
+	t.Run("next", func(t *testing.T) {
+		status, body := get("/posts?cursor=2&tag=go", true, "text/html")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<p>3</p><p>4</p><div hx-get="/posts?cursor=4&amp;tag=go" hx-trigger="revealed" hx-swap="outerHTML" aria-busy="true"></div>`, body)
+
+		// the sentinel is omitted on the last page
+		status, body = get("/posts?cursor=4&tag=go", true, "text/html")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<p>5</p><p>6</p>`, body)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		status, body := get("/posts", false, "application/json")
+		require.Equal(t, http.StatusOK, status)
+		require.JSONEq(t, `{"items":[1,2],"next":"2"}`, body)
+	})
+}
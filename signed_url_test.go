@@ -0,0 +1,88 @@
+package xun
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignURL(t *testing.T) {
+	app := New(WithMux(http.NewServeMux()), WithSignKey([]byte("secret")))
+
+	t.Run("valid", func(t *testing.T) {
+		signed := app.SignURL("/private/invoice.pdf?id=1", time.Hour)
+		require.True(t, strings.HasPrefix(signed, "/private/invoice.pdf?"))
+
+		u, err := url.Parse(signed)
+		require.NoError(t, err)
+		require.Equal(t, "1", u.Query().Get("id"))
+		require.NoError(t, app.VerifyURL(u))
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		u, err := url.Parse(strings.Replace(app.SignURL("/private/invoice.pdf?id=1", time.Hour), "id=1", "id=2", 1))
+		require.NoError(t, err)
+		require.ErrorIs(t, app.VerifyURL(u), ErrInvalidSignature)
+
+		u, err = url.Parse("/private/invoice.pdf")
+		require.NoError(t, err)
+		require.ErrorIs(t, app.VerifyURL(u), ErrInvalidSignature)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		u, err := url.Parse(app.SignURL("/private/invoice.pdf", -time.Minute))
+		require.NoError(t, err)
+		require.ErrorIs(t, app.VerifyURL(u), ErrURLExpired)
+	})
+
+	t.Run("different_key", func(t *testing.T) {
+		other := New(WithMux(http.NewServeMux()), WithSignKey([]byte("other")))
+		u, err := url.Parse(other.SignURL("/private/invoice.pdf", time.Hour))
+		require.NoError(t, err)
+		require.ErrorIs(t, app.VerifyURL(u), ErrInvalidSignature)
+	})
+}
+
+func TestVerifySignedURL(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/private/invoice.pdf": {Data: []byte(`%PDF-1.7`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	app.UseStatic("/private/", app.VerifySignedURL)
+
+	app.Start()
+	defer app.Close()
+
+	resp, err := client.Get(srv.URL + app.SignURL("/private/invoice.pdf", time.Minute))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = client.Get(srv.URL + "/private/invoice.pdf")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusForbidden, resp.StatusCode)
+}
+
+func TestSignKeyWarning(t *testing.T) {
+	var logs bytes.Buffer
+	New(WithMux(http.NewServeMux()), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	require.Contains(t, logs.String(), "sign key is not set")
+
+	logs.Reset()
+	New(WithMux(http.NewServeMux()), WithSignKey([]byte("secret")), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	require.NotContains(t, logs.String(), "sign key")
+}
@@ -0,0 +1,79 @@
+package xun
+
+import (
+	"slices"
+	"strings"
+)
+
+// staticMiddleware holds middlewares that are applied to static files matched by pattern.
+type staticMiddleware struct {
+	pattern     string
+	middlewares []Middleware
+}
+
+// match reports whether the URL path of a static file is matched by the pattern.
+// A pattern containing any glob character is matched by matchGlob, otherwise it is used as a path prefix.
+func (sm *staticMiddleware) match(path string) bool {
+	if strings.ContainsAny(sm.pattern, "*?[") {
+		return matchGlob(strings.TrimPrefix(sm.pattern, "/"), strings.TrimPrefix(path, "/"))
+	}
+
+	return strings.HasPrefix(path, sm.pattern)
+}
+
+// UseStatic registers middlewares for static files whose URL path starts with
+// the prefix, or matches the glob pattern, e.g. "/private/" or "/reports/**/*.pdf".
+//
+// It allows protecting assets in the public directory with auth checks,
+// instead of everything in public being world-readable. App middlewares
+// registered by Use are executed before them.
+//
+//	app.UseStatic("/private/", func(next xun.HandleFunc) xun.HandleFunc {
+//		return func(c *xun.Context) error {
+//			if !isAuthenticated(c) {
+//				c.WriteStatus(http.StatusUnauthorized)
+//				return xun.ErrCancelled
+//			}
+//			return next(c)
+//		}
+//	})
+func (app *App) UseStatic(pattern string, middleware ...Middleware) {
+	app.staticMiddlewares = append(app.staticMiddlewares, staticMiddleware{
+		pattern:     pattern,
+		middlewares: middleware,
+	})
+}
+
+// staticChain is the middleware chain of a static file.
+type staticChain struct {
+	app  *App
+	path string
+}
+
+// matched returns the static middlewares matched by the file path.
+func (sc *staticChain) matched() []Middleware {
+	var middlewares []Middleware
+	for _, sm := range sc.app.staticMiddlewares {
+		if sm.match(sc.path) {
+			middlewares = append(middlewares, sm.middlewares...)
+		}
+	}
+	return middlewares
+}
+
+// Next applies the app middlewares and the static middlewares matched by the file path to the given HandleFunc.
+func (sc *staticChain) Next(hf HandleFunc) HandleFunc {
+	middlewares := sc.matched()
+
+	next := hf
+	for i := len(middlewares); i > 0; i-- {
+		next = middlewares[i-1](next)
+	}
+
+	return sc.app.Next(next)
+}
+
+func (sc *staticChain) middlewareList() []Middleware {
+	// concat into a new slice, so the app middlewares are never appended in place
+	return slices.Concat(sc.app.middlewareList(), sc.matched())
+}
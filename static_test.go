@@ -0,0 +1,101 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUseStatic(t *testing.T) {
+	fsys := fstest.MapFS{
+		"public/skin.css":             {Data: []byte(`body { color: red; }`)},
+		"public/private/report.pdf":   {Data: []byte(`%PDF-1.7`)},
+		"public/reports/2025/q1.xlsx": {Data: []byte(`xlsx`)},
+		"public/reports/readme.txt":   {Data: []byte(`readme`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+
+	auth := func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			if c.Request().Header.Get("X-User") == "" {
+				c.WriteStatus(http.StatusUnauthorized)
+				return ErrCancelled
+			}
+			return next(c)
+		}
+	}
+
+	var logged []string
+	app.Use(func(next HandleFunc) HandleFunc {
+		return func(c *Context) error {
+			logged = append(logged, c.Request().URL.Path)
+			return next(c)
+		}
+	})
+
+	app.UseStatic("/private/", auth)
+	app.UseStatic("/reports/**/*.xlsx", auth)
+
+	app.Start()
+	defer app.Close()
+
+	tests := []struct {
+		path   string
+		user   string
+		status int
+	}{
+		{path: "/skin.css", status: http.StatusOK},
+		{path: "/private/report.pdf", status: http.StatusUnauthorized},
+		{path: "/private/report.pdf", user: "xun", status: http.StatusOK},
+		{path: "/reports/2025/q1.xlsx", status: http.StatusUnauthorized},
+		{path: "/reports/2025/q1.xlsx", user: "xun", status: http.StatusOK},
+		{path: "/reports/readme.txt", status: http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.path+"_"+test.user, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+test.path, nil)
+			require.NoError(t, err)
+			if test.user != "" {
+				req.Header.Set("X-User", test.user)
+			}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, test.status, resp.StatusCode)
+		})
+	}
+
+	// app middlewares are still applied to static files
+	require.Len(t, logged, len(tests))
+}
+
+func TestStaticMiddlewareList(t *testing.T) {
+	app := New(WithFsys(fstest.MapFS{}))
+	defer app.Close()
+
+	// spare capacity would be shared by the lists of all static files
+	app.middlewares = make([]Middleware, 0, 4)
+	app.Use(func(next HandleFunc) HandleFunc { return next })
+
+	app.UseStatic("/private/", func(next HandleFunc) HandleFunc { return next })
+	app.UseStatic("/reports/", func(next HandleFunc) HandleFunc { return next }, func(next HandleFunc) HandleFunc { return next })
+
+	private := (&staticChain{app: app, path: "/private/report.pdf"}).middlewareList()
+	reports := (&staticChain{app: app, path: "/reports/q1.xlsx"}).middlewareList()
+
+	require.Len(t, app.middlewares, 1)
+	require.Len(t, private, 2)
+	require.Len(t, reports, 3)
+	require.NotSame(t, &private[1], &reports[1])
+}
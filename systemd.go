@@ -0,0 +1,78 @@
+package xun
+
+import (
+	"net"
+	"os"
+	"strconv"
+)
+
+const (
+	// SdNotifyReady tells systemd that the service is ready. It is sent by
+	// app.Start once all templates are loaded.
+	SdNotifyReady = "READY=1"
+	// SdNotifyStopping tells systemd that the service is stopping. It is sent by app.Shutdown.
+	SdNotifyStopping = "STOPPING=1"
+)
+
+// listenFdsStart is the first file descriptor passed by systemd socket activation.
+var listenFdsStart = 3
+
+// SystemdListeners returns the listeners passed by systemd socket activation
+// in the LISTEN_FDS and LISTEN_PID environment variables, or nil if the
+// process is not socket activated. Listen returns the first of them.
+func SystemdListeners() ([]net.Listener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 1 {
+		return nil, nil
+	}
+
+	// the sockets must not be inherited by child processes
+	os.Unsetenv("LISTEN_PID")     // nolint: errcheck
+	os.Unsetenv("LISTEN_FDS")     // nolint: errcheck
+	os.Unsetenv("LISTEN_FDNAMES") // nolint: errcheck
+
+	listeners := make([]net.Listener, 0, n)
+	for fd := listenFdsStart; fd < listenFdsStart+n; fd++ {
+		f := os.NewFile(uintptr(fd), "LISTEN_FD_"+strconv.Itoa(fd))
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, it := range listeners {
+				it.Close()
+			}
+			return nil, err
+		}
+		listeners = append(listeners, ln)
+	}
+
+	return listeners, nil
+}
+
+// SdNotify sends the state, e.g. SdNotifyReady, to the systemd notify socket
+// in the NOTIFY_SOCKET environment variable. It is a no-op if the variable is
+// not set, e.g. the service is not started by systemd with Type=notify.
+func SdNotify(state string) error {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return nil
+	}
+
+	// abstract socket
+	if name[0] == '@' {
+		name = "\x00" + name[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
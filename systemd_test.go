@@ -0,0 +1,82 @@
+//go:build linux || darwin || dragonfly || freebsd || netbsd || openbsd
+
+package xun
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"syscall"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemdListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+	f.Close()
+
+	start := listenFdsStart
+	listenFdsStart = fd
+	defer func() {
+		listenFdsStart = start
+	}()
+
+	t.Setenv("LISTEN_PID", "1")
+	t.Setenv("LISTEN_FDS", "1")
+
+	listeners, err := SystemdListeners()
+	require.NoError(t, err)
+	require.Nil(t, listeners)
+
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+
+	activated, err := Listen(":0")
+	require.NoError(t, err)
+	defer activated.Close()
+
+	require.Equal(t, ln.Addr().String(), activated.Addr().String())
+	require.Empty(t, os.Getenv("LISTEN_FDS"))
+}
+
+func TestSdNotify(t *testing.T) {
+	require.NoError(t, SdNotify(SdNotifyReady))
+
+	name := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: name, Net: "unixgram"})
+	require.NoError(t, err)
+	defer conn.Close()
+
+	t.Setenv("NOTIFY_SOCKET", name)
+
+	read := func() string {
+		buf := make([]byte, 64)
+		conn.SetReadDeadline(time.Now().Add(time.Second)) // nolint: errcheck
+		n, err := conn.Read(buf)
+		require.NoError(t, err)
+		return string(buf[:n])
+	}
+
+	app := New(WithMux(http.NewServeMux()), WithFsys(fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<p>home</p>`)},
+	}))
+	defer app.Close()
+
+	require.NoError(t, app.Start())
+	require.Equal(t, SdNotifyReady, read())
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.Equal(t, SdNotifyStopping, read())
+}
@@ -0,0 +1,132 @@
+package xun
+
+import (
+	"reflect"
+)
+
+// table is the tabular form of the data rendered by export viewers, e.g. CsvViewer.
+//
+// The data can be:
+//   - [][]string: each item is a row, without header.
+//   - a slice of structs or pointers to structs: each item is a row, and the
+//     exported fields are the columns. The header is the `export` tag of the
+//     field or its name, and `export:"-"` skips it.
+//   - a struct, e.g. the view model of a table page: its first exported slice
+//     field is used as the rows.
+type table struct {
+	header []string
+	fields [][]int
+	rows   reflect.Value
+	raw    [][]string
+}
+
+// newTable returns the table of data, or ErrUnsupportedTable.
+func newTable(data any) (*table, error) {
+	if raw, ok := data.([][]string); ok {
+		return &table{raw: raw}, nil
+	}
+
+	v := reflect.ValueOf(data)
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, ErrUnsupportedTable
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.Struct {
+		rows, ok := firstSlice(v)
+		if !ok {
+			return nil, ErrUnsupportedTable
+		}
+		return newTable(rows.Interface())
+	}
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, ErrUnsupportedTable
+	}
+
+	et := v.Type().Elem()
+	if et.Kind() == reflect.Pointer {
+		et = et.Elem()
+	}
+
+	if et.Kind() != reflect.Struct {
+		return nil, ErrUnsupportedTable
+	}
+
+	t := &table{rows: v}
+	for _, f := range reflect.VisibleFields(et) {
+		if !f.IsExported() || (f.Anonymous && f.Type.Kind() == reflect.Struct) {
+			continue
+		}
+
+		name := f.Tag.Get("export")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		t.header = append(t.header, name)
+		t.fields = append(t.fields, f.Index)
+	}
+
+	return t, nil
+}
+
+// firstSlice returns the first exported slice field of the struct v.
+func firstSlice(v reflect.Value) (reflect.Value, bool) {
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); f.IsExported() && f.Type.Kind() == reflect.Slice {
+			return v.Field(i), true
+		}
+	}
+
+	return reflect.Value{}, false
+}
+
+// Each calls fn with the values of each row in order. A value is nil if the
+// row or its embedded struct is a nil pointer.
+func (t *table) Each(fn func(row []any) error) error {
+	if t.raw != nil {
+		row := make([]any, 0)
+		for _, r := range t.raw {
+			row = row[:0]
+			for _, s := range r {
+				row = append(row, s)
+			}
+			if err := fn(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	row := make([]any, len(t.fields))
+	for i := 0; i < t.rows.Len(); i++ {
+		it := t.rows.Index(i)
+		if it.Kind() == reflect.Pointer {
+			it = it.Elem()
+		}
+
+		for j, index := range t.fields {
+			row[j] = nil
+			if !it.IsValid() {
+				continue
+			}
+
+			f, err := it.FieldByIndexErr(index)
+			if err == nil {
+				row[j] = f.Interface()
+			}
+		}
+
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
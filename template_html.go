@@ -4,7 +4,9 @@ import (
 	"html/template"
 	"io"
 	"io/fs"
+	"net/http"
 	"strings"
+	"sync"
 
 	"errors"
 )
@@ -19,6 +21,11 @@ type HtmlTemplate struct {
 	name   string
 	path   string
 	layout string
+	meta   map[string]any
+	funcs  template.FuncMap
+
+	requestFuncs requestFuncs
+	clones       *sync.Pool
 
 	dependencies map[string]struct{}
 	dependents   map[string]*HtmlTemplate
@@ -37,19 +44,28 @@ func NewHtmlTemplate(name, path string) *HtmlTemplate {
 // Load loads the template from the given file system.
 //
 // It parses the file, and determines the dependencies of the template.
-// The dependencies are stored in the `dependencies` field.
+// The dependencies are stored in the `dependencies` field. The YAML front
+// matter at the start of the file is stored in the `meta` field, and its
+// `layout` takes precedence over the `<!--layout:name-->` comment.
 func (t *HtmlTemplate) Load(fsys fs.FS, templates map[string]*HtmlTemplate) error { // skipcq: GO-R1005
 	buf, err := fs.ReadFile(fsys, t.path)
 	if err != nil {
 		return err
 	}
 
-	nt := template.New(t.name).Funcs(FuncMap)
+	meta, buf, err := splitFrontMatter(buf)
+	if err != nil {
+		return err
+	}
+
+	nt := template.New(t.name).Funcs(t.funcs).Funcs(t.requestFuncs.placeholders()).Funcs(FuncMap)
 	dependencies := make(map[string]struct{})
 
 	defer func() {
 		t.template = nt
 		t.dependencies = dependencies
+		t.meta = meta
+		t.clones = &sync.Pool{}
 	}()
 
 	if len(buf) == 0 {
@@ -72,9 +88,9 @@ func (t *HtmlTemplate) Load(fsys fs.FS, templates map[string]*HtmlTemplate) erro
 		dependencies[tn] = struct{}{}
 	}
 
-	layoutName := ""
+	layoutName, _ := meta["layout"].(string)
 	// <!--layout:home-->   xxxxx  \n
-	if len(buf) > 11 && string(buf[0:11]) == "<!--layout:" {
+	if layoutName == "" && len(buf) > 11 && string(buf[0:11]) == "<!--layout:" {
 		n := len(buf) - 2
 		for i := 11; i < n; i++ {
 			if buf[i] == '-' && buf[i+1] == '-' && buf[i+2] == '>' {
@@ -86,28 +102,28 @@ func (t *HtmlTemplate) Load(fsys fs.FS, templates map[string]*HtmlTemplate) erro
 				break
 			}
 		}
+	}
 
-		if layoutName != "" {
-			layoutName = "layouts/" + layoutName
+	if layoutName != "" {
+		layoutName = "layouts/" + layoutName
 
-			layout, ok := templates[layoutName]
-			if ok {
-				_, err = nt.AddParseTree(layoutName, layout.template.Tree)
-				if err != nil {
-					return err
-				}
+		layout, ok := templates[layoutName]
+		if ok {
+			_, err = nt.AddParseTree(layoutName, layout.template.Tree)
+			if err != nil {
+				return err
+			}
 
-				layout.dependents[t.name] = t
+			layout.dependents[t.name] = t
 
-				for tn := range layout.dependencies {
-					dependencies[tn] = struct{}{}
-				}
+			for tn := range layout.dependencies {
+				dependencies[tn] = struct{}{}
 			}
-
-			t.layout = layoutName
-		} else {
-			t.layout = ""
 		}
+
+		t.layout = layoutName
+	} else {
+		t.layout = ""
 	}
 
 	for tn := range dependencies {
@@ -153,8 +169,106 @@ func (t *HtmlTemplate) Reload(fsys fs.FS, templates map[string]*HtmlTemplate) er
 // If the template has a layout, it uses the layout to render the data.
 // Otherwise, it renders the data using the template itself.
 func (t *HtmlTemplate) Execute(wr io.Writer, data any) error {
+	return t.execute(wr, nil, data)
+}
+
+// executeTemplate renders the associated template name, e.g. a "subject" block of an email.
+// It reports false if the template doesn't define it.
+func (t *HtmlTemplate) executeTemplate(wr io.Writer, name string, data any) (bool, error) {
+	if t.template.Lookup(name) == nil {
+		return false, nil
+	}
+
+	pool := t.clones
+
+	nt, ok := pool.Get().(*template.Template)
+	if !ok {
+		var err error
+		nt, err = t.template.Clone()
+		if err != nil {
+			return true, err
+		}
+	}
+	defer pool.Put(nt)
+
+	if len(t.requestFuncs) > 0 {
+		nt.Funcs(t.requestFuncs.bind(nil))
+	}
+
+	return true, nt.ExecuteTemplate(wr, name, data)
+}
+
+// executeLayout renders the template with the layout instead of its own one,
+// e.g. "layouts/print" for print-optimized HTML. Blocks defined by the template
+// take precedence over the defaults of the layout.
+func (t *HtmlTemplate) executeLayout(wr io.Writer, r *http.Request, layout *HtmlTemplate, data any) error {
+	nt, err := t.template.Clone()
+	if err != nil {
+		return err
+	}
+
+	return t.executeWith(nt, wr, r, layout, data)
+}
+
+// executeFragment renders the template, e.g. an htmx fragment without a layout,
+// as the "content" block of the layout.
+func (t *HtmlTemplate) executeFragment(wr io.Writer, r *http.Request, layout *HtmlTemplate, data any) error {
+	nt, err := t.template.Clone()
+	if err != nil {
+		return err
+	}
+
+	if _, err := nt.AddParseTree("content", nt.Tree); err != nil {
+		return err
+	}
+
+	return t.executeWith(nt, wr, r, layout, data)
+}
+
+// executeWith executes nt, a clone of the template, with the layout.
+func (t *HtmlTemplate) executeWith(nt *template.Template, wr io.Writer, r *http.Request, layout *HtmlTemplate, data any) error {
+	for _, it := range layout.template.Templates() {
+		if it.Tree == nil || (it.Name() != layout.name && nt.Lookup(it.Name()) != nil) {
+			continue
+		}
+
+		// copy the tree, because it is escaped in place on execution
+		if _, err := nt.AddParseTree(it.Name(), it.Tree.Copy()); err != nil {
+			return err
+		}
+	}
+
+	if len(t.requestFuncs) > 0 {
+		nt.Funcs(t.requestFuncs.bind(r))
+	}
+
+	return nt.ExecuteTemplate(wr, layout.name, data)
+}
+
+// execute renders the template with the functions bound to the request r.
+//
+// The loaded template is never executed, because it can't be cloned after
+// execution and its parse trees are shared with its dependents. A clone
+// from the pool is executed instead.
+func (t *HtmlTemplate) execute(wr io.Writer, r *http.Request, data any) error {
+	pool := t.clones
+
+	nt, ok := pool.Get().(*template.Template)
+	if !ok {
+		var err error
+		nt, err = t.template.Clone()
+		if err != nil {
+			return err
+		}
+	}
+	defer pool.Put(nt)
+
+	if len(t.requestFuncs) > 0 {
+		nt.Funcs(t.requestFuncs.bind(r))
+	}
+
 	if t.layout != "" {
-		return t.template.ExecuteTemplate(wr, t.layout, data)
+		return nt.ExecuteTemplate(wr, t.layout, data)
 	}
-	return t.template.Execute(wr, data)
+	return nt.Execute(wr, data)
 }
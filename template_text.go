@@ -3,6 +3,8 @@ package xun
 import (
 	"io"
 	"io/fs"
+	"net/http"
+	"sync"
 	"text/template"
 )
 
@@ -13,6 +15,10 @@ type TextTemplate struct {
 	name    string
 	mime    MimeType
 	charset string
+	funcs   template.FuncMap
+
+	requestFuncs requestFuncs
+	clones       *sync.Pool
 }
 
 // Load loads the template from the given file system.
@@ -22,7 +28,8 @@ func (t *TextTemplate) Load(fsys fs.FS) error {
 		return err
 	}
 
-	nt := template.New(t.name).Funcs(FuncMap)
+	nt := template.New(t.name).Funcs(t.funcs).Funcs(t.requestFuncs.placeholders()).Funcs(FuncMap)
+	t.clones = &sync.Pool{}
 
 	if len(buf) == 0 {
 		nt, _ = nt.Parse("")
@@ -50,5 +57,26 @@ func (t *TextTemplate) Reload(fsys fs.FS) error {
 
 // Execute executes the template with the given data and writes the result to the given writer.
 func (t *TextTemplate) Execute(wr io.Writer, data any) error {
-	return t.template.Execute(wr, data)
+	return t.execute(wr, nil, data)
+}
+
+// execute executes the template with the functions bound to the request r.
+func (t *TextTemplate) execute(wr io.Writer, r *http.Request, data any) error {
+	if len(t.requestFuncs) == 0 {
+		return t.template.Execute(wr, data)
+	}
+
+	pool := t.clones
+
+	nt, ok := pool.Get().(*template.Template)
+	if !ok {
+		var err error
+		nt, err = t.template.Clone()
+		if err != nil {
+			return err
+		}
+	}
+	defer pool.Put(nt)
+
+	return nt.Funcs(t.requestFuncs.bind(r)).Execute(wr, data)
 }
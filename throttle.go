@@ -0,0 +1,65 @@
+package xun
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ThrottlerKey is the key of the Throttler in the Context values. Throttling
+// middlewares, e.g. the throttle extension, should set it with
+// c.Set(xun.ThrottlerKey, t), so it can be used by c.ThrottleKey.
+const ThrottlerKey = "throttler"
+
+const throttleKeysKey = "throttle_keys"
+
+// Throttler limits the attempts of identifiers by a brute-force protection,
+// e.g. the logins of an email.
+type Throttler interface {
+	// Attempt returns how long the key is locked out, or reserves an attempt
+	// of the key and returns 0. The reserved attempt counts as a failure until
+	// it succeeds, so parallel attempts can't bypass the lockout.
+	Attempt(ctx context.Context, key string) (time.Duration, error)
+}
+
+// ThrottleKey declares the identifiers of the current attempt, e.g. the email
+// and IP of a login, after they are bound and before the credentials are
+// verified. An attempt is reserved for each of them before the credentials are
+// verified. If any of them is locked out, 429 Too Many Requests is written with
+// a Retry-After header, and ErrCancelled is returned.
+//
+// The reserved attempts are reset by the throttling middleware if the attempt
+// succeeds. If no Throttler is set, it does nothing.
+func (c *Context) ThrottleKey(keys ...string) error {
+	t, ok := c.Get(ThrottlerKey).(Throttler)
+	if !ok {
+		return nil
+	}
+
+	var wait time.Duration
+	for _, key := range keys {
+		d, err := t.Attempt(c.req.Context(), key)
+		if err != nil {
+			return err
+		}
+		wait = max(wait, d)
+	}
+
+	if wait > 0 {
+		c.WriteHeader("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		c.WriteStatus(http.StatusTooManyRequests)
+		return ErrCancelled
+	}
+
+	ks, _ := c.Get(throttleKeysKey).([]string)
+	c.Set(throttleKeysKey, append(ks, keys...))
+	return nil
+}
+
+// ThrottleKeys returns the keys declared by c.ThrottleKey.
+func (c *Context) ThrottleKeys() []string {
+	ks, _ := c.Get(throttleKeysKey).([]string)
+	return ks
+}
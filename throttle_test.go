@@ -0,0 +1,36 @@
+package xun
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type lockedThrottler map[string]time.Duration
+
+func (t lockedThrottler) Attempt(_ context.Context, key string) (time.Duration, error) {
+	return t[key], nil
+}
+
+func TestThrottleKey(t *testing.T) {
+	rw := httptest.NewRecorder()
+	c := &Context{req: httptest.NewRequest(http.MethodPost, "/login", nil), rw: &stdResponseWriter{ResponseWriter: rw}}
+
+	// no throttler
+	require.NoError(t, c.ThrottleKey("email:a"))
+	require.Empty(t, c.ThrottleKeys())
+
+	c.Set(ThrottlerKey, lockedThrottler{"email:b": 1500 * time.Millisecond})
+
+	require.NoError(t, c.ThrottleKey("email:a", "ip:1"))
+	require.Equal(t, []string{"email:a", "ip:1"}, c.ThrottleKeys())
+
+	require.ErrorIs(t, c.ThrottleKey("email:b"), ErrCancelled)
+	require.Equal(t, []string{"email:a", "ip:1"}, c.ThrottleKeys())
+	require.Equal(t, http.StatusTooManyRequests, rw.Code)
+	require.Equal(t, "2", rw.Header().Get("Retry-After"))
+}
@@ -0,0 +1,141 @@
+package xun
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+
+	jsoniter "github.com/json-iterator/go"
+)
+
+// triggerEvent is a client-side event of a HX-Trigger header. detail is the
+// JSON encoded detail, or nil if it has no detail.
+type triggerEvent struct {
+	name   string
+	detail []byte
+}
+
+// TriggerEvent triggers the client-side event name with the detail once the
+// response is received by htmx, by the HX-Trigger header. detail may be nil.
+//
+// Events are accumulated in the header, so it can be called many times, e.g.
+// by middlewares and handlers. An event that is triggered again replaces its
+// detail.
+//
+//	c.TriggerEvent("showMessage", map[string]string{"level": "info", "message": "Saved"})
+func (c *Context) TriggerEvent(name string, detail any) {
+	c.trigger("HX-Trigger", name, detail)
+}
+
+// TriggerAfterSwap triggers the client-side event name with the detail after
+// the swap step, by the HX-Trigger-After-Swap header. See TriggerEvent.
+func (c *Context) TriggerAfterSwap(name string, detail any) {
+	c.trigger("HX-Trigger-After-Swap", name, detail)
+}
+
+// TriggerAfterSettle triggers the client-side event name with the detail after
+// the settle step, by the HX-Trigger-After-Settle header. See TriggerEvent.
+func (c *Context) TriggerAfterSettle(name string, detail any) {
+	c.trigger("HX-Trigger-After-Settle", name, detail)
+}
+
+// trigger merges the event into the header key. The header is written as a
+// list of event names if no event has detail, or as a JSON object otherwise.
+func (c *Context) trigger(key, name string, detail any) {
+	var buf []byte
+	if detail != nil {
+		var err error
+		buf, err = json.Marshal(detail)
+		if err != nil {
+			c.app.logger.Warn("xun: trigger event", slog.String("name", name), slog.Any("err", err))
+			return
+		}
+	}
+
+	h := c.rw.Header()
+	events := parseTriggers(h.Get(key))
+
+	found := false
+	for i, it := range events {
+		if it.name == name {
+			events[i].detail = buf
+			found = true
+		}
+	}
+	if !found {
+		events = append(events, triggerEvent{name: name, detail: buf})
+	}
+
+	h.Set(key, formatTriggers(events))
+}
+
+// parseTriggers parses the events of a HX-Trigger header in order, e.g.
+// `event1, event2` or `{"event1":"A message","event2":{"level":"info"}}`.
+func parseTriggers(v string) []triggerEvent {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return nil
+	}
+
+	var events []triggerEvent
+	if v[0] != '{' {
+		for _, it := range strings.Split(v, ",") {
+			if it = strings.TrimSpace(it); it != "" {
+				events = append(events, triggerEvent{name: it})
+			}
+		}
+		return events
+	}
+
+	iter := json.BorrowIterator([]byte(v))
+	defer json.ReturnIterator(iter)
+
+	iter.ReadMapCB(func(it *jsoniter.Iterator, name string) bool {
+		detail := it.SkipAndReturnBytes()
+		if bytes.Equal(detail, []byte("null")) {
+			detail = nil
+		}
+		events = append(events, triggerEvent{name: name, detail: detail})
+		return true
+	})
+
+	return events
+}
+
+// formatTriggers formats the events as the value of a HX-Trigger header.
+func formatTriggers(events []triggerEvent) string {
+	plain := true
+	for _, it := range events {
+		if it.detail != nil || strings.ContainsAny(it.name, ", ") {
+			plain = false
+			break
+		}
+	}
+
+	if plain {
+		names := make([]string, len(events))
+		for i, it := range events {
+			names[i] = it.name
+		}
+		return strings.Join(names, ", ")
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('{')
+	for i, it := range events {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		name, _ := json.Marshal(it.name)
+		sb.Write(name)
+		sb.WriteByte(':')
+		if it.detail == nil {
+			sb.WriteString("null")
+		} else {
+			sb.Write(it.detail)
+		}
+	}
+	sb.WriteByte('}')
+
+	return sb.String()
+}
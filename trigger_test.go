@@ -0,0 +1,62 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTriggerEvent(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	app.Get("/plain", func(c *Context) error {
+		c.TriggerEvent("closeModal", nil)
+		c.TriggerEvent("refresh", nil)
+		c.TriggerEvent("closeModal", nil)
+		return nil
+	})
+
+	app.Get("/detail", func(c *Context) error {
+		c.TriggerEvent("closeModal", nil)
+		c.TriggerEvent("showMessage", map[string]string{"level": "info"})
+		c.TriggerEvent("showMessage", "Saved")
+		c.TriggerAfterSwap("swapped", 1)
+		return nil
+	})
+
+	app.Get("/merge", func(c *Context) error {
+		c.WriteHeader("HX-Trigger", "event1, event2")
+		c.WriteHeader("HX-Trigger-After-Settle", `{"xun:focus":{"target":"#email"},"event2":null}`)
+		c.TriggerEvent("event3", nil)
+		c.TriggerAfterSettle("event3", []int{1, 2})
+		c.TriggerAfterSettle("invalid", make(chan int))
+		return nil
+	})
+
+	app.Start()
+
+	get := func(path string) http.Header {
+		resp, err := client.Get(srv.URL + path)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.Header
+	}
+
+	h := get("/plain")
+	require.Equal(t, "closeModal, refresh", h.Get("HX-Trigger"))
+
+	h = get("/detail")
+	require.Equal(t, `{"closeModal":null,"showMessage":"Saved"}`, h.Get("HX-Trigger"))
+	require.Equal(t, `{"swapped":1}`, h.Get("HX-Trigger-After-Swap"))
+
+	h = get("/merge")
+	require.Equal(t, "event1, event2, event3", h.Get("HX-Trigger"))
+	require.Equal(t, `{"xun:focus":{"target":"#email"},"event2":null,"event3":[1,2]}`, h.Get("HX-Trigger-After-Settle"))
+}
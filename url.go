@@ -0,0 +1,235 @@
+package xun
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+)
+
+// isTrustedProxy reports whether the request is sent by a proxy in WithTrustedProxies.
+func (app *App) isTrustedProxy(r *http.Request) bool {
+	if len(app.trustedProxies) == 0 {
+		return false
+	}
+
+	addr, err := netip.ParseAddr(remoteHost(r))
+	if err != nil {
+		return false
+	}
+
+	return app.isTrustedAddr(addr)
+}
+
+// isTrustedAddr reports whether the address is in WithTrustedProxies.
+func (app *App) isTrustedAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	for _, p := range app.trustedProxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// remoteHost returns the host of the remote address of the request.
+func remoteHost(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// clientIP returns the IP of the client of the request r. See Context.ClientIP.
+func (app *App) clientIP(r *http.Request) string {
+	host := remoteHost(r)
+	if !app.isTrustedProxy(r) {
+		return host
+	}
+
+	// the proxies append the address they receive from, so the rightmost
+	// untrusted one is the client, and the ones before it can be forged.
+	ips := strings.Split(r.Header.Get("X-Forwarded-For"), ",")
+	for i := len(ips) - 1; i >= 0; i-- {
+		addr, err := netip.ParseAddr(strings.TrimSpace(ips[i]))
+		if err != nil {
+			break
+		}
+		host = addr.Unmap().String()
+		if !app.isTrustedAddr(addr) {
+			break
+		}
+	}
+
+	return host
+}
+
+// ClientIP returns the IP of the client, e.g. for rate limits and audit logs.
+// It is the remote address of the request, or the X-Forwarded-For address
+// appended by the closest untrusted hop if the request is sent by a proxy in
+// WithTrustedProxies.
+func (c *Context) ClientIP() string {
+	return c.app.clientIP(c.req)
+}
+
+// absoluteURL returns the absolute URL of path for the request r.
+func (app *App) absoluteURL(r *http.Request, path string) string {
+	if strings.Contains(path, "://") || r == nil {
+		return path
+	}
+
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	host := r.Host
+
+	if app.isTrustedProxy(r) {
+		if v := forwarded(r, "X-Forwarded-Proto"); v == "http" || v == "https" {
+			scheme = v
+		}
+		if v := forwarded(r, "X-Forwarded-Host"); v != "" {
+			host = v
+		}
+	}
+
+	return scheme + "://" + host + "/" + strings.TrimPrefix(path, "/")
+}
+
+// forwarded returns the value of the X-Forwarded-* header of the client-facing proxy.
+func forwarded(r *http.Request, key string) string {
+	v, _, _ := strings.Cut(r.Header.Get(key), ",")
+	return strings.TrimSpace(v)
+}
+
+// AbsoluteURL returns the absolute URL of path for the current request, e.g.
+// "https://example.com/users/1", for emails, redirects and canonical tags.
+//
+// The scheme and host come from the request, so the current tenant host of
+// multiple virtual hosts is kept. X-Forwarded-Proto and X-Forwarded-Host are
+// used only if the request is sent by a proxy in WithTrustedProxies.
+// It is also available as the `urlAbs` template function.
+func (c *Context) AbsoluteURL(path string) string {
+	return c.app.absoluteURL(c.req, path)
+}
+
+// URL returns the path of the route registered with WithName, e.g.
+// "/users/42" for `GET /users/{id}` of app.URL("user.show", "id", 42). The
+// values are name/value pairs, and the pairs that aren't wildcards are added
+// to the query string. See URLArgs to fill the wildcards in order.
+//
+// It is also available as the `url` template function, e.g.
+// `{{ url "user.show" "id" .ID }}`. It returns ErrRouteNotFound if the name
+// isn't registered, and an error if a wildcard is missing.
+func (app *App) URL(name string, pairs ...any) (string, error) {
+	path, _, err := app.routePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("xun: odd values of route %q", name)
+	}
+
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		params[fmt.Sprint(pairs[i])] = fmt.Sprint(pairs[i+1])
+	}
+
+	return buildURL(name, path, params)
+}
+
+// URLArgs returns the path of the route registered with WithName like URL,
+// but the args fill the wildcards of the pattern in order, e.g.
+// app.URLArgs("user.show", 42). It returns an error if the number of args
+// doesn't match the wildcards.
+//
+// It is also available as the `urlArgs` template function, e.g.
+// `{{ urlArgs "user.show" .ID }}`.
+func (app *App) URLArgs(name string, args ...any) (string, error) {
+	path, wildcards, err := app.routePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	if len(args) != len(wildcards) {
+		return "", fmt.Errorf("xun: route %q has %d wildcards, but %d args", name, len(wildcards), len(args))
+	}
+
+	params := make(map[string]string, len(args))
+	for i, it := range wildcards {
+		params[it] = fmt.Sprint(args[i])
+	}
+
+	return buildURL(name, path, params)
+}
+
+// routePath returns the path of the route name, and the names of its wildcards in order.
+func (app *App) routePath(name string) (string, []string, error) {
+	app.mu.RLock()
+	pattern, ok := app.names[name]
+	app.mu.RUnlock()
+
+	if !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrRouteNotFound, name)
+	}
+
+	_, _, path := splitPattern(pattern)
+	path = "/" + path
+
+	var wildcards []string
+	for _, m := range rePathValue.FindAllStringSubmatch(path, -1) {
+		if m[1] != "$" {
+			wildcards = append(wildcards, m[1])
+		}
+	}
+
+	return path, wildcards, nil
+}
+
+// buildURL fills the wildcards of the path of the route name by the params,
+// and adds the others to the query string.
+func buildURL(name, path string, params map[string]string) (string, error) {
+	var err error
+	path = rePathValue.ReplaceAllStringFunc(path, func(s string) string {
+		m := rePathValue.FindStringSubmatch(s)
+		if m[1] == "$" {
+			return ""
+		}
+
+		v, ok := params[m[1]]
+		if !ok {
+			err = fmt.Errorf("xun: missing %q of route %q", m[1], name)
+			return s
+		}
+		delete(params, m[1])
+
+		if m[2] == "" {
+			return url.PathEscape(v)
+		}
+
+		// {path...} matches the remaining segments
+		segments := strings.Split(v, "/")
+		for i, it := range segments {
+			segments[i] = url.PathEscape(it)
+		}
+		return strings.Join(segments, "/")
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(params) > 0 {
+		query := make(url.Values, len(params))
+		for k, v := range params {
+			query.Set(k, v)
+		}
+		path += "?" + query.Encode()
+	}
+
+	return path, nil
+}
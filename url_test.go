@@ -0,0 +1,226 @@
+package xun
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAbsoluteURL(t *testing.T) {
+	app := New(WithMux(http.NewServeMux()), WithTrustedProxies("10.0.0.0/8", "192.0.2.1", "invalid"))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		host       string
+		tls        bool
+		headers    map[string]string
+		path       string
+		expected   string
+	}{
+		{name: "http", remoteAddr: "203.0.113.1:1234", host: "example.com", path: "/users/1", expected: "http://example.com/users/1"},
+		{name: "https", remoteAddr: "203.0.113.1:1234", host: "example.com", tls: true, path: "users/1", expected: "https://example.com/users/1"},
+		{name: "tenant_host", remoteAddr: "203.0.113.1:1234", host: "acme.example.com", path: "/", expected: "http://acme.example.com/"},
+		{name: "absolute", remoteAddr: "203.0.113.1:1234", host: "example.com", path: "https://cdn.example.com/app.js", expected: "https://cdn.example.com/app.js"},
+		{
+			name: "untrusted_proxy", remoteAddr: "203.0.113.1:1234", host: "example.com", path: "/",
+			headers:  map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "evil.com"},
+			expected: "http://example.com/",
+		},
+		{
+			name: "trusted_cidr", remoteAddr: "10.1.2.3:1234", host: "app:8080", path: "/",
+			headers:  map[string]string{"X-Forwarded-Proto": "https", "X-Forwarded-Host": "example.com, app:8080"},
+			expected: "https://example.com/",
+		},
+		{
+			name: "trusted_ip", remoteAddr: "192.0.2.1:1234", host: "app:8080", path: "/",
+			headers:  map[string]string{"X-Forwarded-Proto": "javascript"},
+			expected: "http://app:8080/",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			req.Host = test.host
+			if test.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			for k, v := range test.headers {
+				req.Header.Set(k, v)
+			}
+
+			c := &Context{app: app, req: req}
+			require.Equal(t, test.expected, c.AbsoluteURL(test.path))
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	app := New(WithMux(http.NewServeMux()), WithTrustedProxies("10.0.0.0/8"))
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		expected   string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.1:1234", expected: "203.0.113.1"},
+		{name: "untrusted_proxy", remoteAddr: "203.0.113.1:1234", forwarded: "198.51.100.1", expected: "203.0.113.1"},
+		{name: "trusted_proxy", remoteAddr: "10.0.0.1:1234", forwarded: "198.51.100.1", expected: "198.51.100.1"},
+		{name: "forged", remoteAddr: "10.0.0.1:1234", forwarded: "1.2.3.4, 198.51.100.1", expected: "198.51.100.1"},
+		{name: "proxies", remoteAddr: "10.0.0.1:1234", forwarded: "198.51.100.1, 10.0.0.2", expected: "198.51.100.1"},
+		{name: "invalid", remoteAddr: "10.0.0.1:1234", forwarded: "unknown", expected: "10.0.0.1"},
+		{name: "missing", remoteAddr: "10.0.0.1:1234", expected: "10.0.0.1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = test.remoteAddr
+			if test.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", test.forwarded)
+			}
+
+			c := &Context{app: app, req: req}
+			require.Equal(t, test.expected, c.ClientIP())
+		})
+	}
+}
+
+func TestUrlAbsFunc(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html": {Data: []byte(`<link rel="canonical" href="{{ urlAbs "/about" }}">{{ block "content" . }}{{ end }}`)},
+		"pages/about.html":  {Data: []byte(`<!--layout:main-->{{ define "content" }}{{ urlAbs "/" }}{{ end }}`)},
+		"text/email.txt":    {Data: []byte(`{{ urlAbs .Path }}`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithTrustedProxies("127.0.0.1"))
+
+	app.Get("/email", func(c *Context) error {
+		return c.View(map[string]string{"Path": "/verify?token=1"}, "text/email.txt")
+	})
+
+	app.Start()
+	defer app.Close()
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/about", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("X-Forwarded-Proto", "https")
+	req.Header.Set("X-Forwarded-Host", "example.com")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `<link rel="canonical" href="https://example.com/about">https://example.com/`, string(buf))
+
+	req.Host = "acme.example.com"
+	req.Header.Del("X-Forwarded-Host")
+	req.URL.Path = "/email"
+	req.Header.Set("Accept", "text/plain")
+
+	resp, err = client.Do(req)
+	require.NoError(t, err)
+	buf, err = io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `https://acme.example.com/verify?token=1`, string(buf))
+}
+
+func TestURL(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html": {Data: []byte(`<a href="{{ urlArgs "user.show" 42 }}">{{ url "files" "path" "a b/c.txt" "v" 2 }}</a>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Get("/users/{id}", func(c *Context) error { return nil }, WithName("user.show"))
+	app.Get("/orgs/{org}/repos/{repo}/{$}", func(c *Context) error { return nil }, WithName("repo.show"))
+	app.Get("/reports/{$}", func(c *Context) error { return nil }, WithName("reports"), WithExport("csv"))
+	app.Get("example.com/files/{path...}", func(c *Context) error { return nil }, WithName("files"))
+
+	app.Start()
+
+	tests := []struct {
+		name   string
+		route  string
+		values []any
+		want   string
+	}{
+		{"pairs", "user.show", []any{"id", 42, "tab", "repos"}, "/users/42?tab=repos"},
+		{"escaped", "user.show", []any{"id", "a/b"}, "/users/a%2Fb"},
+		{"multiple", "repo.show", []any{"org", "yaitoo", "repo", "xun"}, "/orgs/yaitoo/repos/xun/"},
+		{"export", "reports", nil, "/reports/"},
+		{"remaining", "files", []any{"path", "docs/a b.md"}, "/files/docs/a%20b.md"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			u, err := app.URL(test.route, test.values...)
+			require.NoError(t, err)
+			require.Equal(t, test.want, u)
+		})
+	}
+
+	// a single value is never guessed as the wildcard
+	_, err := app.URL("user.show", 42)
+	require.Error(t, err)
+
+	u, err := app.URLArgs("user.show", 42)
+	require.NoError(t, err)
+	require.Equal(t, "/users/42", u)
+
+	u, err = app.URLArgs("repo.show", "yaitoo", "xun")
+	require.NoError(t, err)
+	require.Equal(t, "/orgs/yaitoo/repos/xun/", u)
+
+	// a pair of values is never guessed as the wildcards
+	_, err = app.URLArgs("repo.show", "org", "yaitoo", "tab", "issues")
+	require.Error(t, err)
+
+	_, err = app.URL("user.edit", "id", 42)
+	require.ErrorIs(t, err, ErrRouteNotFound)
+
+	_, err = app.URLArgs("user.edit", 42)
+	require.ErrorIs(t, err, ErrRouteNotFound)
+
+	_, err = app.URL("repo.show", "org", "yaitoo", "tab", "issues")
+	require.Error(t, err)
+
+	// a name is registered by one route only
+	require.Panics(t, func() {
+		app.Get("/members/{id}", func(c *Context) error { return nil }, WithName("user.show"))
+	})
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/", nil)
+	require.NoError(t, err)
+	req.Header.Set("Accept", "text/html")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	buf, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, `<a href="/users/42">/files/a%20b/c.txt?v=2</a>`, string(buf))
+}
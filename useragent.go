@@ -0,0 +1,221 @@
+package xun
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const userAgentKey = "user_agent_info"
+
+// DeviceCookie is the name of the cookie that c.DeviceID stores the device id in.
+var DeviceCookie = "xun_device"
+
+// DeviceCookieMaxAge is how long the device id cookie is kept by browsers.
+var DeviceCookieMaxAge = 2 * 365 * 24 * time.Hour
+
+// Device types of UserAgent.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// UserAgent is the browser, OS and device parsed from the User-Agent header.
+// Fields that can't be recognized are empty.
+type UserAgent struct {
+	Raw            string
+	Browser        string
+	BrowserVersion string
+	OS             string
+	OSVersion      string
+	Device         string
+}
+
+// IsMobile reports whether the device is a phone.
+func (ua UserAgent) IsMobile() bool {
+	return ua.Device == DeviceMobile
+}
+
+// IsTablet reports whether the device is a tablet.
+func (ua UserAgent) IsTablet() bool {
+	return ua.Device == DeviceTablet
+}
+
+// IsBot reports whether the client is a crawler or a command line tool.
+func (ua UserAgent) IsBot() bool {
+	return ua.Device == DeviceBot
+}
+
+// UserAgentInfo returns the browser, OS and device of the client. The
+// Sec-CH-UA-Mobile client hint takes precedence over the User-Agent header
+// for mobile devices. It is parsed once per request.
+func (c *Context) UserAgentInfo() UserAgent {
+	if ua, ok := c.Get(userAgentKey).(UserAgent); ok {
+		return ua
+	}
+
+	ua := requestUserAgent(c.req)
+	c.Set(userAgentKey, ua)
+	return ua
+}
+
+// requestUserAgent parses the User-Agent header of r, and applies the
+// Sec-CH-UA-Mobile client hint.
+func requestUserAgent(r *http.Request) UserAgent {
+	ua := ParseUserAgent(r.Header.Get("User-Agent"))
+
+	if ua.Device != DeviceBot {
+		switch r.Header.Get("Sec-CH-UA-Mobile") {
+		case "?1":
+			if ua.Device != DeviceTablet {
+				ua.Device = DeviceMobile
+			}
+		case "?0":
+			if ua.Device == DeviceMobile {
+				ua.Device = DeviceDesktop
+			}
+		}
+	}
+
+	return ua
+}
+
+var bots = []string{"bot", "crawler", "spider", "slurp", "curl/", "wget/", "python-requests", "go-http-client"}
+
+var browsers = []struct {
+	token string
+	name  string
+}{
+	{"Edg/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"CriOS/", "Chrome"},
+	{"Chrome/", "Chrome"},
+	{"MSIE ", "Internet Explorer"},
+	{"Trident/", "Internet Explorer"},
+}
+
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+}
+
+// ParseUserAgent parses the browser, OS and device of a User-Agent header.
+func ParseUserAgent(s string) UserAgent {
+	ua := UserAgent{Raw: s}
+	if s == "" {
+		return ua
+	}
+
+	lower := strings.ToLower(s)
+	for _, it := range bots {
+		if strings.Contains(lower, it) {
+			ua.Device = DeviceBot
+			return ua
+		}
+	}
+
+	for _, it := range browsers {
+		if v, ok := versionAfter(s, it.token); ok {
+			ua.Browser, ua.BrowserVersion = it.name, v
+			break
+		}
+	}
+
+	if ua.Browser == "" && strings.Contains(s, "Safari/") {
+		ua.Browser = "Safari"
+		ua.BrowserVersion, _ = versionAfter(s, "Version/")
+	}
+
+	switch {
+	case strings.Contains(s, "Windows NT "):
+		ua.OS = "Windows"
+		v, _ := versionAfter(s, "Windows NT ")
+		ua.OSVersion = windowsVersions[v]
+	case strings.Contains(s, "iPhone") || strings.Contains(s, "iPad") || strings.Contains(s, "iPod"):
+		ua.OS = "iOS"
+		ua.OSVersion, _ = versionAfter(s, " OS ")
+	case strings.Contains(s, "Android"):
+		ua.OS = "Android"
+		ua.OSVersion, _ = versionAfter(s, "Android ")
+	case strings.Contains(s, "Mac OS X"):
+		ua.OS = "macOS"
+		ua.OSVersion, _ = versionAfter(s, "Mac OS X ")
+	case strings.Contains(s, "CrOS"):
+		ua.OS = "ChromeOS"
+	case strings.Contains(s, "Linux"):
+		ua.OS = "Linux"
+	}
+
+	switch {
+	case strings.Contains(s, "iPad") || strings.Contains(s, "Tablet") ||
+		(ua.OS == "Android" && !strings.Contains(s, "Mobile")):
+		ua.Device = DeviceTablet
+	case strings.Contains(s, "Mobi") || strings.Contains(s, "iPhone") || strings.Contains(s, "iPod"):
+		ua.Device = DeviceMobile
+	default:
+		ua.Device = DeviceDesktop
+	}
+
+	return ua
+}
+
+// versionAfter returns the version that follows the token, e.g. "17.2" of
+// "OS 17_2 like Mac OS X" for " OS ".
+func versionAfter(s, token string) (string, bool) {
+	i := strings.Index(s, token)
+	if i < 0 {
+		return "", false
+	}
+
+	s = s[i+len(token):]
+	n := 0
+	for n < len(s) && (s[n] >= '0' && s[n] <= '9' || s[n] == '.' || s[n] == '_') {
+		n++
+	}
+
+	return strings.ReplaceAll(s[:n], "_", "."), true
+}
+
+// DeviceID returns a stable id of the browser, e.g. for audit logs or listing
+// the devices of sessions. It is stored in the DeviceCookie, and a new one is
+// issued if the browser doesn't have it yet.
+//
+// The id is random instead of derived from request attributes, so it can't be
+// used to track the browser after the cookie is cleared.
+func (c *Context) DeviceID() string {
+	if cookie, err := c.req.Cookie(DeviceCookie); err == nil && cookie.Value != "" {
+		return cookie.Value
+	}
+
+	if id, ok := c.Get(DeviceCookie).(string); ok {
+		return id
+	}
+
+	buf := make([]byte, 16)
+	rand.Read(buf) // nolint: errcheck
+	id := base64.RawURLEncoding.EncodeToString(buf)
+
+	http.SetCookie(c.rw, &http.Cookie{
+		Name:     DeviceCookie,
+		Value:    id,
+		Path:     "/",
+		MaxAge:   int(DeviceCookieMaxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(c.AbsoluteURL("/"), "https://"),
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	c.Set(DeviceCookie, id)
+	return id
+}
@@ -0,0 +1,106 @@
+package xun
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		ua   string
+		want UserAgent
+	}{
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: UserAgent{Browser: "Chrome", BrowserVersion: "120.0.0.0", OS: "Windows", OSVersion: "10", Device: DeviceDesktop},
+		},
+		{
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36 Edg/120.0.2210.91",
+			want: UserAgent{Browser: "Edge", BrowserVersion: "120.0.2210.91", OS: "Windows", OSVersion: "10", Device: DeviceDesktop},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPhone; CPU iPhone OS 17_2 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.2 Mobile/15E148 Safari/604.1",
+			want: UserAgent{Browser: "Safari", BrowserVersion: "17.2", OS: "iOS", OSVersion: "17.2", Device: DeviceMobile},
+		},
+		{
+			ua:   "Mozilla/5.0 (iPad; CPU OS 16_6 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			want: UserAgent{Browser: "Chrome", BrowserVersion: "120.0.6099.119", OS: "iOS", OSVersion: "16.6", Device: DeviceTablet},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36",
+			want: UserAgent{Browser: "Chrome", BrowserVersion: "120.0.6099.144", OS: "Android", OSVersion: "14", Device: DeviceMobile},
+		},
+		{
+			ua:   "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+			want: UserAgent{Browser: "Chrome", BrowserVersion: "120.0.0.0", OS: "Android", OSVersion: "13", Device: DeviceTablet},
+		},
+		{
+			ua:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10.15; rv:121.0) Gecko/20100101 Firefox/121.0",
+			want: UserAgent{Browser: "Firefox", BrowserVersion: "121.0", OS: "macOS", OSVersion: "10.15", Device: DeviceDesktop},
+		},
+		{
+			ua:   "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			want: UserAgent{Device: DeviceBot},
+		},
+		{
+			ua:   "curl/8.4.0",
+			want: UserAgent{Device: DeviceBot},
+		},
+		{
+			ua:   "",
+			want: UserAgent{},
+		},
+	}
+
+	for _, test := range tests {
+		test.want.Raw = test.ua
+		require.Equal(t, test.want, ParseUserAgent(test.ua), test.ua)
+	}
+}
+
+func TestUserAgentInfo(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.6099.144 Mobile Safari/537.36")
+	req.Header.Set("Sec-CH-UA-Mobile", "?0")
+
+	c := &Context{req: req}
+	ua := c.UserAgentInfo()
+	require.False(t, ua.IsMobile())
+	require.Equal(t, DeviceDesktop, ua.Device)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Sec-CH-UA-Mobile", "?1")
+
+	c = &Context{req: req}
+	require.True(t, c.UserAgentInfo().IsMobile())
+}
+
+func TestDeviceID(t *testing.T) {
+	app := New()
+	defer app.Close()
+
+	rw := httptest.NewRecorder()
+	c := &Context{app: app, req: httptest.NewRequest(http.MethodGet, "/", nil), rw: rw}
+
+	id := c.DeviceID()
+	require.Len(t, id, 22)
+	require.Equal(t, id, c.DeviceID())
+
+	cookies := rw.Result().Cookies()
+	require.Len(t, cookies, 1)
+	require.Equal(t, DeviceCookie, cookies[0].Name)
+	require.Equal(t, id, cookies[0].Value)
+	require.True(t, cookies[0].HttpOnly)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.AddCookie(cookies[0])
+	rw = httptest.NewRecorder()
+	c = &Context{app: app, req: req, rw: rw}
+
+	require.Equal(t, id, c.DeviceID())
+	require.Empty(t, rw.Result().Cookies())
+}
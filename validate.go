@@ -1,6 +1,9 @@
 package xun
 
 import (
+	"errors"
+	"net/http"
+
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	"github.com/go-playground/validator/v10"
@@ -43,11 +46,67 @@ func AddValidator(trans ut.Translator, register func(v *validator.Validate, tran
 	return v
 }
 
+// findValidator returns the validator of the best matched locale, e.g.
+// the "zh" validator for "zh-CN". It returns the default validator if
+// none of them is matched.
 func findValidator(locales ...string) *Validator {
-	for _, locale := range locales {
-		if v, ok := validators[locale]; ok {
-			return v
-		}
+	supported := make([]string, 0, len(validators))
+	for locale := range validators {
+		supported = append(supported, locale)
+	}
+
+	if locale, ok := MatchLocale(locales, supported); ok {
+		return validators[locale]
 	}
 	return defaultValidator
 }
+
+// ValidateFunc validates the data bound from the request r, e.g. a pointer to
+// a struct, and returns the fields that failed. See WithValidator.
+type ValidateFunc func(r *http.Request, data any) []FieldError
+
+// ValidateStruct is the ValidateFunc of go-playground/validator. The messages
+// are translated by the validator of the locale negotiated like c.Locale, i.e.
+// by the Accept-Language of the request and the locales set by WithLocales.
+// See AddValidator.
+func ValidateStruct(r *http.Request, data any) []FieldError {
+	if !isStruct(data) {
+		return nil
+	}
+
+	languages := parseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if locales := bindOptionsOf(r).locales; len(locales) > 0 {
+		return validateStruct(data, negotiateLocale(languages, locales))
+	}
+
+	return validateStruct(data, languages...)
+}
+
+// validateStruct validates the struct data by the validator of the languages,
+// and returns the fields that failed with their translated messages.
+func validateStruct(data any, languages ...string) []FieldError {
+	validate := findValidator(languages...)
+
+	err := validate.Struct(data)
+	if err == nil {
+		return nil
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		// e.g. an InvalidValidationError of a nil pointer
+		return []FieldError{{Code: FieldInvalid, Message: err.Error()}}
+	}
+
+	fields := make([]FieldError, 0, len(errs))
+	for _, err := range errs {
+		n := err.Field()
+		if n == "" {
+			n = err.StructField()
+		}
+
+		fields = append(fields, FieldError{Field: n, Code: fieldCode(err), Message: err.Translate(validate.Translator)})
+	}
+
+	return fields
+}
@@ -11,9 +11,10 @@ import (
 
 // HtmlViewEngine is a view engine that loads templates from a file system.
 //
-// It supports 2 types of templates:
+// It supports 3 types of templates:
 //   - Components: These are templates that are loaded from the "components" directory.
 //   - Pages: These are templates that are loaded from the "layouts/views/pages/" directory.
+//   - Emails: These are templates that are loaded from the "emails" directory, and rendered by app.Mail.
 //
 // Components are used to build up larger templates, while pages are used to render
 // the final HTML that is sent to the client.
@@ -26,7 +27,7 @@ type HtmlViewEngine struct {
 
 // Load loads all templates from the given file system.
 //
-// It loads all components, layouts, pages and views from the given file system.
+// It loads all components, layouts, pages, views and emails from the given file system.
 func (ve *HtmlViewEngine) Load(fsys fs.FS, app *App) error {
 	if ve.templates == nil {
 		ve.templates = map[string]*HtmlTemplate{}
@@ -50,8 +51,12 @@ func (ve *HtmlViewEngine) Load(fsys fs.FS, app *App) error {
 		return err
 	}
 
-	return ve.loadViews()
+	err = ve.loadViews("views")
+	if err != nil {
+		return err
+	}
 
+	return ve.loadViews("emails")
 }
 
 // FileChanged is called when a file has been changed.
@@ -59,33 +64,46 @@ func (ve *HtmlViewEngine) Load(fsys fs.FS, app *App) error {
 // It is used to reload templates when they have been changed.
 func (ve *HtmlViewEngine) FileChanged(fsys fs.FS, app *App, event fsnotify.Event) error { // skipcq: RVV-B0012
 
-	if event.Has(fsnotify.Remove) || !strings.EqualFold(filepath.Ext(event.Name), ".html") {
+	if event.Has(fsnotify.Remove) || !strings.EqualFold(filepath.Ext(event.Name), ".html") || app.isIgnored(event.Name) {
 		return nil
 	}
 
 	name := event.Name[:len(event.Name)-5]
 
-	if event.Has(fsnotify.Write) {
-		t, ok := ve.templates[name]
-		if ok {
-			return t.Reload(fsys, ve.templates)
-		}
-	} else if event.Has(fsnotify.Create) {
-
+	var err error
+	if t, ok := ve.templates[name]; ok && event.Has(fsnotify.Write) {
+		err = t.Reload(fsys, ve.templates)
+	} else if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
+		// a template is loaded on write too if its first load failed, so the
+		// diagnostic is cleared once it is fixed
 		if strings.HasPrefix(event.Name, "components/") || strings.HasPrefix(event.Name, "layouts/") {
-			_, err := ve.loadTemplate(event.Name)
-			return err
+			_, err = ve.loadTemplate(event.Name)
 		} else if strings.HasPrefix(event.Name, "pages/") {
-			return ve.loadPage(event.Name)
-		} else if strings.HasPrefix(event.Name, "views/") {
-			return ve.loadView(event.Name)
+			err = ve.loadPage(event.Name)
+		} else if strings.HasPrefix(event.Name, "views/") || strings.HasPrefix(event.Name, "emails/") {
+			err = ve.loadView(event.Name)
+		} else {
+			return nil
 		}
+	}
 
-		return nil
+	app.diagnose(fsys, event.Name, err)
+	return err
+
+}
+
+// skip reports whether the file or directory is ignored by WithIgnore.
+// It returns fs.SkipDir for an ignored directory so that it is not walked.
+func (ve *HtmlViewEngine) skip(path string, d fs.DirEntry) (bool, error) {
+	if !ve.app.isIgnored(path) {
+		return false, nil
 	}
 
-	return nil
+	if d.IsDir() {
+		return true, fs.SkipDir
+	}
 
+	return true, nil
 }
 
 func (ve *HtmlViewEngine) loadComponents() error {
@@ -93,12 +111,16 @@ func (ve *HtmlViewEngine) loadComponents() error {
 		if err != nil {
 			return err
 		}
+		if skip, err := ve.skip(path, d); skip {
+			return err
+		}
 		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".html") {
 			return nil
 		}
 
 		_, err = ve.loadTemplate(path)
-		return err
+		ve.app.diagnose(ve.fsys, path, err)
+		return nil
 	})
 
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
@@ -113,6 +135,8 @@ func (ve *HtmlViewEngine) loadTemplate(path string) (*HtmlTemplate, error) {
 	name := path[:len(path)-5]
 
 	t := NewHtmlTemplate(name, path)
+	t.funcs = ve.app.funcs
+	t.requestFuncs = ve.app.requestFuncs
 
 	if err := t.Load(ve.fsys, ve.templates); err != nil {
 		return nil, err
@@ -127,6 +151,19 @@ func (ve *HtmlViewEngine) loadTemplate(path string) (*HtmlTemplate, error) {
 
 	ve.templates[name] = t
 
+	// the components and layouts are read by requests while the templates are
+	// reloaded by the watcher
+	ve.app.mu.Lock()
+	defer ve.app.mu.Unlock()
+
+	if strings.HasPrefix(name, "layouts/") {
+		ve.app.layouts[name] = t
+	}
+
+	if strings.HasPrefix(name, "components/") {
+		ve.app.components[name] = t
+	}
+
 	return t, nil
 }
 
@@ -136,12 +173,13 @@ func (ve *HtmlViewEngine) loadLayouts() error {
 			return err
 		}
 
-		if !d.IsDir() {
-
-			_, err = ve.loadTemplate(path)
-
+		if skip, err := ve.skip(path, d); skip {
 			return err
+		}
 
+		if !d.IsDir() {
+			_, err = ve.loadTemplate(path)
+			ve.app.diagnose(ve.fsys, path, err)
 		}
 
 		return nil
@@ -160,11 +198,16 @@ func (ve *HtmlViewEngine) loadPages() error {
 			return err
 		}
 
+		if skip, err := ve.skip(path, d); skip {
+			return err
+		}
+
 		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".html") {
 			return nil
 		}
 
-		return ve.loadPage(path)
+		ve.app.diagnose(ve.fsys, path, ve.loadPage(path))
+		return nil
 	})
 
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
@@ -179,6 +222,8 @@ func (ve *HtmlViewEngine) loadPage(path string) error {
 	name := path[6:] // delete prefix  "pages/"
 
 	t := NewHtmlTemplate(name, path)
+	t.funcs = ve.app.funcs
+	t.requestFuncs = ve.app.requestFuncs
 
 	if err := t.Load(ve.fsys, ve.templates); err != nil {
 		return err
@@ -191,27 +236,36 @@ func (ve *HtmlViewEngine) loadPage(path string) error {
 		name = name[:len(name)-10]
 	}
 
-	_, _, pattern := splitFile(name)
+	host, urlPath, pattern := splitFile(name)
 	pattern = strings.TrimSuffix(pattern, ".html")
 
-	ve.app.HandlePage(pattern, path[6:len(path)-5], &HtmlViewer{
+	v := &HtmlViewer{
+		app:      ve.app,
 		template: t,
-	})
+	}
+
+	ve.app.HandlePage(pattern, path[6:len(path)-5], v)
+	ve.app.addPage(path[6:len(path)-5], host, strings.TrimSuffix(urlPath, ".html"), pattern, v)
 
 	return nil
 }
 
-func (ve *HtmlViewEngine) loadViews() error {
-	err := fs.WalkDir(ve.fsys, "views", func(path string, d fs.DirEntry, err error) error {
+func (ve *HtmlViewEngine) loadViews(dir string) error {
+	err := fs.WalkDir(ve.fsys, dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
+		if skip, err := ve.skip(path, d); skip {
+			return err
+		}
+
 		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".html") {
 			return nil
 		}
 
-		return ve.loadView(path)
+		ve.app.diagnose(ve.fsys, path, ve.loadView(path))
+		return nil
 	})
 
 	if err != nil && errors.Is(err, fs.ErrNotExist) {
@@ -230,6 +284,7 @@ func (ve *HtmlViewEngine) loadView(path string) error {
 	}
 
 	ve.app.viewers[path[:len(path)-5]] = &HtmlViewer{
+		app:      ve.app,
 		template: t,
 	}
 
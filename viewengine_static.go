@@ -3,6 +3,7 @@ package xun
 import (
 	"errors"
 	"io/fs"
+	"log/slog"
 	"strings"
 
 	"github.com/yaitoo/xun/fsnotify"
@@ -24,6 +25,13 @@ func (ve *StaticViewEngine) Load(fsys fs.FS, app *App) error {
 			return err
 		}
 
+		if app.isIgnored(path) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
 		if !d.IsDir() {
 			ve.handle(fsys, app, path)
 		}
@@ -45,10 +53,15 @@ func (ve *StaticViewEngine) Load(fsys fs.FS, app *App) error {
 // it will be registered with the application.
 //
 // If the file changed is a Write/Remove event and the path is in the "public"
-// directory, nothing will be done.
+// directory, nothing will be done, except that a new fingerprint is registered
+// for a Write event if WithFingerprint is enabled. Files matched by WithIgnore are skipped.
 func (ve *StaticViewEngine) FileChanged(fsys fs.FS, app *App, event fsnotify.Event) error {
-	// Nothing should be updated for Write/Remove events.
-	if event.Has(fsnotify.Create) && strings.HasPrefix(event.Name, "public/") {
+	if !strings.HasPrefix(event.Name, "public/") || app.isIgnored(event.Name) {
+		return nil
+	}
+
+	// Nothing should be updated for Write/Remove events, unless the fingerprint of the file is changed.
+	if event.Has(fsnotify.Create) || (app.fingerprint && event.Has(fsnotify.Write)) {
 		ve.handle(fsys, app, event.Name)
 	}
 
@@ -66,7 +79,28 @@ func (ve *StaticViewEngine) handle(fsys fs.FS, app *App, path string) {
 	name = strings.TrimPrefix(name, "public/")
 
 	app.HandleFile(name, &FileViewer{
-		fsys: fsys,
-		path: path,
+		fsys:        fsys,
+		path:        path,
+		contentType: app.contentType(path),
+	})
+
+	// html files and virtual host files are not referenced as assets
+	if !app.fingerprint || strings.HasSuffix(name, ".html") || name == "" || name[0] == '@' {
+		return
+	}
+
+	fp, err := fingerprint(fsys, path, name)
+	if err != nil {
+		app.logger.Error("xun: fingerprint", slog.String("path", path), slog.Any("err", err))
+		return
+	}
+
+	app.HandleFile(fp, &FileViewer{
+		fsys:         fsys,
+		path:         path,
+		contentType:  app.contentType(path),
+		cacheControl: "public, max-age=31536000, immutable",
 	})
+
+	app.setManifest(name, fp)
 }
@@ -32,9 +32,15 @@ func (ve *TextViewEngine) Load(fsys fs.FS, app *App) error {
 			return err
 		}
 
-		if !d.IsDir() {
-			return ve.loadText(path)
+		if app.isIgnored(path) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
 
+		if !d.IsDir() {
+			app.diagnose(fsys, path, ve.loadText(path))
 		}
 
 		return nil
@@ -62,33 +68,36 @@ func (ve *TextViewEngine) loadText(path string) error {
 // FileChanged is called when a file in the file system has changed. It checks if the change is a
 // file creation event in the "text/" directory, and if so, calls the handle method to update the
 // corresponding view in the app.
-func (ve *TextViewEngine) FileChanged(fsys fs.FS, _ *App, event fsnotify.Event) error { // skipcq: RVV-B0012
-	if event.Has(fsnotify.Remove) {
+func (ve *TextViewEngine) FileChanged(fsys fs.FS, app *App, event fsnotify.Event) error { // skipcq: RVV-B0012
+	if event.Has(fsnotify.Remove) || app.isIgnored(event.Name) {
 		return nil
 	}
 
+	var err error
 	if event.Has(fsnotify.Write) {
 		t, ok := ve.templates[event.Name]
-		if ok {
-			return t.Reload(fsys)
+		if !ok {
+			return nil
 		}
+		err = t.Reload(fsys)
 	} else if event.Has(fsnotify.Create) {
-
-		if strings.HasPrefix(event.Name, "text/") {
-			err := ve.loadText(event.Name)
-			return err
+		if !strings.HasPrefix(event.Name, "text/") {
+			return nil
 		}
-
-		return nil
+		err = ve.loadText(event.Name)
 	}
 
-	return nil
+	app.diagnose(fsys, event.Name, err)
+	return err
 }
 
 func (ve *TextViewEngine) loadTemplate(path string) (*TextTemplate, error) {
 
 	t := &TextTemplate{
-		name: path,
+		name:  path,
+		funcs: ve.app.funcs,
+
+		requestFuncs: ve.app.requestFuncs,
 	}
 
 	if err := t.Load(ve.fsys); err != nil {
@@ -6,7 +6,8 @@ import (
 
 // BufPool is a pool of *bytes.Buffer for reuse to reduce memory alloc.
 //
-// It is used by the Viewer to render the content.
+// It is used by the Viewer to render the content of apps without their own
+// pool set by WithBufferPool.
 // The pool is created with a size of 100, but you can change it by setting the
 // BufPool variable before creating any Viewer instances.
 var BufPool *BufferPool
@@ -15,9 +16,35 @@ func init() {
 	BufPool = NewBufferPool(100)
 }
 
+type bufPoolKey struct{}
+
+// bufPoolOf returns the BufferPool of the app that serves the request r, or
+// BufPool if the app has no pool of its own.
+func bufPoolOf(r *http.Request) *BufferPool {
+	if r != nil {
+		if bp, ok := r.Context().Value(bufPoolKey{}).(*BufferPool); ok {
+			return bp
+		}
+	}
+
+	return BufPool
+}
+
 // Viewer is the interface that wraps the minimum set of methods required for
 // an effective viewer.
 type Viewer interface {
 	MimeType() *MimeType
 	Render(w http.ResponseWriter, r *http.Request, data any) error
 }
+
+// ViewModel is the data that is presented differently by viewers, so one
+// handler can serve both its pages and its API, e.g. without the secrets
+// that are only rendered in HTML forms in the JSON response.
+//
+// It is unwrapped by c.View: HtmlViewer renders HTMLData, and the other
+// viewers, e.g. JsonViewer, XmlViewer and the exporters of WithExport, render
+// JSONData.
+type ViewModel interface {
+	JSONData() any
+	HTMLData() any
+}
@@ -0,0 +1,108 @@
+package xun
+
+import (
+	"encoding"
+	"encoding/csv"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// CsvViewer is a viewer that writes the given table data as CSV to the http.ResponseWriter.
+//
+// The data is a [][]string, a slice of structs, or a struct with a slice field,
+// e.g. the data of a table page. Rows are streamed to the client, and the
+// header is written from the `export` tags of the struct fields.
+//
+// It sets the Content-Type header to "text/csv".
+type CsvViewer struct {
+}
+
+var csvViewerMime = &MimeType{Type: "text", SubType: "csv"}
+
+// MimeType returns the MIME type of the CSV content.
+//
+// It returns "text/csv".
+func (*CsvViewer) MimeType() *MimeType {
+	return csvViewerMime
+}
+
+// Render renders the given data as CSV to the http.ResponseWriter.
+//
+// It sets the Content-Type header to "text/csv; charset=utf-8", and the
+// Content-Disposition header if the request path ends with ".csv".
+func (*CsvViewer) Render(w http.ResponseWriter, r *http.Request, data any) error {
+	t, err := newTable(data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Add("Content-Type", "text/csv; charset=utf-8")
+	setAttachment(w, r, ".csv")
+
+	cw := csv.NewWriter(w)
+	if t.header != nil {
+		if err := cw.Write(t.header); err != nil {
+			return err
+		}
+	}
+
+	var record []string
+	err = t.Each(func(row []any) error {
+		record = record[:0]
+		for _, v := range row {
+			record = append(record, formatCell(v))
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// setAttachment sets Content-Disposition to download the export as a file,
+// if the request path is the export route with the ext suffix, e.g. /users.csv.
+func setAttachment(w http.ResponseWriter, r *http.Request, ext string) {
+	if r == nil || !strings.HasSuffix(r.URL.Path, ext) || w.Header().Get("Content-Disposition") != "" {
+		return
+	}
+
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{
+		"filename": path.Base(r.URL.Path),
+	}))
+}
+
+// formatCell formats the value of a table cell as a string.
+func formatCell(v any) string {
+	if v == nil {
+		return ""
+	}
+
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return ""
+		}
+	}
+
+	switch it := v.(type) {
+	case string:
+		return it
+	case encoding.TextMarshaler:
+		buf, err := it.MarshalText()
+		if err != nil {
+			return ""
+		}
+		return string(buf)
+	case fmt.Stringer:
+		return it.String()
+	}
+
+	v = reflect.Indirect(reflect.ValueOf(v)).Interface()
+	return fmt.Sprint(v)
+}
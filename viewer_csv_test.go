@@ -0,0 +1,151 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type exportUser struct {
+	ID        int        `export:"id"`
+	Name      string     `export:"name"`
+	Password  string     `export:"-"`
+	CreatedAt time.Time  `export:"created_at"`
+	DeletedAt *time.Time `export:"deleted_at"`
+}
+
+func TestCsvViewer(t *testing.T) {
+	created := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []exportUser{
+		{ID: 1, Name: "xun", Password: "secret", CreatedAt: created},
+		{ID: 2, Name: "yaitoo, \"inc\"", CreatedAt: created, DeletedAt: &created},
+	}
+
+	tests := []struct {
+		name string
+		data any
+		want string
+	}{
+		{
+			name: "structs",
+			data: users,
+			want: "id,name,created_at,deleted_at\n" +
+				"1,xun,2025-01-02T03:04:05Z,\n" +
+				"2,\"yaitoo, \"\"inc\"\"\",2025-01-02T03:04:05Z,2025-01-02T03:04:05Z\n",
+		},
+		{
+			name: "pointers",
+			data: []*exportUser{&users[0], nil},
+			want: "id,name,created_at,deleted_at\n" +
+				"1,xun,2025-01-02T03:04:05Z,\n" +
+				",,,\n",
+		},
+		{
+			name: "view_model",
+			data: &struct {
+				Page  int
+				Items []exportUser
+			}{Page: 1, Items: users[:1]},
+			want: "id,name,created_at,deleted_at\n" +
+				"1,xun,2025-01-02T03:04:05Z,\n",
+		},
+		{
+			name: "strings",
+			data: [][]string{{"a", "b"}, {"1", "2"}},
+			want: "a,b\n1,2\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rw := httptest.NewRecorder()
+			err := (&CsvViewer{}).Render(rw, httptest.NewRequest(http.MethodGet, "/users.csv", nil), test.data)
+			require.NoError(t, err)
+			require.Equal(t, "text/csv; charset=utf-8", rw.Header().Get("Content-Type"))
+			require.Equal(t, `attachment; filename=users.csv`, rw.Header().Get("Content-Disposition"))
+			require.Equal(t, test.want, rw.Body.String())
+		})
+	}
+
+	t.Run("unsupported", func(t *testing.T) {
+		for _, data := range []any{nil, 1, "users", []int{1}, struct{ Page int }{}} {
+			err := (&CsvViewer{}).Render(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), data)
+			require.ErrorIs(t, err, ErrUnsupportedTable)
+		}
+	})
+}
+
+func TestExport(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fstest.MapFS{
+		"pages/users.html": {Data: []byte(`<ul>{{ range .Items }}<li>{{ .Name }}</li>{{ end }}</ul>`)},
+		"views/users.html": {Data: []byte(`<ul>{{ range .Items }}<li>{{ .Name }}</li>{{ end }}</ul>`)},
+	}))
+	defer app.Close()
+
+	data := struct {
+		Items []exportUser
+	}{Items: []exportUser{{ID: 1, Name: "xun"}}}
+
+	app.Get("/users", func(c *Context) error {
+		return c.View(data, "views/users")
+	}, WithExport("csv"))
+
+	app.Get("/admin/{$}", func(c *Context) error {
+		return c.View(data)
+	}, WithExport("csv", "tsv"))
+
+	app.Get("/{$}", func(c *Context) error {
+		return c.View(data)
+	}, WithExport("csv"))
+
+	app.Get("/teams/{id}", func(c *Context) error {
+		return c.View(data)
+	}, WithExport("csv"))
+
+	// a {name...} wildcard can't be followed by the extension, so it is skipped
+	app.Get("/files/{path...}", func(c *Context) error {
+		return c.View(data)
+	}, WithExport("csv"))
+
+	app.Start()
+	defer app.Close()
+
+	get := func(path, accept string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(buf)
+	}
+
+	resp, body := get("/users", "text/html")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "<ul><li>xun</li></ul>", body)
+
+	// the export viewer is used even if the handler renders a named view
+	for _, path := range []string{"/users.csv", "/admin.csv", "/index.csv", "/teams/1/index.csv"} {
+		resp, body = get(path, "text/html")
+		require.Equal(t, http.StatusOK, resp.StatusCode, path)
+		require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get("Content-Type"))
+		require.Equal(t, "id,name,created_at,deleted_at\n1,xun,0001-01-01T00:00:00Z,\n", body)
+	}
+
+	// exporter is not registered
+	resp, _ = get("/admin.tsv", "*/*")
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -25,6 +25,9 @@ import (
 type FileViewer struct {
 	fsys fs.FS
 	path string
+
+	contentType  string
+	cacheControl string
 }
 
 var fileViewerMime = &MimeType{Type: "*", SubType: "*"}
@@ -38,7 +41,15 @@ func (*FileViewer) MimeType() *MimeType {
 
 // Render serves a file from the file system using the FileViewer.
 // It writes the file to the http.ResponseWriter.
+// If a custom MIME type is registered for the file extension, it is used as Content-Type.
 func (v *FileViewer) Render(w http.ResponseWriter, r *http.Request, data any) error {
+	if v.contentType != "" {
+		w.Header().Set("Content-Type", v.contentType)
+	}
+
+	if v.cacheControl != "" {
+		w.Header().Set("Cache-Control", v.cacheControl)
+	}
 	http.ServeFileFS(w, r, v.fsys, v.path)
 	return nil
 }
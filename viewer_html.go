@@ -11,6 +11,7 @@ import (
 // The `Render` method renders the template with the given data and writes the
 // result to the http.ResponseWriter.
 type HtmlViewer struct {
+	app      *App
 	template *HtmlTemplate
 }
 
@@ -26,13 +27,27 @@ func (*HtmlViewer) MimeType() *MimeType {
 // Render renders the template with the given data and writes the result to the http.ResponseWriter.
 //
 // This implementation uses the `HtmlTemplate.Execute` method to render the template.
+// If a layout variant is selected by WithLayoutRules, e.g. layouts/main.mobile.html,
+// it is used instead of the layout of the template, and the response varies
+// by the LayoutVary headers.
 // The rendered result is written to the http.ResponseWriter.
 func (v *HtmlViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
 	w.Header().Add("Content-Type", "text/html; charset=utf-8")
-	buf := BufPool.Get()
-	defer BufPool.Put(buf)
+	if v.app != nil && len(v.app.layoutRules) > 0 && v.template.layout != "" {
+		for _, h := range LayoutVary {
+			w.Header().Add("Vary", h)
+		}
+	}
+	bp := bufPoolOf(r)
+	buf := bp.Get()
+	defer bp.Put(buf)
 
-	err := v.template.Execute(buf, data)
+	var err error
+	if layout := v.layoutVariant(r); layout != nil {
+		err = v.template.executeLayout(buf, r, layout, data)
+	} else {
+		err = v.template.execute(buf, r, data)
+	}
 	if err != nil {
 		return err
 	}
@@ -40,3 +55,21 @@ func (v *HtmlViewer) Render(w http.ResponseWriter, r *http.Request, data any) er
 	_, err = buf.WriteTo(w)
 	return err
 }
+
+// layoutVariant returns the variant of the template layout selected by the
+// layout rules of the app, or nil if the default layout should be used.
+func (v *HtmlViewer) layoutVariant(r *http.Request) *HtmlTemplate {
+	if v.app == nil || len(v.app.layoutRules) == 0 || v.template.layout == "" {
+		return nil
+	}
+
+	variant := v.app.layoutVariant(r)
+	if variant == "" {
+		return nil
+	}
+
+	v.app.mu.RLock()
+	defer v.app.mu.RUnlock()
+
+	return v.app.layouts[v.template.layout+"."+variant]
+}
@@ -2,12 +2,42 @@ package xun
 
 import (
 	"net/http"
+	"reflect"
+	"regexp"
+
+	jsoniter "github.com/json-iterator/go"
 )
 
+// streamFlushSize is the size of encoded JSON that is flushed to the client by a streaming JsonViewer.
+const streamFlushSize = 32 * 1024
+
+// prettyJSON encodes JSON with indentation for `?pretty=1`.
+var prettyJSON = jsoniter.Config{UseNumber: false, IndentionStep: 2}.Froze()
+
+// jsonpCallback matches valid JSONP callback names, e.g. "cb" or "app.handlers.users".
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// maxCallbackLength is the max length of JSONP callback names.
+const maxCallbackLength = 128
+
 // JsonViewer is a viewer that writes the given data as JSON to the http.ResponseWriter.
 //
 // It sets the Content-Type header to "application/json".
 type JsonViewer struct {
+	// Stream encodes the data directly to the http.ResponseWriter with chunked
+	// transfer instead of buffering the whole response, e.g. for endpoints
+	// returning large slices. Slices and arrays are flushed to the client as
+	// their elements are encoded.
+	//
+	// NOTE: the status code can't be changed if an error occurs after the
+	// response is flushed.
+	Stream bool
+
+	// JSONP is the name of the query parameter of the JSONP callback, e.g.
+	// "callback" for `?callback=handleUsers`, for legacy integrations. It is
+	// disabled if it is empty. Callback names are validated as JavaScript
+	// identifiers, and invalid ones are rejected with 400 Bad Request.
+	JSONP string
 }
 
 var jsonViewerMime = &MimeType{Type: "application", SubType: "json"}
@@ -20,18 +50,151 @@ func (*JsonViewer) MimeType() *MimeType {
 }
 
 // Render renders the given data as JSON to the http.ResponseWriter.
+// It is trimmed to the fields selected by the SparseFields middleware, and
+// wrapped in an EnvelopeResponse if the Envelope middleware is used. It is
+// indented for `?pretty=1`, e.g. for debugging.
 //
-// It sets the Content-Type header to "application/json".
-func (*JsonViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
-	buf := BufPool.Get()
-	defer BufPool.Put(buf)
+// It sets the Content-Type header to "application/json", or
+// "text/javascript; charset=utf-8" for JSONP callbacks.
+func (v *JsonViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
+	data, err := selectFields(r, data)
+	if err != nil {
+		return err
+	}
+	data = envelope(r, data)
+
+	api := json
+	var callback string
+	if r != nil {
+		q := r.URL.Query()
+		if p := q.Get("pretty"); p == "1" || p == "true" {
+			api = prettyJSON
+		}
 
-	err := json.NewEncoder(buf).Encode(data)
+		if v.JSONP != "" {
+			callback = q.Get(v.JSONP)
+			if callback != "" && (len(callback) > maxCallbackLength || !jsonpCallback.MatchString(callback)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return nil
+			}
+		}
+	}
+
+	if v.Stream {
+		return v.stream(w, api, callback, data)
+	}
+
+	bp := bufPoolOf(r)
+	buf := bp.Get()
+	defer bp.Put(buf)
+
+	if callback != "" {
+		buf.WriteString("/**/" + callback + "(")
+	}
+
+	err = api.NewEncoder(buf).Encode(data)
 	if err != nil {
 		return err
 	}
 
-	w.Header().Add("Content-Type", "application/json")
+	if callback != "" {
+		buf.Truncate(buf.Len() - 1) // the trailing newline of Encode
+		buf.WriteString(");\n")
+	}
+
+	setJsonHeader(w, callback)
 	_, err = buf.WriteTo(w)
 	return err
 }
+
+// setJsonHeader sets the Content-Type of JSON, or the JSONP callback.
+func setJsonHeader(w http.ResponseWriter, callback string) {
+	if callback == "" {
+		w.Header().Add("Content-Type", "application/json")
+		return
+	}
+
+	w.Header().Add("Content-Type", "text/javascript; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+}
+
+// stream encodes the data to the http.ResponseWriter, and flushes it to the
+// client every streamFlushSize bytes. The output is the same as Render.
+func (*JsonViewer) stream(w http.ResponseWriter, api jsoniter.API, callback string, data any) error {
+	s := api.BorrowStream(w)
+	defer api.ReturnStream(s)
+
+	written := false
+	flushStream := func() error {
+		if !written {
+			// the Content-Type is sent with the first chunk, so errors before it can still be written as usual
+			setJsonHeader(w, callback)
+			written = true
+		}
+		return s.Flush()
+	}
+
+	if callback != "" {
+		s.WriteRaw("/**/" + callback + "(")
+	}
+
+	writeVal := func(data any) error {
+		rv := reflect.ValueOf(data)
+		if (rv.Kind() != reflect.Slice || rv.IsNil() || rv.Type().Elem().Kind() == reflect.Uint8) && rv.Kind() != reflect.Array {
+			s.WriteVal(data)
+			return nil
+		}
+
+		s.WriteArrayStart()
+		for i := 0; i < rv.Len(); i++ {
+			if i > 0 {
+				s.WriteMore()
+			}
+			s.WriteVal(rv.Index(i).Interface())
+			if s.Error != nil {
+				return s.Error
+			}
+
+			if s.Buffered() >= streamFlushSize {
+				if err := flushStream(); err != nil {
+					return err
+				}
+				flush(w)
+			}
+		}
+		s.WriteArrayEnd()
+		return nil
+	}
+
+	if e, ok := data.(*EnvelopeResponse); ok {
+		// the fields are written like the encoding of EnvelopeResponse
+		s.WriteObjectStart()
+		s.WriteObjectField("data")
+		if err := writeVal(e.Data); err != nil {
+			return err
+		}
+		if len(e.Meta) > 0 {
+			s.WriteMore()
+			s.WriteObjectField("meta")
+			s.WriteVal(e.Meta)
+		}
+		if len(e.Errors) > 0 {
+			s.WriteMore()
+			s.WriteObjectField("errors")
+			s.WriteVal(e.Errors)
+		}
+		s.WriteObjectEnd()
+	} else if err := writeVal(data); err != nil {
+		return err
+	}
+
+	if callback != "" {
+		s.WriteRaw(");")
+	}
+	s.WriteRaw("\n")
+	if s.Error != nil {
+		return s.Error
+	}
+
+	return flushStream()
+}
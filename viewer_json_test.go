@@ -3,6 +3,8 @@ package xun
 import (
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -28,3 +30,103 @@ func TestJsonViewerRenderError(t *testing.T) {
 	require.Equal(t, 200, rw.Code)
 
 }
+
+func TestJsonViewerStream(t *testing.T) {
+	type item struct {
+		ID   int
+		Name string
+	}
+
+	items := make([]item, 5000)
+	for i := range items {
+		items[i] = item{ID: i, Name: "xun"}
+	}
+
+	tests := []struct {
+		name string
+		data any
+	}{
+		{name: "slice", data: items},
+		{name: "empty_slice", data: []item{}},
+		{name: "nil_slice", data: []item(nil)},
+		{name: "bytes", data: []byte("xun")},
+		{name: "array", data: [2]int{1, 2}},
+		{name: "struct", data: item{ID: 1, Name: "xun"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want := httptest.NewRecorder()
+			require.NoError(t, (&JsonViewer{}).Render(want, httptest.NewRequest(http.MethodGet, "/", nil), test.data))
+
+			rw := httptest.NewRecorder()
+			require.NoError(t, (&JsonViewer{Stream: true}).Render(rw, httptest.NewRequest(http.MethodGet, "/", nil), test.data))
+
+			require.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+			require.Equal(t, want.Body.String(), rw.Body.String())
+		})
+	}
+
+	t.Run("flushed", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		require.NoError(t, (&JsonViewer{Stream: true}).Render(rw, httptest.NewRequest(http.MethodGet, "/", nil), items))
+		require.True(t, rw.Flushed)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		rw := httptest.NewRecorder()
+		err := (&JsonViewer{Stream: true}).Render(rw, httptest.NewRequest(http.MethodGet, "/", nil), []any{1, make(chan int)})
+		require.Error(t, err)
+		require.Empty(t, rw.Header().Get("Content-Type"))
+		require.Equal(t, 0, rw.Body.Len())
+	})
+}
+
+func TestJsonViewerPrettyAndJSONP(t *testing.T) {
+	type item struct {
+		ID   int
+		Tags []string
+	}
+
+	data := []item{{ID: 1, Tags: []string{"a"}}, {ID: 2}}
+
+	tests := []struct {
+		name        string
+		url         string
+		jsonp       string
+		status      int
+		contentType string
+		body        string
+	}{
+		{name: "pretty", url: "/?pretty=1", status: 200, contentType: "application/json",
+			body: "[\n  {\n    \"ID\": 1,\n    \"Tags\": [\n      \"a\"\n    ]\n  },\n  {\n    \"ID\": 2,\n    \"Tags\": null\n  }\n]\n"},
+		{name: "not_pretty", url: "/?pretty=0", status: 200, contentType: "application/json",
+			body: "[{\"ID\":1,\"Tags\":[\"a\"]},{\"ID\":2,\"Tags\":null}]\n"},
+		{name: "jsonp_disabled", url: "/?callback=cb", status: 200, contentType: "application/json",
+			body: "[{\"ID\":1,\"Tags\":[\"a\"]},{\"ID\":2,\"Tags\":null}]\n"},
+		{name: "jsonp", url: "/?callback=app.cb_1", jsonp: "callback", status: 200, contentType: "text/javascript; charset=utf-8",
+			body: "/**/app.cb_1([{\"ID\":1,\"Tags\":[\"a\"]},{\"ID\":2,\"Tags\":null}]);\n"},
+		{name: "jsonp_without_callback", url: "/", jsonp: "callback", status: 200, contentType: "application/json",
+			body: "[{\"ID\":1,\"Tags\":[\"a\"]},{\"ID\":2,\"Tags\":null}]\n"},
+		{name: "jsonp_invalid", url: "/?callback=alert(1)", jsonp: "callback", status: 400},
+		{name: "jsonp_too_long", url: "/?callback=" + strings.Repeat("a", maxCallbackLength+1), jsonp: "callback", status: 400},
+	}
+
+	for _, test := range tests {
+		for _, stream := range []bool{false, true} {
+			t.Run(test.name+"_"+strconv.FormatBool(stream), func(t *testing.T) {
+				v := &JsonViewer{Stream: stream, JSONP: test.jsonp}
+				rw := httptest.NewRecorder()
+				err := v.Render(rw, httptest.NewRequest(http.MethodGet, test.url, nil), data)
+				require.NoError(t, err)
+				require.Equal(t, test.status, rw.Code)
+				require.Equal(t, test.contentType, rw.Header().Get("Content-Type"))
+				require.Equal(t, test.body, rw.Body.String())
+
+				if test.jsonp != "" && test.status == 200 && test.contentType != "application/json" {
+					require.Equal(t, "nosniff", rw.Header().Get("X-Content-Type-Options"))
+				}
+			})
+		}
+	}
+}
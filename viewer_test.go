@@ -0,0 +1,77 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+type accountModel struct {
+	Name   string
+	APIKey string
+}
+
+type accountPublic struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func (m *accountModel) JSONData() any {
+	return accountPublic{Name: m.Name}
+}
+
+func (m *accountModel) HTMLData() any {
+	return m
+}
+
+func TestViewModel(t *testing.T) {
+	fsys := fstest.MapFS{
+		"layouts/main.html":  {Data: []byte(`<main>{{ block "content" . }}{{ end }}</main>`)},
+		"views/account.html": {Data: []byte(`<p>{{ .Name }}:{{ .APIKey }}</p>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.Get("/account", func(c *Context) error {
+		return c.View(&accountModel{Name: "xun", APIKey: "secret"}, "views/account")
+	})
+
+	app.Get("/account/fragment", func(c *Context) error {
+		return c.View(&accountModel{Name: "xun", APIKey: "secret"}, "views/account")
+	}, WithFragmentLayout("main"))
+
+	app.Get("/accounts", func(c *Context) error {
+		return c.View(&accountModel{Name: "xun", APIKey: "secret"})
+	}, WithViewer(&XmlViewer{}))
+
+	app.Start()
+
+	get := func(path, accept string) string {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(buf)
+	}
+
+	require.Equal(t, "<p>xun:secret</p>", get("/account", "text/html"))
+	require.Equal(t, "{\"name\":\"xun\"}\n", get("/account", "application/json"))
+	require.Equal(t, "<main><p>xun:secret</p></main>", get("/account/fragment", "text/html"))
+
+	// data viewers render the JSONData without the secrets
+	body := get("/accounts", "application/xml")
+	require.Contains(t, body, "<name>xun</name>")
+	require.NotContains(t, body, "secret")
+}
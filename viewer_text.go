@@ -19,10 +19,11 @@ func (v *TextViewer) MimeType() *MimeType {
 // If there is an error executing the template, it is returned.
 func (v *TextViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
 	w.Header().Add("Content-Type", v.template.mime.String()+v.template.charset)
-	buf := BufPool.Get()
-	defer BufPool.Put(buf)
+	bp := bufPoolOf(r)
+	buf := bp.Get()
+	defer bp.Put(buf)
 
-	err := v.template.Execute(buf, data)
+	err := v.template.execute(buf, r, data)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,211 @@
+package xun
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// XlsxViewer is a viewer that writes the given table data as an Excel spreadsheet to the http.ResponseWriter.
+//
+// The data is the same as CsvViewer. Rows are streamed into the worksheet
+// without buffering the spreadsheet. Numbers and booleans are written as typed
+// cells, and time.Time as date cells.
+//
+// It sets the Content-Type header to "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet".
+type XlsxViewer struct {
+}
+
+var xlsxViewerMime = &MimeType{Type: "application", SubType: "vnd.openxmlformats-officedocument.spreadsheetml.sheet"}
+
+// MimeType returns the MIME type of the xlsx content.
+//
+// It returns "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet".
+func (*XlsxViewer) MimeType() *MimeType {
+	return xlsxViewerMime
+}
+
+const (
+	xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+		`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+		`</Types>`
+
+	xlsxRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+		`</Relationships>`
+
+	xlsxWorkbook = xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets>` +
+		`</workbook>`
+
+	xlsxWorkbookRels = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+		`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+		`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+		`</Relationships>`
+
+	// styles: 0 is default, 1 is date time, and 2 is bold for the header.
+	xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<fonts count="2"><font/><font><b/></font></fonts>` +
+		`<fills count="1"><fill><patternFill patternType="none"/></fill></fills>` +
+		`<borders count="1"><border/></borders>` +
+		`<cellStyleXfs count="1"><xf/></cellStyleXfs>` +
+		`<cellXfs count="3"><xf/><xf numFmtId="22" applyNumberFormat="1"/><xf fontId="1" applyFont="1"/></cellXfs>` +
+		`</styleSheet>`
+
+	xlsxSheetStart = xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd   = `</sheetData></worksheet>`
+)
+
+// xlsxEpoch is the day zero of the date cells.
+var xlsxEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// Render renders the given data as xlsx to the http.ResponseWriter.
+//
+// It sets the Content-Type header, and the Content-Disposition header if the
+// request path ends with ".xlsx".
+func (*XlsxViewer) Render(w http.ResponseWriter, r *http.Request, data any) error {
+	t, err := newTable(data)
+	if err != nil {
+		return err
+	}
+
+	w.Header().Add("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	setAttachment(w, r, ".xlsx")
+
+	zw := zip.NewWriter(w)
+
+	for _, it := range []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+	} {
+		f, err := zw.Create(it.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(f, it.content); err != nil {
+			return err
+		}
+	}
+
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+
+	bw := bufio.NewWriter(f)
+	bw.WriteString(xlsxSheetStart) // nolint: errcheck
+
+	if t.header != nil {
+		bw.WriteString("<row>") // nolint: errcheck
+		for _, h := range t.header {
+			writeXlsxString(bw, h, 2)
+		}
+		bw.WriteString("</row>") // nolint: errcheck
+	}
+
+	err = t.Each(func(row []any) error {
+		bw.WriteString("<row>") // nolint: errcheck
+		for _, v := range row {
+			writeXlsxCell(bw, v)
+		}
+		_, err := bw.WriteString("</row>")
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	bw.WriteString(xlsxSheetEnd) // nolint: errcheck
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
+
+// writeXlsxCell writes the value v as a typed cell.
+func writeXlsxCell(w *bufio.Writer, v any) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			w.WriteString("<c/>") // nolint: errcheck
+			return
+		}
+		rv = rv.Elem()
+		v = rv.Interface()
+	}
+
+	if !rv.IsValid() {
+		w.WriteString("<c/>") // nolint: errcheck
+		return
+	}
+
+	if t, ok := v.(time.Time); ok {
+		if t.IsZero() {
+			w.WriteString("<c/>") // nolint: errcheck
+			return
+		}
+		// date cells have no time zone, so the wall clock of t is written.
+		y, m, d := t.Date()
+		wall := time.Date(y, m, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+		writeXlsxNumber(w, strconv.FormatFloat(wall.Sub(xlsxEpoch).Hours()/24, 'f', -1, 64), 1)
+		return
+	}
+
+	switch rv.Kind() {
+	case reflect.Bool:
+		b := "0"
+		if rv.Bool() {
+			b = "1"
+		}
+		w.WriteString(`<c t="b"><v>` + b + `</v></c>`) // nolint: errcheck
+		return
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeXlsxNumber(w, strconv.FormatInt(rv.Int(), 10), 0)
+		return
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		writeXlsxNumber(w, strconv.FormatUint(rv.Uint(), 10), 0)
+		return
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); !math.IsNaN(f) && !math.IsInf(f, 0) {
+			writeXlsxNumber(w, strconv.FormatFloat(f, 'g', -1, 64), 0)
+			return
+		}
+	}
+
+	writeXlsxString(w, formatCell(v), 0)
+}
+
+func writeXlsxNumber(w *bufio.Writer, n string, style int) {
+	w.WriteString("<c") // nolint: errcheck
+	if style > 0 {
+		w.WriteString(` s="` + strconv.Itoa(style) + `"`) // nolint: errcheck
+	}
+	w.WriteString("><v>" + n + "</v></c>") // nolint: errcheck
+}
+
+func writeXlsxString(w *bufio.Writer, s string, style int) {
+	w.WriteString(`<c t="inlineStr"`) // nolint: errcheck
+	if style > 0 {
+		w.WriteString(` s="` + strconv.Itoa(style) + `"`) // nolint: errcheck
+	}
+	w.WriteString(`><is><t xml:space="preserve">`) // nolint: errcheck
+	xml.EscapeText(w, []byte(s))                   // nolint: errcheck
+	w.WriteString("</t></is></c>")                 // nolint: errcheck
+}
@@ -0,0 +1,109 @@
+package xun
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func readXlsxSheet(t *testing.T, buf []byte) string {
+	t.Helper()
+
+	zr, err := zip.NewReader(bytes.NewReader(buf), int64(len(buf)))
+	require.NoError(t, err)
+
+	var names []string
+	var sheet string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+
+		r, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(r)
+		require.NoError(t, err)
+		r.Close()
+
+		if f.Name == "xl/worksheets/sheet1.xml" {
+			sheet = string(content)
+		}
+	}
+
+	require.ElementsMatch(t, []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml",
+		"xl/_rels/workbook.xml.rels", "xl/styles.xml", "xl/worksheets/sheet1.xml"}, names)
+
+	return sheet
+}
+
+func TestXlsxViewer(t *testing.T) {
+	created := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+
+	type order struct {
+		ID     uint    `export:"id"`
+		Item   string  `export:"item"`
+		Amount float64 `export:"amount"`
+		Paid   bool    `export:"paid"`
+		At     time.Time
+		Note   *string `export:"note"`
+	}
+
+	rw := httptest.NewRecorder()
+	err := (&XlsxViewer{}).Render(rw, httptest.NewRequest(http.MethodGet, "/orders.xlsx", nil), []order{
+		{ID: 1, Item: "<tea> & cake", Amount: 12.5, Paid: true, At: created},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", rw.Header().Get("Content-Type"))
+	require.Equal(t, "attachment; filename=orders.xlsx", rw.Header().Get("Content-Disposition"))
+
+	sheet := readXlsxSheet(t, rw.Body.Bytes())
+	require.Contains(t, sheet, `<row><c t="inlineStr" s="2"><is><t xml:space="preserve">id</t></is></c>`)
+	require.Contains(t, sheet, `<t xml:space="preserve">At</t>`)
+	require.Contains(t, sheet, `<row><c><v>1</v></c>`+
+		`<c t="inlineStr"><is><t xml:space="preserve">&lt;tea&gt; &amp; cake</t></is></c>`+
+		`<c><v>12.5</v></c>`+
+		`<c t="b"><v>1</v></c>`+
+		`<c s="1"><v>45659.5</v></c>`+
+		`<c/></row>`)
+
+	err = (&XlsxViewer{}).Render(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil), 1)
+	require.ErrorIs(t, err, ErrUnsupportedTable)
+}
+
+func TestXlsxViewerAccept(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	app.Get("/reports", func(c *Context) error {
+		return c.View([][]string{{"month", "total"}, {"2025-01", "42"}})
+	}, WithViewer(&JsonViewer{}, &XlsxViewer{}), WithExport("xlsx"))
+
+	app.Start()
+
+	for path, accept := range map[string]string{
+		"/reports":      "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+		"/reports.xlsx": "*/*",
+	} {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", accept)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", resp.Header.Get("Content-Type"))
+		require.Contains(t, readXlsxSheet(t, buf), `<t xml:space="preserve">2025-01</t>`)
+	}
+}
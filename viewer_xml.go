@@ -7,31 +7,35 @@ import (
 
 // XmlViewer is a viewer that writes the given data as xml to the http.ResponseWriter.
 //
-// It sets the Content-Type header to "application/xml".
+// It is selected by c.View for "Accept: application/xml" or "Accept: text/xml"
+// when it is registered by WithViewer or WithHandlerViewers, e.g.
+//
+//	app.Get("/users", handler, xun.WithViewer(&xun.JsonViewer{}, &xun.XmlViewer{}))
 type XmlViewer struct {
 }
 
-var XmlViewerMime = &MimeType{Type: "text", SubType: "xml"}
+var XmlViewerMime = &MimeType{Type: "application", SubType: "xml"}
 
 // MimeType returns the MIME type of the xml content.
 //
-// It returns "text/xml".
+// It returns "application/xml", which matches "text/xml" too.
 func (*XmlViewer) MimeType() *MimeType {
 	return XmlViewerMime
 }
 
 // Render renders the given data as xml to the http.ResponseWriter.
 //
-// It sets the Content-Type header to "text/xml; charset=utf-8".
+// It sets the Content-Type header to "application/xml; charset=utf-8".
 func (*XmlViewer) Render(w http.ResponseWriter, r *http.Request, data any) error { // skipcq: RVV-B0012
-	buf := BufPool.Get()
-	defer BufPool.Put(buf)
+	bp := bufPoolOf(r)
+	buf := bp.Get()
+	defer bp.Put(buf)
 
 	err := xml.NewEncoder(buf).Encode(data)
 	if err != nil {
 		return err
 	}
-	w.Header().Add("Content-Type", "text/xml; charset=utf-8")
+	w.Header().Add("Content-Type", "application/xml; charset=utf-8")
 	_, err = buf.WriteTo(w)
 	return err
 }
@@ -2,6 +2,7 @@ package xun
 
 import (
 	"encoding/xml"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -28,3 +29,54 @@ func TestXmlViewerRenderError(t *testing.T) {
 	require.Equal(t, 200, rw.Code)
 
 }
+
+func TestXmlViewerNegotiation(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+
+	type user struct {
+		Name string
+	}
+
+	app.Get("/user", func(c *Context) error {
+		return c.View(&user{Name: "xun"})
+	}, WithViewer(&JsonViewer{}, &XmlViewer{}))
+
+	app.Start()
+	defer app.Close()
+
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+		body        string
+	}{
+		{name: "application_xml", accept: "application/xml", contentType: "application/xml; charset=utf-8", body: "<user><Name>xun</Name></user>"},
+		{name: "text_xml", accept: "text/xml", contentType: "application/xml; charset=utf-8", body: "<user><Name>xun</Name></user>"},
+		{name: "preference", accept: "application/xml, */*", contentType: "application/xml; charset=utf-8", body: "<user><Name>xun</Name></user>"},
+		{name: "json", accept: "application/json, application/xml;q=0.9", contentType: "application/json", body: "{\"Name\":\"xun\"}\n"},
+		{name: "fallback", accept: "", contentType: "application/json", body: "{\"Name\":\"xun\"}\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, srv.URL+"/user", nil)
+			require.NoError(t, err)
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			resp, err := client.Do(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			buf, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, test.contentType, resp.Header.Get("Content-Type"))
+			require.Equal(t, test.body, string(buf))
+		})
+	}
+}
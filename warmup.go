@@ -0,0 +1,69 @@
+package xun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+)
+
+// ReadyzPattern is the route pattern of the readiness endpoint enabled by WithReadyz.
+const ReadyzPattern = "GET /readyz"
+
+// Warmup registers a task that must complete before app.Start returns and
+// before /readyz reports ready, e.g. cache priming, template precompile or
+// upstream pings. Tasks run concurrently with the app-level context.
+func (app *App) Warmup(fn func(ctx context.Context) error) {
+	app.mu.Lock()
+	defer app.mu.Unlock()
+
+	app.warmups = append(app.warmups, fn)
+}
+
+// Ready reports whether app.Start has completed all warmup tasks, and
+// app.Shutdown has not been called.
+func (app *App) Ready() bool {
+	return app.ready.Load()
+}
+
+// warmup runs the warmup tasks and returns their errors joined.
+func (app *App) warmup() error {
+	app.mu.RLock()
+	tasks := app.warmups
+	app.mu.RUnlock()
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+
+	for _, fn := range tasks {
+		wg.Add(1)
+		go func(fn func(ctx context.Context) error) {
+			defer wg.Done()
+			if err := fn(app.ctx); err != nil {
+				mu.Lock()
+				errs = append(errs, err)
+				mu.Unlock()
+			}
+		}(fn)
+	}
+
+	wg.Wait()
+
+	return errors.Join(errs...)
+}
+
+// handleReadyz registers the readiness endpoint. It writes 503 Service
+// Unavailable until the app is ready, and once it starts shutting down.
+func (app *App) handleReadyz() {
+	app.mux.HandleFunc(ReadyzPattern, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "no-store")
+		if !app.Ready() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+}
@@ -0,0 +1,71 @@
+package xun
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWarmup(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithReadyz())
+	defer app.Close()
+
+	readyz := func() int {
+		resp, err := client.Get(srv.URL + "/readyz")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	var primed atomic.Int32
+	release := make(chan struct{})
+	app.Warmup(func(ctx context.Context) error {
+		<-release
+		primed.Add(1)
+		return nil
+	})
+	app.Warmup(func(ctx context.Context) error {
+		primed.Add(1)
+		return nil
+	})
+
+	done := make(chan error)
+	go func() {
+		done <- app.Start()
+	}()
+
+	require.Equal(t, http.StatusServiceUnavailable, readyz())
+	require.False(t, app.Ready())
+
+	close(release)
+	require.NoError(t, <-done)
+
+	require.Equal(t, int32(2), primed.Load())
+	require.True(t, app.Ready())
+	require.Equal(t, http.StatusOK, readyz())
+
+	require.NoError(t, app.Shutdown(context.Background()))
+	require.Equal(t, http.StatusServiceUnavailable, readyz())
+}
+
+func TestWarmupError(t *testing.T) {
+	app := New(WithMux(http.NewServeMux()))
+	defer app.Close()
+
+	errPing := errors.New("ping: connection refused")
+	app.Warmup(func(ctx context.Context) error {
+		return errPing
+	})
+
+	require.ErrorIs(t, app.Start(), errPing)
+	require.False(t, app.Ready())
+}
@@ -0,0 +1,187 @@
+package xun
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"golang.org/x/net/websocket"
+)
+
+// websocketGoingAway is the close status of the connections closed by Shutdown.
+const websocketGoingAway = 1001
+
+// WebSocketHandler handles the connection of a WebSocket route. The
+// connection is closed when it returns.
+type WebSocketHandler func(c *Context, conn *WebSocketConn) error
+
+// WebSocketMessage is a message sent by the htmx ws extension with `ws-send`.
+type WebSocketMessage struct {
+	// Headers are the `HEADERS` of the message, e.g. HX-Trigger and HX-Target.
+	Headers map[string]string
+	// Values are the values of the form that is sent.
+	Values url.Values
+}
+
+// WebSocketConn is a WebSocket connection of app.WebSocket. It is safe to
+// send messages from multiple goroutines.
+type WebSocketConn struct {
+	ws *websocket.Conn
+	c  *Context
+	mu sync.Mutex
+}
+
+// WebSocket registers a WebSocket route for the htmx ws extension, e.g.
+// `<div hx-ext="ws" ws-connect="/chat">`. The connection is upgraded after the
+// middlewares pass, so they can authenticate the client, and the handler
+// receives the messages of `ws-send` forms and sends rendered fragments that
+// htmx swaps by their ids.
+//
+//	app.WebSocket("/chat", func(c *xun.Context, conn *xun.WebSocketConn) error {
+//		for {
+//			msg, err := conn.Receive()
+//			if err != nil {
+//				return err
+//			}
+//			if err := conn.Render("components/message", msg.Values.Get("text")); err != nil {
+//				return err
+//			}
+//		}
+//	})
+//
+// Cross-origin requests are rejected with 403 Forbidden. Errors of the
+// handler are logged, as the response can't be written after the upgrade.
+// The connections are closed with 1001 Going Away once the app starts
+// draining by Shutdown, so Receive returns io.EOF and the handler returns.
+func (app *App) WebSocket(pattern string, h WebSocketHandler, opts ...RoutingOption) {
+	app.Get(pattern, func(c *Context) error {
+		var err error
+		s := websocket.Server{
+			Handshake: checkWebSocketOrigin,
+			Handler: func(ws *websocket.Conn) {
+				defer ws.Close()
+
+				conn := &WebSocketConn{ws: ws, c: c}
+				done := make(chan struct{})
+				defer close(done)
+
+				go func() {
+					select {
+					case <-c.Draining():
+						conn.goingAway()
+					case <-done:
+					}
+				}()
+
+				err = h(c, conn)
+			},
+		}
+
+		c.writtenStatus = true // the connection is hijacked by the server
+		s.ServeHTTP(c.rw, c.req)
+
+		if err != nil && !errors.Is(err, ErrCancelled) && !errors.Is(err, io.EOF) {
+			app.logger.Error("xun: websocket", slog.String("pattern", pattern), slog.Any("err", err))
+		}
+
+		return nil
+	}, opts...)
+}
+
+// checkWebSocketOrigin accepts requests without Origin, e.g. from non-browser
+// clients, and the requests whose Origin matches the Host.
+func checkWebSocketOrigin(cfg *websocket.Config, r *http.Request) (err error) {
+	cfg.Origin, err = websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Origin == nil || cfg.Origin.Host == r.Host {
+		return nil
+	}
+
+	return ErrCrossOriginWebSocket
+}
+
+// Receive reads the next message of `ws-send`. It returns io.EOF if the
+// connection is closed.
+func (conn *WebSocketConn) Receive() (*WebSocketMessage, error) {
+	var raw map[string]any
+	if err := websocket.JSON.Receive(conn.ws, &raw); err != nil {
+		if errors.Is(err, net.ErrClosed) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+
+	msg := &WebSocketMessage{
+		Headers: make(map[string]string),
+		Values:  make(url.Values),
+	}
+
+	for k, v := range raw {
+		switch it := v.(type) {
+		case map[string]any:
+			if k != "HEADERS" {
+				continue
+			}
+			for name, value := range it {
+				if s, ok := value.(string); ok {
+					msg.Headers[name] = s
+				}
+			}
+		case []any:
+			for _, item := range it {
+				msg.Values.Add(k, fmt.Sprint(item))
+			}
+		default:
+			msg.Values.Set(k, fmt.Sprint(it))
+		}
+	}
+
+	return msg, nil
+}
+
+// Send sends the HTML as a text message, e.g. fragments with `hx-swap-oob`.
+func (conn *WebSocketConn) Send(html []byte) error {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	return websocket.Message.Send(conn.ws, string(html))
+}
+
+// Render renders the template name, a component or a view, with the data and
+// sends it. The htmx ws extension swaps it by its id, or the swap that is
+// added to its root element as `hx-swap-oob`, e.g. "beforeend:#messages".
+// The components the client doesn't have access to are omitted.
+func (conn *WebSocketConn) Render(name string, data any, swap ...string) error {
+	buf, err := conn.c.app.renderOOB(conn.c, name, data)
+	if err != nil {
+		return err
+	}
+
+	if len(swap) > 0 && swap[0] != "" {
+		buf = addSwapOOB(buf, swap[0])
+	}
+
+	return conn.Send(buf)
+}
+
+// goingAway sends the close frame of 1001 Going Away, and closes the connection.
+func (conn *WebSocketConn) goingAway() {
+	conn.mu.Lock()
+	defer conn.mu.Unlock()
+
+	conn.ws.WriteClose(websocketGoingAway) // nolint: errcheck
+	conn.ws.Close()                        // nolint: errcheck
+}
+
+// Close closes the connection.
+func (conn *WebSocketConn) Close() error {
+	return conn.ws.Close()
+}
@@ -0,0 +1,113 @@
+package xun
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+func TestWebSocket(t *testing.T) {
+	fsys := fstest.MapFS{
+		"components/message.html": {Data: []byte(`<li id="message">{{ . }}</li>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys))
+	defer app.Close()
+
+	app.WebSocket("/chat", func(c *Context, conn *WebSocketConn) error {
+		for {
+			msg, err := conn.Receive()
+			if err != nil {
+				return err
+			}
+
+			if err := conn.Render("components/message", msg.Headers["HX-Trigger"]+":"+strings.Join(msg.Values["text"], ","), msg.Values.Get("swap")); err != nil {
+				return err
+			}
+		}
+	})
+
+	app.Start()
+
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/chat"
+
+	t.Run("send_and_render", func(t *testing.T) {
+		ws, err := websocket.Dial(wsURL, "", srv.URL)
+		require.NoError(t, err)
+		defer ws.Close()
+
+		err = websocket.Message.Send(ws, `{"text":"hello","HEADERS":{"HX-Request":"true","HX-Trigger":"chat"}}`)
+		require.NoError(t, err)
+
+		var got string
+		require.NoError(t, websocket.Message.Receive(ws, &got))
+		require.Equal(t, `<li id="message">chat:hello</li>`, got)
+
+		err = websocket.Message.Send(ws, `{"text":["a","b"],"swap":"beforeend:#messages","HEADERS":{"HX-Trigger":"chat"}}`)
+		require.NoError(t, err)
+
+		require.NoError(t, websocket.Message.Receive(ws, &got))
+		require.Equal(t, `<li id="message" hx-swap-oob="beforeend:#messages">chat:a,b</li>`, got)
+	})
+
+	t.Run("cross_origin", func(t *testing.T) {
+		_, err := websocket.Dial(wsURL, "", "http://evil.example.com")
+		require.Error(t, err)
+	})
+
+	t.Run("not_upgraded", func(t *testing.T) {
+		resp, err := client.Get(srv.URL + "/chat")
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	})
+}
+
+func TestWebSocketDraining(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux))
+	defer app.Close()
+
+	returned := make(chan error, 1)
+	app.WebSocket("/chat", func(c *Context, conn *WebSocketConn) error {
+		for {
+			if _, err := conn.Receive(); err != nil {
+				returned <- err
+				return err
+			}
+		}
+	})
+
+	app.Start()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/chat", "", srv.URL)
+	require.NoError(t, err)
+	defer ws.Close()
+
+	require.Eventually(t, func() bool { return app.streams.len() == 1 }, time.Second, 10*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// the socket is closed on draining, so Shutdown doesn't wait for its deadline
+	require.NoError(t, app.Shutdown(ctx))
+	require.ErrorIs(t, <-returned, io.EOF)
+
+	var msg string
+	require.ErrorIs(t, websocket.Message.Receive(ws, &msg), io.EOF)
+}
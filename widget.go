@@ -0,0 +1,171 @@
+package xun
+
+import (
+	"bytes"
+	"html"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+)
+
+// WidgetPrefix is the URL prefix of the refresh endpoints of the widgets registered by app.Widget.
+const WidgetPrefix = "/_widgets"
+
+// WidgetRefreshEvent is the event that refreshes all widgets on the page, e.g.
+// triggered by the `HX-Trigger: refresh-widgets` header of a response.
+const WidgetRefreshEvent = "refresh-widgets"
+
+// Widget is a card of a dashboard, registered by app.Widget.
+type Widget struct {
+	// Name is the unique name of the widget, e.g. "sales".
+	Name string
+	// Title is the title in the header of the card.
+	Title string
+	// Size is added to the class of the card as `widget-{size}`, e.g. "wide".
+	Size string
+	// Access is the access required to see the widget, see WithAccess.
+	Access string
+	// View is the view or component that renders the data of the widget, e.g. "components/widgets/sales".
+	View string
+	// Refresh is the hx-trigger that refreshes the widget, e.g. "every 30s",
+	// in addition to WidgetRefreshEvent.
+	Refresh string
+	// Load returns the data of the widget. The data is nil if it isn't set.
+	Load func(c *Context) (any, error)
+}
+
+// Widget registers the widget w of dashboards, and its refresh endpoint at
+// WidgetPrefix/{name} that renders its card with the data returned by Load.
+// The `widgets` template function renders the cards of the widgets that the
+// client has access to, which are loaded by htmx after first paint.
+//
+//	app.Widget(xun.Widget{
+//		Name:    "sales",
+//		Title:   "Sales",
+//		Size:    "wide",
+//		Access:  "sales:view",
+//		View:    "components/widgets/sales",
+//		Refresh: "every 30s",
+//		Load: func(c *xun.Context) (any, error) {
+//			return db.Sales(c.Context())
+//		},
+//	})
+//
+//	<div class="dashboard">{{ widgets }}</div>
+//
+// The refresh endpoint responds 403 Forbidden if the client doesn't have the
+// access. It should be called after the App is created, when the view is loaded.
+func (app *App) Widget(w Widget, opts ...RoutingOption) {
+	if _, ok := app.widgetTemplate(w.View); !ok {
+		app.logger.Error("xun: widget view not found", slog.String("name", w.Name), slog.String("view", w.View))
+		return
+	}
+
+	app.widgets = append(app.widgets, &w)
+
+	app.Get(WidgetPrefix+"/"+url.PathEscape(w.Name), func(c *Context) error {
+		if !app.HasAccess(c.req, w.Access) {
+			return NewHttpError(http.StatusForbidden, "")
+		}
+
+		var data any
+		if w.Load != nil {
+			var err error
+			if data, err = w.Load(c); err != nil {
+				return err
+			}
+		}
+
+		t, ok := app.widgetTemplate(w.View)
+		if !ok {
+			return ErrViewerNotFound
+		}
+
+		buf := app.bufferPool().Get()
+		defer app.bufferPool().Put(buf)
+
+		writeWidgetCard(buf, &w, false)
+		if err := t.execute(buf, c.req, data); err != nil {
+			return err
+		}
+		buf.WriteString(`</div></section>`)
+
+		c.rw.Header().Set("Content-Type", "text/html; charset=utf-8")
+		c.WriteStatus(http.StatusOK)
+		_, err := buf.WriteTo(c.rw)
+		return err
+	}, opts...)
+}
+
+// Widgets returns the placeholders of the cards of the widgets that the client
+// of the request r has access to, in order of registration, or of names if
+// they are given. htmx swaps them with the cards of the refresh endpoints
+// after first paint. It is also available to templates as the `widgets`
+// function.
+//
+//	{{ widgets }}
+//	{{ widgets "sales" "orders" }}
+func (app *App) Widgets(r *http.Request, names ...string) template.HTML {
+	items := app.widgets
+	if len(names) > 0 {
+		items = make([]*Widget, 0, len(names))
+		for _, name := range names {
+			for _, w := range app.widgets {
+				if w.Name == name {
+					items = append(items, w)
+				}
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	for _, w := range items {
+		if !app.HasAccess(r, w.Access) {
+			continue
+		}
+
+		writeWidgetCard(&buf, w, true)
+		buf.WriteString(`</div></section>`)
+	}
+
+	return template.HTML(buf.String()) // nolint: gosec
+}
+
+// widgetTemplate returns the template of the view or component name.
+func (app *App) widgetTemplate(name string) (*HtmlTemplate, bool) {
+	if v, ok := app.viewers[name].(*HtmlViewer); ok {
+		return v.template, true
+	}
+
+	app.mu.RLock()
+	defer app.mu.RUnlock()
+
+	t, ok := app.components[name]
+	return t, ok
+}
+
+// writeWidgetCard writes the opening tags of the card of the widget, up to its
+// body. The placeholder is loaded on first paint, and the card is refreshed
+// by WidgetRefreshEvent and the Refresh of the widget.
+func writeWidgetCard(buf *bytes.Buffer, w *Widget, placeholder bool) {
+	class := "widget"
+	if w.Size != "" {
+		class += " widget-" + w.Size
+	}
+
+	trigger := WidgetRefreshEvent + " from:body"
+	if w.Refresh != "" {
+		trigger += ", " + w.Refresh
+	}
+	if placeholder {
+		trigger = "load"
+	}
+
+	buf.WriteString(`<section id="widget-` + html.EscapeString(w.Name) + `" class="` + html.EscapeString(class) + `"`)
+	buf.WriteString(` hx-get="` + html.EscapeString(WidgetPrefix+"/"+url.PathEscape(w.Name)) + `" hx-trigger="` + html.EscapeString(trigger) + `" hx-swap="outerHTML"`)
+	if placeholder {
+		buf.WriteString(` aria-busy="true"`)
+	}
+	buf.WriteString(`><header>` + html.EscapeString(w.Title) + `</header><div class="widget-body">`)
+}
@@ -0,0 +1,98 @@
+package xun
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWidget(t *testing.T) {
+	fsys := fstest.MapFS{
+		"pages/index.html":                {Data: []byte(`<main>{{ widgets }}</main>`)},
+		"pages/sales.html":                {Data: []byte(`<main>{{ widgets "sales" }}</main>`)},
+		"components/widgets/sales.html":   {Data: []byte(`<b>{{ .Total }}</b>`)},
+		"components/widgets/secrets.html": {Data: []byte(`<b>secret</b>`)},
+	}
+
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app := New(WithMux(mux), WithFsys(fsys), WithAccess(func(r *http.Request, access string) bool {
+		return r.Header.Get("X-Role") == "admin"
+	}))
+	defer app.Close()
+
+	app.Widget(Widget{
+		Name:    "sales",
+		Title:   "Sales & Orders",
+		Size:    "wide",
+		View:    "components/widgets/sales",
+		Refresh: "every 30s",
+		Load: func(c *Context) (any, error) {
+			return map[string]int{"Total": 42}, nil
+		},
+	})
+
+	app.Widget(Widget{
+		Name:   "secrets",
+		Title:  "Secrets",
+		Access: "admin:view",
+		View:   "components/widgets/secrets",
+	})
+
+	// missing views are not registered
+	app.Widget(Widget{Name: "missing", View: "components/widgets/missing"})
+
+	app.Start()
+
+	get := func(path, role string) (int, string) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("X-Role", role)
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		buf, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(buf)
+	}
+
+	salesPlaceholder := `<section id="widget-sales" class="widget widget-wide" hx-get="/_widgets/sales" hx-trigger="load" hx-swap="outerHTML" aria-busy="true"><header>Sales &amp; Orders</header><div class="widget-body"></div></section>`
+	secretsPlaceholder := `<section id="widget-secrets" class="widget" hx-get="/_widgets/secrets" hx-trigger="load" hx-swap="outerHTML" aria-busy="true"><header>Secrets</header><div class="widget-body"></div></section>`
+
+	t.Run("widgets", func(t *testing.T) {
+		status, body := get("/", "admin")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<main>`+salesPlaceholder+secretsPlaceholder+`</main>`, body)
+	})
+
+	t.Run("access", func(t *testing.T) {
+		status, body := get("/", "")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<main>`+salesPlaceholder+`</main>`, body)
+
+		status, _ = get("/_widgets/secrets", "")
+		require.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("names", func(t *testing.T) {
+		_, body := get("/sales", "admin")
+		require.Equal(t, `<main>`+salesPlaceholder+`</main>`, body)
+	})
+
+	t.Run("refresh", func(t *testing.T) {
+		status, body := get("/_widgets/sales", "")
+		require.Equal(t, http.StatusOK, status)
+		require.Equal(t, `<section id="widget-sales" class="widget widget-wide" hx-get="/_widgets/sales" hx-trigger="refresh-widgets from:body, every 30s" hx-swap="outerHTML"><header>Sales &amp; Orders</header><div class="widget-body"><b>42</b></div></section>`, body)
+
+		status, body = get("/_widgets/secrets", "admin")
+		require.Equal(t, http.StatusOK, status)
+		require.Contains(t, body, `<b>secret</b>`)
+	})
+}